@@ -14,7 +14,7 @@ import (
 )
 
 const (
-	LatestDbVersion     = 2 // CHANGE THIS when a new upgrade is needed
+	LatestDbVersion     = 3 // CHANGE THIS when a new upgrade is needed
 	IgorConfHome        = "/etc/igor/"
 	IgorConfFileDefault = "igor-server.yaml"
 	IgorConfPathDefault = IgorConfHome + IgorConfFileDefault
@@ -112,6 +112,16 @@ func main() {
 		// repeat formula from above
 		sqlFile = getNextSqlFile("migrations/migrate1to2.sql")
 		fmt.Println("Executing upgrade step: 1 -> 2")
+		if _, err := runNextUpgradeStep(sqlFile); err != nil {
+			fmt.Fprintf(os.Stderr, "There was an issue performing the upgrade: %v\n", err)
+			restoreBackup(sqliteDbLoc, backupPath)
+			os.Exit(1)
+		}
+	}
+
+	if userVersion == 2 {
+		sqlFile = getNextSqlFile("migrations/migrate2to3.sql")
+		fmt.Println("Executing upgrade step: 2 -> 3")
 		if isUpgraded, err := runNextUpgradeStep(sqlFile); err != nil {
 			fmt.Fprintf(os.Stderr, "There was an issue performing the upgrade: %v\n", err)
 			restoreBackup(sqliteDbLoc, backupPath)
@@ -0,0 +1,214 @@
+-- Disable the enforcement of foreign-keys constraints
+PRAGMA foreign_keys = off;
+-- Create "health_check_results" table
+CREATE TABLE `health_check_results` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `host_name` text NOT NULL,
+  `check` text NOT NULL,
+  `type` text NULL,
+  `passed` numeric NULL,
+  `detail` text NULL
+);
+-- Create index "idx_health_check_results_host_name" to table: "health_check_results"
+CREATE INDEX `idx_health_check_results_host_name` ON `health_check_results` (`host_name`);
+-- Create "host_status_records" table
+CREATE TABLE `host_status_records` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `host_name` text NOT NULL,
+  `prev_status` text NULL,
+  `status` text NOT NULL
+);
+-- Create index "idx_host_status_records_host_name" to table: "host_status_records"
+CREATE INDEX `idx_host_status_records_host_name` ON `host_status_records` (`host_name`);
+-- Create "host_attributes" table
+CREATE TABLE `host_attributes` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `host_id` integer NOT NULL,
+  `key` text NOT NULL,
+  `value` text NULL,
+  `mismatch` text NULL
+);
+-- Create index "idx_host_attr" to table: "host_attributes"
+CREATE UNIQUE INDEX `idx_host_attr` ON `host_attributes` (`host_id`, `key`);
+-- Add column "state_reason" to table: "hosts"
+ALTER TABLE `hosts` ADD COLUMN `state_reason` text NULL;
+-- Add column "repair_ticket" to table: "hosts"
+ALTER TABLE `hosts` ADD COLUMN `repair_ticket` text NULL;
+-- Add column "repair_return" to table: "hosts"
+ALTER TABLE `hosts` ADD COLUMN `repair_return` datetime NULL;
+-- Add column "switch_name" to table: "hosts"
+ALTER TABLE `hosts` ADD COLUMN `switch_name` text NULL;
+-- Create "vlan_pools" table
+CREATE TABLE `vlan_pools` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `name` text NOT NULL,
+  `description` text NULL,
+  `min` integer NULL,
+  `max` integer NULL
+);
+-- Create index "vlan_pools_name" to table: "vlan_pools"
+CREATE UNIQUE INDEX `vlan_pools_name` ON `vlan_pools` (`name`);
+-- Create "vlan_pools_groups" table
+CREATE TABLE `vlan_pools_groups` (
+  `vlan_pool_id` integer NULL,
+  `group_id` integer NULL,
+  PRIMARY KEY (`vlan_pool_id`, `group_id`),
+  CONSTRAINT `fk_vlan_pools_groups_group` FOREIGN KEY (`group_id`) REFERENCES `groups` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT `fk_vlan_pools_groups_vlan_pool` FOREIGN KEY (`vlan_pool_id`) REFERENCES `vlan_pools` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create "vlan_pools_policies" table
+CREATE TABLE `vlan_pools_policies` (
+  `vlan_pool_id` integer NULL,
+  `host_policy_id` integer NULL,
+  PRIMARY KEY (`vlan_pool_id`, `host_policy_id`),
+  CONSTRAINT `fk_vlan_pools_policies_host_policy` FOREIGN KEY (`host_policy_id`) REFERENCES `host_policies` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT `fk_vlan_pools_policies_vlan_pool` FOREIGN KEY (`vlan_pool_id`) REFERENCES `vlan_pools` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create "named_vlans" table
+CREATE TABLE `named_vlans` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `name` text NOT NULL,
+  `vlan_id` integer NOT NULL,
+  `description` text NULL,
+  `owner_id` integer NULL,
+  CONSTRAINT `fk_named_vlans_owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "named_vlans_name" to table: "named_vlans"
+CREATE UNIQUE INDEX `named_vlans_name` ON `named_vlans` (`name`);
+-- Create index "named_vlans_vlan_id" to table: "named_vlans"
+CREATE UNIQUE INDEX `named_vlans_vlan_id` ON `named_vlans` (`vlan_id`);
+-- Create "named_vlans_groups" table
+CREATE TABLE `named_vlans_groups` (
+  `named_vlan_id` integer NULL,
+  `group_id` integer NULL,
+  PRIMARY KEY (`named_vlan_id`, `group_id`),
+  CONSTRAINT `fk_named_vlans_groups_group` FOREIGN KEY (`group_id`) REFERENCES `groups` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT `fk_named_vlans_groups_named_vlan` FOREIGN KEY (`named_vlan_id`) REFERENCES `named_vlans` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create "host_interfaces" table
+CREATE TABLE `host_interfaces` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `host_id` integer NOT NULL,
+  `role` text NOT NULL,
+  `mac` text NOT NULL,
+  `eth` text NULL,
+  `switch_name` text NULL,
+  `name` text NULL,
+  CONSTRAINT `fk_hosts_interfaces` FOREIGN KEY (`host_id`) REFERENCES `hosts` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "host_interfaces_mac" to table: "host_interfaces"
+CREATE UNIQUE INDEX `host_interfaces_mac` ON `host_interfaces` (`mac`);
+-- Create index "idx_host_interfaces_host_id" to table: "host_interfaces"
+CREATE INDEX `idx_host_interfaces_host_id` ON `host_interfaces` (`host_id`);
+-- Add column "nic_roles" to table: "reservations"
+ALTER TABLE `reservations` ADD COLUMN `nic_roles` text NULL;
+-- Create "res_networks" table
+CREATE TABLE `res_networks` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `reservation_id` integer NOT NULL,
+  `name` text NOT NULL,
+  `vlan` integer NULL,
+  `hosts` text NULL,
+  `nic_roles` text NULL,
+  CONSTRAINT `fk_reservations_networks` FOREIGN KEY (`reservation_id`) REFERENCES `reservations` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_res_networks_reservation_id" to table: "res_networks"
+CREATE INDEX `idx_res_networks_reservation_id` ON `res_networks` (`reservation_id`);
+-- Add column "iso" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `iso` text NULL;
+-- Add column "disk" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `disk` text NULL;
+-- Add column "disk_format" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `disk_format` text NULL;
+-- Add column "disk_checksum" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `disk_checksum` text NULL;
+-- Create "boot_configs" table
+CREATE TABLE `boot_configs` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `path` text NOT NULL,
+  `host_name` text NULL,
+  `content` text NULL,
+  CONSTRAINT `uni_boot_configs_path` UNIQUE (`path`)
+);
+-- Create index "idx_boot_configs_host_name" to table: "boot_configs"
+CREATE INDEX `idx_boot_configs_host_name` ON `boot_configs` (`host_name`);
+-- Create "boot_stages" table
+CREATE TABLE `boot_stages` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `reservation_id` integer NOT NULL,
+  `host_name` text NOT NULL,
+  `stage` text NOT NULL,
+  `detail` text NULL,
+  CONSTRAINT `fk_reservations_boot_stages` FOREIGN KEY (`reservation_id`) REFERENCES `reservations` (`id`) ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_boot_stage" to table: "boot_stages"
+CREATE UNIQUE INDEX `idx_boot_stage` ON `boot_stages` (`reservation_id`, `host_name`, `stage`);
+-- Create "boot_templates" table
+CREATE TABLE `boot_templates` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `breed` text NOT NULL,
+  `version` integer NOT NULL,
+  `description` text NULL,
+  `author` text NULL,
+  `template` text NOT NULL
+);
+-- Create index "idx_boot_template" to table: "boot_templates"
+CREATE UNIQUE INDEX `idx_boot_template` ON `boot_templates` (`breed`, `version`);
+-- Add column "template" to table: "kickstarts"
+ALTER TABLE `kickstarts` ADD COLUMN `template` numeric NULL;
+-- Add column "vars" to table: "profiles"
+ALTER TABLE `profiles` ADD COLUMN `vars` text NULL;
+-- Add column "user_data" to table: "profiles"
+ALTER TABLE `profiles` ADD COLUMN `user_data` text NULL;
+-- Create "user_ssh_keys" table
+CREATE TABLE `user_ssh_keys` (
+  `id` integer NULL PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime NULL,
+  `updated_at` datetime NULL,
+  `deleted_at` datetime NULL,
+  `user_id` integer NOT NULL,
+  `name` text NOT NULL,
+  `key` text NOT NULL,
+  `fingerprint` text NOT NULL
+);
+-- Create index "idx_user_key_name" to table: "user_ssh_keys"
+CREATE UNIQUE INDEX `idx_user_key_name` ON `user_ssh_keys` (`user_id`, `name`);
+-- Create index "idx_user_key_fp" to table: "user_ssh_keys"
+CREATE UNIQUE INDEX `idx_user_key_fp` ON `user_ssh_keys` (`user_id`, `fingerprint`);
+-- Add column "status" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `status` text NOT NULL DEFAULT 'ready';
+-- Add column "validation" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `validation` text NULL;
+-- Add column "approved_by" to table: "distro_images"
+ALTER TABLE `distro_images` ADD COLUMN `approved_by` text NULL;
+PRAGMA foreign_keys = on;
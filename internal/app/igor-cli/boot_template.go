@@ -0,0 +1,379 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"igor2/internal/pkg/api"
+	"igor2/internal/pkg/common"
+)
+
+func newBootTemplateCmd() *cobra.Command {
+
+	cmdBootTemplate := &cobra.Command{
+		Use:   "boottemplate",
+		Short: "Perform a boot template command " + adminOnly,
+		Long: `
+Boot template primary command. A sub-command must be invoked to do anything.
+
+A boot template renders the kernel arguments igor puts in a host's PXE config
+when it installs a distro image of a given breed, for example telling the
+installer where its kickstart is. Each breed has a built-in template (version 0)
+and admins can save new versions. The latest saved version of a breed is used
+for every reservation install after it's saved.
+
+Templates use Go text/template syntax and are given these fields:
+
+  .BootMode      bios, uefi or ipxe
+  .KickstartURL  blank unless the image installs with a kickstart
+  .NoCloudURL    seed url of igor's cloud-init NoCloud datasource, used as
+                 ds=nocloud;s=URL (quote it in grub configs)
+  .Host          .Name .IP .Mac
+  .Reservation   .Name .Owner .Group .Distro .Profile .Vlan
+  .Image         .Name .ImageID .Type .Breed .Kernel .Initrd
+
+` + sBold("All boot template commands are admin-only.") + `
+`,
+	}
+
+	cmdBootTemplate.AddCommand(newBootTemplateCreateCmd())
+	cmdBootTemplate.AddCommand(newBootTemplateShowCmd())
+	cmdBootTemplate.AddCommand(newBootTemplateDelCmd())
+	cmdBootTemplate.AddCommand(newBootTemplateRenderCmd())
+	return cmdBootTemplate
+}
+
+func newBootTemplateCreateCmd() *cobra.Command {
+
+	cmdCreateBootTemplate := &cobra.Command{
+		Use:   "create BREED {-f FILE | --from-version VER} [-d DESC]",
+		Short: "Save a new boot template version " + adminOnly,
+		Long: `
+Saves a new version of a breed's boot template. The new version is used for
+every reservation install after this. The template is tried with sample data
+in each boot mode before it's saved.
+
+` + requiredArgs + `
+
+  BREED : distro breed the template is for, ex. redhat
+
+` + requiredFlags + `
+
+Use the -f flag to give the file holding the template.
+
+Or use the --from-version flag to save an older version again, which rolls the
+breed back to it. Use 0 for the built-in template.
+
+` + optionalFlags + `
+
+Use the -d flag to describe the change.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flagset := cmd.Flags()
+			file, _ := flagset.GetString("file")
+			desc, _ := flagset.GetString("desc")
+			fromVersion := -1
+			if flagset.Changed("from-version") {
+				fromVersion, _ = flagset.GetInt("from-version")
+			}
+			res, err := doCreateBootTemplate(args[0], file, fromVersion, desc)
+			if err != nil {
+				return err
+			}
+			printRespSimple(res)
+			return nil
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return []string{"BREED"}, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+
+	var file, desc string
+	var fromVersion int
+	cmdCreateBootTemplate.Flags().StringVarP(&file, "file", "f", "", "file holding the template")
+	cmdCreateBootTemplate.Flags().IntVar(&fromVersion, "from-version", 0, "save an existing version again")
+	cmdCreateBootTemplate.Flags().StringVarP(&desc, "desc", "d", "", "description of the change")
+	cmdCreateBootTemplate.MarkFlagsMutuallyExclusive("file", "from-version")
+	cmdCreateBootTemplate.MarkFlagsOneRequired("file", "from-version")
+	_ = registerFlagArgsFunc(cmdCreateBootTemplate, "file", []string{"FILENAME"})
+	_ = registerFlagArgsFunc(cmdCreateBootTemplate, "from-version", []string{"VER"})
+	_ = registerFlagArgsFunc(cmdCreateBootTemplate, "desc", []string{"\"DESCRIPTION\""})
+
+	return cmdCreateBootTemplate
+}
+
+func newBootTemplateShowCmd() *cobra.Command {
+
+	cmdShowBootTemplate := &cobra.Command{
+		Use:   "show [-b BREED1,...] [--all] [-x]",
+		Short: "Show boot templates " + adminOnly,
+		Long: `
+Shows the boot template each breed uses.
+
+` + optionalFlags + `
+
+Use the -b flag to only show the given breeds.
+
+Use the --all flag to show every version instead of only the one in use. The
+version in use is marked with a *.
+
+Use the -x flag to render screen output without pretty formatting.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			breeds, _ := flagset.GetStringSlice("breeds")
+			all, _ := flagset.GetBool("all")
+			simplePrint = flagset.Changed("simple")
+			printBootTemplates(doShowBootTemplates(breeds, all))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNoArgs,
+	}
+
+	var breeds []string
+	var all bool
+	cmdShowBootTemplate.Flags().StringSliceVarP(&breeds, "breeds", "b", nil, "comma-delimited list of breeds to show")
+	cmdShowBootTemplate.Flags().BoolVar(&all, "all", false, "show every version")
+	cmdShowBootTemplate.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
+	_ = registerFlagArgsFunc(cmdShowBootTemplate, "breeds", []string{"BREED1"})
+
+	return cmdShowBootTemplate
+}
+
+func newBootTemplateDelCmd() *cobra.Command {
+
+	cmdDelBootTemplate := &cobra.Command{
+		Use:   "del BREED [--version VER]",
+		Short: "Delete boot template versions " + adminOnly,
+		Long: `
+Deletes saved versions of a breed's boot template. If the version in use is
+deleted the breed goes back to the latest version left, or its built-in
+template if there are none.
+
+` + requiredArgs + `
+
+  BREED : distro breed
+
+` + optionalFlags + `
+
+Use the --version flag to delete only that version. Without it every saved
+version of the breed is deleted.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			version, _ := cmd.Flags().GetInt("version")
+			printRespSimple(doDeleteBootTemplate(args[0], version))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var version int
+	cmdDelBootTemplate.Flags().IntVar(&version, "version", 0, "version to delete")
+	_ = registerFlagArgsFunc(cmdDelBootTemplate, "version", []string{"VER"})
+
+	return cmdDelBootTemplate
+}
+
+func newBootTemplateRenderCmd() *cobra.Command {
+
+	cmdRenderBootTemplate := &cobra.Command{
+		Use:   "render HOST [--distro DISTRO] [--boot-mode MODE] [-f FILE]",
+		Short: "Preview the boot config of a host " + adminOnly,
+		Long: `
+Shows the PXE config igor would write for a host without writing it. The
+host's active reservation is used unless a distro is given.
+
+` + requiredArgs + `
+
+  HOST : host name
+
+` + optionalFlags + `
+
+Use the --distro flag to render the config for the given distro instead of the
+host's reservation.
+
+Use the --boot-mode flag to render the config for a boot mode other than the
+host's own (bios, uefi or ipxe).
+
+Use the -f flag to try a template file instead of the active template of the
+image breed. Nothing is saved.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flagset := cmd.Flags()
+			distro, _ := flagset.GetString("distro")
+			mode, _ := flagset.GetString("boot-mode")
+			file, _ := flagset.GetString("file")
+			rb, err := doRenderBootTemplate(args[0], distro, mode, file)
+			if err != nil {
+				return err
+			}
+			printBootRender(rb)
+			return nil
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var distro, mode, file string
+	cmdRenderBootTemplate.Flags().StringVar(&distro, "distro", "", "distro to render the config for")
+	cmdRenderBootTemplate.Flags().StringVar(&mode, "boot-mode", "", "boot mode to render the config for")
+	cmdRenderBootTemplate.Flags().StringVarP(&file, "file", "f", "", "template file to try")
+	_ = registerFlagArgsFunc(cmdRenderBootTemplate, "distro", []string{"DISTRO"})
+	_ = registerFlagArgsFunc(cmdRenderBootTemplate, "boot-mode", []string{"bios", "uefi", "ipxe"})
+	_ = registerFlagArgsFunc(cmdRenderBootTemplate, "file", []string{"FILENAME"})
+
+	return cmdRenderBootTemplate
+}
+
+func doCreateBootTemplate(breed, file string, fromVersion int, desc string) (*common.ResponseBodyBasic, error) {
+	params := map[string]interface{}{"breed": breed}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		params["template"] = string(data)
+	} else {
+		params["fromVersion"] = fromVersion
+	}
+	if desc != "" {
+		params["description"] = desc
+	}
+	body := doSend(http.MethodPost, api.BootTemplates, params)
+	return unmarshalBasicResponse(body), nil
+}
+
+func doShowBootTemplates(breeds []string, all bool) *common.ResponseBodyBootTemplates {
+	var params string
+	for _, b := range breeds {
+		params += "breed=" + b + "&"
+	}
+	if all {
+		params += "all=true&"
+	}
+	if params != "" {
+		params = "?" + strings.TrimSuffix(params, "&")
+	}
+	body := doSend(http.MethodGet, api.BootTemplates+params, nil)
+	rb := common.ResponseBodyBootTemplates{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func doDeleteBootTemplate(breed string, version int) *common.ResponseBodyBasic {
+	apiPath := api.BootTemplates + "/" + breed
+	if version > 0 {
+		apiPath += "?version=" + strconv.Itoa(version)
+	}
+	body := doSend(http.MethodDelete, apiPath, nil)
+	return unmarshalBasicResponse(body)
+}
+
+func doRenderBootTemplate(host, distro, mode, file string) (*common.ResponseBodyBootRender, error) {
+	params := map[string]interface{}{"host": host}
+	if distro != "" {
+		params["distro"] = distro
+	}
+	if mode != "" {
+		params["bootMode"] = mode
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		params["template"] = string(data)
+	}
+	body := doSend(http.MethodPost, api.BootTemplatesRender, params)
+	rb := common.ResponseBodyBootRender{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb, nil
+}
+
+func printBootTemplates(rb *common.ResponseBodyBootTemplates) {
+
+	checkAndSetColorLevel(rb)
+
+	btList := rb.Data["bootTemplates"]
+	if len(btList) == 0 {
+		printSimple("no boot templates to show", cRespWarn)
+		return
+	}
+
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"BREED", "VERSION", "DESCRIPTION", "AUTHOR", "CREATED", "TEMPLATE"})
+
+	for _, bt := range btList {
+		version := strconv.Itoa(bt.Version)
+		if bt.Active {
+			version += "*"
+		}
+		created := ""
+		if bt.Created > 0 {
+			created = getLocTime(time.Unix(bt.Created, 0)).Format(common.DateTimeCompactFormat)
+		}
+		tw.AppendRow([]interface{}{
+			bt.Breed,
+			version,
+			bt.Description,
+			bt.Author,
+			created,
+			bt.Template,
+		})
+	}
+
+	if simplePrint {
+		tw.Style().Options.SeparateRows = false
+		tw.Style().Options.SeparateColumns = true
+		tw.Style().Options.DrawBorder = false
+	} else {
+		tw.SetStyle(igorTableStyle)
+	}
+
+	fmt.Printf("\n%s\n\n", tw.Render())
+}
+
+func printBootRender(rb *common.ResponseBodyBootRender) {
+
+	checkAndSetColorLevel(rb)
+
+	render := rb.Data["render"]
+	version := "template from file"
+	if render.Version == 0 {
+		version = "built-in template"
+	} else if render.Version > 0 {
+		version = fmt.Sprintf("template version %d", render.Version)
+	}
+	fmt.Printf("\nhost %s, reservation %s, breed %s, %s boot, %s\n\n%s\n", render.Host, render.Reservation,
+		render.Breed, render.BootMode, version, render.Config)
+}
@@ -25,6 +25,9 @@ const (
 	BgBlocked    = 3   // node blocked (yellow)
 	BgRestricted = 213 // node restricted from user (bright pink)
 	BgError      = 9   // node install error (bright red)
+	BgRepair     = 208 // node out for repair (dark orange)
+	BgBurnIn     = 30  // node in burn-in (teal)
+	BgDecomm     = 236 // node decommissioned (dark gray)
 )
 
 var (
@@ -40,6 +43,9 @@ var (
 	cInstError         = color.S256(FgUp, BgError).AddOpts(color.OpBold)
 	cBlockedUp         = color.S256(FgUp, BgBlocked).AddOpts(color.OpBold)
 	cRestrictedUp      = color.S256(FgUp, BgRestricted)
+	cRepair            = color.S256(FgUp, BgRepair).AddOpts(color.OpBold)
+	cBurnIn            = color.S256(FgUp, BgBurnIn).AddOpts(color.OpBold)
+	cDecommissioned    = color.S256(248, BgDecomm)
 
 	cL3NoRes = color.S256(123, BgUnreserved).AddOpts(color.OpBold)
 	cL3Res   = color.S256(123, BgResYes).AddOpts(color.OpBold)
@@ -9,9 +9,12 @@ import (
 	"fmt"
 	"igor2/internal/pkg/api"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"igor2/internal/pkg/common"
 
@@ -37,6 +40,11 @@ needed. This includes assigning a policy to a host.
 	cmdHost.AddCommand(newHostDelCmd())
 	cmdHost.AddCommand(newHostBlockCmd())
 	cmdHost.AddCommand(newHostUnblockCmd())
+	cmdHost.AddCommand(newHostStateCmd())
+	cmdHost.AddCommand(newHostHealthCmd())
+	cmdHost.AddCommand(newHostStatusHistoryCmd())
+	cmdHost.AddCommand(newHostImportCmd())
+	cmdHost.AddCommand(newHostExportCmd())
 	return cmdHost
 }
 
@@ -45,7 +53,7 @@ func newHostShowCmd() *cobra.Command {
 	cmdShowHosts := &cobra.Command{
 		Use: "show [-n NODES] [-d HOSTNAME1,...] [-e ETH1,...] [-i IP1,...]\n" +
 			"       [-p POL1,...] [-m MACID1,...] [-s STATE1,...] [-r RES1,...]\n" +
-			"       [--powered {true|false}] [-x]",
+			"       [--powered {true|false}] [--detail] [-x]",
 		Short: "Show host information",
 		Long: `
 Shows host information, returning matches to specified parameters. If no 
@@ -90,8 +98,16 @@ Use -n NODES to filter by name list or range of hosts:
 Use the --powered flag to only display powered nodes. Set it to false to only 
 display unpowered nodes.
 
+Use the --detail flag to also list the hardware inventory each node last
+reported to igor (CPU, memory, disks, NICs and firmware). Facts that don't
+match igor's record for the node, such as an unexpected MAC address, are
+flagged. A node reports its inventory by POSTing to ` + api.CbInventory + `
+on the igor callback service.
+
 When searching by state (-s) acceptable parameters are ` + sBold("available") + `, ` + sBold("reserved") + `,
-` + sBold("blocked") + ` and ` + sBold("error") + `.
+` + sBold("blocked") + `, ` + sBold("error") + `, ` + sBold("repair") + `, ` + sBold("burn-in") + ` and ` + sBold("decommissioned") + `. Hosts in
+repair, burn-in or decommissioned also show the reason given by the admin who
+changed their state (see 'igor host state').
 
 Use the -x flag to render screen output without pretty formatting.
 `,
@@ -108,12 +124,17 @@ Use the -x flag to render screen output without pretty formatting.
 			reservations, _ := flagset.GetStringSlice("reservations")
 			states, _ := flagset.GetStringSlice("states")
 			simplePrint = flagset.Changed("simple")
+			detail := flagset.Changed("detail")
 			var powered *bool
 			if flagset.Changed("powered") {
 				poweredVal, _ := flagset.GetBool("powered")
 				powered = &poweredVal
 			}
-			printHosts(doShowHosts(names, hostnames, eths, ips, macs, policies, reservations, states, powered))
+			rb := doShowHosts(names, hostnames, eths, ips, macs, policies, reservations, states, powered, detail)
+			printHosts(rb)
+			if detail {
+				printHostAttributes(rb)
+			}
 			return nil
 		},
 		DisableFlagsInUseLine: true,
@@ -129,7 +150,8 @@ Use the -x flag to render screen output without pretty formatting.
 		reservations,
 		states []string
 	var names string
-	var powerVal bool
+	var powerVal,
+		detail bool
 
 	cmdShowHosts.Flags().StringVarP(&names, "nodes", "n", "", "node list or range")
 	cmdShowHosts.Flags().StringSliceVarP(&hostnames, "hostnames", "d", nil, "comma-delimited hostname list")
@@ -140,9 +162,10 @@ Use the -x flag to render screen output without pretty formatting.
 	cmdShowHosts.Flags().StringSliceVarP(&reservations, "reservations", "r", nil, "comma-delimited reservation list")
 	cmdShowHosts.Flags().StringSliceVarP(&states, "states", "s", nil, "comma-delimited state list")
 	cmdShowHosts.Flags().BoolVar(&powerVal, "powered", true, "filter on powered or unpowered nodes")
+	cmdShowHosts.Flags().BoolVar(&detail, "detail", false, "include reported hardware inventory")
 	cmdShowHosts.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
 
-	_ = registerFlagArgsFunc(cmdShowHosts, "states", []string{"available", "reserved", "blocked", "error"})
+	_ = registerFlagArgsFunc(cmdShowHosts, "states", []string{"available", "reserved", "blocked", "error", "repair", "burn-in", "decommissioned"})
 	_ = registerFlagArgsFunc(cmdShowHosts, "names", []string{"NODES"})
 	_ = registerFlagArgsFunc(cmdShowHosts, "hostnames", []string{"HOSTNAME1"})
 	_ = registerFlagArgsFunc(cmdShowHosts, "IPs", []string{"IP1"})
@@ -157,7 +180,7 @@ Use the -x flag to render screen output without pretty formatting.
 func newHostEditCmd() *cobra.Command {
 
 	cmdEditHost := &cobra.Command{
-		Use:   "edit NAME {[-p POLICY] [-d HOSTNAME] [-b BOOT] [-e ETH] [-i IP] [-m MACID]}",
+		Use:   "edit NAME {[-p POLICY] [-d HOSTNAME] [-b BOOT] [-e ETH] [-s SWITCH] [-i IP] [-m MACID] [--nics NICS]}",
 		Short: "Edit host information " + adminOnly,
 		Long: `
 Edits host information.
@@ -183,14 +206,30 @@ the sequence of hosts given in the same config file. Igor uses this hostname to
 communicate with the host itself. If the actual hostname is different, specify
 it here and igor will use this hostname instead.
 
-Use the -b flag to change the boot type of the host (bios or uefi).
+Use the -b flag to change the boot type of the host (bios, uefi or ipxe).
+Hosts set to ipxe fetch their kernel and initrd over HTTP from the igor server
+using URLs that only work for that host during its reservation.
 
 Use the -i flag to change the host's IP.
 
-Use the -e flag to change the host's ethernet switch identifier.
+Use the -e flag to change the host's ethernet switch identifier (the port on
+its switch).
+
+Use the -s flag to change the switch the host is connected to. The name must
+match a switch in the server's vlan.switches config.
 
 Use the -m flag to change the MAC address.
 
+The -p, -e, -s and -m flags describe the host's primary network interface.
+Use the --nics flag to set the host's other interfaces, replacing any it had
+before. Entries are separated by semicolons and each is a comma-separated list
+of key=value pairs. The role and mac keys are required; eth and switch give
+the switch port the interface is cabled to, and name is the interface name on
+the host OS. Roles are labels such as 'data' or 'fabric' that reservations use
+to choose which interfaces join their vlan. Use --nics "" to remove them all.
+
+  --nics "role=data,mac=00:11:22:33:44:55,eth=Et5;role=fabric,mac=00:11:22:33:44:66,eth=Et1/1,switch=ib1"
+
 ` + adminOnlyBanner + `
 `,
 		Args: cobra.ExactArgs(1),
@@ -202,7 +241,13 @@ Use the -m flag to change the MAC address.
 			ip, _ := flagset.GetString("ip")
 			eth, _ := flagset.GetString("eth")
 			mac, _ := flagset.GetString("mac")
-			printRespSimple(doEditHost(args[0], boot, hostname, hostPolicy, ip, eth, mac))
+			switchName, _ := flagset.GetString("switch")
+			var nics *string
+			if flagset.Changed("nics") {
+				n, _ := flagset.GetString("nics")
+				nics = &n
+			}
+			printRespSimple(doEditHost(args[0], boot, hostname, hostPolicy, ip, eth, switchName, mac, nics))
 		},
 		DisableFlagsInUseLine: true,
 		ValidArgsFunction:     validateNameArg,
@@ -213,19 +258,25 @@ Use the -m flag to change the MAC address.
 		eth,
 		hostname,
 		hostPolicy,
+		switchName,
+		nics,
 		mac string
 
 	cmdEditHost.Flags().StringVarP(&hostPolicy, "policy", "p", "", "name of policy to assign to this host")
 	cmdEditHost.Flags().StringVarP(&hostname, "hostname", "d", "", "hostname of the host")
-	cmdEditHost.Flags().StringVarP(&boot, "boot", "b", "", "boot type of the host (bios or uefi)")
+	cmdEditHost.Flags().StringVarP(&boot, "boot", "b", "", "boot type of the host (bios, uefi or ipxe)")
 	cmdEditHost.Flags().StringVarP(&ip, "ip", "i", "", "ipv4 address")
 	cmdEditHost.Flags().StringVarP(&mac, "mac", "m", "", "MAC address")
 	cmdEditHost.Flags().StringVarP(&eth, "eth", "e", "", "eth config string")
+	cmdEditHost.Flags().StringVarP(&switchName, "switch", "s", "", "name of the switch the host is connected to")
+	cmdEditHost.Flags().StringVar(&nics, "nics", "", "semicolon-delimited list of additional interfaces")
 	_ = registerFlagArgsFunc(cmdEditHost, "policy", []string{"POLICY"})
 	_ = registerFlagArgsFunc(cmdEditHost, "hostname", []string{"HOSTNAME"})
 	_ = registerFlagArgsFunc(cmdEditHost, "ip", []string{"IP"})
 	_ = registerFlagArgsFunc(cmdEditHost, "mac", []string{"MACID"})
 	_ = registerFlagArgsFunc(cmdEditHost, "eth", []string{"ETH"})
+	_ = registerFlagArgsFunc(cmdEditHost, "switch", []string{"SWITCH"})
+	_ = registerFlagArgsFunc(cmdEditHost, "nics", []string{"\"role=ROLE,mac=MACID,...\""})
 
 	return cmdEditHost
 }
@@ -252,6 +303,9 @@ system.
 A host cannot be deleted if it has associated reservations. They must expire,
 be deleted, or edited to drop the node first.
 
+To retire a host while keeping its history, use 'igor host state
+decommissioned' instead.
+
 ` + adminOnlyBanner + `
 `,
 		Args: cobra.ExactArgs(1),
@@ -416,7 +470,279 @@ Once executed the specified hosts will be able to accept reservations.
 	return cmdUnblockHosts
 }
 
-func doShowHosts(names string, hostnames []string, eths []string, ips []string, macs []string, hostPolicies []string, reservations []string, states []string, powered *bool) *common.ResponseBodyHosts {
+func newHostStateCmd() *cobra.Command {
+
+	cmdStateHosts := &cobra.Command{
+		Use:   "state STATE NODES -r REASON [-t TICKET] [--return DATETIME]",
+		Short: "Change the lifecycle state of hosts " + adminOnly,
+		Long: `
+Moves one or more hosts into a lifecycle state. Every change requires a reason
+which is kept with the host and shown by 'igor host show'.
+
+` + requiredArgs + `
+
+  STATE  - the new state of the hosts, one of:
+    * ` + sBold("repair") + `         : the host is out for repair. A ticket reference is
+                       required and an expected return date may be given.
+    * ` + sBold("burn-in") + `        : the host is being burned in. Only admins can reserve
+                       it, and it must be asked for by name. Reservations of
+                       other users must be removed first.
+    * ` + sBold("decommissioned") + ` : the host is retired. It is never scheduled but is
+                       kept so its history isn't lost.
+    * ` + sBold("available") + `      : return a host in repair, burn-in, decommissioned
+                       or error to the reservable pool.
+
+  NODES  - a name list or range of hosts
+    * name list is comma-delimited: kn1,kn2,kn3,...
+    * range is the form prefix[n,m-n,...] where m,n are integers representing
+      a single or contiguous ranges of hosts, ex. kn[3,7-9,22-35,47]
+
+  -r REASON  - the reason for the change
+
+` + optionalFlags + `
+
+Use the -t flag to give the ticket reference of a repair.
+
+Use the --return flag to give the date a host in repair is expected back. The
+format is ` + exEndDts() + ` (` + common.DateTimeCompactFormat + `).
+
+` + notesOnUsage + `
+
+A host cannot be moved to repair or decommissioned if it has any current or
+future reservation; the reservation must expire, be deleted, or edited to drop
+the node first. Blocked hosts must be returned with 'igor host unblock'.
+
+All hosts must be able to make the change or none of them are changed.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			reason, _ := flagset.GetString("reason")
+			ticket, _ := flagset.GetString("ticket")
+			returnDate, _ := flagset.GetString("return")
+			printRespSimple(doStateHost(args[0], args[1], reason, ticket, returnDate))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return []string{"repair", "burn-in", "decommissioned", "available"}, cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return []string{"NODES"}, cobra.ShellCompDirectiveNoFileComp
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	var reason,
+		ticket,
+		returnDate string
+
+	cmdStateHosts.Flags().StringVarP(&reason, "reason", "r", "", "reason for the state change")
+	cmdStateHosts.Flags().StringVarP(&ticket, "ticket", "t", "", "repair ticket reference")
+	cmdStateHosts.Flags().StringVar(&returnDate, "return", "", "expected return date of a repair")
+	_ = cmdStateHosts.MarkFlagRequired("reason")
+	_ = registerFlagArgsFunc(cmdStateHosts, "reason", []string{"REASON"})
+	_ = registerFlagArgsFunc(cmdStateHosts, "ticket", []string{"TICKET"})
+	_ = registerFlagArgsFunc(cmdStateHosts, "return", []string{"DATETIME"})
+
+	return cmdStateHosts
+}
+
+func newHostHealthCmd() *cobra.Command {
+
+	cmdHostHealth := &cobra.Command{
+		Use:   "health NAME [-c CHECK1,...] [-l LIMIT] [-x]",
+		Short: "Show host health check history",
+		Long: `
+Shows the results of health checks run against a host, newest first. Health
+checks are configured by igor admins and may include HTTP endpoint checks,
+admin-supplied scripts and BMC sensor thresholds.
+
+` + requiredArgs + `
+
+  NAME : host name
+
+` + optionalFlags + `
+
+Use the -c flag to only show results for the named check(s).
+
+Use the -l flag to limit the number of results returned.
+
+Use the -x flag to render screen output without pretty formatting.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			checks, _ := flagset.GetStringSlice("checks")
+			limit, _ := flagset.GetInt("limit")
+			simplePrint = flagset.Changed("simple")
+			printHostHealth(doShowHostHealth(args[0], checks, limit))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var checks []string
+	var limit int
+
+	cmdHostHealth.Flags().StringSliceVarP(&checks, "checks", "c", nil, "comma-delimited check name list")
+	cmdHostHealth.Flags().IntVarP(&limit, "limit", "l", 0, "max number of results to show")
+	cmdHostHealth.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
+	_ = registerFlagArgsFunc(cmdHostHealth, "checks", []string{"CHECK1"})
+	_ = registerFlagArgsFunc(cmdHostHealth, "limit", []string{"LIMIT"})
+
+	return cmdHostHealth
+}
+
+func newHostStatusHistoryCmd() *cobra.Command {
+
+	cmdStatusHistory := &cobra.Command{
+		Use:   "status-history NAME [-d DAYS] [-l LIMIT] [-x]",
+		Short: "Show host power/network status changes",
+		Long: `
+Shows the recorded changes in a host's power/network status, newest first.
+Statuses are the same as those shown by 'igor host show': off, on, ping, up and
+unknown.
+
+A host that changes status many times within a short period is flagged as
+flapping. This is often a sign of failing hardware or a network problem.
+
+` + requiredArgs + `
+
+  NAME : host name
+
+` + optionalFlags + `
+
+Use the -d flag to set the number of days of history to show. The default is
+7 days.
+
+Use the -l flag to limit the number of changes returned.
+
+Use the -x flag to render screen output without pretty formatting.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			days, _ := flagset.GetInt("days")
+			limit, _ := flagset.GetInt("limit")
+			simplePrint = flagset.Changed("simple")
+			printHostStatusHistory(doShowHostStatusHistory(args[0], days, limit))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var days, limit int
+
+	cmdStatusHistory.Flags().IntVarP(&days, "days", "d", 0, "number of days of history to show")
+	cmdStatusHistory.Flags().IntVarP(&limit, "limit", "l", 0, "max number of changes to show")
+	cmdStatusHistory.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
+	_ = registerFlagArgsFunc(cmdStatusHistory, "days", []string{"DAYS"})
+	_ = registerFlagArgsFunc(cmdStatusHistory, "limit", []string{"LIMIT"})
+
+	return cmdStatusHistory
+}
+
+func newHostImportCmd() *cobra.Command {
+
+	cmdImportHosts := &cobra.Command{
+		Use:   "import FILE [-f FORMAT] [--preview]",
+		Short: "Create and update hosts from a CSV or YAML file " + adminOnly,
+		Long: `
+Creates and updates hosts from a CSV or YAML file, such as an export from a
+CMDB. Each entry in the file is the full description of a host, so blank
+optional fields reset the host to its default value. Hosts not in the file are
+left alone.
+
+` + requiredArgs + `
+
+  FILE : path to the CSV or YAML file
+
+` + optionalFlags + `
+
+Use the -f flag to give the file format, either csv or yaml. If not given the
+format is taken from the file extension.
+
+Use the --preview flag to show the changes the import would make without
+applying them.
+
+` + notesOnUsage + `
+
+A CSV file must have a header row. The allowed columns are:
+
+  name,hostname,mac,ip,eth,switch,bootMode,policy
+
+Only name, mac and ip are required. Host names must follow the cluster naming
+convention (prefix followed by a number). The YAML format is the same as the
+igor-clusters.yaml file and the output of 'igor host export -f yaml'.
+
+Host names, hostnames, MAC and IP addresses must be unique across the cluster.
+If any entry is invalid, nothing is changed. New hosts are created in the
+blocked state and must be unblocked before they can be reserved.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			format, _ := flagset.GetString("format")
+			preview := flagset.Changed("preview")
+			printHostImport(doImportHosts(args[0], format, preview), preview)
+		},
+		DisableFlagsInUseLine: true,
+	}
+
+	var format string
+	var preview bool
+
+	cmdImportHosts.Flags().StringVarP(&format, "format", "f", "", "file format, csv or yaml")
+	cmdImportHosts.Flags().BoolVar(&preview, "preview", false, "show changes without applying them")
+	_ = registerFlagArgsFunc(cmdImportHosts, "format", []string{"csv", "yaml"})
+
+	return cmdImportHosts
+}
+
+func newHostExportCmd() *cobra.Command {
+
+	cmdExportHosts := &cobra.Command{
+		Use:   "export [-f FORMAT] [-o FILE]",
+		Short: "Export hosts as CSV or YAML",
+		Long: `
+Exports all cluster hosts as CSV or YAML. The output can be edited and passed
+back to 'igor host import'.
+
+` + optionalFlags + `
+
+Use the -f flag to choose the format, either csv (default) or yaml.
+
+Use the -o flag to write the export to a file instead of the screen.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			format, _ := flagset.GetString("format")
+			outFile, _ := flagset.GetString("out")
+			doExportHosts(format, outFile)
+		},
+		DisableFlagsInUseLine: true,
+	}
+
+	var format,
+		outFile string
+
+	cmdExportHosts.Flags().StringVarP(&format, "format", "f", "csv", "output format, csv or yaml")
+	cmdExportHosts.Flags().StringVarP(&outFile, "out", "o", "", "file to write the export to")
+	_ = registerFlagArgsFunc(cmdExportHosts, "format", []string{"csv", "yaml"})
+	_ = registerFlagArgsFunc(cmdExportHosts, "out", []string{"FILE"})
+
+	return cmdExportHosts
+}
+
+func doShowHosts(names string, hostnames []string, eths []string, ips []string, macs []string, hostPolicies []string, reservations []string, states []string, powered *bool, detail bool) *common.ResponseBodyHosts {
 
 	var params string
 	if len(names) > 0 {
@@ -460,6 +786,9 @@ func doShowHosts(names string, hostnames []string, eths []string, ips []string,
 	if powered != nil {
 		params += "powered=" + strconv.FormatBool(*powered) + "&"
 	}
+	if detail {
+		params += "detail=true&"
+	}
 	if params != "" {
 		params = strings.TrimSuffix(params, "&")
 		params = "?" + params
@@ -472,7 +801,7 @@ func doShowHosts(names string, hostnames []string, eths []string, ips []string,
 	return &rb
 }
 
-func doEditHost(name, boot, hostname, hostPolicy, ip, eth, mac string) *common.ResponseBodyBasic {
+func doEditHost(name, boot, hostname, hostPolicy, ip, eth, switchName, mac string, nics *string) *common.ResponseBodyBasic {
 	apiPath := api.Hosts + "/" + name
 	params := make(map[string]interface{})
 	if hostname != "" {
@@ -490,9 +819,15 @@ func doEditHost(name, boot, hostname, hostPolicy, ip, eth, mac string) *common.R
 	if eth != "" {
 		params["eth"] = eth
 	}
+	if switchName != "" {
+		params["switch"] = switchName
+	}
 	if mac != "" {
 		params["mac"] = mac
 	}
+	if nics != nil {
+		params["nics"] = *nics
+	}
 	body := doSend(http.MethodPatch, apiPath, params)
 	return unmarshalBasicResponse(body)
 }
@@ -526,6 +861,235 @@ func doBlockHost(block bool, hosts string) *common.ResponseBodyBasic {
 	return unmarshalBasicResponse(body)
 }
 
+func doStateHost(state, hosts, reason, ticket, returnDate string) *common.ResponseBodyBasic {
+	params := make(map[string]interface{})
+	params["state"] = state
+	params["hosts"] = hosts
+	params["reason"] = reason
+	if ticket != "" {
+		params["ticket"] = ticket
+	}
+	if returnDate != "" {
+		rt, err := time.ParseInLocation(common.DateTimeCompactFormat, returnDate, cli.tzLoc)
+		if err != nil {
+			checkClientErr(fmt.Errorf("return date format invalid or not recognized: %v", err))
+		}
+		params["returnDate"] = rt.Unix()
+	}
+	body := doSend(http.MethodPatch, api.HostsState, params)
+	return unmarshalBasicResponse(body)
+}
+
+func doImportHosts(path, format string, preview bool) *common.ResponseBodyHostImport {
+
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = "csv"
+		case ".yaml", ".yml":
+			format = "yaml"
+		default:
+			checkClientErr(fmt.Errorf("can't tell the format of %s; use the -f flag", path))
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		checkClientErr(err)
+	}
+
+	params := make(map[string]interface{})
+	params["format"] = format
+	params["data"] = string(data)
+	if preview {
+		params["preview"] = true
+	}
+	body := doSend(http.MethodPost, api.HostsImport, params)
+	rb := common.ResponseBodyHostImport{}
+	err = json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func doExportHosts(format, outFile string) {
+
+	body := doSend(http.MethodGet, api.HostsExport+"?format="+format, nil)
+	rb := unmarshalBasicResponse(body)
+	if !rb.IsSuccess() {
+		printRespSimple(rb)
+	}
+
+	export, _ := rb.Data["export"].(string)
+	if outFile == "" {
+		fmt.Print(export)
+		return
+	}
+	if err := os.WriteFile(outFile, []byte(export), 0644); err != nil {
+		checkClientErr(err)
+	}
+	printSimple(fmt.Sprintf("hosts exported to %s", outFile), cRespSuccess)
+}
+
+func doShowHostHealth(name string, checks []string, limit int) *common.ResponseBodyHealth {
+
+	var params string
+	for _, c := range checks {
+		params += "check=" + c + "&"
+	}
+	if limit > 0 {
+		params += "limit=" + strconv.Itoa(limit) + "&"
+	}
+	if params != "" {
+		params = "?" + strings.TrimSuffix(params, "&")
+	}
+	apiPath := api.Hosts + "/" + name + "/health" + params
+	body := doSend(http.MethodGet, apiPath, nil)
+	rb := common.ResponseBodyHealth{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func doShowHostStatusHistory(name string, days, limit int) *common.ResponseBodyStatusHistory {
+
+	var params string
+	if days > 0 {
+		params += "days=" + strconv.Itoa(days) + "&"
+	}
+	if limit > 0 {
+		params += "limit=" + strconv.Itoa(limit) + "&"
+	}
+	if params != "" {
+		params = "?" + strings.TrimSuffix(params, "&")
+	}
+	apiPath := api.Hosts + "/" + name + "/status-history" + params
+	body := doSend(http.MethodGet, apiPath, nil)
+	rb := common.ResponseBodyStatusHistory{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func printHostStatusHistory(rb *common.ResponseBodyStatusHistory) {
+
+	checkAndSetColorLevel(rb)
+
+	history, ok := rb.Data["history"]
+	if !ok || len(history.Changes) == 0 {
+		printRespSimple(rb)
+		return
+	}
+
+	if history.Flapping {
+		printSimple(fmt.Sprintf("%s is currently flapping", history.Host), cRespWarn)
+	}
+
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"TIME", "FROM", "TO"})
+
+	for _, c := range history.Changes {
+		tw.AppendRow([]interface{}{
+			getLocTime(c.Time).Format(common.DateTimeLongFormat),
+			c.PrevStatus,
+			sBold(c.Status),
+		})
+	}
+
+	if simplePrint {
+		tw.Style().Options.SeparateRows = false
+		tw.Style().Options.SeparateColumns = true
+		tw.Style().Options.DrawBorder = false
+	} else {
+		tw.SetStyle(igorTableStyle)
+	}
+
+	fmt.Printf("\n%s\n\n", tw.Render())
+}
+
+func printHostImport(rb *common.ResponseBodyHostImport, preview bool) {
+
+	checkAndSetColorLevel(rb)
+
+	changes := rb.Data["changes"]
+	if !rb.IsSuccess() || len(changes) == 0 {
+		printRespSimple(rb)
+		return
+	}
+
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"HOST", "ACTION", "CHANGES"})
+
+	var created, updated int
+	for _, c := range changes {
+		switch c.Action {
+		case "create":
+			created++
+		case "update":
+			updated++
+		}
+		tw.AppendRow([]interface{}{
+			c.Host,
+			c.Action,
+			strings.Join(c.Changes, "\n"),
+		})
+	}
+
+	if simplePrint {
+		tw.Style().Options.SeparateRows = false
+		tw.Style().Options.SeparateColumns = true
+		tw.Style().Options.DrawBorder = false
+	} else {
+		tw.SetStyle(igorTableStyle)
+	}
+
+	fmt.Printf("\n%s\n\n", tw.Render())
+	if preview {
+		printSimple(fmt.Sprintf("preview only: %d host(s) would be created and %d updated", created, updated), cRespWarn)
+	} else {
+		printSimple(fmt.Sprintf("%d host(s) created and %d updated", created, updated), cRespSuccess)
+	}
+}
+
+func printHostHealth(rb *common.ResponseBodyHealth) {
+
+	checkAndSetColorLevel(rb)
+
+	results := rb.Data["health"]
+	if len(results) == 0 {
+		printRespSimple(rb)
+		return
+	}
+
+	passColor := func(passed bool) string {
+		if passed {
+			return hsAvailable.Sprint("pass")
+		}
+		return cInstError.Sprint("FAIL")
+	}
+
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"CHECKED", "CHECK", "TYPE", "RESULT", "DETAIL"})
+
+	for _, r := range results {
+		tw.AppendRow([]interface{}{
+			getLocTime(r.Checked).Format(common.DateTimeLongFormat),
+			sBold(r.Check),
+			r.Type,
+			passColor(r.Passed),
+			r.Detail,
+		})
+	}
+
+	if simplePrint {
+		tw.Style().Options.SeparateRows = false
+		tw.Style().Options.SeparateColumns = true
+		tw.Style().Options.DrawBorder = false
+	} else {
+		tw.SetStyle(igorTableStyle)
+	}
+
+	fmt.Printf("\n%s\n\n", tw.Render())
+}
+
 func printHosts(rb *common.ResponseBodyHosts) {
 
 	checkAndSetColorLevel(rb)
@@ -547,11 +1111,31 @@ func printHosts(rb *common.ResponseBodyHosts) {
 			return hsReserved.Sprint(state)
 		case "blocked":
 			return cBlockedUp.Sprint(state)
+		case "repair":
+			return cRepair.Sprint(state)
+		case "burn-in":
+			return cBurnIn.Sprint(state)
+		case "decommissioned":
+			return cDecommissioned.Sprint(state)
 		default:
 			return cInstError.Sprintf(state)
 		}
 	}
 
+	stateInfo := func(h common.HostData) string {
+		info := resStateColor(h.State)
+		if h.RepairTicket != "" {
+			info += "\nticket: " + h.RepairTicket
+		}
+		if h.RepairReturn > 0 {
+			info += "\nreturn: " + getLocTime(time.Unix(h.RepairReturn, 0)).Format(common.DateTimeCompactFormat)
+		}
+		if h.StateReason != "" {
+			info += "\n" + h.StateReason
+		}
+		return info
+	}
+
 	netStateColor := func(powered string) string {
 		if simplePrint {
 			return powered
@@ -569,19 +1153,44 @@ func printHosts(rb *common.ResponseBodyHosts) {
 		}
 	}
 
+	// show the switch alongside the port when hosts are spread across several switches
+	ethInfo := func(h common.HostData) string {
+		info := h.Eth
+		if h.Switch != "" {
+			info = h.Switch + ":" + h.Eth
+		}
+		for _, nic := range h.Nics {
+			if nic.Switch != "" {
+				info += "\n" + nic.Role + ": " + nic.Switch + ":" + nic.Eth
+			} else if nic.Eth != "" {
+				info += "\n" + nic.Role + ": " + nic.Eth
+			}
+		}
+		return info
+	}
+
+	// additional interfaces are listed under the primary mac with their role
+	macInfo := func(h common.HostData) string {
+		info := h.Mac
+		for _, nic := range h.Nics {
+			info += "\n" + nic.Role + ": " + nic.Mac
+		}
+		return info
+	}
+
 	tw := table.NewWriter()
 	tw.AppendHeader(table.Row{"NODE", "RES-STATE", "NET-STATE", "BOOT-TYPE", "MACID", "HOSTNAME", "IP", "ETH", "POLICY", "ACCESS-GROUPS", "RESTRICTED", "RESERVATIONS"})
 
 	for _, h := range hosts {
 		tw.AppendRow([]interface{}{
 			sBold(h.Name),
-			resStateColor(h.State),
+			stateInfo(h),
 			netStateColor(h.Powered),
 			h.BootMode,
-			h.Mac,
+			macInfo(h),
 			h.HostName,
 			h.IP,
-			h.Eth,
+			ethInfo(h),
 			h.HostPolicy,
 			strings.Join(h.AccessGroups, "\n"),
 			h.Restricted,
@@ -600,3 +1209,35 @@ func printHosts(rb *common.ResponseBodyHosts) {
 	fmt.Printf("\n" + tw.Render() + "\n\n")
 
 }
+
+func printHostAttributes(rb *common.ResponseBodyHosts) {
+
+	hosts := rb.Data["hosts"]
+	for _, h := range hosts {
+		if len(h.Attributes) == 0 {
+			printSimple(fmt.Sprintf("%s: no hardware inventory reported", h.Name), cRespWarn)
+			continue
+		}
+
+		tw := table.NewWriter()
+		tw.SetTitle(h.Name + " - reported " + getLocTime(h.Attributes[0].Reported).Format(common.DateTimeLongFormat))
+		tw.AppendHeader(table.Row{"ATTRIBUTE", "VALUE", "MISMATCH"})
+		for _, a := range h.Attributes {
+			mismatch := a.Mismatch
+			if mismatch != "" && !simplePrint {
+				mismatch = cInstError.Sprint(mismatch)
+			}
+			tw.AppendRow([]interface{}{a.Key, a.Value, mismatch})
+		}
+
+		if simplePrint {
+			tw.Style().Options.SeparateRows = false
+			tw.Style().Options.SeparateColumns = true
+			tw.Style().Options.DrawBorder = false
+		} else {
+			tw.SetStyle(igorTableStyle)
+		}
+
+		fmt.Print(tw.Render() + "\n\n")
+	}
+}
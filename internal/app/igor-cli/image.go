@@ -28,7 +28,11 @@ func newImageCmd() *cobra.Command {
 		Long: `
 Image primary command. A sub-command must be invoked to do anything.
 
-Images represent bootable files (currently limited to kernel/initrd pairs).
+Images represent bootable files, either a kernel/initrd pair, a distro's
+install media (ISO) or a full disk image. The kernel and initrd of an ISO are
+found automatically and its contents are served to hosts as an install source.
+A disk image is written to the host's local disk by a netbooted imaging
+environment, after which the host boots from it.
 Based on igor's configuration, a user can upload bootable files while creating
 a distro. If uploading is not enabled, an image must be registered as a 
 separate step first, which requires an administrative role in handling the 
@@ -37,12 +41,21 @@ addition of new images to igor for private and public use.
 Images intended to be installed and booted locally must include both the 
 parameter localBoot = true and a breed.  
 
+New images are validated when they are registered. The kernel type and version
+are read from its header, the initrd compression is identified, the image's
+boot methods are checked against what the kernel supports and, if the server
+has an image scanner command configured, it is run on the image files. An image
+that fails any check is quarantined and can't be used in distros until an admin
+approves it with 'igor image approve'.
+
 ` + sBold("All image commands are admin-only.") + `
 `,
 	}
 
 	cmdImage.AddCommand(newImageRegisterCmd())
 	cmdImage.AddCommand(newImageShowCmd())
+	cmdImage.AddCommand(newImageApproveCmd())
+	cmdImage.AddCommand(newImageValidateCmd())
 	cmdImage.AddCommand(newImageDelCmd())
 	return cmdImage
 }
@@ -52,12 +65,15 @@ func newImageRegisterCmd() *cobra.Command {
 	cmdRegisterImage := &cobra.Command{
 		Use: "register {-k FILENAME.KERNEL -i FILENAME.INITRD |\n" +
 			"       --kstaged FILENAME.KERNEL --istaged FILENAME.INITRD |\n" +
+			"       --iso FILENAME.ISO | --isostaged FILENAME.ISO |\n" +
+			"       --disk FILENAME.IMG | --diskstaged FILENAME.IMG}\n" +
 			"       --boot {bios,uefi}\n" +
 			"       [-l --localBoot {true|false} -b --breed BREED]\n",
 		Short: "Register image files or distro",
 		Long: `
-Registers bootable file(s) (ex. a kernel/initrd file pair) with igor. This
-command is used when uploading is not enabled for users.
+Registers bootable file(s) (ex. a kernel/initrd file pair, an ISO or a disk
+image) with igor.
+This command is used when uploading is not enabled for users.
 
 ` + requiredFlags + `
 
@@ -67,8 +83,21 @@ command is used when uploading is not enabled for users.
   -i : name/path to the initrd file. If including a distro for local boot,
   		include the initrd file name if using a custom name. Otherwise,
   		Igor will look for a default name based on OS breed.
+  --iso : name/path to a distro install ISO, used in place of -k and -i. The
+  		kernel and initrd are taken from the ISO's well-known boot file
+  		locations (ex. images/pxeboot, casper, install.amd) and the breed
+  		is detected from them when possible. The ISO contents are served
+  		from the image's install source URL shown by 'igor image show',
+  		which kickstarts can use as their package source.
+  --disk : name/path to a full disk image, used in place of -k and -i. The
+  		file name must end in .raw, .img or .qcow2, optionally followed
+  		by .gz, .xz or .zst if compressed. Hosts netboot the server's
+  		imaging environment, which writes the image to local disk and
+  		verifies its checksum, then boot locally. No kickstart is needed.
   --boot: at least one or more comma-separated strings indicating this 
   		image's compatible boot methods. Available values are: bios,uefi
+  		BIOS boot needs a bzImage kernel and UEFI boot needs a kernel with
+  		an EFI stub, otherwise the image is quarantined.
 
 ` + optionalFlags + `
 
@@ -93,12 +122,16 @@ server.imageStageDir setting in the server config for directory path.
 			flagset := cmd.Flags()
 			kstaged, _ := flagset.GetString("kstaged")
 			istaged, _ := flagset.GetString("istaged")
+			isoStaged, _ := flagset.GetString("isostaged")
+			isoPath, _ := flagset.GetString("iso")
+			diskStaged, _ := flagset.GetString("diskstaged")
+			diskPath, _ := flagset.GetString("disk")
 			kpath, _ := flagset.GetString("kernel")
 			ipath, _ := flagset.GetString("initrd")
 			boot, _ := flagset.GetStringSlice("boot")
 			localBoot, _ := flagset.GetBool("localBoot")
 			breed, _ := flagset.GetString("breed")
-			res, err := doRegisterImage(kstaged, istaged, kpath, ipath, boot, breed, localBoot)
+			res, err := doRegisterImage(kstaged, istaged, kpath, ipath, isoStaged, isoPath, diskStaged, diskPath, boot, breed, localBoot)
 			if err != nil {
 				return err
 			}
@@ -109,13 +142,17 @@ server.imageStageDir setting in the server config for directory path.
 		ValidArgsFunction:     validateNoArgs,
 	}
 
-	var kstaged, istaged, kpath, ipath, breed string
+	var kstaged, istaged, kpath, ipath, isoStaged, isoPath, diskStaged, diskPath, breed string
 	var boot []string
 	var localBoot bool
 	cmdRegisterImage.Flags().StringVar(&kstaged, "kstaged", "", "name of the .kernel file already staged in the staged_images folder on the Igor server")
 	cmdRegisterImage.Flags().StringVar(&istaged, "istaged", "", "name of the .initrd file already staged in the staged_images folder on the Igor server")
+	cmdRegisterImage.Flags().StringVar(&isoStaged, "isostaged", "", "name of the .iso file already staged in the staged_images folder on the Igor server")
+	cmdRegisterImage.Flags().StringVar(&diskStaged, "diskstaged", "", "name of the disk image file already staged in the staged_images folder on the Igor server")
 	cmdRegisterImage.Flags().StringVarP(&kpath, "kernel", "k", "", "name/path of the .kernel file to upload")
 	cmdRegisterImage.Flags().StringVarP(&ipath, "initrd", "i", "", "name/path of the .initrd file to upload")
+	cmdRegisterImage.Flags().StringVar(&isoPath, "iso", "", "name/path of the .iso file to upload")
+	cmdRegisterImage.Flags().StringVar(&diskPath, "disk", "", "name/path of the disk image file to upload")
 	cmdRegisterImage.Flags().StringSlice("boot", boot, "the compatible boot system to use the image with")
 	cmdRegisterImage.Flags().StringVarP(&breed, "breed", "b", "", "name of the OS breed")
 	cmdRegisterImage.Flags().BoolVarP(&localBoot, "localBoot", "l", false, "true = image is intended for local install/boot")
@@ -152,6 +189,56 @@ Use the -x flag to render screen output without pretty formatting.
 
 }
 
+func newImageApproveCmd() *cobra.Command {
+
+	return &cobra.Command{
+		Use:   "approve NAME",
+		Short: "Approve a quarantined image " + adminOnly,
+		Long: `
+Approves an image that failed validation so it can be used in distros. Review
+the failed checks shown by 'igor image show' before approving.
+
+` + requiredArgs + `
+
+  NAME : image name (ref-ID)
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			printRespSimple(doUpdateImage(args[0], "approve"))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+}
+
+func newImageValidateCmd() *cobra.Command {
+
+	return &cobra.Command{
+		Use:   "validate NAME",
+		Short: "Validate an image again " + adminOnly,
+		Long: `
+Runs the image checks again, for example on images registered before validation
+was added or after the server's image scanner has been updated. An image that
+fails is quarantined, which stops new distros from using it but doesn't affect
+distros that already do.
+
+` + requiredArgs + `
+
+  NAME : image name (ref-ID)
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			printRespSimple(doUpdateImage(args[0], "validate"))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+}
+
 func newImageDelCmd() *cobra.Command {
 
 	return &cobra.Command{
@@ -180,7 +267,7 @@ will also be destroyed automatically.
 	}
 }
 
-func doRegisterImage(kstaged, istaged, kpath, ipath string, boot []string, breed string, localBoot bool) (*common.ResponseBodyBasic, error) {
+func doRegisterImage(kstaged, istaged, kpath, ipath, isoStaged, isoPath, diskStaged, diskPath string, boot []string, breed string, localBoot bool) (*common.ResponseBodyBasic, error) {
 	params := map[string]interface{}{}
 	params["boot"] = boot
 	if localBoot {
@@ -189,14 +276,22 @@ func doRegisterImage(kstaged, istaged, kpath, ipath string, boot []string, breed
 	if breed != "" {
 		params["breed"] = breed
 	}
-	if kstaged != "" && istaged != "" {
+	if diskStaged != "" {
+		params["diskstaged"] = diskStaged
+	} else if diskPath != "" {
+		params["diskFile"] = openFile(diskPath)
+	} else if isoStaged != "" {
+		params["isostaged"] = isoStaged
+	} else if isoPath != "" {
+		params["isoFile"] = openFile(isoPath)
+	} else if kstaged != "" && istaged != "" {
 		params["kstaged"] = kstaged
 		params["istaged"] = istaged
 	} else if kpath != "" && ipath != "" {
 		params["kernelFile"] = openFile(kpath)
 		params["initrdFile"] = openFile(ipath)
 	} else {
-		return nil, fmt.Errorf("paths to either uploadable kernel/initrd files or staged files names are required for image registration")
+		return nil, fmt.Errorf("paths to either uploadable kernel/initrd, iso or disk image files or staged files names are required for image registration")
 	}
 	if len(params) > 0 {
 		body := doSendMultiform(http.MethodPost, api.ImageRegister, params)
@@ -216,6 +311,12 @@ func doShowImages() *common.ResponseBodyImages {
 	return &rb
 }
 
+func doUpdateImage(name, action string) *common.ResponseBodyBasic {
+	apiPath := api.Images + "/" + name
+	body := doSend(http.MethodPatch, apiPath, map[string]interface{}{action: true})
+	return unmarshalBasicResponse(body)
+}
+
 func doDeleteImage(name string) *common.ResponseBodyBasic {
 	apiPath := api.Images + "/" + name
 	body := doSend(http.MethodDelete, apiPath, nil)
@@ -236,9 +337,18 @@ func printImages(rb *common.ResponseBodyImages) {
 	})
 
 	tw := table.NewWriter()
-	tw.AppendHeader(table.Row{"NAME", "ID", "TYPE", "KERNEL", "INITRD", "BREED", "BOOT-TYPE", "LOCAL", "DISTROS"})
+	tw.AppendHeader(table.Row{"NAME", "ID", "TYPE", "KERNEL", "INITRD", "BREED", "BOOT-TYPE", "LOCAL", "DISTROS", "INSTALL-SOURCE", "STATUS"})
 
 	for _, di := range imageList {
+		status := di.Status
+		if status == "quarantined" {
+			// show why so the admin can decide whether to approve it
+			for _, c := range di.Checks {
+				if strings.HasPrefix(c, "FAIL") {
+					status += "\n" + c
+				}
+			}
+		}
 		tw.AppendRow([]interface{}{
 			di.Name,
 			di.ImageID,
@@ -249,6 +359,8 @@ func printImages(rb *common.ResponseBodyImages) {
 			di.Boot,
 			di.Local,
 			strings.Join(di.Distros, "\n"),
+			di.Source,
+			status,
 		})
 	}
 
@@ -260,6 +372,6 @@ func printImages(rb *common.ResponseBodyImages) {
 		tw.SetStyle(igorTableStyle)
 	}
 
-	fmt.Printf("\n" + tw.Render() + "\n\n")
+	fmt.Printf("\n%s\n\n", tw.Render())
 
 }
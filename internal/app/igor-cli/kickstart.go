@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 
 	"igor2/internal/pkg/api"
@@ -39,6 +40,22 @@ execute additional functions or add packages as needed after the main
 installation process completes. See Igor's kickstart documentation for further
 details and requirements.
 
+A kickstart (or preseed, cloud-init or autoinstall file) marked as a template
+is rendered for each host that fetches it using Go text/template syntax, so
+one file can serve many hosts and users. Templates are given these fields:
+
+  .Host          .Name .IP .Mac
+  .Reservation   .Name .Owner .Group .Distro .Profile .Vlan
+  .Owner         .Name .FullName .Email .SSHKeys
+  .Hosts         names of every host in the reservation
+  .Vars          variables set on the reservation's profile, ex. .Vars.timezone
+  .Image         .Name .ImageID .Type .Breed .Kernel .Initrd
+  .BootMode      bios, uefi or ipxe
+  .KickstartURL  where the host fetched the kickstart from
+  .NoCloudURL    seed url of igor's cloud-init NoCloud datasource
+
+Using a profile variable the reservation's profile doesn't set is an error.
+
 ` + sBold("All kickstart commands are admin-only.") + `
 `,
 	}
@@ -53,7 +70,7 @@ details and requirements.
 func newKSRegisterCmd() *cobra.Command {
 
 	cmdRegisterKS := &cobra.Command{
-		Use:   "register -k KICKSTART.FILE ",
+		Use:   "register -k KICKSTART.FILE [--template]",
 		Short: "Register kickstart file " + adminOnly,
 		Long: `
 Upload and register a kickstart file to Igor.
@@ -65,13 +82,19 @@ included and referenced by file name.
 
 Use -k flag to specify the name of the kickstart file
 
+` + optionalFlags + `
+
+Use the --template flag to mark the file as a template that is rendered for
+each host that fetches it.
+
 ` + adminOnlyBanner + `
 `,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			flagset := cmd.Flags()
 			ks, _ := flagset.GetString("kickstart")
-			res, err := doRegisterKS(ks)
+			isTemplate, _ := flagset.GetBool("template")
+			res, err := doRegisterKS(ks, isTemplate)
 			if err != nil {
 				return err
 			}
@@ -83,7 +106,9 @@ Use -k flag to specify the name of the kickstart file
 	}
 
 	var ks string
+	var isTemplate bool
 	cmdRegisterKS.Flags().StringVarP(&ks, "kickstart", "k", "", "name of the kickstart file to register")
+	cmdRegisterKS.Flags().BoolVar(&isTemplate, "template", false, "render the file for each host")
 	_ = cmdRegisterKS.MarkFlagRequired("kickstart")
 	_ = registerFlagArgsFunc(cmdRegisterKS, "kickstart", []string{"FILENAME"})
 
@@ -123,7 +148,7 @@ Use the -x flag to render screen output without pretty formatting.
 func newKSEditCmd() *cobra.Command {
 
 	cmdEditKS := &cobra.Command{
-		Use:   "edit NAME -k KICKSTART.FILE -n NEW_NAME ",
+		Use:   "edit NAME {-k KICKSTART.FILE -n NEW_NAME --template=true|false}",
 		Short: "Replace kickstart file or change its name" + adminOnly,
 		Long: `
 Upload and register a kickstart file to Igor to replace the existing Kickstart file.
@@ -148,6 +173,9 @@ be deleted once the new file is saved.
 Use -n flag to change the name of the existing kickstart. NOTE: this changes only
 the name Igor references it by, it does not change the file name.
 
+Use --template=true to render the file for each host that fetches it, or
+--template=false to serve it as is.
+
 ` + adminOnlyBanner + `
 `,
 		Args: cobra.ExactArgs(1),
@@ -155,7 +183,12 @@ the name Igor references it by, it does not change the file name.
 			flagset := cmd.Flags()
 			ks, _ := flagset.GetString("kickstart")
 			name, _ := flagset.GetString("name")
-			res, err := doUpdateKS(args[0], ks, name)
+			template := ""
+			if flagset.Changed("template") {
+				isTemplate, _ := flagset.GetBool("template")
+				template = strconv.FormatBool(isTemplate)
+			}
+			res, err := doUpdateKS(args[0], ks, name, template)
 			if err != nil {
 				return err
 			}
@@ -167,8 +200,10 @@ the name Igor references it by, it does not change the file name.
 	}
 
 	var ks, name string
+	var isTemplate bool
 	cmdEditKS.Flags().StringVarP(&ks, "kickstart", "k", "", "name of the kickstart file to register")
 	cmdEditKS.Flags().StringVarP(&name, "name", "n", "", "new name for the kickstart")
+	cmdEditKS.Flags().BoolVar(&isTemplate, "template", false, "render the file for each host")
 	// _ = cmdEditKS.MarkFlagRequired("kickstart")
 	_ = registerFlagArgsFunc(cmdEditKS, "kickstart", []string{"FILENAME"})
 	_ = registerFlagArgsFunc(cmdEditKS, "name", []string{"NAME"})
@@ -204,10 +239,13 @@ Any distros using the kickstart file must be deleted first.
 	}
 }
 
-func doRegisterKS(ks string) (*common.ResponseBodyBasic, error) {
+func doRegisterKS(ks string, isTemplate bool) (*common.ResponseBodyBasic, error) {
 
 	params := map[string]interface{}{}
 	params["kickstart"] = openFile(ks)
+	if isTemplate {
+		params["template"] = "true"
+	}
 	body := doSendMultiform(http.MethodPost, api.KickstartRegister, params)
 	return unmarshalBasicResponse(body), nil
 }
@@ -222,7 +260,7 @@ func doShowKS() *common.ResponseBodyKickstarts {
 	return &rb
 }
 
-func doUpdateKS(target, ks, name string) (*common.ResponseBodyBasic, error) {
+func doUpdateKS(target, ks, name, template string) (*common.ResponseBodyBasic, error) {
 	apiPath := api.Kickstarts + "/" + target
 	params := map[string]interface{}{}
 	if ks != "" {
@@ -232,6 +270,9 @@ func doUpdateKS(target, ks, name string) (*common.ResponseBodyBasic, error) {
 	if name != "" {
 		params["name"] = name
 	}
+	if template != "" {
+		params["template"] = template
+	}
 	body := doSendMultiform(http.MethodPatch, apiPath, params)
 	return unmarshalBasicResponse(body), nil
 }
@@ -256,13 +297,14 @@ func printKickstart(rb *common.ResponseBodyKickstarts) {
 	})
 
 	tw := table.NewWriter()
-	tw.AppendHeader(table.Row{"NAME", "FILE NAME", "OWNER"})
+	tw.AppendHeader(table.Row{"NAME", "FILE NAME", "OWNER", "TEMPLATE"})
 
 	for _, ks := range ksList {
 		tw.AppendRow([]interface{}{
 			ks.Name,
 			ks.FileName,
 			ks.Owner,
+			ks.Template,
 		})
 	}
 
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"igor2/internal/pkg/api"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
 
@@ -50,7 +51,7 @@ ensure compatibility.
 func newProfileCreateCmd() *cobra.Command {
 
 	cmdCreateProfile := &cobra.Command{
-		Use:   "create NAME DISTRO [ -k \"KARGS\" --desc \"DESCRIPTION\"]",
+		Use:   "create NAME DISTRO [ -k \"KARGS\" --var KEY=VALUE ... --user-data FILE --desc \"DESCRIPTION\"]",
 		Short: "Create a profile",
 		Long: `
 Creates a new igor profile. A profile is a distro wrapper for adding kernel
@@ -69,15 +70,34 @@ Use the -k flag to add kernel arguments that will be executed after any kernel
 arguments specified in the distro, if present. Use a double-quotes around the
 field if it contains spaces.
 
+Use the --var flag to set a variable that kickstarts marked as templates can
+use as .Vars.KEY when a reservation using the profile installs. Repeat the
+flag to set more than one.
+
+Use the --user-data flag to give a file of cloud-init user-data, ex. a
+#cloud-config file. Hosts of reservations using the profile get it from igor's
+cloud-init NoCloud datasource.
+
 ` + descFlagText + `
 `,
 		Args: cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			flagset := cmd.Flags()
 			desc, _ := flagset.GetString("desc")
 			kargs, _ := flagset.GetString("kargs")
-			res := doCreateProfile(args[0], args[1], desc, kargs)
+			varList, _ := flagset.GetStringArray("var")
+			vars, err := parseProfileVars(varList)
+			if err != nil {
+				return err
+			}
+			userDataFile, _ := flagset.GetString("user-data")
+			userData, err := readUserData(userDataFile)
+			if err != nil {
+				return err
+			}
+			res := doCreateProfile(args[0], args[1], desc, kargs, vars, userData)
 			printRespSimple(res)
+			return nil
 		},
 		DisableFlagsInUseLine: true,
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -88,10 +108,15 @@ field if it contains spaces.
 		},
 	}
 
-	var desc, kernelArgs string
+	var desc, kernelArgs, userData string
+	var vars []string
 
 	cmdCreateProfile.Flags().StringVar(&desc, "desc", "", "description of the profile")
 	cmdCreateProfile.Flags().StringVarP(&kernelArgs, "kargs", "k", "", "kernel arguments to add to the profile")
+	cmdCreateProfile.Flags().StringArrayVar(&vars, "var", nil, "kickstart template variable as KEY=VALUE")
+	cmdCreateProfile.Flags().StringVar(&userData, "user-data", "", "file of cloud-init user-data")
+	_ = registerFlagArgsFunc(cmdCreateProfile, "var", []string{"KEY=VALUE"})
+	_ = registerFlagArgsFunc(cmdCreateProfile, "user-data", []string{"FILENAME"})
 	_ = registerFlagArgsFunc(cmdCreateProfile, "kargs", []string{"\"KARGS\""})
 	_ = registerFlagArgsFunc(cmdCreateProfile, "desc", []string{"\"DESCRIPTION\""})
 
@@ -153,7 +178,7 @@ Use the -x flag to render screen output without pretty formatting.
 func newProfileEditCmd() *cobra.Command {
 
 	cmdEditProfile := &cobra.Command{
-		Use:   "edit NAME { [-n NEWNAME] [-k \"KARGS\"] [--desc \"DESCRIPTION\"] }",
+		Use:   "edit NAME { [-n NEWNAME] [-k \"KARGS\"] [--var KEY=VALUE ...] [--user-data FILE] [--desc \"DESCRIPTION\"] }",
 		Short: "Edit profile information",
 		Long: `
 Edits profile information. This can only be done by the profile owner or an 
@@ -170,15 +195,37 @@ Use the -n flag to re-name the profile.
 Use the -k flag to replace the kernel arguments field. Use a double-quotes around
 the field if it contains spaces.
 
+Use the --var flag to set a kickstart template variable. Other variables are
+kept. Give a variable no value (ex. --var KEY=) to remove it. Repeat the flag to
+change more than one.
+
+Use the --user-data flag to replace the profile's cloud-init user-data with the
+contents of a file. Use --user-data "" to remove it.
+
 ` + descFlagText + `
 `,
 		Args: cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			flagset := cmd.Flags()
 			name, _ := flagset.GetString("name")
 			desc, _ := flagset.GetString("desc")
 			kargs, _ := flagset.GetString("kernel-args")
-			printRespSimple(doEditProfile(args[0], name, desc, kargs))
+			varList, _ := flagset.GetStringArray("var")
+			vars, err := parseProfileVars(varList)
+			if err != nil {
+				return err
+			}
+			var userData *string
+			if flagset.Changed("user-data") {
+				userDataFile, _ := flagset.GetString("user-data")
+				ud, udErr := readUserData(userDataFile)
+				if udErr != nil {
+					return udErr
+				}
+				userData = &ud
+			}
+			printRespSimple(doEditProfile(args[0], name, desc, kargs, vars, userData))
+			return nil
 		},
 		DisableFlagsInUseLine: true,
 		ValidArgsFunction:     validateNameArg,
@@ -186,11 +233,17 @@ the field if it contains spaces.
 
 	var name,
 		desc,
-		kernelArgs string
+		kernelArgs,
+		userData string
+	var vars []string
 
 	cmdEditProfile.Flags().StringVarP(&name, "name", "n", "", "update the profile name")
 	cmdEditProfile.Flags().StringVar(&desc, "desc", "", "update the description")
 	cmdEditProfile.Flags().StringVarP(&kernelArgs, "kernel-args", "k", "", "update kernel arguments")
+	cmdEditProfile.Flags().StringArrayVar(&vars, "var", nil, "set kickstart template variable as KEY=VALUE")
+	cmdEditProfile.Flags().StringVar(&userData, "user-data", "", "replace cloud-init user-data with a file")
+	_ = registerFlagArgsFunc(cmdEditProfile, "var", []string{"KEY=VALUE"})
+	_ = registerFlagArgsFunc(cmdEditProfile, "user-data", []string{"FILENAME"})
 	_ = registerFlagArgsFunc(cmdEditProfile, "name", []string{"NAME"})
 	_ = registerFlagArgsFunc(cmdEditProfile, "kernel-args", []string{"\"KARGS\""})
 	_ = registerFlagArgsFunc(cmdEditProfile, "desc", []string{"\"DESCRIPTION\""})
@@ -226,7 +279,7 @@ A profile cannot be deleted if it is associated with a reservation.
 	return cmdDeleteProfile
 }
 
-func doCreateProfile(name, distro, desc, kargs string) *common.ResponseBodyBasic {
+func doCreateProfile(name, distro, desc, kargs string, vars map[string]string, userData string) *common.ResponseBodyBasic {
 
 	params := map[string]interface{}{}
 	params["name"] = name
@@ -237,6 +290,12 @@ func doCreateProfile(name, distro, desc, kargs string) *common.ResponseBodyBasic
 	if kargs != "" {
 		params["kernelArgs"] = kargs
 	}
+	if len(vars) > 0 {
+		params["vars"] = vars
+	}
+	if userData != "" {
+		params["userData"] = userData
+	}
 
 	body := doSend(http.MethodPost, api.Profiles, params)
 	return unmarshalBasicResponse(body)
@@ -277,7 +336,7 @@ func doShowProfile(names, owners, kargs, distros []string) *common.ResponseBodyP
 	return &rb
 }
 
-func doEditProfile(name, newName, desc, kargs string) *common.ResponseBodyBasic {
+func doEditProfile(name, newName, desc, kargs string, vars map[string]string, userData *string) *common.ResponseBodyBasic {
 	apiPath := api.Profiles + "/" + name
 	params := map[string]interface{}{}
 	if newName != "" {
@@ -289,11 +348,39 @@ func doEditProfile(name, newName, desc, kargs string) *common.ResponseBodyBasic
 	if kargs != "" {
 		params["kernelArgs"] = kargs
 	}
+	if len(vars) > 0 {
+		params["vars"] = vars
+	}
+	if userData != nil {
+		params["userData"] = *userData
+	}
 
 	body := doSend(http.MethodPatch, apiPath, params)
 	return unmarshalBasicResponse(body)
 }
 
+// readUserData returns the contents of a cloud-init user-data file, or blank if no file is given.
+func readUserData(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(file)
+	return string(data), err
+}
+
+// parseProfileVars turns KEY=VALUE flag values into a map of profile variables.
+func parseProfileVars(varList []string) (map[string]string, error) {
+	vars := make(map[string]string, len(varList))
+	for _, v := range varList {
+		key, value, found := strings.Cut(v, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("variable '%s' must be in the form KEY=VALUE", v)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
 func doDeleteProfile(name string) *common.ResponseBodyBasic {
 	apiPath := api.Profiles + "/" + name
 
@@ -324,13 +411,19 @@ func printProfiles(rb *common.ResponseBodyProfiles) {
 			profileInfo += "  -OWNER:       " + d.Owner + "\n"
 			profileInfo += "  -DISTRO:      " + d.Distro + "\n"
 			profileInfo += "  -KERNEL-ARGS: " + d.KernelArgs + "\n"
+			for _, v := range profileVarLines(d.Vars) {
+				profileInfo += "  -VAR:         " + v + "\n"
+			}
+			if d.UserData != "" {
+				profileInfo += fmt.Sprintf("  -USER-DATA:   %d bytes\n", len(d.UserData))
+			}
 			fmt.Print(profileInfo + "\n\n")
 		}
 
 	} else {
 
 		tw := table.NewWriter()
-		tw.AppendHeader(table.Row{"NAME", "DESCRIPTION", "OWNER", "DISTRO", "KERNEL-ARGS"})
+		tw.AppendHeader(table.Row{"NAME", "DESCRIPTION", "OWNER", "DISTRO", "KERNEL-ARGS", "VARS"})
 		tw.AppendSeparator()
 
 		for _, p := range profileList {
@@ -341,6 +434,7 @@ func printProfiles(rb *common.ResponseBodyProfiles) {
 				p.Owner,
 				p.Distro,
 				multiline(40, p.KernelArgs),
+				strings.Join(profileVarLines(p.Vars), "\n"),
 			})
 		}
 
@@ -353,6 +447,10 @@ func printProfiles(rb *common.ResponseBodyProfiles) {
 				Name:     "KERNEL-ARGS",
 				WidthMax: 40,
 			},
+			{
+				Name:     "VARS",
+				WidthMax: 40,
+			},
 		})
 
 		tw.SetStyle(igorTableStyle)
@@ -360,3 +458,13 @@ func printProfiles(rb *common.ResponseBodyProfiles) {
 	}
 
 }
+
+// profileVarLines returns the profile variables as KEY=VALUE lines sorted by key.
+func profileVarLines(vars map[string]string) []string {
+	lines := make([]string, 0, len(vars))
+	for k, v := range vars {
+		lines = append(lines, k+"="+v)
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -79,7 +79,8 @@ func newResCreateCmd() *cobra.Command {
 
 	cmdCreateRes := &cobra.Command{
 		Use: "create NAME -n NODES {-p PROFILE | -d DISTRO} [-s START -e END \n" +
-			"       -g GROUP -v VLAN -k \"KARGS\" --desc \"DESCRIPTION\" --no-cycle\n" +
+			"       -g GROUP -v VLAN {--nics ROLE1,... | --topology SPEC} -k \"KARGS\"\n" +
+			"       --desc \"DESCRIPTION\" --no-cycle\n" +
 			"       (-o OWNER)]",
 		Short: "Create a reservation",
 		Long: `
@@ -136,12 +137,32 @@ membership confers the ability to extend or delete the reservation and to issue
 power commands to its assigned nodes. The reservation creator must be a member
 of the provided group.
 
-Use the -v flag to set a VLAN id number or name of an existing reservation. If
-a number is provided, the new reservation will use the specified VLAN value if
-not already taken. (The id range is available by running the 'igor settings' 
-command.) If a name is provided, the VLAN of the new reservation is set to the
+Use the -v flag to set a VLAN id number, the name of a vlan created with
+'igor vlan create', or the name of an existing reservation. If a number is
+provided, the new reservation will use the specified VLAN value if not already
+taken. (The id range is available by running the 'igor settings' command, and
+pools within it with 'igor vlan pool show'.) If a vlan name is provided, the
+reservation uses that vlan if you own it or belong to one of its groups. If a
+reservation name is provided, the VLAN of the new reservation is set to the
 same VLAN as the named reservation. If this flag is not used on a VLAN-enabled
-cluster then an id will be automatically assigned.
+cluster then an id will be automatically assigned, preferring any vlan pools
+set aside for your groups or the policies of the reserved hosts.
+
+Use the --nics flag on a VLAN-enabled cluster to choose which interfaces of
+the reserved nodes join the reservation's VLAN, by role. The node's main
+interface has the role 'primary'; other roles such as 'data' or 'fabric' are
+shown by 'igor host show'. Without this flag the primary interface and any
+roles set in the server's vlan.nicRoles setting are used.
+
+Use the --topology flag on a VLAN-enabled cluster to split the reservation into
+several isolated networks, each with a VLAN of its own. The spec is a list of
+networks separated by semicolons, each written as NAME[:NODES[:ROLE1,...]].
+NODES is a node range; leaving it out puts every reserved node on the network.
+ROLES are the interfaces that join the network, as with --nics; leaving them
+out uses the same default. An interface can only be on one network. The first
+network uses the VLAN given by -v or picked automatically, and the others are
+always picked automatically. Nodes added to the reservation later only join
+networks that include every node. This flag can't be combined with --nics.
 
 Use the --no-cycle flag to prevent the reservation's nodes from being power-
 cycled when it becomes active. This will leave the nodes in whatever power
@@ -179,6 +200,13 @@ igor res create Twit2 -p twitserv -n dq74,dq9 -s ` + exStartDts() + ` -e 6d -v T
   Requests a reservation named 'Twit2' using the profile 'twitserv' on three
   nodes starting ` + exStartDay() + ` for six days and shares the same vlan used
   by the reservation 'Twit1'.
+
+
+igor res create lab -d cent7 -n kn[1-4] --topology "ctrl;data1:kn[1-2]:data;data2:kn[3-4]:data"
+
+  * Uses a topology of three networks.
+  Puts the primary interface of all four nodes on a control network, and the
+  'data' interfaces of kn1-2 and kn3-4 on two separate data networks.
 `,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
@@ -192,13 +220,15 @@ igor res create Twit2 -p twitserv -n dq74,dq9 -s ` + exStartDts() + ` -e 6d -v T
 			start, _ := flagset.GetString("start")
 			end, _ := flagset.GetString("end")
 			vlan, _ := flagset.GetString("vlan")
+			nics, _ := flagset.GetStringSlice("nics")
+			topology, _ := flagset.GetString("topology")
 			kernelArgs, _ := flagset.GetString("kernel-args")
 			var noCycle *bool
 			if flagset.Changed("no-cycle") {
 				noCycleVal, _ := flagset.GetBool("no-cycle")
 				noCycle = &noCycleVal
 			}
-			printRespSimple(doCreateReservation(args[0], distro, profile, owner, group, desc, start, end, vlan, nodes, kernelArgs, noCycle))
+			printRespSimple(doCreateReservation(args[0], distro, profile, owner, group, desc, start, end, vlan, nics, topology, nodes, kernelArgs, noCycle))
 		},
 		DisableFlagsInUseLine: true,
 		ValidArgsFunction:     validateNameArg,
@@ -212,9 +242,11 @@ igor res create Twit2 -p twitserv -n dq74,dq9 -s ` + exStartDts() + ` -e 6d -v T
 		profile,
 		group,
 		vlan,
+		topology,
 		kernelArgs,
 		distro string
 	var noCycle bool
+	var nics []string
 
 	cmdCreateRes.Flags().StringVarP(&distro, "distro", "d", "", "distro to use")
 	cmdCreateRes.Flags().StringVarP(&profile, "profile", "p", "", "profile to use")
@@ -223,7 +255,9 @@ igor res create Twit2 -p twitserv -n dq74,dq9 -s ` + exStartDts() + ` -e 6d -v T
 	cmdCreateRes.Flags().StringVarP(&end, "end", "e", "", "end time (other than default)")
 	cmdCreateRes.Flags().StringVarP(&owner, "owner", "o", "", "assign different owner "+adminOnly)
 	cmdCreateRes.Flags().StringVarP(&group, "group", "g", "", "group allowed to access")
-	cmdCreateRes.Flags().StringVarP(&vlan, "vlan", "v", "", "vlan number or existing res name")
+	cmdCreateRes.Flags().StringVarP(&vlan, "vlan", "v", "", "vlan number, vlan name or existing res name")
+	cmdCreateRes.Flags().StringSliceVar(&nics, "nics", nil, "comma-delimited list of interface roles to join the vlan")
+	cmdCreateRes.Flags().StringVar(&topology, "topology", "", "networks of the reservation, each with its own vlan")
 	cmdCreateRes.Flags().StringVarP(&kernelArgs, "kernel-args", "k", "", "kernel args to append to a distro")
 	cmdCreateRes.Flags().StringVar(&desc, "desc", "", "description of the reservation")
 	cmdCreateRes.Flags().BoolVar(&noCycle, "no-cycle", false, "do not power cycle nodes at startup")
@@ -238,7 +272,9 @@ igor res create Twit2 -p twitserv -n dq74,dq9 -s ` + exStartDts() + ` -e 6d -v T
 	_ = registerFlagArgsFunc(cmdCreateRes, "end", []string{"DATE/DUR"})
 	_ = registerFlagArgsFunc(cmdCreateRes, "owner", []string{"USER"})
 	_ = registerFlagArgsFunc(cmdCreateRes, "group", []string{"GROUP"})
-	_ = registerFlagArgsFunc(cmdCreateRes, "vlan", []string{"ID/RES"})
+	_ = registerFlagArgsFunc(cmdCreateRes, "vlan", []string{"ID/VLAN/RES"})
+	_ = registerFlagArgsFunc(cmdCreateRes, "nics", []string{"ROLE1"})
+	_ = registerFlagArgsFunc(cmdCreateRes, "topology", []string{"\"SPEC\""})
 	_ = registerFlagArgsFunc(cmdCreateRes, "kernel-args", []string{"\"KARGS\""})
 	_ = registerFlagArgsFunc(cmdCreateRes, "desc", []string{"\"DESCRIPTION\""})
 
@@ -264,7 +300,19 @@ present, search will only match against the user's reservations.
 Use the -n, -o, -d, -p and -g flags to narrow results. Multiple values for a
 given flag should be comma-delimited.
 
-Use the -x flag to render screen output without pretty formatting.
+Use the -x flag to render screen output without pretty formatting. This output
+also lists the furthest boot stage each host of a started reservation has
+reached, in order:
+
+  pxe-written        igor wrote the host's PXE config
+  kernel-fetched     the host fetched its kernel over TFTP or HTTP
+  kickstart-fetched  the installer fetched its kickstart/preseed file
+  install-complete   the install or disk image write called back as done
+  os-reachable       the host answered a status probe after booting
+
+A host that stays at one stage long after the others have moved on is likely
+stuck there. Kernel fetches over TFTP are only seen when igor runs the built-in
+TFTP server.
 `,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -494,7 +542,7 @@ member or an admin.
 	return cmdDeleteRes
 }
 
-func doCreateReservation(resName, distro, profile, owner, group, desc, stime, etime, vlan, nodes, kernelArgs string, noCycle *bool) *common.ResponseBodyBasic {
+func doCreateReservation(resName, distro, profile, owner, group, desc, stime, etime, vlan string, nics []string, topology, nodes, kernelArgs string, noCycle *bool) *common.ResponseBodyBasic {
 
 	params := map[string]interface{}{"name": resName}
 
@@ -536,6 +584,12 @@ func doCreateReservation(resName, distro, profile, owner, group, desc, stime, et
 	if vlan != "" {
 		params["vlan"] = vlan
 	}
+	if len(nics) > 0 {
+		params["nics"] = nics
+	}
+	if topology != "" {
+		params["topology"] = topology
+	}
 	if desc != "" {
 		params["description"] = desc
 	}
@@ -699,6 +753,16 @@ func printReservations(rb *common.ResponseBodyReservations) {
 			resInfo += "  -DISTRO:       " + r.Distro + "\n"
 			resInfo += "  -HOSTS:        " + r.HostRange + "\n"
 			resInfo += "  -VLAN:         " + strconv.Itoa(r.Vlan) + "\n"
+			if len(r.NicRoles) > 0 {
+				resInfo += "  -NICS:         " + strings.Join(r.NicRoles, ",") + "\n"
+			}
+			for _, n := range r.Networks {
+				hosts := n.Hosts
+				if hosts == "" {
+					hosts = "(all)"
+				}
+				resInfo += fmt.Sprintf("  -NETWORK:      %s vlan=%d hosts=%s nics=%s\n", n.Name, n.Vlan, hosts, strings.Join(n.Nics, ","))
+			}
 			resInfo += "  -START:        " + getLocTime(time.Unix(r.Start, 0)).Format(timeFmt) + "\n"
 			resInfo += "  -END:          " + getLocTime(time.Unix(r.End, 0)).Format(timeFmt) + "\n"
 			resInfo += "  -ORIG-END:     " + getLocTime(time.Unix(r.OrigEnd, 0)).Format(timeFmt) + "\n"
@@ -707,6 +771,17 @@ func printReservations(rb *common.ResponseBodyReservations) {
 			if len(r.InstallError) > 0 {
 				resInfo += "  -INSTALL-ERR:  " + r.InstallError + "\n"
 			}
+			for i, b := range r.BootProgress {
+				label := "                "
+				if i == 0 {
+					label = "  -BOOT:         "
+				}
+				resInfo += label + b.Host + " " + b.Stage + " " + getLocTime(time.Unix(b.Time, 0)).Format(timeFmt)
+				if b.Detail != "" {
+					resInfo += " (" + b.Detail + ")"
+				}
+				resInfo += "\n"
+			}
 			fmt.Print(resInfo + "\n\n")
 		}
 
@@ -755,7 +830,7 @@ func printReservations(rb *common.ResponseBodyReservations) {
 				r.Distro,
 				multilineNodeList(20, r.HostRange, ""),
 				multilineNodeList(20, downNA, ""),
-				r.Vlan,
+				resVlanCell(&r),
 				getLocTime(time.Unix(r.Start, 0)).Format(startTimeFmt),
 				getLocTime(time.Unix(r.End, 0)).Format(timeFmt),
 				installed,
@@ -782,3 +857,15 @@ func printReservations(rb *common.ResponseBodyReservations) {
 	}
 
 }
+
+// resVlanCell returns the vlan of the reservation, or the vlan of each of its networks if it has a topology.
+func resVlanCell(r *common.ReservationData) string {
+	if len(r.Networks) == 0 {
+		return strconv.Itoa(r.Vlan)
+	}
+	vlans := make([]string, 0, len(r.Networks))
+	for _, n := range r.Networks {
+		vlans = append(vlans, n.Name+":"+strconv.Itoa(n.Vlan))
+	}
+	return strings.Join(vlans, "\n")
+}
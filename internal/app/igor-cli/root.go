@@ -84,8 +84,10 @@ to turn off color, set the NO_COLOR environment variable in your shell or use
 	rootCmd.AddCommand(newHostCmd())
 	rootCmd.AddCommand(newHostPowerCmd()) // adding power command to root menu for user convenience
 	rootCmd.AddCommand(newHostPolicyCmd())
+	rootCmd.AddCommand(newVlanCmd())
 	rootCmd.AddCommand(newImageCmd())
 	rootCmd.AddCommand(newKSCmd())
+	rootCmd.AddCommand(newBootTemplateCmd())
 	rootCmd.AddCommand(newDistroCmd())
 	rootCmd.AddCommand(newProfileCmd())
 	rootCmd.AddCommand(newResCmd())
@@ -37,6 +37,9 @@ const (
 	Unreserved   = "UNRESERVED"
 	Restricted   = "RESTRICTED"
 	InstallErr   = "INST ERROR"
+	Repair       = "REPAIR"
+	BurnIn       = "BURN-IN"
+	Decommission = "DECOMMISSIONED"
 )
 
 func newShowCmd() *cobra.Command {
@@ -64,6 +67,9 @@ Node Reservation Status (background color):
   ` + cBlockedUp.Sprint(Blocked) + `     : node not accepting reservations
   ` + cRestrictedUp.Sprint(Restricted) + `  : node has group/time access policy restriction
   ` + cInstError.Sprint("INSTALL ERR") + ` : reservation failed to install
+  ` + cRepair.Sprint(Repair) + `      : node out for repair
+  ` + cBurnIn.Sprint(BurnIn) + `     : node in burn-in, reservable by admins only
+  ` + cDecommissioned.Sprint("DECOMM") + `      : node decommissioned, never scheduled
 
   ` + cOwnerRes.Sprint("RESERVED") + `    : node reserved by you or accessible via member group
   ` + cOtherRes.Sprint("RESERVED") + `    : node reserved by another user
@@ -447,6 +453,9 @@ func printShow(rb *common.ResponseBodyShow, flagset *pflag.FlagSet) {
 	var blockedNodes []string
 	var restrictedNodes []string
 	var powerUnknownNodes []string
+	var repairNodes []string
+	var burnInNodes []string
+	var decommNodes []string
 
 	for i := 0; i < len(showData.Hosts); i++ {
 		h := &showData.Hosts[i]
@@ -459,6 +468,12 @@ func printShow(rb *common.ResponseBodyShow, flagset *pflag.FlagSet) {
 		}
 		if h.State == strings.ToLower(Blocked) {
 			blockedNodes = append(blockedNodes, h.Name)
+		} else if h.State == strings.ToLower(Repair) {
+			repairNodes = append(repairNodes, h.Name)
+		} else if h.State == strings.ToLower(BurnIn) {
+			burnInNodes = append(burnInNodes, h.Name)
+		} else if h.State == strings.ToLower(Decommission) {
+			decommNodes = append(decommNodes, h.Name)
 		} else if h.State == strings.ToLower(Reserved) {
 			continue
 		} else if !resNodes[i+1] {
@@ -535,6 +550,18 @@ func printShow(rb *common.ResponseBodyShow, flagset *pflag.FlagSet) {
 		makeNodeRow(installErrorNodes, cInstError, InstallErr)
 	}
 
+	if len(repairNodes) > 0 {
+		makeNodeRow(repairNodes, cRepair, Repair)
+	}
+
+	if len(burnInNodes) > 0 {
+		makeNodeRow(burnInNodes, cBurnIn, BurnIn)
+	}
+
+	if len(decommNodes) > 0 {
+		makeNodeRow(decommNodes, cDecommissioned, Decommission)
+	}
+
 	if simplePrint {
 		nst.Style().Options.SeparateRows = false
 		nst.Style().Options.SeparateColumns = false
@@ -816,6 +843,12 @@ func printNodeMap(cData common.ClusterData, hData []common.HostData, rData []com
 				} else if hDataMap[seqID].State == "blocked" {
 					// set node background for blocked
 					row = append(row, colorNode.SetBg(BgBlocked).AddOpts(color.Bold).Sprint(name))
+				} else if hDataMap[seqID].State == "repair" {
+					// set node background for repair
+					row = append(row, colorNode.SetBg(BgRepair).AddOpts(color.Bold).Sprint(name))
+				} else if hDataMap[seqID].State == "decommissioned" {
+					// set node background for decommissioned
+					row = append(row, colorNode.SetBg(BgDecomm).Sprint(name))
 				} else if resIndex, ok := n2r[seqID]; ok {
 
 					// set node background based on user reservation access
@@ -834,6 +867,9 @@ func printNodeMap(cData common.ClusterData, hData []common.HostData, rData []com
 						row = append(row, colorNode.Sprint(name))
 					}
 
+				} else if hDataMap[seqID].State == "burn-in" {
+					// set node background for burn-in when not running an admin reservation
+					row = append(row, colorNode.SetBg(BgBurnIn).AddOpts(color.Bold).Sprint(name))
 				} else if restricted[seqID] {
 					// set node background for restricted
 					row = append(row, colorNode.SetBg(BgRestricted).Sprintf(name))
@@ -44,6 +44,11 @@ value of 0 will include the entire history up to the start point.
 Use the -v flag can be specified for verbose output, showing additional stat
 usage breakdown by user.
 
+Hosts that changed power/network status during the stats window are listed
+with the number of status changes, the number of times the host dropped from
+the up status, and the percentage of recorded time the host was up. Hosts that
+changed status repeatedly in a short period are marked as flapping.
+
 ` + adminOnlyBanner + ``,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -141,4 +146,15 @@ func printStats(rb *common.ResponseBodyStats) {
 	fmt.Printf("Extensions used: %v\n", data.Global.NumExtensions)
 	fmt.Printf("Total Reservation Time: %v\n", data.Global.TotalResTime)
 
+	if len(data.Reliability) > 0 {
+		fmt.Printf("\nHost Reliability (hosts with status changes, least reliable first):\n")
+		for _, hr := range data.Reliability {
+			flapping := ""
+			if hr.Flapping {
+				flapping = "\tFLAPPING"
+			}
+			fmt.Printf("%v\tstatus changes: %v\toutages: %v\tup: %.1f%%%s\n", hr.Host, hr.Transitions, hr.Outages, hr.UpPercent, flapping)
+		}
+	}
+
 }
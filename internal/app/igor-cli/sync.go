@@ -22,20 +22,30 @@ import (
 func newSyncCmd() *cobra.Command {
 
 	cmdSync := &cobra.Command{
-		Use:   "sync {arista} [-f] [-q] [-s]",
+		Use:   "sync {vlan|arista|nxapi|ssh|status} [-f] [-q] [-s]",
 		Short: "Report/repair status of vlan service " + adminOnly,
 		Long: `
 Displays status and information about the vlan network service based on command
 given.
 
-` + requiredArgs + ` (there is only one supported vlan switch type at this time)
+` + requiredArgs + `
 
-    arista :
+    vlan :
        For each host currently associated with a reservation, sync will report
+       - the switch the host is connected to
        - the vlan value assigned to the host by the switch
        - the vlan value assigned to the host by the reservation
        - whether the reservation is powered
 
+    arista, nxapi, ssh :
+       Same as vlan, but only for hosts on switches using the named driver.
+
+    status :
+       Shows the result of the most recent scheduled vlan reconcile, if the
+       server has one configured. Hosts whose switch port vlan differs from
+       the vlan of their reservation are listed along with when the drift was
+       first seen and whether it was repaired. Flags are ignored.
+
 ` + optionalFlags + `
 
 Use the -f flag to force host vlan ids in the switch to the value indicated by
@@ -61,6 +71,10 @@ All the hosts included in a reservation will undergo the sync operation.
 			force := flagset.Changed("force")
 			quiet := flagset.Changed("quiet")
 			scope, _ := flagset.GetString("scope")
+			if strings.ToLower(args[0]) == "status" {
+				printSyncStatus(doSyncStatus())
+				return
+			}
 			result := doSync(args[0], force, quiet, scope)
 			printSync(result)
 		},
@@ -68,7 +82,7 @@ All the hosts included in a reservation will undergo the sync operation.
 			if len(args) != 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
-			return []string{"arista"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"vlan", "arista", "nxapi", "ssh", "status"}, cobra.ShellCompDirectiveNoFileComp
 		},
 	}
 
@@ -126,7 +140,7 @@ func printSync(rb *common.ResponseBodySync) {
 		printSimple("no hosts are in use, nothing to report", cRespSuccess)
 	} else {
 		pgt := table.NewWriter()
-		headers := table.Row{"HOST", "POWERED", "RESERVATION VLAN", "SWITCH VLAN"}
+		headers := table.Row{"HOST", "SWITCH", "POWERED", "RESERVATION VLAN", "SWITCH VLAN"}
 		if force {
 			headers = append(headers, "FORCE RESULTS")
 		}
@@ -141,6 +155,7 @@ func printSync(rb *common.ResponseBodySync) {
 			} else {
 				poweredColor = color.FgLightGreen.Sprint(powered)
 			}
+			switchName, _ := nodeReportData["switch"].(string)
 			resVlan := nodeReportData["res_vlan"].(string)
 			switchVlan := nodeReportData["switch_vlan"].(string)
 			mismatch := switchVlan != resVlan
@@ -151,7 +166,7 @@ func printSync(rb *common.ResponseBodySync) {
 				switchVlanColor = color.FgLightGreen.Sprint(switchVlan)
 			}
 			if !quiet || mismatch {
-				row := []interface{}{node, poweredColor, resVlan, switchVlanColor}
+				row := []interface{}{node, switchName, poweredColor, resVlan, switchVlanColor}
 				if force && mismatch {
 					status := nodeReportData["status"].(string)
 					row = append(row, status)
@@ -168,3 +183,58 @@ func printSync(rb *common.ResponseBodySync) {
 		}
 	}
 }
+
+func doSyncStatus() *common.ResponseBodySyncStatus {
+	body := doSend(http.MethodGet, api.SyncStatus, nil)
+	rb := common.ResponseBodySyncStatus{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func printSyncStatus(rb *common.ResponseBodySyncStatus) {
+
+	vs, ok := rb.Data["status"]
+	if !rb.IsSuccess() || !ok {
+		printRespSimple(rb)
+		return
+	}
+
+	fmt.Printf("last reconcile: %s (every %d min, force=%v), %d port(s) checked\n",
+		getLocTime(vs.Time).Format(common.DateTimeLongFormat), vs.Interval, vs.Force, vs.Checked)
+	if vs.Error != "" {
+		cRespError.Printf("%sreconcile failed: %s\n", respPrefix, vs.Error)
+		if len(vs.Drift) > 0 {
+			fmt.Println("drift from the last successful reconcile:")
+		}
+	}
+	if len(vs.Drift) == 0 {
+		if vs.Error == "" {
+			printSimple("no vlan drift found", cRespSuccess)
+		}
+		return
+	}
+
+	pgt := table.NewWriter()
+	pgt.AppendHeader(table.Row{"HOST", "SWITCH", "PORT", "RESERVATION", "EXPECTED VLAN", "SWITCH VLAN", "SINCE", "REPAIRED"})
+	for _, d := range vs.Drift {
+		expected, actual := d.Expected, d.Actual
+		if expected == "" {
+			expected = "(none)"
+		}
+		if actual == "" {
+			actual = "(none)"
+		}
+		repaired := "no"
+		if d.Repaired {
+			repaired = color.FgLightGreen.Sprint("yes")
+		} else if d.RepairError != "" {
+			repaired = color.S256(15, 9).Sprint("failed: " + d.RepairError)
+		}
+		pgt.AppendRow([]interface{}{d.Host, d.Switch, d.Port, d.Reservation, expected, color.S256(15, 9).Sprint(actual),
+			getLocTime(d.Since).Format(common.DateTimeCompactFormat), repaired})
+	}
+	pgt.SetStyle(table.StyleLight)
+	pgt.Style().Options.DrawBorder = false
+	fmt.Println(pgt.Render())
+}
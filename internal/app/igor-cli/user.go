@@ -40,6 +40,7 @@ need to recognized by LDAP on that node if that feature is enabled.
 	cmdUser.AddCommand(newUserEditCmd())
 	cmdUser.AddCommand(newUserDelCmd())
 	cmdUser.AddCommand(newResetPassCmd())
+	cmdUser.AddCommand(newUserKeysCmd())
 
 	return cmdUser
 }
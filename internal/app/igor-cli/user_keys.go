@@ -0,0 +1,286 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"igor2/internal/pkg/api"
+	"igor2/internal/pkg/common"
+)
+
+func newUserKeysCmd() *cobra.Command {
+
+	cmdKeys := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage your registered SSH keys",
+		Long: `
+User SSH key primary command. A sub-command must be invoked to do anything.
+
+SSH public keys registered with igor are given to the hosts of reservations
+you own or belong to through the group of the reservation. Kickstart files
+marked as templates can install them using .Owner.SSHKeys (the owner's keys)
+or .SSHKeys (the keys of the owner and group members), and cloud-init images
+get them as public-keys from igor's NoCloud datasource. Hosts can also fetch
+them in authorized_keys form from the callback service at
+
+  http://<igor callback host>:<port>` + api.CbKeys + `
+
+Members of the group of all users are not included.
+
+By default these commands act on the last user to successfully log in to igor.
+Elevated admins can use the -u flag to manage another user's keys.
+`,
+	}
+
+	cmdKeys.AddCommand(newUserKeysAddCmd())
+	cmdKeys.AddCommand(newUserKeysListCmd())
+	cmdKeys.AddCommand(newUserKeysDelCmd())
+
+	return cmdKeys
+}
+
+func newUserKeysAddCmd() *cobra.Command {
+
+	cmdAddKey := &cobra.Command{
+		Use:   "add {KEY | -f FILE} [-n NAME] [-u USER]",
+		Short: "Register an SSH public key",
+		Long: `
+Registers an SSH public key with igor.
+
+` + requiredArgs + `
+
+  KEY : the public key, ex. "ssh-ed25519 AAAA... me@laptop" (quote it)
+    >> OR <<
+  -f FILE : file to read the public key from, ex. ~/.ssh/id_ed25519.pub
+
+` + optionalFlags + `
+
+Use the -n flag to name the key. The name defaults to the key's comment if it
+is a valid name, otherwise to key1, key2, etc.
+
+Use the -u flag to add the key for another user (elevated admins only).
+
+` + notesOnUsage + `
+
+Only one key can be added at a time and it can't have authorized_keys options.
+A user can register up to 20 keys.
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flagset := cmd.Flags()
+			file, _ := flagset.GetString("file")
+			name, _ := flagset.GetString("name")
+			userName, _ := flagset.GetString("user")
+			userName, err := keysUserName(userName)
+			if err != nil {
+				return err
+			}
+			var key string
+			switch {
+			case len(args) == 1 && file != "":
+				return fmt.Errorf("give either KEY or -f FILE, not both")
+			case len(args) == 1:
+				key = args[0]
+			case file != "":
+				data, rErr := os.ReadFile(file)
+				if rErr != nil {
+					return rErr
+				}
+				key = string(data)
+			default:
+				return fmt.Errorf("give the key or a file to read it from with -f")
+			}
+			printRespSimple(doAddSSHKey(userName, key, name))
+			return nil
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return []string{"\"KEY\""}, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+
+	var file,
+		name,
+		userName string
+	cmdAddKey.Flags().StringVarP(&file, "file", "f", "", "file containing the public key")
+	cmdAddKey.Flags().StringVarP(&name, "name", "n", "", "name of the key")
+	cmdAddKey.Flags().StringVarP(&userName, "user", "u", "", "user to add the key for")
+	_ = registerFlagArgsFunc(cmdAddKey, "name", []string{"NAME"})
+	_ = registerFlagArgsFunc(cmdAddKey, "user", []string{"USER"})
+
+	return cmdAddKey
+}
+
+func newUserKeysListCmd() *cobra.Command {
+
+	cmdListKeys := &cobra.Command{
+		Use:   "list [-u USER] [-x]",
+		Short: "Show registered SSH keys",
+		Long: `
+Shows the SSH public keys registered with igor.
+
+` + optionalFlags + `
+
+Use the -u flag to show another user's keys (elevated admins only).
+
+Use the -x flag to render screen output without pretty formatting. The full
+keys are printed in this mode.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flagset := cmd.Flags()
+			simplePrint = flagset.Changed("simple")
+			userName, _ := flagset.GetString("user")
+			userName, err := keysUserName(userName)
+			if err != nil {
+				return err
+			}
+			printSSHKeys(doListSSHKeys(userName))
+			return nil
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNoArgs,
+	}
+
+	var userName string
+	cmdListKeys.Flags().StringVarP(&userName, "user", "u", "", "user to show keys of")
+	cmdListKeys.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
+	_ = registerFlagArgsFunc(cmdListKeys, "user", []string{"USER"})
+
+	return cmdListKeys
+}
+
+func newUserKeysDelCmd() *cobra.Command {
+
+	cmdDelKey := &cobra.Command{
+		Use:   "del NAME [-u USER]",
+		Short: "Delete a registered SSH key",
+		Long: `
+Deletes an SSH public key registered with igor. Hosts that already installed
+the key keep it until they are reinstalled.
+
+` + requiredArgs + `
+
+  NAME : name of the key
+
+` + optionalFlags + `
+
+Use the -u flag to delete another user's key (elevated admins only).
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userName, _ := cmd.Flags().GetString("user")
+			userName, err := keysUserName(userName)
+			if err != nil {
+				return err
+			}
+			printRespSimple(doDeleteSSHKey(userName, args[0]))
+			return nil
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var userName string
+	cmdDelKey.Flags().StringVarP(&userName, "user", "u", "", "user to delete the key of")
+	_ = registerFlagArgsFunc(cmdDelKey, "user", []string{"USER"})
+
+	return cmdDelKey
+}
+
+// keysUserName returns the user whose keys are managed, which is the last user to log in unless
+// one was given.
+func keysUserName(given string) (string, error) {
+	if given != "" {
+		return given, nil
+	}
+	osUser, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return readLastAccessUser(osUser)
+}
+
+func doAddSSHKey(userName, key, name string) *common.ResponseBodyBasic {
+	params := map[string]interface{}{"key": key}
+	if name != "" {
+		params["name"] = name
+	}
+	body := doSend(http.MethodPost, api.Users+"/"+userName+"/keys", params)
+	return unmarshalBasicResponse(body)
+}
+
+func doListSSHKeys(userName string) *common.ResponseBodySSHKeys {
+	body := doSend(http.MethodGet, api.Users+"/"+userName+"/keys", nil)
+	rb := common.ResponseBodySSHKeys{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func doDeleteSSHKey(userName, name string) *common.ResponseBodyBasic {
+	body := doSend(http.MethodDelete, api.Users+"/"+userName+"/keys/"+name, nil)
+	return unmarshalBasicResponse(body)
+}
+
+func printSSHKeys(rb *common.ResponseBodySSHKeys) {
+
+	checkAndSetColorLevel(rb)
+
+	keys := rb.Data["keys"]
+	if len(keys) == 0 {
+		printSimple("no ssh keys registered", cRespWarn)
+		return
+	}
+
+	tw := table.NewWriter()
+	header := table.Row{"NAME", "TYPE", "FINGERPRINT", "COMMENT", "ADDED"}
+	if simplePrint {
+		header = append(header, "KEY")
+	}
+	tw.AppendHeader(header)
+
+	for _, k := range keys {
+		fields := strings.SplitN(k.Key, " ", 3)
+		keyType, comment := fields[0], ""
+		if len(fields) == 3 {
+			comment = fields[2]
+		}
+		row := table.Row{
+			k.Name,
+			keyType,
+			k.Fingerprint,
+			comment,
+			getLocTime(time.Unix(k.Created, 0)).Format("Jan 02 2006"),
+		}
+		if simplePrint {
+			row = append(row, k.Key)
+		}
+		tw.AppendRow(row)
+	}
+
+	if simplePrint {
+		tw.Style().Options.SeparateRows = false
+		tw.Style().Options.SeparateColumns = true
+		tw.Style().Options.DrawBorder = false
+	} else {
+		tw.SetStyle(igorTableStyle)
+	}
+
+	fmt.Printf("\n%s\n\n", tw.Render())
+}
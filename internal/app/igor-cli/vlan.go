@@ -0,0 +1,729 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+
+	"igor2/internal/pkg/api"
+	"igor2/internal/pkg/common"
+)
+
+func newVlanCmd() *cobra.Command {
+
+	cmdVlan := &cobra.Command{
+		Use:   "vlan",
+		Short: "Perform a vlan command",
+		Long: `
+Vlan primary command. A sub-command must be invoked to do anything.
+
+On clusters with vlan segmentation enabled, every reservation is placed on its
+own vlan. By default igor picks a free id from the server's vlan range when the
+reservation is made and releases it when the reservation ends.
+
+Named vlans are ids set aside permanently under a name. They persist after the
+reservations using them end, so a project can keep the same network segment
+from one reservation to the next. A named vlan can be used by its owner and by
+members of its groups by passing its name to 'igor res create -v'.
+
+Vlan pools carve up the server's vlan range. A pool can be limited to members
+of certain groups, to reservations whose hosts use certain policies, or both.
+Automatically assigned vlans come from a pool the reservation is eligible for
+before falling back to ids that aren't part of any pool.
+
+` + sBold("Creating named vlans and all pool commands except 'show' are admin-only.") + `
+`,
+	}
+
+	cmdVlan.AddCommand(newVlanCreateCmd())
+	cmdVlan.AddCommand(newVlanShowCmd())
+	cmdVlan.AddCommand(newVlanEditCmd())
+	cmdVlan.AddCommand(newVlanDelCmd())
+	cmdVlan.AddCommand(newVlanPoolCmd())
+	return cmdVlan
+}
+
+func newVlanCreateCmd() *cobra.Command {
+
+	cmdCreateVlan := &cobra.Command{
+		Use:   "create NAME [--id ID | -p POOL] [-o OWNER] [-g GRP1,...] [-d DESC]",
+		Short: "Create a named vlan " + adminOnly,
+		Long: `
+Creates a named vlan that persists until it is deleted.
+
+` + requiredArgs + `
+
+  NAME : vlan name
+
+` + optionalFlags + `
+
+Use the --id flag to choose the vlan id, or the -p flag to take the next free
+id from a vlan pool. If neither is given the id is picked the same way as for
+a reservation made by the owner. An id chosen with --id may already be in use,
+but only by reservations belonging to the owner.
+
+Use the -o flag to set the owner of the vlan. The default owner is the user
+running the command.
+
+Use the -g flag to set groups whose members can also use the vlan.
+
+Use the -d flag to give the vlan a description.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			id, _ := flagset.GetInt("id")
+			pool, _ := flagset.GetString("pool")
+			owner, _ := flagset.GetString("owner")
+			groups, _ := flagset.GetStringSlice("groups")
+			desc, _ := flagset.GetString("desc")
+			printRespSimple(doCreateVlan(args[0], id, pool, owner, groups, desc, flagset.Changed("desc")))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var id int
+	var pool, owner, desc string
+	var groups []string
+
+	cmdCreateVlan.Flags().IntVar(&id, "id", 0, "vlan id to assign")
+	cmdCreateVlan.Flags().StringVarP(&pool, "pool", "p", "", "vlan pool to take the id from")
+	cmdCreateVlan.Flags().StringVarP(&owner, "owner", "o", "", "owner of the vlan")
+	cmdCreateVlan.Flags().StringSliceVarP(&groups, "groups", "g", nil, "comma-delimited list of groups that can use the vlan")
+	cmdCreateVlan.Flags().StringVarP(&desc, "desc", "d", "", "description of the vlan")
+	cmdCreateVlan.MarkFlagsMutuallyExclusive("id", "pool")
+	_ = registerFlagArgsFunc(cmdCreateVlan, "id", []string{"ID"})
+	_ = registerFlagArgsFunc(cmdCreateVlan, "pool", []string{"POOL"})
+	_ = registerFlagArgsFunc(cmdCreateVlan, "owner", []string{"OWNER"})
+	_ = registerFlagArgsFunc(cmdCreateVlan, "groups", []string{"GRP1"})
+	_ = registerFlagArgsFunc(cmdCreateVlan, "desc", []string{"\"DESCRIPTION\""})
+
+	return cmdCreateVlan
+}
+
+func newVlanShowCmd() *cobra.Command {
+
+	cmdShowVlan := &cobra.Command{
+		Use:   "show [-n NAME1,...] [-o OWNER1,...] [-p POOL1,...] [-x]",
+		Short: "Show vlan information",
+		Long: `
+Shows named vlans and the vlans of current reservations, returning matches to
+specified parameters. If no optional filtering parameters are provided then
+all vlans will be returned.
+
+` + optionalFlags + `
+
+Use the -n, -o and -p flags to filter the returned list by vlan names, owners
+and pools respectively.
+
+Use the -x flag to render screen output without pretty formatting.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			names, _ := flagset.GetStringSlice("names")
+			owners, _ := flagset.GetStringSlice("owners")
+			pools, _ := flagset.GetStringSlice("pools")
+			simplePrint = flagset.Changed("simple")
+			printVlans(doShowVlans(names, owners, pools))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNoArgs,
+	}
+
+	var names, owners, pools []string
+
+	cmdShowVlan.Flags().StringSliceVarP(&names, "names", "n", nil, "comma-delimited list of vlan names")
+	cmdShowVlan.Flags().StringSliceVarP(&owners, "owners", "o", nil, "comma-delimited list of owner names")
+	cmdShowVlan.Flags().StringSliceVarP(&pools, "pools", "p", nil, "comma-delimited list of vlan pool names")
+	cmdShowVlan.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
+	_ = registerFlagArgsFunc(cmdShowVlan, "names", []string{"NAME1"})
+	_ = registerFlagArgsFunc(cmdShowVlan, "owners", []string{"OWNER1"})
+	_ = registerFlagArgsFunc(cmdShowVlan, "pools", []string{"POOL1"})
+
+	return cmdShowVlan
+}
+
+func newVlanEditCmd() *cobra.Command {
+
+	cmdEditVlan := &cobra.Command{
+		Use:   "edit NAME { [-n NEWNAME] [-o OWNER] [-g GRP1,...] [-r GRP1,...] [-d DESC] }",
+		Short: "Edit a named vlan",
+		Long: `
+Edits named vlan information. Only the vlan owner or an admin can edit a vlan.
+The vlan id cannot be changed.
+
+` + requiredArgs + `
+
+  NAME : vlan name
+
+` + optionalFlags + `
+
+Use the -n flag to re-name the vlan.
+
+Use the -o flag to give the vlan to another user. This can only be done by an
+admin.
+
+Use the -g flag to add groups and the -r flag to remove groups that can use the
+vlan.
+
+Use the -d flag to change the description of the vlan.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			name, _ := flagset.GetString("name")
+			owner, _ := flagset.GetString("owner")
+			groupAdd, _ := flagset.GetStringSlice("add-groups")
+			groupRemove, _ := flagset.GetStringSlice("remove-groups")
+			desc, _ := flagset.GetString("desc")
+			printRespSimple(doEditVlan(args[0], name, owner, groupAdd, groupRemove, desc, flagset.Changed("desc")))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var name, owner, desc string
+	var groupA, groupR []string
+
+	cmdEditVlan.Flags().StringVarP(&name, "name", "n", "", "new name to assign to this vlan")
+	cmdEditVlan.Flags().StringVarP(&owner, "owner", "o", "", "new owner of the vlan")
+	cmdEditVlan.Flags().StringSliceVarP(&groupA, "add-groups", "g", nil, "comma-delimited list of groups to grant use")
+	cmdEditVlan.Flags().StringSliceVarP(&groupR, "remove-groups", "r", nil, "comma-delimited list of groups to remove use")
+	cmdEditVlan.Flags().StringVarP(&desc, "desc", "d", "", "description of the vlan")
+	_ = registerFlagArgsFunc(cmdEditVlan, "name", []string{"NAME"})
+	_ = registerFlagArgsFunc(cmdEditVlan, "owner", []string{"OWNER"})
+	_ = registerFlagArgsFunc(cmdEditVlan, "add-groups", []string{"GRP1"})
+	_ = registerFlagArgsFunc(cmdEditVlan, "remove-groups", []string{"GRP1"})
+	_ = registerFlagArgsFunc(cmdEditVlan, "desc", []string{"\"DESCRIPTION\""})
+
+	return cmdEditVlan
+}
+
+func newVlanDelCmd() *cobra.Command {
+
+	cmdDeleteVlan := &cobra.Command{
+		Use:   "del NAME",
+		Short: "Delete a named vlan",
+		Long: `
+Deletes a named vlan, releasing its id. A named vlan cannot be deleted while a
+reservation is using it. Only the vlan owner or an admin can delete a vlan.
+
+` + requiredArgs + `
+
+  NAME : vlan name
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			printRespSimple(doDeleteVlan(args[0]))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	return cmdDeleteVlan
+}
+
+func newVlanPoolCmd() *cobra.Command {
+
+	cmdVlanPool := &cobra.Command{
+		Use:   "pool",
+		Short: "Perform a vlan pool command",
+		Long: `
+Vlan pool command. A sub-command must be invoked to do anything.
+
+A vlan pool sets aside a range of ids inside the server's vlan range. Pools
+may not overlap. Ids that aren't part of any pool are available to everyone.
+
+A pool limited to groups only supplies vlans to reservations whose owner is a
+member of one of the groups. A pool limited to policies only supplies vlans to
+reservations whose hosts all use one of the policies. When igor picks a vlan
+for a reservation it tries policy-limited pools first, then group-limited
+pools, then pools without limits and finally ids outside any pool.
+
+` + sBold("All pool commands except 'show' are admin-only.") + `
+`,
+	}
+
+	cmdVlanPool.AddCommand(newVlanPoolCreateCmd())
+	cmdVlanPool.AddCommand(newVlanPoolShowCmd())
+	cmdVlanPool.AddCommand(newVlanPoolEditCmd())
+	cmdVlanPool.AddCommand(newVlanPoolDelCmd())
+	return cmdVlanPool
+}
+
+func newVlanPoolCreateCmd() *cobra.Command {
+
+	cmdCreateVlanPool := &cobra.Command{
+		Use:   "create NAME RANGE [-g GRP1,...] [-p POL1,...] [-d DESC]",
+		Short: "Create a vlan pool " + adminOnly,
+		Long: `
+Creates a new vlan pool.
+
+` + requiredArgs + `
+
+  NAME  : pool name
+  RANGE : first and last vlan id of the pool in the form MIN-MAX, ex. 200-299
+
+` + optionalFlags + `
+
+Use the -g flag to limit the pool to members of the given groups.
+
+Use the -p flag to limit the pool to reservations whose hosts use the given
+policies.
+
+Use the -d flag to give the pool a description.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flagset := cmd.Flags()
+			groups, _ := flagset.GetStringSlice("groups")
+			policies, _ := flagset.GetStringSlice("policies")
+			desc, _ := flagset.GetString("desc")
+			if res, err := doCreateVlanPool(args[0], args[1], groups, policies, desc); err != nil {
+				return err
+			} else {
+				printRespSimple(res)
+				return nil
+			}
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return []string{"NAME", "RANGE"}, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+
+	var desc string
+	var groups, policies []string
+
+	cmdCreateVlanPool.Flags().StringSliceVarP(&groups, "groups", "g", nil, "comma-delimited list of groups the pool is limited to")
+	cmdCreateVlanPool.Flags().StringSliceVarP(&policies, "policies", "p", nil, "comma-delimited list of policies the pool is limited to")
+	cmdCreateVlanPool.Flags().StringVarP(&desc, "desc", "d", "", "description of the pool")
+	_ = registerFlagArgsFunc(cmdCreateVlanPool, "groups", []string{"GRP1"})
+	_ = registerFlagArgsFunc(cmdCreateVlanPool, "policies", []string{"POL1"})
+	_ = registerFlagArgsFunc(cmdCreateVlanPool, "desc", []string{"\"DESCRIPTION\""})
+
+	return cmdCreateVlanPool
+}
+
+func newVlanPoolShowCmd() *cobra.Command {
+
+	cmdShowVlanPool := &cobra.Command{
+		Use:   "show [-n NAME1,...] [-x]",
+		Short: "Show vlan pool information",
+		Long: `
+Shows vlan pools along with how many of their ids are in use. If no optional
+filtering parameters are provided then all pools will be returned.
+
+` + optionalFlags + `
+
+Use the -n flag to filter the returned list by pool names.
+
+Use the -x flag to render screen output without pretty formatting.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flagset := cmd.Flags()
+			names, _ := flagset.GetStringSlice("names")
+			simplePrint = flagset.Changed("simple")
+			printVlanPools(doShowVlanPools(names))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNoArgs,
+	}
+
+	var names []string
+
+	cmdShowVlanPool.Flags().StringSliceVarP(&names, "names", "n", nil, "comma-delimited list of pool names")
+	cmdShowVlanPool.Flags().BoolVarP(&simplePrint, "simple", "x", false, "use simple text output")
+	_ = registerFlagArgsFunc(cmdShowVlanPool, "names", []string{"NAME1"})
+
+	return cmdShowVlanPool
+}
+
+func newVlanPoolEditCmd() *cobra.Command {
+
+	cmdEditVlanPool := &cobra.Command{
+		Use: "edit NAME { [-n NEWNAME] [--range RANGE] [-g GRP1,...] [-r GRP1,...]\n" +
+			"            [--add-policies POL1,...] [--remove-policies POL1,...] [-d DESC] }",
+		Short: "Edit a vlan pool " + adminOnly,
+		Long: `
+Edits vlan pool information. Changing a pool does not affect vlans that were
+already handed out from it.
+
+` + requiredArgs + `
+
+  NAME : pool name
+
+` + optionalFlags + `
+
+Use the -n flag to re-name the pool.
+
+Use the --range flag to resize the pool, in the form MIN-MAX.
+
+Use the -g flag to add groups and the -r flag to remove groups the pool is
+limited to. If the last group is removed then members of any group can use it.
+
+Use the --add-policies and --remove-policies flags to change the policies the
+pool is limited to.
+
+Use the -d flag to change the description of the pool.
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flagset := cmd.Flags()
+			name, _ := flagset.GetString("name")
+			vlanRange, _ := flagset.GetString("range")
+			groupAdd, _ := flagset.GetStringSlice("add-groups")
+			groupRemove, _ := flagset.GetStringSlice("remove-groups")
+			policyAdd, _ := flagset.GetStringSlice("add-policies")
+			policyRemove, _ := flagset.GetStringSlice("remove-policies")
+			desc, _ := flagset.GetString("desc")
+			if res, err := doEditVlanPool(args[0], name, vlanRange, groupAdd, groupRemove, policyAdd, policyRemove, desc, flagset.Changed("desc")); err != nil {
+				return err
+			} else {
+				printRespSimple(res)
+				return nil
+			}
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	var name, vlanRange, desc string
+	var groupA, groupR, policyA, policyR []string
+
+	cmdEditVlanPool.Flags().StringVarP(&name, "name", "n", "", "new name to assign to this pool")
+	cmdEditVlanPool.Flags().StringVar(&vlanRange, "range", "", "new vlan id range of the pool")
+	cmdEditVlanPool.Flags().StringSliceVarP(&groupA, "add-groups", "g", nil, "comma-delimited list of groups to limit the pool to")
+	cmdEditVlanPool.Flags().StringSliceVarP(&groupR, "remove-groups", "r", nil, "comma-delimited list of groups to remove from the pool")
+	cmdEditVlanPool.Flags().StringSliceVar(&policyA, "add-policies", nil, "comma-delimited list of policies to limit the pool to")
+	cmdEditVlanPool.Flags().StringSliceVar(&policyR, "remove-policies", nil, "comma-delimited list of policies to remove from the pool")
+	cmdEditVlanPool.Flags().StringVarP(&desc, "desc", "d", "", "description of the pool")
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "name", []string{"NAME"})
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "range", []string{"RANGE"})
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "add-groups", []string{"GRP1"})
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "remove-groups", []string{"GRP1"})
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "add-policies", []string{"POL1"})
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "remove-policies", []string{"POL1"})
+	_ = registerFlagArgsFunc(cmdEditVlanPool, "desc", []string{"\"DESCRIPTION\""})
+
+	return cmdEditVlanPool
+}
+
+func newVlanPoolDelCmd() *cobra.Command {
+
+	cmdDeleteVlanPool := &cobra.Command{
+		Use:   "del NAME",
+		Short: "Delete a vlan pool " + adminOnly,
+		Long: `
+Deletes a vlan pool. Vlans already handed out from the pool are not affected
+and its ids become available to everyone.
+
+` + requiredArgs + `
+
+  NAME : pool name
+
+` + adminOnlyBanner + `
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			printRespSimple(doDeleteVlanPool(args[0]))
+		},
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validateNameArg,
+	}
+
+	return cmdDeleteVlanPool
+}
+
+func doCreateVlan(name string, id int, pool string, owner string, groups []string, desc string, descSet bool) *common.ResponseBodyBasic {
+	params := map[string]interface{}{"name": name}
+	if id > 0 {
+		params["id"] = id
+	}
+	if pool != "" {
+		params["pool"] = pool
+	}
+	if owner != "" {
+		params["owner"] = owner
+	}
+	if len(groups) > 0 {
+		params["groups"] = groups
+	}
+	if descSet {
+		params["description"] = desc
+	}
+	body := doSend(http.MethodPost, api.Vlans, params)
+	return unmarshalBasicResponse(body)
+}
+
+func doShowVlans(names []string, owners []string, pools []string) *common.ResponseBodyVlans {
+
+	var params string
+	for _, n := range names {
+		params += "name=" + n + "&"
+	}
+	for _, o := range owners {
+		params += "owner=" + o + "&"
+	}
+	for _, p := range pools {
+		params += "pool=" + p + "&"
+	}
+	if params != "" {
+		params = "?" + strings.TrimSuffix(params, "&")
+	}
+	body := doSend(http.MethodGet, api.Vlans+params, nil)
+	rb := common.ResponseBodyVlans{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func doEditVlan(name string, newName string, owner string, groupAdd []string, groupRemove []string, desc string, descSet bool) *common.ResponseBodyBasic {
+	apiPath := api.Vlans + "/" + name
+	params := make(map[string]interface{})
+	if newName != "" {
+		params["name"] = newName
+	}
+	if owner != "" {
+		params["owner"] = owner
+	}
+	if len(groupAdd) > 0 {
+		params["addGroups"] = groupAdd
+	}
+	if len(groupRemove) > 0 {
+		params["removeGroups"] = groupRemove
+	}
+	if descSet {
+		params["description"] = desc
+	}
+	body := doSend(http.MethodPatch, apiPath, params)
+	return unmarshalBasicResponse(body)
+}
+
+func doDeleteVlan(name string) *common.ResponseBodyBasic {
+	apiPath := api.Vlans + "/" + name
+	body := doSend(http.MethodDelete, apiPath, nil)
+	return unmarshalBasicResponse(body)
+}
+
+// parseVlanRange splits a MIN-MAX vlan range into its two ids.
+func parseVlanRange(vlanRange string) (int, int, error) {
+	parts := strings.Split(vlanRange, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("vlan range '%s' must be in the form MIN-MAX", vlanRange)
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("vlan range '%s' must be in the form MIN-MAX", vlanRange)
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("vlan range '%s' must be in the form MIN-MAX", vlanRange)
+	}
+	return min, max, nil
+}
+
+func doCreateVlanPool(name string, vlanRange string, groups []string, policies []string, desc string) (*common.ResponseBodyBasic, error) {
+	min, max, err := parseVlanRange(vlanRange)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"name": name, "min": min, "max": max}
+	if len(groups) > 0 {
+		params["groups"] = groups
+	}
+	if len(policies) > 0 {
+		params["policies"] = policies
+	}
+	if desc != "" {
+		params["description"] = desc
+	}
+	body := doSend(http.MethodPost, api.VlanPools, params)
+	return unmarshalBasicResponse(body), nil
+}
+
+func doShowVlanPools(names []string) *common.ResponseBodyVlanPools {
+
+	var params string
+	for _, n := range names {
+		params += "name=" + n + "&"
+	}
+	if params != "" {
+		params = "?" + strings.TrimSuffix(params, "&")
+	}
+	body := doSend(http.MethodGet, api.VlanPools+params, nil)
+	rb := common.ResponseBodyVlanPools{}
+	err := json.Unmarshal(*body, &rb)
+	checkUnmarshalErr(err)
+	return &rb
+}
+
+func doEditVlanPool(name string, newName string, vlanRange string, groupAdd []string, groupRemove []string, policyAdd []string, policyRemove []string, desc string, descSet bool) (*common.ResponseBodyBasic, error) {
+	apiPath := api.VlanPools + "/" + name
+	params := make(map[string]interface{})
+	if newName != "" {
+		params["name"] = newName
+	}
+	if vlanRange != "" {
+		min, max, err := parseVlanRange(vlanRange)
+		if err != nil {
+			return nil, err
+		}
+		params["min"] = min
+		params["max"] = max
+	}
+	if len(groupAdd) > 0 {
+		params["addGroups"] = groupAdd
+	}
+	if len(groupRemove) > 0 {
+		params["removeGroups"] = groupRemove
+	}
+	if len(policyAdd) > 0 {
+		params["addPolicies"] = policyAdd
+	}
+	if len(policyRemove) > 0 {
+		params["removePolicies"] = policyRemove
+	}
+	if descSet {
+		params["description"] = desc
+	}
+	body := doSend(http.MethodPatch, apiPath, params)
+	return unmarshalBasicResponse(body), nil
+}
+
+func doDeleteVlanPool(name string) *common.ResponseBodyBasic {
+	apiPath := api.VlanPools + "/" + name
+	body := doSend(http.MethodDelete, apiPath, nil)
+	return unmarshalBasicResponse(body)
+}
+
+func printVlans(rb *common.ResponseBodyVlans) {
+
+	checkAndSetColorLevel(rb)
+
+	vlanList := rb.Data["vlans"]
+	if len(vlanList) == 0 {
+		printSimple("no vlans to show (yet) or no matches based on search criteria", cRespWarn)
+	}
+
+	sort.Slice(vlanList, func(i, j int) bool {
+		return vlanList[i].ID < vlanList[j].ID
+	})
+
+	if simplePrint {
+
+		var vinfo string
+		for _, v := range vlanList {
+			vinfo = "VLAN: " + strconv.Itoa(v.ID) + "\n"
+			vinfo += "  -NAME:         " + v.Name + "\n"
+			vinfo += "  -POOL:         " + v.Pool + "\n"
+			vinfo += "  -OWNER:        " + v.Owner + "\n"
+			vinfo += "  -GROUPS:       " + strings.Join(v.Groups, ",") + "\n"
+			vinfo += "  -RESERVATIONS: " + strings.Join(v.Reservations, ",") + "\n"
+			vinfo += "  -DESCRIPTION:  " + v.Description + "\n"
+			fmt.Print(vinfo + "\n\n")
+		}
+
+	} else {
+
+		tw := table.NewWriter()
+		tw.AppendHeader(table.Row{"VLAN", "NAME", "POOL", "OWNER", "GROUPS", "RESERVATIONS", "DESCRIPTION"})
+		tw.AppendSeparator()
+
+		for _, v := range vlanList {
+			tw.AppendRow([]interface{}{
+				v.ID,
+				v.Name,
+				v.Pool,
+				v.Owner,
+				strings.Join(v.Groups, "\n"),
+				strings.Join(v.Reservations, "\n"),
+				v.Description,
+			})
+		}
+
+		tw.SetColumnConfigs([]table.ColumnConfig{
+			{Name: "VLAN", Align: text.AlignRight},
+			{Name: "DESCRIPTION", WidthMax: 40},
+		})
+
+		tw.SetStyle(igorTableStyle)
+		fmt.Printf("\n%s\n\n", tw.Render())
+	}
+}
+
+func printVlanPools(rb *common.ResponseBodyVlanPools) {
+
+	checkAndSetColorLevel(rb)
+
+	poolList := rb.Data["vlanPools"]
+	if len(poolList) == 0 {
+		printSimple("no vlan pools to show (yet) or no matches based on search criteria", cRespWarn)
+	}
+
+	sort.Slice(poolList, func(i, j int) bool {
+		return poolList[i].Min < poolList[j].Min
+	})
+
+	if simplePrint {
+
+		var pinfo string
+		for _, p := range poolList {
+			pinfo = "POOL: " + p.Name + "\n"
+			pinfo += "  -RANGE:       " + fmt.Sprintf("%d-%d", p.Min, p.Max) + "\n"
+			pinfo += "  -USED:        " + fmt.Sprintf("%d/%d", p.Used, p.Max-p.Min+1) + "\n"
+			pinfo += "  -GROUPS:      " + strings.Join(p.Groups, ",") + "\n"
+			pinfo += "  -POLICIES:    " + strings.Join(p.Policies, ",") + "\n"
+			pinfo += "  -DESCRIPTION: " + p.Description + "\n"
+			fmt.Print(pinfo + "\n\n")
+		}
+
+	} else {
+
+		tw := table.NewWriter()
+		tw.AppendHeader(table.Row{"NAME", "RANGE", "USED", "GROUPS", "POLICIES", "DESCRIPTION"})
+		tw.AppendSeparator()
+
+		for _, p := range poolList {
+			tw.AppendRow([]interface{}{
+				p.Name,
+				fmt.Sprintf("%d-%d", p.Min, p.Max),
+				fmt.Sprintf("%d/%d", p.Used, p.Max-p.Min+1),
+				strings.Join(p.Groups, "\n"),
+				strings.Join(p.Policies, "\n"),
+				p.Description,
+			})
+		}
+
+		tw.SetColumnConfigs([]table.ColumnConfig{
+			{Name: "USED", Align: text.AlignRight},
+			{Name: "DESCRIPTION", WidthMax: 40},
+		})
+
+		tw.SetStyle(igorTableStyle)
+		fmt.Printf("\n%s\n\n", tw.Render())
+	}
+}
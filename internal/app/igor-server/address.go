@@ -0,0 +1,193 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	AddrManagerDnsmasq = "dnsmasq"
+	AddrManagerISC     = "isc"
+	AddrManagerScript  = "script"
+)
+
+// AddressScope assigns addresses from Subnet to hosts whose reservation uses a vlan between
+// VlanMin and VlanMax. A host gets the address HostOffset+SequenceID past the start of the
+// subnet, so it always receives the same address within a scope.
+type AddressScope struct {
+	VlanMin    int    `yaml:"vlanMin" json:"vlanMin"`
+	VlanMax    int    `yaml:"vlanMax" json:"vlanMax"`
+	Subnet     string `yaml:"subnet" json:"subnet"`
+	HostOffset int    `yaml:"hostOffset" json:"hostOffset"`
+}
+
+// AddrEntry is the DHCP and DNS information published for one host of a reservation.
+type AddrEntry struct {
+	Reservation string
+	Host        string
+	Mac         string
+	IP          string // blank if the host has no static address and isn't in a scope
+}
+
+// IAddrManager is an interface for services that hand out DHCP leases and DNS records to
+// reservation hosts.
+type IAddrManager interface {
+	// add publishes the entries, replacing anything already published for the same hosts.
+	add(entries []AddrEntry) error
+	// remove withdraws everything published for the named hosts.
+	remove(hosts []string) error
+}
+
+// AddrInstaller wraps another IResInstaller so host addresses are published before a
+// reservation is installed and withdrawn after it is uninstalled or if its install fails.
+type AddrInstaller struct {
+	installer IResInstaller
+	manager   IAddrManager
+}
+
+func NewAddrInstaller(installer IResInstaller, manager IAddrManager) IResInstaller {
+	return &AddrInstaller{installer: installer, manager: manager}
+}
+
+func (a *AddrInstaller) Install(r *Reservation) error {
+	entries, err := addrEntries(r)
+	if err != nil {
+		return err
+	}
+	if err = a.manager.add(entries); err != nil {
+		logger.Error().Msgf("unable to publish host addresses for reservation %s: %v", r.Name, err)
+		return fmt.Errorf("error publishing host addresses, please notify admin or check error logs for more details")
+	}
+	if err = a.installer.Install(r); err != nil {
+		// the reservation isn't installed so its hosts shouldn't keep the addresses
+		if rmErr := a.manager.remove(namesOfHosts(r.Hosts)); rmErr != nil {
+			logger.Warn().Msgf("unable to withdraw host addresses for reservation %s: %v", r.Name, rmErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *AddrInstaller) Uninstall(r *Reservation) error {
+	err := a.installer.Uninstall(r)
+	releaseHostAddrs(r.Hosts)
+	return err
+}
+
+// releaseHostAddrs withdraws the published addresses of the hosts if address management is enabled.
+// Failures are logged but not returned since the hosts are leaving the reservation regardless.
+func releaseHostAddrs(hosts []Host) {
+	if igor.IAddrManager == nil || len(hosts) == 0 {
+		return
+	}
+	if err := igor.IAddrManager.remove(namesOfHosts(hosts)); err != nil {
+		logger.Warn().Msgf("unable to withdraw host addresses for %v: %v", namesOfHosts(hosts), err)
+	}
+}
+
+// addrEntries returns one entry per reservation host using the mac the host boots from. The address
+// comes from the first scope covering the reservation vlan, or the host's static IP otherwise.
+func addrEntries(r *Reservation) ([]AddrEntry, error) {
+	scope := findAddressScope(r.Vlan)
+	entries := make([]AddrEntry, 0, len(r.Hosts))
+	for _, h := range r.Hosts {
+		e := AddrEntry{Reservation: r.Name, Host: h.Name, Mac: h.bootMac(), IP: h.IP}
+		if scope != nil {
+			ip, err := scopeAddr(scope, h.SequenceID)
+			if err != nil {
+				return nil, fmt.Errorf("host %s: %v", h.Name, err)
+			}
+			e.IP = ip
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func findAddressScope(vlan int) *AddressScope {
+	if vlan <= 0 {
+		return nil
+	}
+	for i, s := range igor.Address.Scopes {
+		if vlan >= s.VlanMin && vlan <= s.VlanMax {
+			return &igor.Address.Scopes[i]
+		}
+	}
+	return nil
+}
+
+// scopeAddr returns the address of the host with the given sequence ID within the scope subnet.
+func scopeAddr(s *AddressScope, seq int) (string, error) {
+	_, subnet, err := net.ParseCIDR(s.Subnet)
+	if err != nil {
+		return "", err
+	}
+	base := subnet.IP.To4()
+	if base == nil {
+		return "", fmt.Errorf("scope subnet %s is not an IPv4 subnet", s.Subnet)
+	}
+	ones, bits := subnet.Mask.Size()
+	size := uint64(1) << uint(bits-ones)
+	offset := uint64(s.HostOffset) + uint64(seq)
+	// skip the network and broadcast addresses
+	if offset == 0 || offset >= size-1 {
+		return "", fmt.Errorf("no address left in scope subnet %s for offset %d", s.Subnet, offset)
+	}
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(base)+uint32(offset))
+	return ip.String(), nil
+}
+
+// validateAddressConfig checks the address section of the server config and applies defaults.
+func validateAddressConfig() error {
+	a := &igor.Address
+	switch a.Manager {
+	case AddrManagerDnsmasq, AddrManagerISC:
+		if a.ConfigDir == "" {
+			a.ConfigDir = filepath.Join(igor.IgorHome, "address")
+			logger.Warn().Msgf("address.configDir not specified, using default (IGOR_HOME) : %v", a.ConfigDir)
+		}
+	case AddrManagerScript:
+		if a.Script == "" {
+			return fmt.Errorf("script is required for manager %s", a.Manager)
+		}
+		if strings.Contains(a.Script, "/") || strings.Contains(a.Script, "..") {
+			return fmt.Errorf("script must be a file name in server.scriptDir, not a path")
+		}
+	default:
+		return fmt.Errorf("manager '%s' not recognized; must be one of: %s, %s, %s",
+			a.Manager, AddrManagerDnsmasq, AddrManagerISC, AddrManagerScript)
+	}
+	if a.Domain != "" && !cmdTargetRE.MatchString(a.Domain) {
+		return fmt.Errorf("domain '%s' is not a valid DNS name", a.Domain)
+	}
+	for i, s := range a.Scopes {
+		if s.VlanMin <= 0 || s.VlanMin > s.VlanMax {
+			return fmt.Errorf("scopes[%d] vlanMin/Max is invalid [%d,%d]", i, s.VlanMin, s.VlanMax)
+		}
+		if s.HostOffset < 0 {
+			return fmt.Errorf("scopes[%d] hostOffset cannot be negative", i)
+		}
+		if _, err := scopeAddr(&a.Scopes[i], 1); err != nil {
+			return fmt.Errorf("scopes[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// newAddrManager returns the IAddrManager implementation for the configured manager.
+func newAddrManager() IAddrManager {
+	switch igor.Address.Manager {
+	case AddrManagerScript:
+		return NewScriptAddrManager(filepath.Join(igor.Server.ScriptDir, igor.Address.Script))
+	default:
+		return NewFileAddrManager(igor.Address.Manager, igor.Address.ConfigDir, igor.Address.Domain, igor.Address.ReloadCmd)
+	}
+}
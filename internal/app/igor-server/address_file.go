@@ -0,0 +1,197 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// FileAddrManager implements IAddrManager.
+// It keeps one small record per host under ConfigDir/igor.d and regenerates host entry files for
+// dnsmasq or ISC dhcpd from all the records whenever something changes:
+//
+//   - dnsmasq: ConfigDir/igor-hosts.conf with dhcp-host and host-record lines, suitable for conf-dir
+//   - isc: ConfigDir/igor-hosts.conf with host blocks for dhcpd.conf to include, plus
+//     ConfigDir/igor-hosts.zone with A records for a zone file to $INCLUDE
+//
+// ReloadCmd, if set, is run after the files are rewritten so the service picks up the changes.
+type FileAddrManager struct {
+	Format    string
+	ConfigDir string
+	Domain    string
+	ReloadCmd string
+	mu        sync.Mutex
+}
+
+// NewFileAddrManager returns a file generator for the given format (dnsmasq or isc).
+func NewFileAddrManager(format, configDir, domain, reloadCmd string) IAddrManager {
+	return &FileAddrManager{
+		Format:    format,
+		ConfigDir: configDir,
+		Domain:    domain,
+		ReloadCmd: reloadCmd,
+	}
+}
+
+func (m *FileAddrManager) recordDir() string {
+	return filepath.Join(m.ConfigDir, "igor.d")
+}
+
+func (m *FileAddrManager) add(entries []AddrEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.recordDir(), 0755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		record := strings.Join([]string{e.Reservation, e.Host, e.Mac, e.IP}, " ") + "\n"
+		if err := os.WriteFile(filepath.Join(m.recordDir(), e.Host), []byte(record), 0644); err != nil {
+			return err
+		}
+	}
+	return m.regenerate()
+}
+
+func (m *FileAddrManager) remove(hosts []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, h := range hosts {
+		if err := os.Remove(filepath.Join(m.recordDir(), h)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return m.regenerate()
+}
+
+// regenerate rewrites the service files from the host records and runs the reload command.
+func (m *FileAddrManager) regenerate() error {
+	entries, err := m.readRecords()
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{}
+	switch m.Format {
+	case AddrManagerISC:
+		files["igor-hosts.conf"] = renderISCHosts(entries)
+		files["igor-hosts.zone"] = renderZoneRecords(entries)
+	default:
+		files["igor-hosts.conf"] = renderDnsmasqHosts(entries, m.Domain)
+	}
+	for name, content := range files {
+		// write to a temp file first so the service never reads a partial file
+		path := filepath.Join(m.ConfigDir, name)
+		if err = os.WriteFile(path+".tmp", []byte(content), 0644); err != nil {
+			return err
+		}
+		if err = os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+
+	if m.ReloadCmd == "" || DEVMODE {
+		return nil
+	}
+	argv, err := shellquote.Split(m.ReloadCmd)
+	if err != nil {
+		return fmt.Errorf("invalid address.reloadCmd: %w", err)
+	}
+	_, err = processWrapper(context.Background(), 30*time.Second, argv...)
+	return err
+}
+
+func (m *FileAddrManager) readRecords() ([]AddrEntry, error) {
+	dirEntries, err := os.ReadDir(m.recordDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make([]AddrEntry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if de.IsDir() {
+			continue
+		}
+		data, rErr := os.ReadFile(filepath.Join(m.recordDir(), de.Name()))
+		if rErr != nil {
+			return nil, rErr
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) < 3 {
+			logger.Warn().Msgf("skipping malformed address record %s", de.Name())
+			continue
+		}
+		e := AddrEntry{Reservation: fields[0], Host: fields[1], Mac: fields[2]}
+		if len(fields) > 3 {
+			e.IP = fields[3]
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Host < entries[j].Host
+	})
+	return entries, nil
+}
+
+const addrFileHeader = "# generated by igor - do not edit\n"
+
+// renderDnsmasqHosts returns dnsmasq dhcp-host entries and, for hosts with an address,
+// host-record entries for DNS.
+func renderDnsmasqHosts(entries []AddrEntry, domain string) string {
+	var b strings.Builder
+	b.WriteString(addrFileHeader)
+	for _, e := range entries {
+		fmt.Fprintf(&b, "# reservation %s\n", e.Reservation)
+		if e.IP == "" {
+			fmt.Fprintf(&b, "dhcp-host=%s,%s\n", e.Mac, e.Host)
+			continue
+		}
+		fmt.Fprintf(&b, "dhcp-host=%s,%s,%s\n", e.Mac, e.Host, e.IP)
+		if domain != "" {
+			fmt.Fprintf(&b, "host-record=%s.%s,%s,%s\n", e.Host, domain, e.Host, e.IP)
+		} else {
+			fmt.Fprintf(&b, "host-record=%s,%s\n", e.Host, e.IP)
+		}
+	}
+	return b.String()
+}
+
+// renderISCHosts returns ISC dhcpd host declarations.
+func renderISCHosts(entries []AddrEntry) string {
+	var b strings.Builder
+	b.WriteString(addrFileHeader)
+	for _, e := range entries {
+		fmt.Fprintf(&b, "# reservation %s\nhost %s {\n  hardware ethernet %s;\n", e.Reservation, e.Host, e.Mac)
+		if e.IP != "" {
+			fmt.Fprintf(&b, "  fixed-address %s;\n", e.IP)
+		}
+		fmt.Fprintf(&b, "  option host-name \"%s\";\n}\n", e.Host)
+	}
+	return b.String()
+}
+
+// renderZoneRecords returns A records, relative to the zone origin, for hosts with an address.
+func renderZoneRecords(entries []AddrEntry) string {
+	var b strings.Builder
+	b.WriteString(strings.ReplaceAll(addrFileHeader, "#", ";"))
+	for _, e := range entries {
+		if e.IP != "" {
+			fmt.Fprintf(&b, "%s\tIN\tA\t%s\n", e.Host, e.IP)
+		}
+	}
+	return b.String()
+}
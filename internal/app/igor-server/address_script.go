@@ -0,0 +1,59 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScriptAddrManager implements IAddrManager.
+// It hands address management to an admin-supplied script in server.scriptDir, run once per host as
+//
+//	script add RESERVATION HOST MAC [IP]
+//	script remove HOST
+//
+// An exit status other than 0 is treated as a failure.
+type ScriptAddrManager struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// NewScriptAddrManager returns an address manager that runs the script at the given path.
+func NewScriptAddrManager(path string) IAddrManager {
+	return &ScriptAddrManager{Path: path, Timeout: 30 * time.Second}
+}
+
+func (m *ScriptAddrManager) add(entries []AddrEntry) error {
+	var failed []string
+	for _, e := range entries {
+		argv := []string{m.Path, "add", e.Reservation, e.Host, e.Mac}
+		if e.IP != "" {
+			argv = append(argv, e.IP)
+		}
+		if _, err := processWrapper(context.Background(), m.Timeout, argv...); err != nil {
+			failed = append(failed, e.Host)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("address script failed for host(s) %s", strings.Join(failed, ","))
+	}
+	return nil
+}
+
+func (m *ScriptAddrManager) remove(hosts []string) error {
+	var failed []string
+	for _, h := range hosts {
+		if _, err := processWrapper(context.Background(), m.Timeout, m.Path, "remove", h); err != nil {
+			failed = append(failed, h)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("address script failed for host(s) %s", strings.Join(failed, ","))
+	}
+	return nil
+}
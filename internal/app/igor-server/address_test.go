@@ -0,0 +1,103 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeAddr(t *testing.T) {
+	s := &AddressScope{VlanMin: 100, VlanMax: 149, Subnet: "10.20.0.0/16", HostOffset: 100}
+	ip, err := scopeAddr(s, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.20.0.105", ip)
+	ip, err = scopeAddr(s, 300)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.20.1.144", ip)
+
+	small := &AddressScope{Subnet: "192.168.5.0/29"}
+	ip, err = scopeAddr(small, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.5.6", ip)
+	_, err = scopeAddr(small, 7) // broadcast
+	assert.Error(t, err)
+	_, err = scopeAddr(small, 0) // network
+	assert.Error(t, err)
+
+	_, err = scopeAddr(&AddressScope{Subnet: "fd00::/64"}, 1)
+	assert.Error(t, err)
+}
+
+func TestAddrEntries(t *testing.T) {
+	saved := igor.Address.Scopes
+	defer func() { igor.Address.Scopes = saved }()
+	igor.Address.Scopes = []AddressScope{{VlanMin: 100, VlanMax: 149, Subnet: "10.20.0.0/24", HostOffset: 10}}
+
+	res := &Reservation{Name: "r1", Hosts: []Host{
+		{Name: "kn1", SequenceID: 1, Mac: "aa:00:00:00:00:01", IP: "192.168.1.1"},
+		{Name: "kn2", SequenceID: 2, Mac: "aa:00:00:00:00:02"},
+	}}
+
+	// outside any scope, hosts keep their static IP
+	entries, err := addrEntries(res)
+	assert.NoError(t, err)
+	assert.Equal(t, AddrEntry{Reservation: "r1", Host: "kn1", Mac: "aa:00:00:00:00:01", IP: "192.168.1.1"}, entries[0])
+	assert.Equal(t, "", entries[1].IP)
+
+	res.Vlan = 120
+	entries, err = addrEntries(res)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.20.0.11", entries[0].IP)
+	assert.Equal(t, "10.20.0.12", entries[1].IP)
+}
+
+func TestFileAddrManager(t *testing.T) {
+	dir := t.TempDir()
+	entries := []AddrEntry{
+		{Reservation: "r1", Host: "kn2", Mac: "aa:00:00:00:00:02"},
+		{Reservation: "r1", Host: "kn1", Mac: "aa:00:00:00:00:01", IP: "10.0.0.1"},
+	}
+
+	dm := NewFileAddrManager(AddrManagerDnsmasq, dir, "cluster.test", "")
+	assert.NoError(t, dm.add(entries))
+	conf, err := os.ReadFile(filepath.Join(dir, "igor-hosts.conf"))
+	assert.NoError(t, err)
+	assert.Equal(t, addrFileHeader+
+		"# reservation r1\ndhcp-host=aa:00:00:00:00:01,kn1,10.0.0.1\nhost-record=kn1.cluster.test,kn1,10.0.0.1\n"+
+		"# reservation r1\ndhcp-host=aa:00:00:00:00:02,kn2\n", string(conf))
+
+	assert.NoError(t, dm.remove([]string{"kn1", "kn9"}))
+	conf, _ = os.ReadFile(filepath.Join(dir, "igor-hosts.conf"))
+	assert.Equal(t, addrFileHeader+"# reservation r1\ndhcp-host=aa:00:00:00:00:02,kn2\n", string(conf))
+
+	im := NewFileAddrManager(AddrManagerISC, dir, "", "")
+	assert.NoError(t, im.add(entries[1:]))
+	conf, _ = os.ReadFile(filepath.Join(dir, "igor-hosts.conf"))
+	assert.Contains(t, string(conf), "host kn1 {\n  hardware ethernet aa:00:00:00:00:01;\n  fixed-address 10.0.0.1;\n")
+	assert.Contains(t, string(conf), "host kn2 {\n  hardware ethernet aa:00:00:00:00:02;\n  option host-name \"kn2\";\n}\n")
+	zone, _ := os.ReadFile(filepath.Join(dir, "igor-hosts.zone"))
+	assert.Equal(t, "; generated by igor - do not edit\nkn1\tIN\tA\t10.0.0.1\n", string(zone))
+}
+
+type failingInstaller struct{}
+
+func (failingInstaller) Install(*Reservation) error   { return errors.New("install failed") }
+func (failingInstaller) Uninstall(*Reservation) error { return nil }
+
+func TestAddrInstallerWithdrawsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewFileAddrManager(AddrManagerDnsmasq, dir, "", "")
+	r := &Reservation{Name: "r1", Hosts: []Host{{Name: "kn1", Mac: "aa:00:00:00:00:01", IP: "10.0.0.1"}}}
+
+	assert.EqualError(t, NewAddrInstaller(failingInstaller{}, manager).Install(r), "install failed")
+	conf, err := os.ReadFile(filepath.Join(dir, "igor-hosts.conf"))
+	assert.NoError(t, err)
+	assert.Equal(t, addrFileHeader, string(conf))
+}
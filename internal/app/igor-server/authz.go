@@ -24,6 +24,7 @@ const (
 )
 
 var urlPartsMatcher = regexp.MustCompile(`^/(\w+)/?`)
+var sshKeyPathMatcher = regexp.MustCompile(`^/users/[^/]+/keys(/[^/]+)?/?$`)
 
 func authzHandler(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +67,23 @@ func authzHandler(handler http.Handler) http.Handler {
 			return
 		}
 
+		if r.Method == http.MethodGet && r.URL.Path == api.VlanPools {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		// anyone can list vlans; named vlan ownership and elevation are checked by the handlers
+		if resource == PermVlans {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		// users manage their own ssh keys; ownership and elevation are checked by the handlers
+		if resource == PermUsers && sshKeyPathMatcher.MatchString(trimmedUrl) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
 		if r.URL.Path == api.HostsBlock {
 			// this perm won't match anything assigned to users so will fail, but will pass
 			// the admin permission of '*'
@@ -79,6 +97,17 @@ func authzHandler(handler http.Handler) http.Handler {
 			return
 		}
 
+		if r.URL.Path == api.HostsState {
+			p, _ := NewPermission("host-state")
+			if authInfo.IsPermitted(p) {
+				handler.ServeHTTP(w, r)
+			} else {
+				rb.Message = "changing host lifecycle state requires admin elevated privilege"
+				makeJsonResponse(w, http.StatusForbidden, rb)
+			}
+			return
+		}
+
 		// allow view-restricted resources to pass if method is GET
 		// these are filtered in the backend before results are returned
 		if r.Method == http.MethodGet && (resource == PermDistros || resource == PermProfiles || resource == PermGroups) {
@@ -0,0 +1,118 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// BootConfig is a generated boot config (pxelinux, grub or ipxe) kept in the database when
+// tftp.dbConfigs is set. Path is relative to the TFTP root, where the file would be written otherwise,
+// so the built-in TFTP server finds it under the same name.
+type BootConfig struct {
+	Base
+	Path     string `gorm:"unique; notNull"`
+	HostName string `gorm:"index"`
+	Content  string
+}
+
+// bootConfigRelPath returns the path of a boot config relative to the TFTP root.
+func bootConfigRelPath(path string) (string, error) {
+	rel, err := filepath.Rel(igor.TFTPPath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("boot config %s is outside the tftp root", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// pendingBootConfig is a boot config change queued for the database but not yet made.
+type pendingBootConfig struct {
+	content string
+	deleted bool
+}
+
+// pendingBootConfigs holds the boot config changes waiting on dbWriteManager by path relative to the
+// TFTP root, so a host that boots right after its reservation is installed still gets its config.
+var (
+	pendingBootConfigs   = make(map[string]*pendingBootConfig)
+	pendingBootConfigsMU sync.Mutex
+)
+
+// queueBootConfig records a boot config change as pending and queues it for the database. The
+// pending entry is dropped once the change is made, unless a newer one has replaced it.
+func queueBootConfig(rel string, p *pendingBootConfig, desc string, fn func(tx *gorm.DB) error) {
+	pendingBootConfigsMU.Lock()
+	pendingBootConfigs[rel] = p
+	pendingBootConfigsMU.Unlock()
+	queueDbWrite(desc, fn, func() {
+		pendingBootConfigsMU.Lock()
+		if pendingBootConfigs[rel] == p {
+			delete(pendingBootConfigs, rel)
+		}
+		pendingBootConfigsMU.Unlock()
+	})
+}
+
+// writeBootConfig saves the generated boot config of a host to disk, or to the database when
+// tftp.dbConfigs is set. It is called while reservations are installed, so the database write is
+// queued rather than made here.
+func writeBootConfig(path, hostName, content string) error {
+	if !igor.Tftp.DbConfigs {
+		return writeFile(path, content)
+	}
+	rel, err := bootConfigRelPath(path)
+	if err != nil {
+		return err
+	}
+	queueBootConfig(rel, &pendingBootConfig{content: content}, "save boot config "+rel, func(tx *gorm.DB) error {
+		return dbSaveBootConfig(&BootConfig{Path: rel, HostName: hostName, Content: content}, tx)
+	})
+	return nil
+}
+
+// removeBootConfig deletes a generated boot config from wherever writeBootConfig put it.
+func removeBootConfig(path string) error {
+	if !igor.Tftp.DbConfigs {
+		return os.Remove(path)
+	}
+	rel, err := bootConfigRelPath(path)
+	if err != nil {
+		return err
+	}
+	queueBootConfig(rel, &pendingBootConfig{deleted: true}, "delete boot config "+rel, func(tx *gorm.DB) error {
+		return dbDeleteBootConfig(rel, tx)
+	})
+	return nil
+}
+
+// readBootConfig returns the content of the boot config at the path relative to the TFTP root from
+// the database, or from the queue if it has a change to it still waiting. The second value is false
+// if there is none.
+func readBootConfig(rel string) (content string, found bool, err error) {
+	pendingBootConfigsMU.Lock()
+	p, ok := pendingBootConfigs[rel]
+	pendingBootConfigsMU.Unlock()
+	if ok {
+		return p.content, !p.deleted, nil
+	}
+	err = performDbTx(func(tx *gorm.DB) error {
+		bc, findErr := dbReadBootConfig(rel, tx)
+		if errors.Is(findErr, gorm.ErrRecordNotFound) {
+			return nil
+		} else if findErr != nil {
+			return findErr
+		}
+		content, found = bc.Content, true
+		return nil
+	})
+	return
+}
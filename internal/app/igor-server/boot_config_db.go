@@ -0,0 +1,33 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dbSaveBootConfig creates the boot config or replaces the content of the one already at its path.
+func dbSaveBootConfig(bc *BootConfig, tx *gorm.DB) error {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"host_name", "content", "updated_at"}),
+	}).Create(bc)
+	return result.Error
+}
+
+// dbReadBootConfig returns the boot config at the given path, or gorm.ErrRecordNotFound.
+func dbReadBootConfig(path string, tx *gorm.DB) (bc BootConfig, err error) {
+	result := tx.Where("path = ?", path).Limit(1).Find(&bc)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return bc, gorm.ErrRecordNotFound
+	}
+	return bc, result.Error
+}
+
+// dbDeleteBootConfig deletes the boot config at the given path if there is one.
+func dbDeleteBootConfig(path string, tx *gorm.DB) error {
+	return tx.Where("path = ?", path).Delete(&BootConfig{}).Error
+}
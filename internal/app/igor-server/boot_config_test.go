@@ -0,0 +1,45 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingBootConfigs(t *testing.T) {
+	savedPath, savedDb := igor.TFTPPath, igor.Tftp.DbConfigs
+	t.Cleanup(func() { igor.TFTPPath, igor.Tftp.DbConfigs = savedPath, savedDb })
+	igor.TFTPPath, igor.Tftp.DbConfigs = t.TempDir(), true
+	path := filepath.Join(igor.TFTPPath, "pxelinux.cfg", "01-aa-bb-cc-dd-ee-ff")
+
+	_, err := bootConfigRelPath("/elsewhere/file")
+	assert.Error(t, err)
+
+	// queued changes are served before they reach the database
+	assert.NoError(t, writeBootConfig(path, "kn1", "DEFAULT install\n"))
+	assert.NoError(t, writeBootConfig(path, "kn1", "DEFAULT local\n"))
+	content, found, err := readBootConfig("pxelinux.cfg/01-aa-bb-cc-dd-ee-ff")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "DEFAULT local\n", content)
+
+	// finishing the first write leaves the newer one pending
+	first := popDbWrite(t)
+	first.done()
+	_, found, _ = readBootConfig("pxelinux.cfg/01-aa-bb-cc-dd-ee-ff")
+	assert.True(t, found)
+
+	assert.NoError(t, removeBootConfig(path))
+	_, found, err = readBootConfig("pxelinux.cfg/01-aa-bb-cc-dd-ee-ff")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	popDbWrite(t).done()
+	popDbWrite(t).done()
+	assert.Empty(t, pendingBootConfigs)
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"igor2/internal/pkg/common"
+)
+
+// The stages a reservation host goes through when it boots the reservation's image, in order.
+const (
+	BootStagePxe       = "pxe-written"
+	BootStageKernel    = "kernel-fetched"
+	BootStageKickstart = "kickstart-fetched"
+	BootStageInstalled = "install-complete"
+	BootStageOsUp      = "os-reachable"
+)
+
+var bootStageOrder = map[string]int{
+	BootStagePxe:       0,
+	BootStageKernel:    1,
+	BootStageKickstart: 2,
+	BootStageInstalled: 3,
+	BootStageOsUp:      4,
+}
+
+// BootStage records when a host of a reservation first reached a stage of booting the reservation's
+// image. Writing the host's PXE config starts a new boot and clears the stages of the previous one.
+type BootStage struct {
+	Base
+	ReservationID int    `gorm:"notNull; uniqueIndex:idx_boot_stage"`
+	HostName      string `gorm:"notNull; uniqueIndex:idx_boot_stage"`
+	Stage         string `gorm:"notNull; uniqueIndex:idx_boot_stage"`
+	Detail        string
+}
+
+// recordBootStage notes that a host of the reservation reached a boot stage. Stages other than
+// BootStagePxe are only kept if the host's PXE config was written for the reservation, so a stray
+// request can't make up progress for a boot that igor didn't start. The write is queued since the
+// PXE config is written while the reservation install transaction is open and dbAccess is held.
+// Queueing never waits, so a large reservation can't stall the installer however many hosts it has.
+func recordBootStage(r *Reservation, hostName, stage, detail string) {
+	if r == nil || r.ID == 0 {
+		// temporary reservations like the maintenance one have no progress to show
+		return
+	}
+	bs := &BootStage{ReservationID: r.ID, HostName: hostName, Stage: stage, Detail: detail}
+	queueDbWrite(fmt.Sprintf("record boot stage %s of host %s", stage, hostName), func(tx *gorm.DB) error {
+		return dbRecordBootStage(bs, tx)
+	}, nil)
+}
+
+// recordOsReachable notes that the hosts answered a probe, against the boot they are part of if any.
+func recordOsReachable(hostNames []string) {
+	for _, name := range hostNames {
+		hostName := name
+		queueDbWrite(fmt.Sprintf("record boot stage %s of host %s", BootStageOsUp, hostName), func(tx *gorm.DB) error {
+			return dbRecordOsReachable(hostName, tx)
+		}, nil)
+	}
+}
+
+// hostsNowUp returns the hosts whose status changed to up.
+func hostsNowUp(transitions []HostStatusRecord) []string {
+	var names []string
+	for _, t := range transitions {
+		if t.Status == HostStatusUp.String() {
+			names = append(names, t.HostName)
+		}
+	}
+	return names
+}
+
+// recordKernelFetch records BootStageKernel if the file the host fetched is the kernel of the image
+// its active reservation boots.
+func recordKernelFetch(host *Host, filename, via string) {
+	res := getActiveReservation(host)
+	if res == nil {
+		return
+	}
+	image := res.Profile.Distro.DistroImage
+	if image.Kernel == "" || filename != path.Join(igor.ImageStoreDir, image.ImageID, image.Kernel) {
+		return
+	}
+	recordBootStage(res, host.Name, BootStageKernel, via)
+}
+
+// getBootProgress returns the furthest boot stage reached by each of the given hosts of the reservation.
+func (r *Reservation) getBootProgress(hostNames []string) []common.HostBootData {
+	latest := make(map[string]BootStage, len(hostNames))
+	for _, bs := range r.BootStages {
+		if cur, ok := latest[bs.HostName]; !ok || bootStageOrder[bs.Stage] > bootStageOrder[cur.Stage] {
+			latest[bs.HostName] = bs
+		}
+	}
+	var progress []common.HostBootData
+	for _, name := range hostNames {
+		if bs, ok := latest[name]; ok {
+			progress = append(progress, common.HostBootData{
+				Host:   name,
+				Stage:  bs.Stage,
+				Time:   bs.CreatedAt.Unix(),
+				Detail: bs.Detail,
+			})
+		}
+	}
+	sort.SliceStable(progress, func(i, j int) bool {
+		return bootStageOrder[progress[i].Stage] < bootStageOrder[progress[j].Stage]
+	})
+	return progress
+}
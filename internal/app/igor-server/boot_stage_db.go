@@ -0,0 +1,38 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dbRecordBootStage saves a boot stage. BootStagePxe replaces every stage the host has for the
+// reservation; other stages are dropped without a BootStagePxe to follow and keep the time they
+// were first reached.
+func dbRecordBootStage(bs *BootStage, tx *gorm.DB) error {
+	if bs.Stage == BootStagePxe {
+		if result := tx.Where("reservation_id = ? AND host_name = ?", bs.ReservationID, bs.HostName).Delete(&BootStage{}); result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(bs).Error
+	}
+	var count int64
+	if result := tx.Model(&BootStage{}).Where("reservation_id = ? AND host_name = ? AND stage = ?",
+		bs.ReservationID, bs.HostName, BootStagePxe).Count(&count); result.Error != nil || count == 0 {
+		return result.Error
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bs).Error
+}
+
+// dbRecordOsReachable saves BootStageOsUp for the latest boot of the host, if it has one.
+func dbRecordOsReachable(hostName string, tx *gorm.DB) error {
+	var boots []BootStage
+	result := tx.Where("host_name = ? AND stage = ?", hostName, BootStagePxe).Order("id desc").Limit(1).Find(&boots)
+	if result.Error != nil || len(boots) == 0 {
+		return result.Error
+	}
+	return dbRecordBootStage(&BootStage{ReservationID: boots[0].ReservationID, HostName: hostName, Stage: BootStageOsUp}, tx)
+}
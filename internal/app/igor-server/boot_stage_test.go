@@ -0,0 +1,76 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"igor2/internal/pkg/common"
+)
+
+func TestGetBootProgress(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	stage := func(host, name string, min int, detail string) BootStage {
+		return BootStage{Base: Base{CreatedAt: t0.Add(time.Duration(min) * time.Minute)}, HostName: host, Stage: name, Detail: detail}
+	}
+	r := &Reservation{BootStages: []BootStage{
+		stage("kn1", BootStagePxe, 0, "bios"),
+		stage("kn1", BootStageOsUp, 9, ""),
+		stage("kn1", BootStageKernel, 1, "tftp"),
+		stage("kn2", BootStagePxe, 0, "bios"),
+		stage("kn2", BootStageKernel, 2, "tftp"),
+		stage("kn3", BootStagePxe, 0, "uefi"),
+		stage("kn9", BootStagePxe, 0, "bios"), // no longer in the reservation
+	}}
+
+	assert.Equal(t, []common.HostBootData{
+		{Host: "kn3", Stage: BootStagePxe, Time: t0.Unix(), Detail: "uefi"},
+		{Host: "kn2", Stage: BootStageKernel, Time: t0.Add(2 * time.Minute).Unix(), Detail: "tftp"},
+		{Host: "kn1", Stage: BootStageOsUp, Time: t0.Add(9 * time.Minute).Unix()},
+	}, r.getBootProgress([]string{"kn1", "kn2", "kn3", "kn4"}))
+
+	assert.Nil(t, (&Reservation{}).getBootProgress([]string{"kn1"}))
+}
+
+func TestHostsNowUp(t *testing.T) {
+	transitions := []HostStatusRecord{
+		{HostName: "kn1", PrevStatus: HostStatusOn.String(), Status: HostStatusUp.String()},
+		{HostName: "kn2", PrevStatus: HostStatusUp.String(), Status: HostStatusOff.String()},
+		{HostName: "kn3", PrevStatus: HostStatusPingable.String(), Status: HostStatusUp.String()},
+	}
+	assert.Equal(t, []string{"kn1", "kn3"}, hostsNowUp(transitions))
+	assert.Nil(t, hostsNowUp(nil))
+}
+
+func TestRecordBootStageWhileLocked(t *testing.T) {
+	t.Cleanup(func() { takeDbWrites() })
+	r := &Reservation{Name: "big"}
+	r.ID = 1
+
+	// the installer records the pxe stage of every host while it holds the database
+	recorded := make(chan struct{})
+	dbAccess.Lock()
+	go func() {
+		for i := 0; i < 3000; i++ {
+			recordBootStage(r, fmt.Sprintf("kn%d", i), BootStagePxe, "bios")
+		}
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording boot stages blocked while the database was locked")
+	}
+	dbAccess.Unlock()
+	assert.Len(t, takeDbWrites(), 3000)
+
+	// reservations that aren't in the database record nothing
+	recordBootStage(&Reservation{Name: "maintenance"}, "kn1", BootStagePxe, "bios")
+	assert.Empty(t, takeDbWrites())
+}
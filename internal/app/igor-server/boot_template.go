@@ -0,0 +1,222 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"igor2/internal/pkg/common"
+)
+
+// BootTemplate is one version of the boot template of a distro breed. The template is a Go text/template
+// given a bootTemplateData that renders the kernel arguments telling the breed's installer where its
+// kickstart is and how to run. Every save makes a new version and the latest one is used. Breeds with no
+// saved versions use their built-in template.
+type BootTemplate struct {
+	Base
+	Breed       string `gorm:"notNull; uniqueIndex:idx_boot_template"`
+	Version     int    `gorm:"notNull; uniqueIndex:idx_boot_template"`
+	Description string
+	Author      string
+	Template    string `gorm:"notNull"`
+}
+
+// bootTemplateRedhat is the built-in template of the redhat breed.
+const bootTemplateRedhat = `{{- with .KickstartURL -}}
+{{if eq $.BootMode "ipxe"}}BOOTIF=01-${netX/mac:hexhyp} {{else if eq $.BootMode "bios"}}inst.lang= {{else}}lang= {{end -}}
+inst.kssendmac {{if eq $.BootMode "bios"}}text{{else}}inst.text{{end}} inst.ksdevice=bootif inst.ks={{.}}
+{{- end}}`
+
+// bootTemplateDefault is the built-in template of every other breed.
+const bootTemplateDefault = `{{- with .KickstartURL -}}
+{{if ne $.BootMode "ipxe"}}lang= {{end -}}
+netcfg/choose_interface={{$.Host.Mac}} text auto-install/enable=true priority=critical
+{{- if eq $.BootMode "bios"}} hostname={{$.Host.Name}}{{end}} url={{.}}
+{{- if eq $.BootMode "bios"}} domain=local.lan{{end}}
+{{- end}}`
+
+// builtinBootTemplate returns the template a breed uses when it has no saved versions.
+func builtinBootTemplate(breed string) string {
+	if breed == "redhat" {
+		return bootTemplateRedhat
+	}
+	return bootTemplateDefault
+}
+
+// bootTemplateData is what a boot template is rendered with.
+type bootTemplateData struct {
+	BootMode     string // bios, uefi or ipxe
+	KickstartURL string // blank unless the image is installed with a kickstart
+	NoCloudURL   string // seed url of the cloud-init NoCloud datasource igor serves
+	Host         struct {
+		Name string
+		IP   string
+		Mac  string // the mac the host boots from
+	}
+	Reservation struct {
+		Name    string
+		Owner   string
+		Group   string
+		Distro  string
+		Profile string
+		Vlan    int
+	}
+	Image struct {
+		Name    string
+		ImageID string
+		Type    string
+		Breed   string
+		Kernel  string
+		Initrd  string
+	}
+}
+
+func newBootTemplateData(host *Host, r *Reservation, ksURL string) *bootTemplateData {
+	image := r.Profile.Distro.DistroImage
+	d := &bootTemplateData{BootMode: host.BootMode, KickstartURL: ksURL, NoCloudURL: noCloudSeedURL()}
+	d.Host.Name, d.Host.IP, d.Host.Mac = host.Name, host.IP, host.bootMac()
+	d.Reservation.Name, d.Reservation.Owner, d.Reservation.Group = r.Name, r.Owner.Name, r.Group.Name
+	d.Reservation.Distro, d.Reservation.Profile, d.Reservation.Vlan = r.Profile.Distro.Name, r.Profile.Name, r.Vlan
+	d.Image.Name, d.Image.ImageID, d.Image.Type = image.Name, image.ImageID, image.Type
+	d.Image.Breed, d.Image.Kernel, d.Image.Initrd = image.Breed, image.Kernel, image.Initrd
+	return d
+}
+
+// parseBootTemplate parses a boot template and tries it in each boot mode with sample data so mistakes
+// that only show when it runs, like a misspelled field, are caught before it is saved.
+func parseBootTemplate(text string) (*template.Template, error) {
+	t, err := template.New("boot").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	sample := &bootTemplateData{KickstartURL: "http://igor.example:8081/igor/cb/svc/ks/sample.ks", NoCloudURL: "http://igor.example:8081/igor/cb/svc/nocloud/"}
+	sample.Host.Name, sample.Host.IP, sample.Host.Mac = "kn1", "10.0.0.1", "aa:bb:cc:dd:ee:ff"
+	sample.Reservation.Name, sample.Reservation.Owner, sample.Reservation.Distro = "sample", "admin", "sample"
+	sample.Image.Name, sample.Image.Type, sample.Image.Kernel, sample.Image.Initrd = "sample", DistroKI, "vmlinuz", "initrd.img"
+	for _, mode := range AllowedBootModes {
+		sample.BootMode = mode
+		if _, err = renderBootTemplate(t, sample); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// renderBootTemplate runs a boot template and returns the kernel arguments it made on one line.
+func renderBootTemplate(t *template.Template, data *bootTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(buf.String()), " "), nil
+}
+
+var (
+	// bootTemplateCache holds the latest saved version of each breed that has one
+	bootTemplateCache   map[string]BootTemplate
+	bootTemplateCacheMU sync.Mutex
+)
+
+// refreshBootTemplates reloads the latest saved version of each breed. It is called at startup and after
+// templates change so reservation installs don't need to read them from the database.
+func refreshBootTemplates() error {
+	var templates []BootTemplate
+	if err := performDbTx(func(tx *gorm.DB) error {
+		var err error
+		templates, err = dbReadBootTemplates(nil, tx)
+		return err
+	}); err != nil {
+		return err
+	}
+	latest := make(map[string]BootTemplate)
+	for _, bt := range templates {
+		if cur, ok := latest[bt.Breed]; !ok || bt.Version > cur.Version {
+			latest[bt.Breed] = bt
+		}
+	}
+	bootTemplateCacheMU.Lock()
+	bootTemplateCache = latest
+	bootTemplateCacheMU.Unlock()
+	return nil
+}
+
+// activeBootTemplate returns the template a breed uses and its version, which is 0 for the built-in one.
+func activeBootTemplate(breed string) (string, int) {
+	bootTemplateCacheMU.Lock()
+	defer bootTemplateCacheMU.Unlock()
+	if bt, ok := bootTemplateCache[breed]; ok {
+		return bt.Template, bt.Version
+	}
+	return builtinBootTemplate(breed), 0
+}
+
+// renderBootArgs returns the kernel arguments of the host's boot config for the reservation using the
+// active template of the image breed.
+func renderBootArgs(host *Host, r *Reservation, ksURL string) (string, error) {
+	breed := r.Profile.Distro.DistroImage.Breed
+	text, version := activeBootTemplate(breed)
+	t, err := template.New("boot").Option("missingkey=error").Parse(text)
+	if err == nil {
+		var args string
+		if args, err = renderBootTemplate(t, newBootTemplateData(host, r, ksURL)); err == nil {
+			return args, nil
+		}
+	}
+	return "", fmt.Errorf("boot template of breed %s (version %d) failed - %v", breed, version, err)
+}
+
+// filterBootTemplateList returns the given saved templates along with the built-in template of each
+// breed in breeds. The active version of each breed is marked.
+func filterBootTemplateList(templates []BootTemplate, breeds []string) []common.BootTemplateData {
+	var result []common.BootTemplateData
+	for _, breed := range breeds {
+		_, activeVersion := activeBootTemplate(breed)
+		result = append(result, common.BootTemplateData{
+			Breed:       breed,
+			Version:     0,
+			Description: "built-in",
+			Active:      activeVersion == 0,
+			Template:    builtinBootTemplate(breed),
+		})
+	}
+	for _, bt := range templates {
+		_, activeVersion := activeBootTemplate(bt.Breed)
+		result = append(result, common.BootTemplateData{
+			Breed:       bt.Breed,
+			Version:     bt.Version,
+			Description: bt.Description,
+			Author:      bt.Author,
+			Created:     bt.CreatedAt.Unix(),
+			Active:      activeVersion == bt.Version,
+			Template:    bt.Template,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Breed != result[j].Breed {
+			return result[i].Breed < result[j].Breed
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+// previewReservation returns a stand-in reservation that boots the distro, used to preview a boot
+// template on a host that isn't reserved.
+func previewReservation(distro *Distro, user *User) *Reservation {
+	return &Reservation{
+		Name:    "preview",
+		Owner:   *user,
+		Profile: Profile{Name: distro.Name, Distro: *distro},
+		End:     time.Now().Add(time.Hour),
+	}
+}
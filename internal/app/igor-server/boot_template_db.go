@@ -0,0 +1,40 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+)
+
+// dbCreateBootTemplate saves the template as the next version of its breed.
+func dbCreateBootTemplate(bt *BootTemplate, tx *gorm.DB) error {
+	var latest int
+	if result := tx.Model(&BootTemplate{}).Where("breed = ?", bt.Breed).Select("COALESCE(MAX(version), 0)").Scan(&latest); result.Error != nil {
+		return result.Error
+	}
+	bt.Version = latest + 1
+	return tx.Create(bt).Error
+}
+
+// dbReadBootTemplates returns the saved versions of the given breeds, or of every breed if none are
+// given, oldest first.
+func dbReadBootTemplates(breeds []string, tx *gorm.DB) (templates []BootTemplate, err error) {
+	if len(breeds) > 0 {
+		tx = tx.Where("breed IN ?", breeds)
+	}
+	result := tx.Order("breed, version").Find(&templates)
+	return templates, result.Error
+}
+
+// dbDeleteBootTemplates deletes one version of a breed's template, or all of them if version is 0.
+// It returns how many were deleted.
+func dbDeleteBootTemplates(breed string, version int, tx *gorm.DB) (int64, error) {
+	tx = tx.Where("breed = ?", breed)
+	if version > 0 {
+		tx = tx.Where("version = ?", version)
+	}
+	result := tx.Delete(&BootTemplate{})
+	return result.RowsAffected, result.Error
+}
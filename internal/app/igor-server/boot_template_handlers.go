@@ -0,0 +1,392 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"text/template"
+
+	"igor2/internal/pkg/api"
+	"igor2/internal/pkg/common"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+)
+
+// destination for route POST /boottemplates
+func handleCreateBootTemplate(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	createParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "create boot template"
+	rb := common.NewResponseBody()
+
+	bt, status, err := doCreateBootTemplate(createParams, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Message = fmt.Sprintf("boot template of breed %s saved as version %d", bt.Breed, bt.Version)
+		clog.Info().Msgf("%s success - %s by user %s", actionPrefix, rb.Message, bt.Author)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route GET /boottemplates
+func handleReadBootTemplates(w http.ResponseWriter, r *http.Request) {
+
+	queryMap := r.URL.Query()
+	clog := hlog.FromRequest(r)
+	actionPrefix := "read boot templates"
+	rb := common.NewResponseBodyBootTemplates()
+
+	breeds := queryMap["breed"]
+	if len(breeds) == 0 {
+		breeds = DistroBreed
+	}
+	allVersions, _ := strconv.ParseBool(queryMap.Get("all"))
+
+	var templates []BootTemplate
+	err := performDbTx(func(tx *gorm.DB) error {
+		var rErr error
+		templates, rErr = dbReadBootTemplates(breeds, tx)
+		return rErr
+	})
+
+	if err != nil {
+		stdErrorResp(rb, http.StatusInternalServerError, actionPrefix, err, clog)
+		makeJsonResponse(w, http.StatusInternalServerError, rb)
+		return
+	}
+
+	list := filterBootTemplateList(templates, breeds)
+	if !allVersions {
+		active := list[:0]
+		for _, bt := range list {
+			if bt.Active {
+				active = append(active, bt)
+			}
+		}
+		list = active
+	}
+	rb.Data["bootTemplates"] = list
+
+	makeJsonResponse(w, http.StatusOK, rb)
+}
+
+// destination for route DELETE /boottemplates/:breed
+//
+// Deletes the version given by the version query parameter, or every saved version of the breed if
+// there is none, which puts the breed back on its built-in template.
+func handleDeleteBootTemplate(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	clog := hlog.FromRequest(r)
+	actionPrefix := "delete boot template"
+	breed := httprouter.ParamsFromContext(r.Context()).ByName("breed")
+	version, _ := strconv.Atoi(r.URL.Query().Get("version"))
+	rb := common.NewResponseBody()
+
+	status, err := doDeleteBootTemplate(breed, version)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		active := "the built-in template"
+		if _, v := activeBootTemplate(breed); v > 0 {
+			active = fmt.Sprintf("version %d", v)
+		}
+		rb.Message = fmt.Sprintf("breed %s now uses %s", breed, active)
+		clog.Info().Msgf("%s success - breed %s version %d deleted by user %s", actionPrefix, breed, version, getUserFromContext(r).Name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route POST /boottemplates/render
+//
+// Renders the boot config a host would get, with the given template or the active one of the image
+// breed. The host's active reservation is used unless a distro is given. Nothing is saved or written.
+func handleRenderBootTemplate(w http.ResponseWriter, r *http.Request) {
+
+	params := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "render boot template"
+	rb := common.NewResponseBodyBootRender()
+
+	render, status, err := doRenderBootTemplate(params, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Data["render"] = *render
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+func doCreateBootTemplate(params map[string]interface{}, r *http.Request) (bt *BootTemplate, code int, err error) {
+
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	bt = &BootTemplate{
+		Breed:  params["breed"].(string),
+		Author: getUserFromContext(r).Name,
+	}
+	if desc, ok := params["description"].(string); ok {
+		bt.Description = desc
+	}
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+		if text, ok := params["template"].(string); ok {
+			bt.Template = text
+		} else {
+			from := int(params["fromVersion"].(float64))
+			if from == 0 {
+				bt.Template = builtinBootTemplate(bt.Breed)
+			} else {
+				saved, rErr := dbReadBootTemplates([]string{bt.Breed}, tx)
+				if rErr != nil {
+					return rErr
+				}
+				for _, s := range saved {
+					if s.Version == from {
+						bt.Template = s.Template
+					}
+				}
+			}
+			if bt.Template == "" {
+				code = http.StatusNotFound
+				return fmt.Errorf("breed %s has no version %d", bt.Breed, from)
+			}
+			if bt.Description == "" {
+				bt.Description = fmt.Sprintf("copy of version %d", from)
+			}
+		}
+		if _, pErr := parseBootTemplate(bt.Template); pErr != nil {
+			code = http.StatusBadRequest
+			return fmt.Errorf("invalid boot template - %v", pErr)
+		}
+		return dbCreateBootTemplate(bt, tx) // uses default err status
+	}); err != nil {
+		return nil, code, err
+	}
+
+	if err = refreshBootTemplates(); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return bt, http.StatusCreated, nil
+}
+
+func doDeleteBootTemplate(breed string, version int) (code int, err error) {
+
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+		count, dErr := dbDeleteBootTemplates(breed, version, tx)
+		if dErr != nil {
+			return dErr
+		}
+		if count == 0 {
+			code = http.StatusNotFound
+			if version > 0 {
+				return fmt.Errorf("breed %s has no version %d", breed, version)
+			}
+			return fmt.Errorf("breed %s has no saved boot templates", breed)
+		}
+		return nil
+	}); err != nil {
+		return code, err
+	}
+
+	if err = refreshBootTemplates(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func doRenderBootTemplate(params map[string]interface{}, r *http.Request) (*common.BootRenderData, int, error) {
+
+	hostName := params["host"].(string)
+	hosts, status, err := doReadHosts(map[string]interface{}{"name": hostName})
+	if err != nil {
+		return nil, status, err
+	} else if len(hosts) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("host %s not found", hostName)
+	}
+	host := hosts[0]
+	if mode, ok := params["bootMode"].(string); ok {
+		host.BootMode = mode
+	}
+
+	var res *Reservation
+	if distroName, ok := params["distro"].(string); ok {
+		distros, dErr := dbReadDistrosTx(map[string]interface{}{"name": distroName})
+		if dErr != nil {
+			return nil, http.StatusInternalServerError, dErr
+		} else if len(distros) == 0 {
+			return nil, http.StatusNotFound, fmt.Errorf("distro %s not found", distroName)
+		}
+		res = previewReservation(&distros[0], getUserFromContext(r))
+	} else if res = getActiveReservation(&host); res == nil {
+		return nil, http.StatusConflict, fmt.Errorf("host %s has no active reservation; give a distro to preview with", hostName)
+	}
+
+	breed := res.Profile.Distro.DistroImage.Breed
+	render := &common.BootRenderData{Host: host.Name, Reservation: res.Name, Breed: breed, BootMode: host.BootMode}
+	var tmpl *template.Template
+	if text, ok := params["template"].(string); ok {
+		if tmpl, err = parseBootTemplate(text); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid boot template - %v", err)
+		}
+		render.Version = -1
+	} else {
+		_, render.Version = activeBootTemplate(breed)
+	}
+
+	if render.Config, _, err = makeBootConfig(&host, res, tmpl); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return render, http.StatusOK, nil
+}
+
+func validateBootTemplateParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		if r.Method == http.MethodPost {
+
+			btParams := getBodyFromContext(r)
+			isRender := r.URL.Path == api.BootTemplatesRender
+
+			if len(btParams) == 0 {
+				validateErr = NewMissingParamError("")
+			} else if isRender && btParams["host"] == nil {
+				validateErr = NewMissingParamError("host")
+			} else if !isRender && btParams["breed"] == nil {
+				validateErr = NewMissingParamError("breed")
+			} else if !isRender && (btParams["template"] == nil) == (btParams["fromVersion"] == nil) {
+				validateErr = fmt.Errorf("one of template or fromVersion is required")
+			} else {
+			paramLoop:
+				for key, val := range btParams {
+					switch {
+					case key == "breed" && !isRender:
+						if breed, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if !hasValidBreed(breed) {
+							validateErr = fmt.Errorf("invalid breed '%s'; must be one of %v", breed, DistroBreed)
+							break paramLoop
+						}
+					case key == "template":
+						if text, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if len(text) == 0 || len(text) > 8192 {
+							validateErr = fmt.Errorf("template must be 1-8192 characters")
+							break paramLoop
+						}
+					case key == "fromVersion" && !isRender:
+						if v, ok := val.(float64); !ok || v < 0 || v != float64(int(v)) {
+							validateErr = NewBadParamTypeError(key, val, "non-negative integer")
+							break paramLoop
+						}
+					case key == "description" && !isRender:
+						if desc, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkDesc(desc); validateErr != nil {
+							break paramLoop
+						}
+					case (key == "host" || key == "distro") && isRender:
+						if name, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkGenericNameRules(name); validateErr != nil {
+							break paramLoop
+						}
+					case key == "bootMode" && isRender:
+						validateErr = fmt.Errorf("invalid boot mode '%v'; must be one of %v", val, AllowedBootModes)
+						for _, mode := range AllowedBootModes {
+							if val == mode {
+								validateErr = nil
+							}
+						}
+						if validateErr != nil {
+							break paramLoop
+						}
+					default:
+						validateErr = NewUnknownParamError(key, val)
+						break paramLoop
+					}
+				}
+			}
+		}
+
+		if r.Method == http.MethodGet {
+		queryParamLoop:
+			for key, vals := range r.URL.Query() {
+				switch key {
+				case "breed":
+					for _, val := range vals {
+						if !hasValidBreed(val) {
+							validateErr = fmt.Errorf("invalid breed '%s'; must be one of %v", val, DistroBreed)
+							break queryParamLoop
+						}
+					}
+				case "all":
+					if _, err := strconv.ParseBool(vals[0]); err != nil {
+						validateErr = NewBadParamTypeError(key, vals[0], "bool")
+						break queryParamLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, vals)
+					break queryParamLoop
+				}
+			}
+		}
+
+		if r.Method == http.MethodDelete {
+			if !hasValidBreed(httprouter.ParamsFromContext(r.Context()).ByName("breed")) {
+				validateErr = fmt.Errorf("invalid breed; must be one of %v", DistroBreed)
+			}
+		queryDelLoop:
+			for key, vals := range r.URL.Query() {
+				switch key {
+				case "version":
+					if v, err := strconv.Atoi(vals[0]); err != nil || v < 1 {
+						validateErr = NewBadParamTypeError(key, vals[0], "positive integer")
+						break queryDelLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, vals)
+					break queryDelLoop
+				}
+			}
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateBootTemplateParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
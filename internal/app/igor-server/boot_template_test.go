@@ -0,0 +1,91 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinBootTemplates(t *testing.T) {
+	ks := "http://igor:8081/igor/cb/svc/ks/rh.ks"
+	data := &bootTemplateData{KickstartURL: ks}
+	data.Host.Name, data.Host.Mac = "kn1", "aa:bb:cc:dd:ee:ff"
+
+	tests := []struct {
+		breed string
+		mode  string
+		want  string
+	}{
+		{"redhat", "bios", "inst.lang= inst.kssendmac text inst.ksdevice=bootif inst.ks=" + ks},
+		{"redhat", "uefi", "lang= inst.kssendmac inst.text inst.ksdevice=bootif inst.ks=" + ks},
+		{"redhat", "ipxe", "BOOTIF=01-${netX/mac:hexhyp} inst.kssendmac inst.text inst.ksdevice=bootif inst.ks=" + ks},
+		{"debian", "bios", "lang= netcfg/choose_interface=aa:bb:cc:dd:ee:ff text auto-install/enable=true priority=critical hostname=kn1 url=" + ks + " domain=local.lan"},
+		{"debian", "uefi", "lang= netcfg/choose_interface=aa:bb:cc:dd:ee:ff text auto-install/enable=true priority=critical url=" + ks},
+		{"ubuntu", "ipxe", "netcfg/choose_interface=aa:bb:cc:dd:ee:ff text auto-install/enable=true priority=critical url=" + ks},
+	}
+	for _, tc := range tests {
+		tmpl, err := parseBootTemplate(builtinBootTemplate(tc.breed))
+		require.NoError(t, err)
+		data.BootMode = tc.mode
+		got, err := renderBootTemplate(tmpl, data)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "%s %s", tc.breed, tc.mode)
+	}
+
+	// images that don't install with a kickstart get no installer arguments
+	tmpl, err := parseBootTemplate(bootTemplateRedhat)
+	require.NoError(t, err)
+	got, err := renderBootTemplate(tmpl, &bootTemplateData{BootMode: "bios"})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseBootTemplate(t *testing.T) {
+	_, err := parseBootTemplate(`inst.ks={{.KickstartURL}} hostname={{.Host.Name}} vlan={{.Reservation.Vlan}}`)
+	assert.NoError(t, err)
+
+	_, err = parseBootTemplate(`inst.ks={{.KickstartURL`)
+	assert.Error(t, err, "syntax error")
+
+	_, err = parseBootTemplate(`hostname={{.Host.Hostname}}`)
+	assert.Error(t, err, "unknown field")
+}
+
+func TestFilterBootTemplateList(t *testing.T) {
+	bootTemplateCacheMU.Lock()
+	saved := bootTemplateCache
+	bootTemplateCache = map[string]BootTemplate{"redhat": {Breed: "redhat", Version: 2, Template: "v2"}}
+	bootTemplateCacheMU.Unlock()
+	defer func() {
+		bootTemplateCacheMU.Lock()
+		bootTemplateCache = saved
+		bootTemplateCacheMU.Unlock()
+	}()
+
+	list := filterBootTemplateList([]BootTemplate{
+		{Breed: "redhat", Version: 2, Template: "v2"},
+		{Breed: "redhat", Version: 1, Template: "v1"},
+	}, []string{"redhat", "debian"})
+
+	require.Len(t, list, 4)
+	type entry struct {
+		breed   string
+		version int
+		active  bool
+	}
+	var got []entry
+	for _, bt := range list {
+		got = append(got, entry{bt.Breed, bt.Version, bt.Active})
+	}
+	assert.Equal(t, []entry{
+		{"debian", 0, true},
+		{"redhat", 0, false},
+		{"redhat", 1, false},
+		{"redhat", 2, true},
+	}, got)
+}
@@ -5,15 +5,31 @@
 package igorserver
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"igor2/internal/pkg/common"
 
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
 )
 
+// cbURL returns the full address of the given path on the node callback service.
+func cbURL(path string) string {
+	scheme := "http"
+	if igor.Server.CbUseTLS != nil && *igor.Server.CbUseTLS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%v%s", scheme, igor.Server.CbHost, igor.Server.CbPort, path)
+}
+
 func handleCbs(w http.ResponseWriter, r *http.Request) {
 	clog := hlog.FromRequest(r)
 	actionPrefix := "convert PXE.cfg to local boot"
@@ -41,6 +57,7 @@ func handleCbs(w http.ResponseWriter, r *http.Request) {
 		if err := setLocalConfig(&host, res); err != nil {
 			clog.Warn().Msgf("%s failed to convert pxe.cfg file to local boot for host %s - %v", actionPrefix, host.Name, err)
 		}
+		recordBootStage(res, host.Name, BootStageInstalled, "")
 		status = http.StatusOK
 	}
 
@@ -50,6 +67,58 @@ func handleCbs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// destination for route GET /cb/svc/ks/*filepath
+//
+// Serves the auto-install files in the kickstart folder to installing hosts and records the fetch in
+// the boot progress of the calling host.
+func handleCbKickstart(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	ksDir := http.Dir(filepath.Join(igor.TFTPPath, igor.KickstartDir))
+	name := httprouter.ParamsFromContext(r.Context()).ByName("filepath")
+	if f, err := ksDir.Open(name); err == nil {
+		fi, statErr := f.Stat()
+		_ = f.Close()
+		if statErr == nil && !fi.IsDir() {
+			var res *Reservation
+			host, ip := callerHost(r)
+			if host != nil {
+				res = getActiveReservation(host)
+				recordBootStage(res, host.Name, BootStageKickstart, path.Base(name))
+			}
+			kss, _ := dbReadKickstartTx(map[string]interface{}{"filename": strings.TrimPrefix(name, "/")})
+			if len(kss) > 0 && kss[0].Template {
+				serveKickstartTemplate(w, &kss[0], host, res, ip, clog)
+				return
+			}
+		}
+	}
+	r.URL.Path = name
+	http.FileServer(ksDir).ServeHTTP(w, r)
+}
+
+// serveKickstartTemplate renders a kickstart marked as a template for the host that asked for it.
+func serveKickstartTemplate(w http.ResponseWriter, ks *Kickstart, host *Host, res *Reservation, ip string, clog *zerolog.Logger) {
+	if host == nil || res == nil {
+		clog.Warn().Msgf("kickstart %s is a template but the host at %s has no active reservation to render it for", ks.Filename, ip)
+		http.Error(w, "no active reservation for this host", http.StatusNotFound)
+		return
+	}
+	ownerKeys, allKeys, err := reservationSSHKeys(res)
+	if err != nil {
+		clog.Error().Msgf("%s: read ssh keys for reservation %s - %v", host.Name, res.Name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	content, err := renderKickstart(ks, host, res, ownerKeys, allKeys)
+	if err != nil {
+		clog.Error().Msgf("%s: render kickstart %s for reservation %s - %v", host.Name, ks.Filename, res.Name, err)
+		http.Error(w, "kickstart template failed to render", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(common.ContentType, "text/plain; charset=utf-8")
+	_, _ = w.Write(content)
+}
+
 func getInfo(w http.ResponseWriter, r *http.Request) {
 	clog := hlog.FromRequest(r)
 	actionPrefix := "get user and hosts based on reservation related to calling host"
@@ -89,3 +158,54 @@ func getInfo(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 }
+
+// destination for route POST /cb/svc/inventory
+//
+// Accepts a hardware inventory report (common.HostInventoryData) from the calling host, identified by
+// its IP address, and stores it as the host's attributes. Any mismatches found against the host record
+// are logged and returned to the caller one per line.
+func handleCbInventory(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	actionPrefix := "store hardware inventory of calling host"
+	rb := common.NewResponseBody()
+	result := ""
+
+	ip := strings.Split(r.RemoteAddr, ":")[0]
+	var inv common.HostInventoryData
+	hosts, status, err := doReadHosts(map[string]interface{}{"ip": ip})
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else if len(hosts) == 0 {
+		clog.Warn().Msgf("%s failed - no hosts found matching IP address %s", actionPrefix, ip)
+		status = http.StatusBadRequest
+	} else if err = json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&inv); err != nil {
+		clog.Warn().Msgf("%s failed - bad inventory report from %s: %v", actionPrefix, ip, err)
+		status = http.StatusBadRequest
+	} else {
+		host := hosts[0]
+		attrs := buildHostAttributes(&host, &inv, time.Now())
+
+		dbAccess.Lock()
+		err = performDbTx(func(tx *gorm.DB) error {
+			return dbReplaceHostAttributes(host.ID, attrs, tx)
+		})
+		dbAccess.Unlock()
+
+		if err != nil {
+			stdErrorResp(rb, http.StatusInternalServerError, actionPrefix, err, clog)
+			status = http.StatusInternalServerError
+		} else {
+			if mismatches := attributeMismatches(attrs); len(mismatches) > 0 {
+				clog.Warn().Msgf("hardware inventory of host %s does not match igor's record: %s", host.Name, strings.Join(mismatches, "; "))
+				result = strings.Join(mismatches, "\n") + "\n"
+			}
+			clog.Info().Msgf("%s success - %d attributes stored for host %s", actionPrefix, len(attrs), host.Name)
+			status = http.StatusOK
+		}
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(result)); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+	"gopkg.in/yaml.v3"
+
+	"igor2/internal/pkg/api"
+)
+
+// emptyCloudConfig is served as user-data or vendor-data when there is none to give
+const emptyCloudConfig = "#cloud-config\n{}\n"
+
+// noCloudSeedURL returns the url to give cloud-init as the seed of its NoCloud datasource, ex. in the
+// kernel argument ds=nocloud;s=URL
+func noCloudSeedURL() string {
+	return cbURL(api.CbNoCloud + "/")
+}
+
+// maxUserDataLen is the largest cloud-init user-data a profile can have
+const maxUserDataLen = 65536
+
+// checkProfileUserData checks cloud-init user-data given for a profile. A blank value removes it.
+func checkProfileUserData(val interface{}) error {
+	ud, ok := val.(string)
+	if !ok {
+		return NewBadParamTypeError("userData", val, "string")
+	}
+	if len(ud) > maxUserDataLen {
+		return fmt.Errorf("user-data is longer than %d bytes", maxUserDataLen)
+	}
+	return nil
+}
+
+// callerHost returns the host a callback request came from, found by its IP address, and the address.
+// The host is nil if no host has the address.
+func callerHost(r *http.Request) (*Host, string) {
+	ip := strings.Split(r.RemoteAddr, ":")[0]
+	hosts, _, err := doReadHosts(map[string]interface{}{"ip": ip})
+	if err != nil || len(hosts) == 0 {
+		return nil, ip
+	}
+	return &hosts[0], ip
+}
+
+// destination for route GET /cb/svc/nocloud/:file
+//
+// Serves the files of a cloud-init NoCloud datasource to the calling host, made from its active
+// reservation.
+func handleCbNoCloud(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	file := httprouter.ParamsFromContext(r.Context()).ByName("file")
+
+	host, ip := callerHost(r)
+	if host == nil {
+		clog.Warn().Msgf("serve cloud-init %s - no host has IP %s", file, ip)
+		http.NotFound(w, r)
+		return
+	}
+	res := getActiveReservation(host)
+	if res == nil {
+		clog.Warn().Msgf("serve cloud-init %s - host %s has no active reservation", file, host.Name)
+		http.NotFound(w, r)
+		return
+	}
+
+	var content []byte
+	var err error
+	switch file {
+	case "meta-data":
+		var keys []string
+		if _, keys, err = reservationSSHKeys(res); err == nil {
+			content, err = noCloudMetaData(host, res, keys)
+		}
+	case "user-data":
+		content = []byte(res.Profile.UserData)
+		if len(content) == 0 {
+			content = []byte(emptyCloudConfig)
+		}
+	case "vendor-data":
+		content, err = noCloudVendorData()
+	case "network-config":
+		content, err = noCloudNetworkConfig(host)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		clog.Error().Msgf("%s: serve cloud-init %s for reservation %s - %v", host.Name, file, res.Name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	clog.Debug().Msgf("%s: served cloud-init %s for reservation %s", host.Name, file, res.Name)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write(content)
+}
+
+// noCloudMetaData returns the meta-data of the host for the reservation. The instance id changes with
+// each reservation so cloud-init runs again when the host is reserved by someone else. The given SSH
+// keys are installed for the image's default user.
+func noCloudMetaData(host *Host, r *Reservation, keys []string) ([]byte, error) {
+	return yaml.Marshal(struct {
+		InstanceID    string   `yaml:"instance-id"`
+		LocalHostname string   `yaml:"local-hostname"`
+		PublicKeys    []string `yaml:"public-keys,omitempty"`
+	}{
+		InstanceID:    fmt.Sprintf("igor-%s-%d-%s", r.Name, r.ID, host.Name),
+		LocalHostname: host.HostName,
+		PublicKeys:    keys,
+	})
+}
+
+// noCloudVendorData returns the site-wide vendor-data file set by the admin, if any.
+func noCloudVendorData() ([]byte, error) {
+	if igor.CloudInit.VendorDataFile == "" {
+		return []byte(emptyCloudConfig), nil
+	}
+	return os.ReadFile(igor.CloudInit.VendorDataFile)
+}
+
+type noCloudEthernet struct {
+	Match struct {
+		MacAddress string `yaml:"macaddress"`
+	} `yaml:"match"`
+	Dhcp4    bool `yaml:"dhcp4"`
+	Optional bool `yaml:"optional,omitempty"`
+}
+
+// noCloudNetworkConfig returns a version 2 network config that brings up each of the host's known
+// interfaces with DHCP. Only the one the host boots from is required to come up. Entries are named
+// by role and a count within that role since a host can have several interfaces with the same role.
+func noCloudNetworkConfig(host *Host) ([]byte, error) {
+	bootMac := host.bootMac()
+	ethernets := make(map[string]noCloudEthernet)
+	seen := make(map[string]bool)
+	roleCount := make(map[string]int)
+	add := func(role, mac string) {
+		mac = strings.ToLower(mac)
+		if mac == "" || seen[mac] {
+			return
+		}
+		seen[mac] = true
+		eth := noCloudEthernet{Dhcp4: true, Optional: !strings.EqualFold(mac, bootMac)}
+		eth.Match.MacAddress = mac
+		ethernets[fmt.Sprintf("%s%d", role, roleCount[role])] = eth
+		roleCount[role]++
+	}
+	add(NicRolePrimary, host.Mac)
+	for _, nic := range host.Interfaces {
+		add(nic.Role, nic.Mac)
+	}
+	return yaml.Marshal(struct {
+		Version   int                        `yaml:"version"`
+		Ethernets map[string]noCloudEthernet `yaml:"ethernets"`
+	}{Version: 2, Ethernets: ethernets})
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoCloudMetaData(t *testing.T) {
+	host := &Host{Name: "kn1", HostName: "kn1.cluster"}
+	r := &Reservation{Base: Base{ID: 42}, Name: "res1"}
+	got, err := noCloudMetaData(host, r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "instance-id: igor-res1-42-kn1\nlocal-hostname: kn1.cluster\n", string(got))
+
+	got, err = noCloudMetaData(host, r, []string{"ssh-ed25519 AAAA alice@laptop"})
+	require.NoError(t, err)
+	assert.Equal(t, "instance-id: igor-res1-42-kn1\nlocal-hostname: kn1.cluster\npublic-keys:\n    - ssh-ed25519 AAAA alice@laptop\n", string(got))
+}
+
+func TestNoCloudNetworkConfig(t *testing.T) {
+	saved := igor.Server.BootNicRole
+	t.Cleanup(func() { igor.Server.BootNicRole = saved })
+	igor.Server.BootNicRole = "boot"
+
+	host := &Host{Mac: "AA:BB:CC:00:00:01", Interfaces: []HostInterface{
+		{Role: "boot", Mac: "aa:bb:cc:00:00:02"},
+		{Role: "data", Mac: "aa:bb:cc:00:00:03"},
+	}}
+	got, err := noCloudNetworkConfig(host)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"version: 2",
+		"ethernets:",
+		"    boot0:",
+		"        match:",
+		"            macaddress: aa:bb:cc:00:00:02",
+		"        dhcp4: true",
+		"    data0:",
+		"        match:",
+		"            macaddress: aa:bb:cc:00:00:03",
+		"        dhcp4: true",
+		"        optional: true",
+		"    primary0:",
+		"        match:",
+		"            macaddress: aa:bb:cc:00:00:01",
+		"        dhcp4: true",
+		"        optional: true",
+		"",
+	}, "\n"), string(got))
+
+	// interfaces sharing a role each get their own entry and the primary mac is only listed once
+	host = &Host{Mac: "aa:bb:cc:00:00:01", Interfaces: []HostInterface{
+		{Role: "primary", Mac: "AA:BB:CC:00:00:01"},
+		{Role: "data", Mac: "aa:bb:cc:00:00:03"},
+		{Role: "data", Mac: "aa:bb:cc:00:00:04"},
+	}}
+	igor.Server.BootNicRole = ""
+	got, err = noCloudNetworkConfig(host)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"version: 2",
+		"ethernets:",
+		"    data0:",
+		"        match:",
+		"            macaddress: aa:bb:cc:00:00:03",
+		"        dhcp4: true",
+		"        optional: true",
+		"    data1:",
+		"        match:",
+		"            macaddress: aa:bb:cc:00:00:04",
+		"        dhcp4: true",
+		"        optional: true",
+		"    primary0:",
+		"        match:",
+		"            macaddress: aa:bb:cc:00:00:01",
+		"        dhcp4: true",
+		"",
+	}, "\n"), string(got))
+}
+
+func TestNoCloudVendorData(t *testing.T) {
+	saved := igor.CloudInit.VendorDataFile
+	t.Cleanup(func() { igor.CloudInit.VendorDataFile = saved })
+
+	igor.CloudInit.VendorDataFile = ""
+	got, err := noCloudVendorData()
+	assert.NoError(t, err)
+	assert.Equal(t, emptyCloudConfig, string(got))
+
+	igor.CloudInit.VendorDataFile = filepath.Join(t.TempDir(), "vendor.yaml")
+	require.NoError(t, os.WriteFile(igor.CloudInit.VendorDataFile, []byte("#cloud-config\nntp:\n  servers: [ntp1]\n"), 0644))
+	got, err = noCloudVendorData()
+	assert.NoError(t, err)
+	assert.Equal(t, "#cloud-config\nntp:\n  servers: [ntp1]\n", string(got))
+}
+
+func TestCheckProfileUserData(t *testing.T) {
+	assert.NoError(t, checkProfileUserData("#cloud-config\npackages: [vim]\n"))
+	assert.NoError(t, checkProfileUserData(""))
+	assert.Error(t, checkProfileUserData(42.0))
+	assert.Error(t, checkProfileUserData(strings.Repeat("x", maxUserDataLen+1)))
+}
@@ -171,10 +171,22 @@ func doCreateClusters(r *http.Request) (clusters []Cluster, hostnameList []strin
 					return fmt.Errorf("required bootMode \"%s\" invalid or not found for host %s; host configuration aborted", bootMode, hostname)
 				}
 
+				if swErr := checkSwitchName(nmv["switch"]); swErr != nil {
+					status = http.StatusBadRequest
+					return fmt.Errorf("%v for host %s; host configuration aborted", swErr, hostname)
+				}
+
+				nics, nicErr := parseHostNics(nmv["nics"])
+				if nicErr != nil {
+					status = http.StatusBadRequest
+					return fmt.Errorf("%v for host %s; host configuration aborted", nicErr, hostname)
+				}
+
 				host := &Host{
 					Name:         hname,
 					HostName:     hostname,
 					Eth:          nmv["eth"],
+					SwitchName:   nmv["switch"],
 					SequenceID:   nmk,
 					Mac:          hwAddr.String(),
 					IP:           hostIpBytes,
@@ -182,6 +194,7 @@ func doCreateClusters(r *http.Request) (clusters []Cluster, hostnameList []strin
 					State:        HostBlocked,
 					HostPolicyID: hostPolicyMap[hostPolicyName].ID,
 					ClusterID:    clusterId,
+					Interfaces:   nics,
 				}
 
 				hostnameList = append(hostnameList, hname)
@@ -30,7 +30,7 @@ func dbReadClustersTx(queryParams map[string]interface{}) (clusters []Cluster, e
 
 func dbReadClusters(queryParams map[string]interface{}, tx *gorm.DB) (clusters []Cluster, err error) {
 
-	tx = tx.Preload("Hosts.HostPolicy").Preload(clause.Associations)
+	tx = tx.Preload("Hosts.HostPolicy").Preload("Hosts.Interfaces").Preload(clause.Associations)
 
 	if len(queryParams) == 0 {
 		result := tx.Find(&clusters)
@@ -33,6 +33,12 @@ func assembleYamlOutput(clusters []Cluster) ([]byte, []ClusterConfig, error) {
 			tempMap["mac"] = h.Mac
 			tempMap["hostname"] = h.HostName
 			tempMap["eth"] = h.Eth
+			if h.SwitchName != "" {
+				tempMap["switch"] = h.SwitchName
+			}
+			if len(h.Interfaces) > 0 {
+				tempMap["nics"] = formatHostNics(h.Interfaces)
+			}
 			tempMap["policy"] = h.HostPolicy.Name
 			tempMap["ip"] = h.IP
 			tempMap["bootMode"] = h.BootMode
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -36,6 +37,12 @@ const (
 	DefaultMaxReserveTime      = 43200
 	LowestMinReserveTime       = 10
 	DefaultExtendWithin        = 4320
+	DefaultHealthCheckInterval = 5
+	DefaultHealthFailThreshold = 3
+	DefaultHealthHistoryDays   = 30
+	DefaultStatusHistoryDays   = 90
+	DefaultFlapWindow          = 60
+	DefaultFlapThreshold       = 6
 
 	//InsomniaPrefix             = "insomnia"
 )
@@ -65,6 +72,11 @@ type Config struct {
 		ScriptDir        string   `yaml:"scriptDir" json:"scriptDir"`
 		UserLocalBootDC  bool     `yaml:"userLocalBootDC" json:"userLocalBootDC"`
 		ProbePorts       []uint   `yaml:"probePorts" json:"probePorts"`
+		BootNicRole      string   `yaml:"bootNicRole" json:"bootNicRole"`
+		ImagingKernel    string   `yaml:"imagingKernel" json:"imagingKernel"`
+		ImagingInitrd    string   `yaml:"imagingInitrd" json:"imagingInitrd"`
+		ImageScanCommand string   `yaml:"imageScanCommand" json:"imageScanCommand"`
+		ImageScanTimeout int      `yaml:"imageScanTimeout" json:"imageScanTimeout"`
 	} `yaml:"server" json:"server"`
 
 	Auth struct {
@@ -168,12 +180,58 @@ type Config struct {
 		// NetworkURL: HTTP URL for sending API commands to the switch
 		NetworkURL string `yaml:"networkURL" json:"networkURL"`
 
+		// Switches: the switches hosts are connected to. If empty, the settings above describe
+		// a single switch that every host is connected to.
+		Switches []SwitchConfig `yaml:"switches" json:"switches"`
+
 		// VLAN segmentation options
 		// Min/Max: specify a range of VLANs to use
 		RangeMin int `yaml:"rangeMin" json:"rangeMin"`
 		RangeMax int `yaml:"rangeMax" json:"rangeMax"`
+
+		// NicRoles: roles of additional host interfaces that join a reservation's vlan along with the
+		// primary interface when the reservation doesn't ask for specific roles
+		NicRoles []string `yaml:"nicRoles" json:"nicRoles"`
+
+		// Reconcile: periodic comparison of switch port vlans with reservation vlans
+		Reconcile struct {
+			// Interval is the number of minutes between runs. Set to 0 to disable.
+			Interval int `yaml:"interval" json:"interval"`
+			// Force repairs any drift that is found.
+			Force        bool `yaml:"force" json:"force"`
+			NotifyAdmins bool `yaml:"notifyAdmins" json:"notifyAdmins"`
+		} `yaml:"reconcile" json:"reconcile"`
 	} `yaml:"vlan" json:"vlan"`
 
+	Address struct {
+		// Manager: selects how host DHCP and DNS entries are published for reservations. Set to "" to disable.
+		Manager string `yaml:"manager" json:"manager"`
+		// ConfigDir: where the dnsmasq and isc managers write their files
+		ConfigDir string `yaml:"configDir" json:"configDir"`
+		// Domain: appended to host names in DNS records written by the dnsmasq manager
+		Domain string `yaml:"domain" json:"domain"`
+		// ReloadCmd: run after the dnsmasq or isc files change
+		ReloadCmd string `yaml:"reloadCmd" json:"reloadCmd"`
+		// Script: used by the script manager and is the name of an executable file in server.scriptDir
+		Script string `yaml:"script" json:"script"`
+		// Scopes: subnets that hosts draw addresses from when they are on a reservation vlan
+		Scopes []AddressScope `yaml:"scopes" json:"scopes"`
+	} `yaml:"address" json:"address"`
+
+	Tftp struct {
+		// Enabled: start igor's own TFTP server, serving server.tftpRoot
+		Enabled bool `yaml:"enabled" json:"enabled"`
+		// Listen: UDP address of the built-in TFTP server
+		Listen string `yaml:"listen" json:"listen"`
+		// DbConfigs: keep generated boot configs in the database instead of writing them under server.tftpRoot
+		DbConfigs bool `yaml:"dbConfigs" json:"dbConfigs"`
+	} `yaml:"tftp" json:"tftp"`
+
+	CloudInit struct {
+		// VendorDataFile: cloud-config given to every host as vendor-data by the NoCloud datasource
+		VendorDataFile string `yaml:"vendorDataFile" json:"vendorDataFile"`
+	} `yaml:"cloudInit" json:"cloudInit"`
+
 	Email struct {
 		SmtpServer    string `yaml:"smtpServer" json:"smtpServer"`
 		SmtpPort      int    `yaml:"smtpPort" json:"smtpPort"`
@@ -199,6 +257,27 @@ type Config struct {
 		PowerCycle       string `yaml:"powerCycle" json:"powerCycle"`
 		PowerStatus      string `yaml:"powerStatus" json:"powerStatus"`
 	} `yaml:"externalCmds" json:"externalCmds"`
+
+	HealthCheck struct {
+		// Interval is the number of minutes between health check runs.
+		Interval int `yaml:"interval" json:"interval"`
+		// FailThreshold is the number of consecutive failed runs before FailAction is applied to a host.
+		FailThreshold int    `yaml:"failThreshold" json:"failThreshold"`
+		FailAction    string `yaml:"failAction" json:"failAction"`
+		NotifyAdmins  bool   `yaml:"notifyAdmins" json:"notifyAdmins"`
+		// HistoryDays is the number of days health check results are kept before being pruned.
+		HistoryDays int                 `yaml:"historyDays" json:"historyDays"`
+		Checks      []HealthCheckConfig `yaml:"checks" json:"checks"`
+	} `yaml:"healthCheck" json:"healthCheck"`
+
+	StatusHistory struct {
+		// HistoryDays is the number of days host status transitions are kept before being pruned.
+		HistoryDays int `yaml:"historyDays" json:"historyDays"`
+		// A host is flapping if it changes status FlapThreshold or more times within FlapWindow minutes.
+		FlapWindow    int  `yaml:"flapWindow" json:"flapWindow"`
+		FlapThreshold int  `yaml:"flapThreshold" json:"flapThreshold"`
+		NotifyAdmins  bool `yaml:"notifyAdmins" json:"notifyAdmins"`
+	} `yaml:"statusHistory" json:"statusHistory"`
 }
 
 func (c *Config) splitRange(s string) []string {
@@ -393,6 +472,7 @@ func initConfigCheck() {
 	igor.TFTPPath = igor.Server.TFTPRoot
 	igor.PXEBIOSDir = "pxelinux.cfg"
 	igor.PXEUEFIDir = filepath.Join("uefi")
+	igor.PXEIPXEDir = "ipxe"
 	igor.ImageStoreDir = "igor_images"
 	igor.KickstartDir = "kickstarts"
 
@@ -416,9 +496,20 @@ func initConfigCheck() {
 		}
 	}
 
+	// and ipxe, whose scripts are served by the callback service
+	tftipxeprep := filepath.Join(igor.TFTPPath, igor.PXEIPXEDir, "igor")
+	if _, err := os.Stat(tftipxeprep); errors.Is(err, os.ErrNotExist) {
+		logger.Warn().Msgf("iPXE repository path(s) not found, creating directory")
+		createErr := os.MkdirAll(tftipxeprep, 0755)
+		if createErr != nil {
+			logger.Error().Msgf("iPXE repository path creation failure: %v", createErr)
+		}
+	}
+
 	logger.Info().Msgf("TFTP root path established: %v", igor.TFTPPath)
 	logger.Info().Msgf("BIOS cfg repository established: %v", tftprep)
 	logger.Info().Msgf("UEFI boot repository established: %v", tftuefiprep)
+	logger.Info().Msgf("iPXE script repository established: %v", tftipxeprep)
 
 	// kickstart rep path
 	ksPath := filepath.Join(igor.TFTPPath, igor.KickstartDir)
@@ -487,6 +578,29 @@ func initConfigCheck() {
 		}
 	}
 
+	if (igor.Server.ImagingKernel == "") != (igor.Server.ImagingInitrd == "") {
+		exitPrintFatal("config error - server.imagingKernel and server.imagingInitrd must be set together")
+	}
+	if igor.Server.ImagingKernel != "" {
+		for _, f := range []string{igor.Server.ImagingKernel, igor.Server.ImagingInitrd} {
+			if _, err := os.Stat(f); err != nil {
+				exitPrintFatal(fmt.Sprintf("config error - imaging boot file %s not readable - %v", f, err))
+			}
+		}
+		logger.Info().Msgf("disk image deployment is enabled")
+	}
+
+	igor.Server.ImageScanCommand = strings.TrimSpace(igor.Server.ImageScanCommand)
+	if igor.Server.ImageScanCommand != "" {
+		if _, err := exec.LookPath(strings.Fields(igor.Server.ImageScanCommand)[0]); err != nil {
+			exitPrintFatal(fmt.Sprintf("config error - server.imageScanCommand not found - %v", err))
+		}
+		logger.Info().Msgf("new images will be scanned with '%s'", igor.Server.ImageScanCommand)
+	}
+	if igor.Server.ImageScanTimeout < 0 {
+		exitPrintFatal("config error - server.imageScanTimeout cannot be negative")
+	}
+
 	if len(igor.Auth.Scheme) == 0 {
 		igor.Auth.Scheme = "local"
 		logger.Warn().Msgf("auth.scheme not specified so using local authentication, LDAP is disabled")
@@ -585,26 +699,95 @@ func initConfigCheck() {
 		}
 	}
 
+	if igor.Server.BootNicRole != "" {
+		if err := checkNicRole(igor.Server.BootNicRole); err != nil {
+			exitPrintFatal(fmt.Sprintf("config error - server.bootNicRole: %v", err))
+		}
+	}
+
 	// set VLAN settings
 	if len(igor.Vlan.Network) > 0 {
-		if igor.Vlan.Network != "arista" {
+		if _, ok := networkSetFuncs[igor.Vlan.Network]; !ok {
 			logger.Warn().Msgf("vlan.network setting '%s' not recognized - no service is configured!", igor.Vlan.Network)
+			igor.Vlan.Network = ""
+			igor.Vlan.Switches = nil
 		} else {
-			if igor.Vlan.NetworkUser == "" {
-				igor.Vlan.NetworkUser = "igor"
-				logger.Info().Msgf("vlan.networkUser not specified, using default : igor")
+			if len(igor.Vlan.Switches) == 0 {
+				if igor.Vlan.NetworkURL == "" && igor.Vlan.Network != NetworkSim {
+					exitPrintFatal("config error - vlan.networkURL cannot be blank when service is configured")
+				}
+				igor.Vlan.Switches = []SwitchConfig{{
+					Name:            DefaultSwitchName,
+					Network:         igor.Vlan.Network,
+					NetworkUser:     igor.Vlan.NetworkUser,
+					NetworkPassword: igor.Vlan.NetworkPassword,
+					NetworkURL:      igor.Vlan.NetworkURL,
+				}}
 			}
-			if igor.Vlan.NetworkURL == "" {
-				exitPrintFatal("config error - vlan.networkURL cannot be blank when service is configured")
+			names := common.NewSet()
+			for i := range igor.Vlan.Switches {
+				if err := validateSwitchConfig(&igor.Vlan.Switches[i]); err != nil {
+					exitPrintFatal(fmt.Sprintf("config error - vlan.switches[%d]: %v", i, err))
+				}
+				if names.Contains(igor.Vlan.Switches[i].Name) {
+					exitPrintFatal(fmt.Sprintf("config error - vlan.switches name '%s' is used more than once", igor.Vlan.Switches[i].Name))
+				}
+				names.Add(igor.Vlan.Switches[i].Name)
 			}
 			if igor.Vlan.RangeMin == 0 || igor.Vlan.RangeMax == 0 || igor.Vlan.RangeMin > igor.Vlan.RangeMax {
 				exitPrintFatal(fmt.Sprintf("config error - vlan.rangeMin/Max is invalid [%d,%d]", igor.Vlan.RangeMin, igor.Vlan.RangeMax))
 			}
+			logger.Info().Msgf("%d switch(es) configured for vlan segmentation", len(igor.Vlan.Switches))
+			if igor.Vlan.Reconcile.Interval < 0 {
+				exitPrintFatal(fmt.Sprintf("config error - vlan.reconcile.interval cannot be negative [%d]", igor.Vlan.Reconcile.Interval))
+			}
+			for _, role := range igor.Vlan.NicRoles {
+				if err := checkNicRole(role); err != nil {
+					exitPrintFatal(fmt.Sprintf("config error - vlan.nicRoles: %v", err))
+				}
+			}
 		}
 	} else {
 		logger.Warn().Msg("no VLAN service is configured")
 	}
 
+	// set address management settings
+	if igor.Address.Manager != "" {
+		if err := validateAddressConfig(); err != nil {
+			exitPrintFatal(fmt.Sprintf("config error - address: %v", err))
+		}
+		if igor.Address.ConfigDir != "" {
+			if err := os.MkdirAll(igor.Address.ConfigDir, 0755); err != nil {
+				exitPrintFatal(fmt.Sprintf("config error - could not create address.configDir %s - %v", igor.Address.ConfigDir, err))
+			}
+		}
+		logger.Info().Msgf("host addresses are published using %s with %d scope(s)", igor.Address.Manager, len(igor.Address.Scopes))
+	} else {
+		logger.Info().Msg("address.manager not specified, host DHCP/DNS entries are not managed")
+	}
+
+	// set built-in tftp server settings
+	if igor.Tftp.Enabled {
+		if igor.Tftp.Listen == "" {
+			igor.Tftp.Listen = ":69"
+		}
+		if _, _, err := net.SplitHostPort(igor.Tftp.Listen); err != nil {
+			exitPrintFatal(fmt.Sprintf("config error - tftp.listen '%s' is not a valid address - %v", igor.Tftp.Listen, err))
+		}
+		if igor.Tftp.DbConfigs {
+			logger.Info().Msg("generated boot configs are kept in the database")
+		}
+	} else if igor.Tftp.DbConfigs {
+		exitPrintFatal("config error - tftp.dbConfigs requires the built-in tftp server (tftp.enabled)")
+	}
+
+	// cloud-init settings
+	if igor.CloudInit.VendorDataFile != "" {
+		if _, err := os.Stat(igor.CloudInit.VendorDataFile); err != nil {
+			exitPrintFatal(fmt.Sprintf("config error - cloudInit.vendorDataFile - %v", err))
+		}
+	}
+
 	// email settings
 	if len(igor.Email.SmtpServer) > 0 {
 
@@ -714,6 +897,55 @@ func initConfigCheck() {
 		igor.ExternalCmds.ConcurrencyLimit = 1
 	}
 
+	if len(igor.HealthCheck.Checks) > 0 {
+		if igor.HealthCheck.Interval <= 0 {
+			logger.Warn().Msgf("healthCheck.interval not specified, using default : %d", DefaultHealthCheckInterval)
+			igor.HealthCheck.Interval = DefaultHealthCheckInterval
+		}
+		if igor.HealthCheck.FailThreshold <= 0 {
+			logger.Warn().Msgf("healthCheck.failThreshold not specified, using default : %d", DefaultHealthFailThreshold)
+			igor.HealthCheck.FailThreshold = DefaultHealthFailThreshold
+		}
+		if igor.HealthCheck.HistoryDays <= 0 {
+			logger.Warn().Msgf("healthCheck.historyDays not specified, using default : %d", DefaultHealthHistoryDays)
+			igor.HealthCheck.HistoryDays = DefaultHealthHistoryDays
+		}
+		switch igor.HealthCheck.FailAction {
+		case "":
+			igor.HealthCheck.FailAction = HealthActionNone
+		case HealthActionNone, HealthActionBlock, HealthActionError, HealthActionMaintenance:
+		default:
+			exitPrintFatal(fmt.Sprintf("config error - healthCheck.failAction '%s' not recognized; must be one of: %s, %s, %s, %s",
+				igor.HealthCheck.FailAction, HealthActionNone, HealthActionBlock, HealthActionError, HealthActionMaintenance))
+		}
+		names := common.NewSet()
+		for i := range igor.HealthCheck.Checks {
+			if err := validateHealthCheckConfig(&igor.HealthCheck.Checks[i]); err != nil {
+				exitPrintFatal(fmt.Sprintf("config error - healthCheck.checks[%d]: %v", i, err))
+			}
+			if names.Contains(igor.HealthCheck.Checks[i].Name) {
+				exitPrintFatal(fmt.Sprintf("config error - healthCheck.checks name '%s' is used more than once", igor.HealthCheck.Checks[i].Name))
+			}
+			names.Add(igor.HealthCheck.Checks[i].Name)
+		}
+		logger.Info().Msgf("%d host health check(s) configured, running every %d minute(s)", len(igor.HealthCheck.Checks), igor.HealthCheck.Interval)
+	} else {
+		logger.Info().Msgf("healthCheck.checks not specified, host health checks are disabled")
+	}
+
+	if igor.StatusHistory.HistoryDays <= 0 {
+		logger.Warn().Msgf("statusHistory.historyDays not specified, using default : %d", DefaultStatusHistoryDays)
+		igor.StatusHistory.HistoryDays = DefaultStatusHistoryDays
+	}
+	if igor.StatusHistory.FlapWindow <= 0 {
+		logger.Warn().Msgf("statusHistory.flapWindow not specified, using default : %d", DefaultFlapWindow)
+		igor.StatusHistory.FlapWindow = DefaultFlapWindow
+	}
+	if igor.StatusHistory.FlapThreshold < 2 {
+		logger.Warn().Msgf("statusHistory.flapThreshold not specified or too small, using default : %d", DefaultFlapThreshold)
+		igor.StatusHistory.FlapThreshold = DefaultFlapThreshold
+	}
+
 	logger.Warn().Msg("--- end: important notes and applying defaults/overrides")
 	logger.Info().Msg("--- end: config file settings")
 }
@@ -733,6 +965,10 @@ func printConfigToLog(s interface{}, namePrefix string) {
 			n := v.Type().Field(i).Name
 			name := namePrefix + n + "."
 			printConfigToLog(v.Field(i).Interface(), name)
+		} else if p == "slice" && v.Type().Field(i).Type.Elem().Kind() == reflect.Struct {
+			for j := 0; j < v.Field(i).Len(); j++ {
+				printConfigToLog(v.Field(i).Index(j).Interface(), fmt.Sprintf("%s[%d].", finalName, j))
+			}
 		} else if p == "map" {
 			iter := v.Field(i).MapRange()
 			for iter.Next() {
@@ -20,7 +20,8 @@ import (
 
 // SQLiteDbUserVersion This is the latest internal version of the SQLite Igor database. The value
 // should map to the most recent db schema.
-const SQLiteDbUserVersion = 2 // (for Igor 2.2)
+const SQLiteDbUserVersion = 3 // (for Igor 2.4)
+//const SQLiteDbUserVersion = 2 // (for Igor 2.2)
 //const SQLiteDbUserVersion = 1 // (for Igor 2.1)
 //const SQLiteDbUserVersion = 0   // (for Igor 2.0)
 
@@ -34,6 +35,11 @@ func init() {
 		})
 }
 
+// gormModels returns every model kept in the database.
+func gormModels() []interface{} {
+	return []interface{}{&Permission{}, &User{}, &Group{}, &Host{}, &HostPolicy{}, &Cluster{}, &Reservation{}, &Kickstart{}, &Distro{}, &Profile{}, &DistroImage{}, &HistoryRecord{}, &MaintenanceRes{}, &HealthCheckResult{}, &HostStatusRecord{}, &HostAttribute{}, &VlanPool{}, &NamedVlan{}, &HostInterface{}, &ResNetwork{}, &BootConfig{}, &BootStage{}, &BootTemplate{}, &UserSSHKey{}}
+}
+
 // NewSqliteGormBackend returns the instantiation of the implementation
 func NewSqliteGormBackend() IGormDb {
 
@@ -95,7 +101,7 @@ func NewSqliteGormBackend() IGormDb {
 	}
 
 	logger.Debug().Msg("auto-migrating GORM models...")
-	err = db.AutoMigrate(&Permission{}, &User{}, &Group{}, &Host{}, &HostPolicy{}, &Cluster{}, &Reservation{}, &Kickstart{}, &Distro{}, &Profile{}, &DistroImage{}, &HistoryRecord{}, &MaintenanceRes{})
+	err = db.AutoMigrate(gormModels()...)
 	if err != nil {
 		exitPrintFatal(fmt.Sprintf("%v", err))
 	}
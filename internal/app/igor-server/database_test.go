@@ -0,0 +1,35 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+)
+
+// newTestDB opens a new sqlite database in a temp dir with every model migrated and makes it the
+// database used by performDbTx until the test ends.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dial := &sqlite.Dialector{DriverName: "sqlite3_igor", DSN: filepath.Join(t.TempDir(), "igor.db")}
+	db, err := gorm.Open(dial, &gorm.Config{Logger: glog.Default.LogMode(glog.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(gormModels()...))
+
+	saved := igor.IGormDb
+	igor.IGormDb = &GormBackend{Database: db}
+	t.Cleanup(func() {
+		igor.IGormDb = saved
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
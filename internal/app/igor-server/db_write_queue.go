@@ -0,0 +1,101 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dbWriteAttempts   = 5
+	dbWriteRetryDelay = 2 * time.Second
+)
+
+// queuedDbWrite is a database change made by dbWriteManager for code that can run inside another
+// transaction, such as the reservation installer. SQLite allows one writer at a time, so writing from
+// there directly would wait on the enclosing transaction forever.
+type queuedDbWrite struct {
+	desc string
+	fn   func(tx *gorm.DB) error
+	done func() // called after the write is made or given up on; may be nil
+}
+
+// dbWrites holds the changes waiting on dbWriteManager. It is a slice rather than a buffered channel
+// so queueing never blocks: callers often hold dbAccess, which dbWriteManager needs before it can
+// make any change.
+var (
+	dbWrites   []queuedDbWrite
+	dbWritesMU sync.Mutex
+	// dbWriteSignal wakes dbWriteManager when changes are queued
+	dbWriteSignal = make(chan struct{}, 1)
+)
+
+// queueDbWrite hands a database change to dbWriteManager. The description is used in the log if the
+// change can't be made.
+func queueDbWrite(desc string, fn func(tx *gorm.DB) error, done func()) {
+	dbWritesMU.Lock()
+	dbWrites = append(dbWrites, queuedDbWrite{desc: desc, fn: fn, done: done})
+	dbWritesMU.Unlock()
+	select {
+	case dbWriteSignal <- struct{}{}:
+	default:
+		// the manager has already been woken and will find this change
+	}
+}
+
+// takeDbWrites removes every queued change and returns them in the order they were queued.
+func takeDbWrites() []queuedDbWrite {
+	dbWritesMU.Lock()
+	defer dbWritesMU.Unlock()
+	queued := dbWrites
+	dbWrites = nil
+	return queued
+}
+
+// dbWriteManager makes queued database changes in order, each in its own transaction once no other
+// part of the server holds the database.
+func dbWriteManager() {
+	defer wg.Done()
+	for {
+		select {
+		case <-shutdownChan:
+			// finish what is already queued so nothing is lost on restart
+			for queued := takeDbWrites(); len(queued) > 0; queued = takeDbWrites() {
+				for _, w := range queued {
+					applyDbWrite(w)
+				}
+			}
+			logger.Info().Msg("stopping database write manager")
+			return
+		case <-dbWriteSignal:
+			for _, w := range takeDbWrites() {
+				applyDbWrite(w)
+			}
+		}
+	}
+}
+
+func applyDbWrite(w queuedDbWrite) {
+	var err error
+	for attempt := 1; attempt <= dbWriteAttempts; attempt++ {
+		dbAccess.Lock()
+		err = performDbTx(w.fn)
+		dbAccess.Unlock()
+		if err == nil {
+			break
+		}
+		logger.Debug().Msgf("attempt %d to %s failed - %v", attempt, w.desc, err)
+		time.Sleep(dbWriteRetryDelay)
+	}
+	if err != nil {
+		logger.Error().Msgf("failed to %s - %v", w.desc, err)
+	}
+	if w.done != nil {
+		w.done()
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// popDbWrite takes the oldest queued database change without making it.
+func popDbWrite(t *testing.T) queuedDbWrite {
+	t.Helper()
+	dbWritesMU.Lock()
+	defer dbWritesMU.Unlock()
+	require.NotEmpty(t, dbWrites)
+	w := dbWrites[0]
+	dbWrites = dbWrites[1:]
+	return w
+}
+
+func TestQueueDbWriteWhileLocked(t *testing.T) {
+	t.Cleanup(func() { takeDbWrites() })
+
+	// the installer queues changes while it holds the database, so queueing must not wait on the manager
+	queued := make(chan struct{})
+	dbAccess.Lock()
+	go func() {
+		for i := 0; i < 5000; i++ {
+			queueDbWrite("test write", func(tx *gorm.DB) error { return nil }, nil)
+		}
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queueing database writes blocked while the database was locked")
+	}
+	dbAccess.Unlock()
+
+	require.Len(t, takeDbWrites(), 5000)
+}
@@ -34,7 +34,10 @@ const (
 	DistroKI = "ki"
 
 	// DistroIso indicates the image represents an installable linux/unix distro
-	// DistroIso = "iso"
+	DistroIso = "iso"
+
+	// DistroDisk indicates the image is a full disk image written to local disk by the imaging initrd
+	DistroDisk = "disk"
 )
 
 // Distro represents an OS in file form
@@ -28,6 +28,17 @@ func doCreateDistro(r *http.Request) (distro *Distro, code int, err error) {
 	user := getUserFromContext(r)
 	code = http.StatusInternalServerError // default status, overridden at end if no errors
 
+	// image files uploaded with the request are staged and scanned before the transaction starts so
+	// the database isn't held while the scanner runs
+	var staged *stagedImage
+	if copyDistro == "" && useDistroImage == "" && imageRef == "" && kickstart == "" && igor.Server.AllowImageUpload &&
+		r.MultipartForm != nil && len(r.MultipartForm.File) > 0 {
+		if staged, code, err = stageImage(r); err != nil {
+			return nil, code, err
+		}
+		code = http.StatusInternalServerError
+	}
+
 	if err = performDbTx(func(tx *gorm.DB) error {
 
 		// verify distro name is unique
@@ -94,7 +105,7 @@ func doCreateDistro(r *http.Request) (distro *Distro, code int, err error) {
 				if kickstart != "" {
 					return fmt.Errorf("distro image intended for local install/boot must be registered as a seperate step")
 				}
-				image, status, err := registerImage(r, tx)
+				image, status, err := registerImage(staged, tx)
 				if err != nil {
 					code = status
 					return err
@@ -110,6 +121,11 @@ func doCreateDistro(r *http.Request) (distro *Distro, code int, err error) {
 			}
 		}
 
+		if distro.DistroImage.Status == ImageQuarantined {
+			code = http.StatusConflict
+			return fmt.Errorf("image %s failed validation and cannot be used in a distro until an admin approves it", distro.DistroImage.Name)
+		}
+
 		// set owner and groups whether public or private
 		// PUBLIC: If user decided to make distro public,
 		// swap user out with admin and set group to all
@@ -262,6 +278,8 @@ func doCreateDistro(r *http.Request) (distro *Distro, code int, err error) {
 
 	}); err == nil {
 		code = http.StatusCreated
+	} else if staged != nil && staged.uploaded {
+		staged.destroy()
 	}
 	return
 }
@@ -27,18 +27,25 @@ var DistroBreed = []string{
 // DistroImage represents boot file(s) associated to a distro.
 type DistroImage struct {
 	Base
-	ImageID    string `gorm:"unique; notNull"`
-	Type       string `gorm:"notNull"`
-	Name       string `gorm:"unique; notNull"`
-	KernelInfo string `gorm:"notNull;default:''"`
-	InitrdInfo string `gorm:"notNull;default:''"`
-	Kernel     string
-	Initrd     string
-	Breed      string
-	LocalBoot  bool
-	BiosBoot   bool `gorm:"notNull; default:false"`
-	UefiBoot   bool `gorm:"notNull; default:false"`
-	Distros    []Distro
+	ImageID      string `gorm:"unique; notNull"`
+	Type         string `gorm:"notNull"`
+	Name         string `gorm:"unique; notNull"`
+	KernelInfo   string `gorm:"notNull;default:''"`
+	InitrdInfo   string `gorm:"notNull;default:''"`
+	Kernel       string
+	Initrd       string
+	Iso          string // file name of the install media for iso images
+	Disk         string // file name of the disk image for disk images
+	DiskFormat   string
+	DiskChecksum string // sha256 of the disk image file
+	Breed        string
+	LocalBoot    bool
+	BiosBoot     bool   `gorm:"notNull; default:false"`
+	UefiBoot     bool   `gorm:"notNull; default:false"`
+	Status       string `gorm:"notNull; default:'ready'"` // ready, or quarantined if it failed validation
+	Validation   string // JSON list of the checks run when the image was registered
+	ApprovedBy   string // admin who approved the image out of quarantine
+	Distros      []Distro
 }
 
 func filterDistroImagesList(distroImages []DistroImage) []common.DistroImageData {
@@ -50,7 +57,7 @@ func filterDistroImagesList(distroImages []DistroImage) []common.DistroImageData
 			distros = append(distros, distro.Name)
 		}
 		local := "no"
-		if image.LocalBoot {
+		if image.LocalBoot || image.Type == DistroDisk {
 			local = "yes"
 		}
 		var boot []string
@@ -70,6 +77,9 @@ func filterDistroImagesList(distroImages []DistroImage) []common.DistroImageData
 			Breed:     image.Breed,
 			Local:     local,
 			Boot:      boot,
+			Source:    isoInstallURL(&image) + diskImageURL(&image),
+			Status:    image.Status,
+			Checks:    image.validationLines(),
 		})
 	}
 
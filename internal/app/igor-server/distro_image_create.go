@@ -22,21 +22,35 @@ import (
 	"gorm.io/gorm"
 )
 
-// doRegisterImage calls registerImage in a new transaction.
+// stagedImage is an image whose files are in the staging directory waiting to be registered.
+type stagedImage struct {
+	image     *DistroImage
+	tempFiles []string
+	uploaded  bool        // the files came with the request rather than being staged beforehand
+	scan      *imageCheck // result of the admin's scanner, if one is configured
+}
+
+// doRegisterImage stages the image files in the request and registers them in a new transaction.
 func doRegisterImage(r *http.Request) (image *DistroImage, status int, err error) {
+	staged, status, err := stageImage(r)
+	if err != nil {
+		return nil, status, err
+	}
 	status = http.StatusInternalServerError
 	err = performDbTx(func(tx *gorm.DB) error {
-		image, status, err = registerImage(r, tx)
+		image, status, err = registerImage(staged, tx)
 		return err
 	})
 	return
 }
 
-// registerImage processes the request to register an image.
-func registerImage(r *http.Request, tx *gorm.DB) (image *DistroImage, status int, err error) {
+// stageImage gets the image files of the request into the staging directory and runs the admin's
+// scanner on them. This is done before registering the image so the database isn't held while the
+// scanner runs.
+func stageImage(r *http.Request) (staged *stagedImage, status int, err error) {
 	clog := hlog.FromRequest(r)
 	clog.Debug().Msgf("Number of files attached: %v", len(r.MultipartForm.File))
-	tempFiles := []string{}
+	staged = &stagedImage{}
 
 	breed := strings.ToLower(r.FormValue("breed"))
 	if breed != "" && !hasValidBreed(breed) {
@@ -45,32 +59,114 @@ func registerImage(r *http.Request, tx *gorm.DB) (image *DistroImage, status int
 	if breed == "" {
 		breed = "generic-linux"
 	}
-	image = detectStagedFiles(r)
+	image := detectStagedFiles(r)
 	if image == nil {
-		image, tempFiles, err = stageUploadedFiles(r)
+		image, staged.tempFiles, err = stageUploadedFiles(r)
 		if err != nil {
 			return nil, http.StatusInternalServerError, err
 		}
+		staged.uploaded = true
+	} else if image.Type == DistroIso {
+		staged.tempFiles = append(staged.tempFiles, image.Iso+".iso")
+	} else if image.Type == DistroDisk {
+		staged.tempFiles = append(staged.tempFiles, image.Disk+".disk")
 	} else {
 		tempK := image.Kernel + ".kernel"
 		tempI := image.Initrd + ".initrd"
-		tempFiles = append(tempFiles, tempK, tempI)
+		staged.tempFiles = append(staged.tempFiles, tempK, tempI)
 	}
 
 	if strings.ToLower(r.FormValue("localBoot")) == "true" {
 		image.LocalBoot = true
 	}
+	// boot modes the image is registered for, checked against its kernel when it's validated
+	for _, b := range r.PostForm["boot"] {
+		switch strings.ToLower(b) {
+		case "bios":
+			image.BiosBoot = true
+		case "uefi":
+			image.UefiBoot = true
+		}
+	}
 
 	image.Breed = breed
-	image, err = processImage(image, tempFiles, tx)
+	staged.image = image
+	if igor.Server.ImageScanCommand != "" {
+		scan := scanStagedImage(staged)
+		staged.scan = &scan
+	}
+	return staged, http.StatusOK, nil
+}
+
+// registerImage processes a staged image and adds it to the database.
+func registerImage(staged *stagedImage, tx *gorm.DB) (image *DistroImage, status int, err error) {
+	image, err = processImage(staged.image, staged.tempFiles, staged.scan, tx)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
 	return image, http.StatusOK, nil
 }
 
+// destroy deletes the staged files of an image.
+func (staged *stagedImage) destroy() {
+	var names []string
+	for _, tf := range staged.tempFiles {
+		names = append(names, strings.TrimSuffix(tf, filepath.Ext(tf)))
+	}
+	destroyStagedImages(names)
+}
+
+// scanStagedImage runs the admin's scanner on the files of an image while they are still in the
+// staging directory. The files are linked under their real names into a directory of their own so
+// the scanner sees the same layout it would in the image store.
+func scanStagedImage(staged *stagedImage) imageCheck {
+	scanDir := filepath.Join(igor.Server.ImageStagePath, "scan-"+uuid.NewString())
+	defer func() { _ = os.RemoveAll(scanDir) }()
+	if err := os.Mkdir(scanDir, 0755); err != nil {
+		return imageCheck{Name: "scan", Detail: err.Error()}
+	}
+	names := map[string]string{
+		".kernel": staged.image.Kernel,
+		".initrd": staged.image.Initrd,
+		".iso":    staged.image.Iso,
+		".disk":   staged.image.Disk,
+	}
+	for _, tf := range staged.tempFiles {
+		ext := filepath.Ext(tf)
+		src := filepath.Join(igor.Server.ImageStagePath, strings.TrimSuffix(tf, ext))
+		dst := filepath.Join(scanDir, filepath.Base(names[ext]))
+		if err := os.Link(src, dst); err != nil {
+			if err = copyFile(src, dst); err != nil {
+				return imageCheck{Name: "scan", Detail: err.Error()}
+			}
+		}
+	}
+	return scanImage(scanDir)
+}
+
 // stageUploadedFiles extracts files from the multipart form and saves them to the staging directory.
 func stageUploadedFiles(r *http.Request) (image *DistroImage, tempFiles []string, err error) {
+	if _, ok := r.MultipartForm.File["isoFile"]; ok {
+		image = &DistroImage{Type: DistroIso}
+		isoFile, tempIsoFile, err := saveUploadedFile(r, "isoFile")
+		if err != nil {
+			return nil, tempFiles, err
+		}
+		image.Iso = isoFile
+		tempFiles = append(tempFiles, tempIsoFile+".iso")
+		return image, tempFiles, nil
+	}
+	if _, ok := r.MultipartForm.File["diskFile"]; ok {
+		image = &DistroImage{Type: DistroDisk}
+		diskFile, tempDiskFile, err := saveUploadedFile(r, "diskFile")
+		if err != nil {
+			return nil, tempFiles, err
+		}
+		image.Disk = diskFile
+		tempFiles = append(tempFiles, tempDiskFile+".disk")
+		return image, tempFiles, nil
+	}
+
 	image = &DistroImage{Type: DistroKI}
 
 	kernelFile, tempKFile, err := saveUploadedFile(r, "kernelFile")
@@ -103,10 +199,12 @@ func saveUploadedFile(r *http.Request, key string) (string, string, error) {
 	return stageFile(file, handler.Filename)
 }
 
-// processImage processes the image files and stores them in the image directory.
-func processImage(image *DistroImage, tempFiles []string, tx *gorm.DB) (*DistroImage, error) {
+// processImage processes the image files and stores them in the image directory. The result of the
+// scan of the staged files, if there was one, is part of the image's validation.
+func processImage(image *DistroImage, tempFiles []string, scan *imageCheck, tx *gorm.DB) (*DistroImage, error) {
 	tempK := ""
 	tempI := ""
+	var staged []string
 	switch image.Type {
 	case DistroKI:
 		tf := tempFiles[0]
@@ -117,6 +215,7 @@ func processImage(image *DistroImage, tempFiles []string, tx *gorm.DB) (*DistroI
 			tempK = strings.TrimSuffix(tempFiles[1], ".kernel")
 			tempI = strings.TrimSuffix(tf, ".initrd")
 		}
+		staged = []string{tempK, tempI}
 		stagedKernel := filepath.Join(igor.Server.ImageStagePath, tempK)
 		stagedInitrd := filepath.Join(igor.Server.ImageStagePath, tempI)
 		if err := checkFileExists(stagedKernel); err != nil {
@@ -127,10 +226,47 @@ func processImage(image *DistroImage, tempFiles []string, tx *gorm.DB) (*DistroI
 		}
 		hash, err := hashKIPair(stagedKernel, stagedInitrd)
 		if err != nil {
-			destroyStagedImages([]string{tempK, tempI})
+			destroyStagedImages(staged)
+			return nil, err
+		}
+		image.ImageID = hash
+	case DistroIso:
+		tempIso := strings.TrimSuffix(tempFiles[0], ".iso")
+		staged = []string{tempIso}
+		stagedIso := filepath.Join(igor.Server.ImageStagePath, tempIso)
+		if err := checkFileExists(stagedIso); err != nil {
+			return nil, err
+		}
+		hash, err := hashIso(stagedIso)
+		if err != nil {
+			destroyStagedImages(staged)
 			return nil, err
 		}
 		image.ImageID = hash
+	case DistroDisk:
+		tempDisk := strings.TrimSuffix(tempFiles[0], ".disk")
+		staged = []string{tempDisk}
+		if igor.Server.ImagingKernel == "" {
+			destroyStagedImages(staged)
+			return nil, fmt.Errorf("disk images cannot be registered until server.imagingKernel and server.imagingInitrd are configured")
+		}
+		format, _, err := parseDiskName(image.Disk)
+		if err != nil {
+			destroyStagedImages(staged)
+			return nil, err
+		}
+		image.DiskFormat = format
+		stagedDisk := filepath.Join(igor.Server.ImageStagePath, tempDisk)
+		if err = checkFileExists(stagedDisk); err != nil {
+			return nil, err
+		}
+		hash, checksum, err := hashDisk(stagedDisk)
+		if err != nil {
+			destroyStagedImages(staged)
+			return nil, err
+		}
+		image.ImageID = hash
+		image.DiskChecksum = checksum
 	default:
 		return nil, fmt.Errorf("image type not recognized: %v", image.Type)
 	}
@@ -138,36 +274,41 @@ func processImage(image *DistroImage, tempFiles []string, tx *gorm.DB) (*DistroI
 	existingImages, err := dbReadImage(map[string]interface{}{"image_id": image.ImageID}, 0, tx)
 
 	if err != nil {
-		destroyStagedImages([]string{tempK, tempI})
+		destroyStagedImages(staged)
 		return nil, err
 	}
 	if len(existingImages) > 0 {
-		destroyStagedImages([]string{tempK, tempI})
+		destroyStagedImages(staged)
 		return &existingImages[0], nil
 	}
 
 	image.Name = refFromHash(image.Type, image.ImageID)
 	if image.Name == "" {
-		destroyStagedImages([]string{tempK, tempI})
+		destroyStagedImages(staged)
 		return nil, fmt.Errorf("failed to create image ref from type: %v and hash: %v", image.Type, image.ImageID)
 	}
 
 	if err = processImageFiles(image, tempFiles); err != nil {
-		destroyStagedImages([]string{tempK, tempI})
+		destroyStagedImages(staged)
 		return nil, err
 	}
 
+	checks := checkImage(image, scan)
+	if image.Status == ImageQuarantined {
+		logger.Warn().Msgf("image %s failed validation and is quarantined until an admin approves it - %v", image.Name, checks)
+	}
+
 	dbAccess.Lock()
 	defer dbAccess.Unlock()
 	if err = dbCreateImage(image, tx); err != nil {
-		destroyStagedImages([]string{tempK, tempI})
+		destroyStagedImages(staged)
 		return nil, err
 	}
 
 	enqueueInitrdJob(image)
 
 	// on success, destroy staged image files
-	destroyStagedImages([]string{tempK, tempI})
+	destroyStagedImages(staged)
 	return image, nil
 }
 
@@ -214,6 +355,40 @@ func processImageFiles(image *DistroImage, srcFiles []string) error {
 		}
 		image.KernelInfo, image.Breed = parseKernelInfo(image)
 
+	case DistroIso:
+		isoSrc := filepath.Join(igor.Server.ImageStagePath, strings.TrimSuffix(srcFiles[0], ".iso"))
+		isoTarget := filepath.Join(targetPath, image.Iso)
+		if err := copyFile(isoSrc, isoTarget); err != nil {
+			_ = removeFolderAndContents(targetPath)
+			return err
+		}
+		isoBreed, err := extractIsoBootFiles(image, isoTarget, targetPath)
+		if err != nil {
+			_ = removeFolderAndContents(targetPath)
+			return err
+		}
+		// prefer the breed found in the kernel, then the one implied by the media layout
+		var kernelBreed string
+		image.KernelInfo, kernelBreed = parseKernelInfo(image)
+		if kernelBreed != "" {
+			image.Breed = kernelBreed
+		} else if isoBreed != "" {
+			image.Breed = isoBreed
+		}
+
+	case DistroDisk:
+		diskSrc := filepath.Join(igor.Server.ImageStagePath, strings.TrimSuffix(srcFiles[0], ".disk"))
+		if err := copyFile(diskSrc, filepath.Join(targetPath, image.Disk)); err != nil {
+			_ = removeFolderAndContents(targetPath)
+			return err
+		}
+		if err := copyImagingFiles(image, targetPath); err != nil {
+			_ = removeFolderAndContents(targetPath)
+			return err
+		}
+		// the kernel is the imaging kernel so it says nothing about what is on the disk
+		image.KernelInfo = "imaging"
+
 	default:
 		if err := removeFolderAndContents(targetPath); err != nil {
 			return err
@@ -298,6 +473,18 @@ func copyFile(srcPath, targetPath string) error {
 
 // detectStagedFiles checks for staged files in the request.
 func detectStagedFiles(r *http.Request) *DistroImage {
+	if diskFile := r.FormValue("diskstaged"); diskFile != "" {
+		return &DistroImage{
+			Type: DistroDisk,
+			Disk: diskFile,
+		}
+	}
+	if isoFile := r.FormValue("isostaged"); isoFile != "" {
+		return &DistroImage{
+			Type: DistroIso,
+			Iso:  isoFile,
+		}
+	}
 	kFile := r.FormValue("kstaged")
 	iFile := r.FormValue("istaged")
 	if kFile != "" && iFile != "" {
@@ -46,6 +46,12 @@ func dbReadImage(queryParams map[string]interface{}, limit int, tx *gorm.DB) (im
 	return images, result.Error
 }
 
+// dbUpdateImage saves changes to an image's fields.
+func dbUpdateImage(image *DistroImage, changes map[string]interface{}, tx *gorm.DB) error {
+	result := tx.Model(image).Updates(changes)
+	return result.Error
+}
+
 // dbDeleteImage deletes an image from the Image database table
 func dbDeleteImage(image *DistroImage, tx *gorm.DB) error {
 	// Ideally, target has already been found in the db
@@ -0,0 +1,208 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"igor2/internal/pkg/api"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+)
+
+const (
+	DiskFormatRaw   = "raw"
+	DiskFormatQcow2 = "qcow2"
+)
+
+// diskFormatExts maps disk image file extensions to their format.
+var diskFormatExts = map[string]string{
+	".raw":   DiskFormatRaw,
+	".img":   DiskFormatRaw,
+	".qcow2": DiskFormatQcow2,
+}
+
+// diskCompressions are the compression types the imaging initrd can stream through.
+var diskCompressions = []string{"gz", "xz", "zst"}
+
+// parseDiskName returns the format and compression of a disk image from its file name, for example
+// rocky9.qcow2.xz is a qcow2 image compressed with xz.
+func parseDiskName(name string) (format, compress string, err error) {
+	base := strings.ToLower(filepath.Base(name))
+	for _, c := range diskCompressions {
+		if strings.HasSuffix(base, "."+c) {
+			compress = c
+			base = strings.TrimSuffix(base, "."+c)
+			break
+		}
+	}
+	format, ok := diskFormatExts[filepath.Ext(base)]
+	if !ok {
+		return "", "", fmt.Errorf("disk image %s must end in .raw, .img or .qcow2, optionally followed by .%s", name, strings.Join(diskCompressions, ", ."))
+	}
+	return format, compress, nil
+}
+
+// hashDisk returns the image ID and the sha256 checksum of a disk image file in one pass.
+func hashDisk(diskPath string) (id, checksum string, err error) {
+	disk, err := os.Open(diskPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer disk.Close()
+
+	idHash := sha1.New()
+	sumHash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(idHash, sumHash), disk); err != nil {
+		return "", "", fmt.Errorf("unable to hash file %v: %v", diskPath, err)
+	}
+	return hex.EncodeToString(idHash.Sum(nil)), hex.EncodeToString(sumHash.Sum(nil)), nil
+}
+
+// copyImagingFiles places the configured imaging kernel and initrd in the image directory so a disk
+// image netboots into the imaging environment.
+func copyImagingFiles(image *DistroImage, targetPath string) error {
+	if igor.Server.ImagingKernel == "" {
+		return fmt.Errorf("disk images cannot be used until server.imagingKernel and server.imagingInitrd are configured")
+	}
+	image.Kernel = filepath.Base(igor.Server.ImagingKernel)
+	image.Initrd = filepath.Base(igor.Server.ImagingInitrd)
+	if err := copyFile(igor.Server.ImagingKernel, filepath.Join(targetPath, image.Kernel)); err != nil {
+		return err
+	}
+	return copyFile(igor.Server.ImagingInitrd, filepath.Join(targetPath, image.Initrd))
+}
+
+// diskImageURL returns the address of the disk image file on the callback service, or a blank string
+// for other image types.
+func diskImageURL(image *DistroImage) string {
+	if image.Type != DistroDisk {
+		return ""
+	}
+	return cbURL(api.CbDisk + "/" + image.Name)
+}
+
+// diskBootArgs returns the kernel args that tell the imaging initrd what to write and where to report
+// when it is done.
+func diskBootArgs(image *DistroImage) string {
+	if image.Type != DistroDisk {
+		return ""
+	}
+	args := []string{
+		"igor.disk=" + diskImageURL(image),
+		"igor.disk.format=" + image.DiskFormat,
+	}
+	if _, compress, err := parseDiskName(image.Disk); err == nil && compress != "" {
+		args = append(args, "igor.disk.compress="+compress)
+	}
+	args = append(args, "igor.disk.sha256="+image.DiskChecksum, "igor.done="+cbURL(api.CbImaged))
+	return strings.Join(args, " ")
+}
+
+// destination for route GET /cb/svc/disk/:imageName
+//
+// Serves the file of a disk image to the imaging initrd. Range requests are supported so an
+// interrupted transfer can be resumed.
+func handleCbDisk(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	imageName := httprouter.ParamsFromContext(r.Context()).ByName("imageName")
+
+	var images []DistroImage
+	err := performDbTx(func(tx *gorm.DB) error {
+		var findErr error
+		images, findErr = dbReadImage(map[string]interface{}{"name": imageName}, 1, tx)
+		return findErr
+	})
+	if err != nil {
+		clog.Error().Msgf("serve disk image %s - %v", imageName, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if len(images) == 0 || images[0].Type != DistroDisk {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(filepath.Join(getImageStorePath(images[0].ImageID), images[0].Disk))
+	if err != nil {
+		clog.Error().Msgf("serve disk image %s - %v", imageName, err)
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, images[0].Disk, fi.ModTime(), f)
+}
+
+// destination for route POST /cb/svc/imaged
+//
+// Receives the result of writing a disk image from the imaging initrd of the calling host, identified
+// by its IP address. The status param is ok or fail, with an optional message. On success the host is
+// switched to local boot the same way as the local boot callback. On failure the host is left to
+// netboot the imaging environment again.
+func handleCbImaged(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	actionPrefix := "disk imaging report"
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := r.PostFormValue("status")
+	if result != "ok" && result != "fail" {
+		http.Error(w, "status must be ok or fail", http.StatusBadRequest)
+		return
+	}
+
+	ip := strings.Split(r.RemoteAddr, ":")[0]
+	hosts, status, err := doReadHosts(map[string]interface{}{"ip": ip})
+	if err != nil {
+		clog.Error().Msgf("%s failed - %v", actionPrefix, err)
+		http.Error(w, http.StatusText(status), status)
+		return
+	} else if len(hosts) == 0 {
+		clog.Warn().Msgf("%s failed - no hosts found matching IP address %s", actionPrefix, ip)
+		http.Error(w, "unknown host", http.StatusBadRequest)
+		return
+	}
+	host := hosts[0]
+	res := getActiveReservation(&host)
+	if res == nil || res.Profile.Distro.DistroImage.Type != DistroDisk {
+		clog.Warn().Msgf("%s failed - host %s has no active reservation with a disk image", actionPrefix, host.Name)
+		http.Error(w, "no active reservation with a disk image", http.StatusBadRequest)
+		return
+	}
+
+	if result == "fail" {
+		clog.Error().Msgf("%s - host %s failed to write image %s for reservation %s: %s", actionPrefix, host.Name,
+			res.Profile.Distro.DistroImage.Name, res.Name, r.PostFormValue("message"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err = setLocalConfig(&host, res); err != nil {
+		clog.Warn().Msgf("%s failed to convert pxe.cfg file to local boot for host %s - %v", actionPrefix, host.Name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	clog.Info().Msgf("%s - host %s finished writing image %s for reservation %s", actionPrefix, host.Name,
+		res.Profile.Distro.DistroImage.Name, res.Name)
+	recordBootStage(res, host.Name, BootStageInstalled, "disk image written")
+	w.WriteHeader(http.StatusOK)
+}
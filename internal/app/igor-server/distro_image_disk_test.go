@@ -0,0 +1,54 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiskName(t *testing.T) {
+	format, compress, err := parseDiskName("/stage/rocky9.QCOW2.xz")
+	assert.NoError(t, err)
+	assert.Equal(t, DiskFormatQcow2, format)
+	assert.Equal(t, "xz", compress)
+
+	format, compress, err = parseDiskName("node.img")
+	assert.NoError(t, err)
+	assert.Equal(t, DiskFormatRaw, format)
+	assert.Equal(t, "", compress)
+
+	for _, bad := range []string{"node.iso", "node.gz", "node.qcow2.bz2", "raw"} {
+		_, _, err = parseDiskName(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestHashDisk(t *testing.T) {
+	diskPath := filepath.Join(t.TempDir(), "disk.raw")
+	assert.NoError(t, os.WriteFile(diskPath, []byte("disk"), 0644))
+	id, checksum, err := hashDisk(diskPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "a07bdcbcbb025d14688be45f90b3b7128d4f9170", id)
+	assert.Equal(t, "1044dec7206e8d7c9fbb4ae8f766668406d2567fc7fc1a160a9d4700fcf8f8e9", checksum)
+}
+
+func TestDiskBootArgs(t *testing.T) {
+	savedHost, savedPort := igor.Server.CbHost, igor.Server.CbPort
+	defer func() { igor.Server.CbHost, igor.Server.CbPort = savedHost, savedPort }()
+	igor.Server.CbHost, igor.Server.CbPort = "igor.test", 8081
+
+	assert.Equal(t, "", diskBootArgs(&DistroImage{Type: DistroKI}))
+	image := &DistroImage{Name: "disk12345678", Type: DistroDisk, Disk: "rocky9.qcow2.zst",
+		DiskFormat: DiskFormatQcow2, DiskChecksum: "abc"}
+	assert.Equal(t, "igor.disk=http://igor.test:8081/igor/cb/svc/disk/disk12345678 igor.disk.format=qcow2 "+
+		"igor.disk.compress=zst igor.disk.sha256=abc igor.done=http://igor.test:8081/igor/cb/svc/imaged", diskBootArgs(image))
+
+	image.Disk, image.DiskFormat = "rocky9.raw", DiskFormatRaw
+	assert.NotContains(t, diskBootArgs(image), "compress")
+}
@@ -27,6 +27,10 @@ func handleRegisterDistroImage(w http.ResponseWriter, r *http.Request) {
 	} else {
 		rb.Data["image"] = image
 		msg := fmt.Sprintf("igor boot image files registered to refID: %s", image.Name)
+		if image.Status == ImageQuarantined {
+			msg += fmt.Sprintf(" but it failed validation and is quarantined until an admin approves it:\n  %s",
+				strings.Join(image.validationLines(), "\n  "))
+		}
 		clog.Info().Msgf("%s success -%s", actionPrefix, msg)
 		rb.Message = msg
 	}
@@ -57,6 +61,28 @@ func handleReadDistroImage(w http.ResponseWriter, r *http.Request) {
 	makeJsonResponse(w, status, rb)
 }
 
+func handleUpdateDistroImage(w http.ResponseWriter, r *http.Request) {
+	ps := httprouter.ParamsFromContext(r.Context())
+	distroImageName := ps.ByName("imageName")
+	updateParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "update distro image"
+	rb := common.NewResponseBody()
+
+	image, status, err := doUpdateDistroImage(distroImageName, updateParams, r)
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Message = fmt.Sprintf("image %s is %s", image.Name, image.Status)
+		if lines := image.validationLines(); len(lines) > 0 {
+			rb.Message += ":\n  " + strings.Join(lines, "\n  ")
+		}
+		clog.Info().Msgf("%s success - image %s is %s by user %s", actionPrefix, image.Name, image.Status, getUserFromContext(r).Name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
 func handleDeleteDistroImage(w http.ResponseWriter, r *http.Request) {
 
 	dbAccess.Lock()
@@ -96,7 +122,7 @@ func validateDistroImageParams(handler http.Handler) http.Handler {
 			postPutParamLoop:
 				for key, val := range diParams {
 					switch key {
-					case "kstaged", "istaged":
+					case "kstaged", "istaged", "isostaged", "diskstaged":
 						if validateErr = checkFileRules(val[0]); validateErr != nil {
 							break postPutParamLoop
 						}
@@ -132,6 +158,29 @@ func validateDistroImageParams(handler http.Handler) http.Handler {
 			}
 		}
 
+		if r.Method == http.MethodPatch {
+			diParams := getBodyFromContext(r)
+			if len(diParams) == 0 {
+				validateErr = NewMissingParamError("")
+			}
+		patchParamLoop:
+			for key, val := range diParams {
+				switch key {
+				case "approve", "validate":
+					if b, ok := val.(bool); !ok {
+						validateErr = NewBadParamTypeError(key, val, "bool")
+						break patchParamLoop
+					} else if !b {
+						validateErr = fmt.Errorf("invalid value for %s, must be true", key)
+						break patchParamLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, val)
+					break patchParamLoop
+				}
+			}
+		}
+
 		if validateErr != nil {
 			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
 			clog.Warn().Msgf("validateDistroImageParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
@@ -0,0 +1,186 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"igor2/internal/pkg/api"
+	"igor2/internal/pkg/iso9660"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+)
+
+// isoBootFiles is a location of the netboot kernel and initrd on distro install media.
+type isoBootFiles struct {
+	Kernel string
+	Initrd string
+	Breed  string
+}
+
+// isoBootPaths are the well-known boot file locations of install media, in the order they are tried.
+var isoBootPaths = []isoBootFiles{
+	{Kernel: "images/pxeboot/vmlinuz", Initrd: "images/pxeboot/initrd.img", Breed: "redhat"},
+	{Kernel: "casper/vmlinuz", Initrd: "casper/initrd", Breed: "ubuntu"},
+	{Kernel: "install.amd/vmlinuz", Initrd: "install.amd/initrd.gz", Breed: "debian"},
+	{Kernel: "boot/x86_64/loader/linux", Initrd: "boot/x86_64/loader/initrd", Breed: "suse"},
+	{Kernel: "isolinux/vmlinuz", Initrd: "isolinux/initrd.img"},
+	{Kernel: "arch/boot/x86_64/vmlinuz-linux", Initrd: "arch/boot/x86_64/initramfs-linux.img"},
+}
+
+// findIsoBootFiles returns the first well-known boot file location present on the install media.
+func findIsoBootFiles(img fs.FS) (*isoBootFiles, error) {
+	for i, bf := range isoBootPaths {
+		if _, err := fs.Stat(img, bf.Kernel); err != nil {
+			continue
+		}
+		if _, err := fs.Stat(img, bf.Initrd); err != nil {
+			continue
+		}
+		return &isoBootPaths[i], nil
+	}
+	return nil, fmt.Errorf("no kernel/initrd pair found at any known location in the iso")
+}
+
+// extractIsoBootFiles copies the kernel and initrd out of the install media at isoPath into the
+// target directory. The image's Kernel and Initrd are set to the extracted file names, and the breed
+// implied by their location is returned.
+func extractIsoBootFiles(image *DistroImage, isoPath, targetPath string) (breed string, err error) {
+	f, err := os.Open(isoPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, err := iso9660.Open(f)
+	if err != nil {
+		return "", err
+	}
+	bf, err := findIsoBootFiles(img)
+	if err != nil {
+		return "", err
+	}
+	if err = extractIsoFile(img, bf.Kernel, filepath.Join(targetPath, path.Base(bf.Kernel))); err != nil {
+		return "", err
+	}
+	if err = extractIsoFile(img, bf.Initrd, filepath.Join(targetPath, path.Base(bf.Initrd))); err != nil {
+		return "", err
+	}
+	image.Kernel = path.Base(bf.Kernel)
+	image.Initrd = path.Base(bf.Initrd)
+	return bf.Breed, nil
+}
+
+func extractIsoFile(img fs.FS, name, targetPath string) error {
+	src, err := img.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(targetPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if _, err = io.Copy(dst, src); err != nil {
+		return fmt.Errorf("unable to extract %s from iso: %v", name, err)
+	}
+	return dst.Sync()
+}
+
+// hashIso hashes the install media file and returns the hash.
+func hashIso(isoPath string) (string, error) {
+	iso, err := os.Open(isoPath)
+	if err != nil {
+		return "", err
+	}
+	defer iso.Close()
+
+	isoHash := sha1.New()
+	if _, err = io.Copy(isoHash, iso); err != nil {
+		return "", fmt.Errorf("unable to hash file %v: %v", isoPath, err)
+	}
+	return hex.EncodeToString(isoHash.Sum(nil)), nil
+}
+
+// isoInstallURL returns the address of the install media tree of an iso image on the callback
+// service, or a blank string for other image types.
+func isoInstallURL(image *DistroImage) string {
+	if image.Type != DistroIso {
+		return ""
+	}
+	return cbURL(api.CbIso + "/" + image.Name + "/")
+}
+
+// isoRepoArg returns the kernel argument that points the installer of the given breed at the install
+// media tree, or a blank string if the breed has none or there is no install media.
+func isoRepoArg(breed, installURL string) string {
+	if installURL == "" {
+		return ""
+	}
+	switch breed {
+	case "redhat":
+		return "inst.repo=" + installURL
+	case "suse":
+		return "install=" + installURL
+	}
+	return ""
+}
+
+// destination for route GET /cb/svc/iso/:imageName/*filepath
+//
+// Serves the files inside the install media of an iso image so installers can use it as their
+// package source.
+func handleCbIso(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	ps := httprouter.ParamsFromContext(r.Context())
+	imageName := ps.ByName("imageName")
+
+	var images []DistroImage
+	err := performDbTx(func(tx *gorm.DB) error {
+		var findErr error
+		images, findErr = dbReadImage(map[string]interface{}{"name": imageName}, 1, tx)
+		return findErr
+	})
+	if err != nil {
+		clog.Error().Msgf("serve iso image %s - %v", imageName, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if len(images) == 0 || images[0].Type != DistroIso {
+		http.NotFound(w, r)
+		return
+	}
+
+	isoPath := filepath.Join(getImageStorePath(images[0].ImageID), images[0].Iso)
+	f, err := os.Open(isoPath)
+	if err != nil {
+		clog.Error().Msgf("serve iso image %s - %v", imageName, err)
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	img, err := iso9660.Open(f)
+	if err != nil {
+		clog.Error().Msgf("serve iso image %s - %v", imageName, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	r.URL.Path = ps.ByName("filepath")
+	http.FileServer(http.FS(img)).ServeHTTP(w, r)
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindIsoBootFiles(t *testing.T) {
+	media := fstest.MapFS{
+		"isolinux/vmlinuz":    {Data: []byte("k")},
+		"isolinux/initrd.img": {Data: []byte("i")},
+		"casper/vmlinuz":      {Data: []byte("k")},
+	}
+	bf, err := findIsoBootFiles(media)
+	assert.NoError(t, err)
+	assert.Equal(t, "isolinux/vmlinuz", bf.Kernel)
+	assert.Equal(t, "", bf.Breed)
+
+	// a complete pair at a distro specific location is preferred
+	media["casper/initrd"] = &fstest.MapFile{Data: []byte("i")}
+	bf, err = findIsoBootFiles(media)
+	assert.NoError(t, err)
+	assert.Equal(t, "ubuntu", bf.Breed)
+
+	_, err = findIsoBootFiles(fstest.MapFS{"images/pxeboot/vmlinuz": {Data: []byte("k")}})
+	assert.Error(t, err)
+}
+
+func TestIsoInstallURL(t *testing.T) {
+	savedHost, savedPort := igor.Server.CbHost, igor.Server.CbPort
+	defer func() { igor.Server.CbHost, igor.Server.CbPort = savedHost, savedPort }()
+	igor.Server.CbHost, igor.Server.CbPort = "igor.test", 8081
+
+	assert.Equal(t, "", isoInstallURL(&DistroImage{Name: "ki12345678", Type: DistroKI}))
+	url := isoInstallURL(&DistroImage{Name: "iso12345678", Type: DistroIso})
+	assert.Equal(t, "http://igor.test:8081/igor/cb/svc/iso/iso12345678/", url)
+
+	assert.Equal(t, "inst.repo="+url, isoRepoArg("redhat", url))
+	assert.Equal(t, "", isoRepoArg("debian", url))
+	assert.Equal(t, "", isoRepoArg("redhat", ""))
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+)
+
+// doUpdateDistroImage approves a quarantined image or runs validation on an image again. Validation
+// is done before the database is locked since the admin's scanner can take a long time.
+func doUpdateDistroImage(distroImageName string, params map[string]interface{}, r *http.Request) (image *DistroImage, code int, err error) {
+	clog := hlog.FromRequest(r)
+
+	var images []DistroImage
+	if err = performDbTx(func(tx *gorm.DB) error {
+		images, code, err = getImages([]string{distroImageName}, tx)
+		return err
+	}); err != nil {
+		return nil, code, err
+	}
+	image = &images[0]
+
+	changes := map[string]interface{}{}
+	if _, ok := params["validate"]; ok {
+		checks := validateImage(image)
+		clog.Info().Msgf("image %s validated again - %s - %v", image.Name, image.Status, checks)
+		changes["status"] = image.Status
+		changes["validation"] = image.Validation
+		changes["bios_boot"] = image.BiosBoot
+		changes["uefi_boot"] = image.UefiBoot
+		changes["approved_by"] = ""
+	}
+	if _, ok := params["approve"]; ok {
+		if image.Status != ImageQuarantined {
+			return nil, http.StatusConflict, fmt.Errorf("image %s is not quarantined", image.Name)
+		}
+		image.Status = ImageReady
+		image.ApprovedBy = getUserFromContext(r).Name
+		changes["status"] = image.Status
+		changes["approved_by"] = image.ApprovedBy
+	}
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+	if err = performDbTx(func(tx *gorm.DB) error {
+		return dbUpdateImage(image, changes, tx)
+	}); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return image, http.StatusOK, nil
+}
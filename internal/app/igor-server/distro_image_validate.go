@@ -0,0 +1,288 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// ImageReady is the status of an image that can be used in distros
+	ImageReady = "ready"
+	// ImageQuarantined is the status of an image that failed validation and can't be used in distros until
+	// an admin approves it
+	ImageQuarantined = "quarantined"
+
+	// kernelHeadLen is how much of a kernel file is read to identify it. It covers the setup sectors of a
+	// bzImage where the version string lives.
+	kernelHeadLen = 64 << 10
+	// defaultImageScanTimeout is how long the image scanner can run if server.imageScanTimeout isn't set
+	defaultImageScanTimeout = 600
+)
+
+// imageCheck is the result of one step of image validation.
+type imageCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+func (c imageCheck) String() string {
+	result := "FAIL"
+	if c.Passed {
+		result = "PASS"
+	}
+	return fmt.Sprintf("%s %s: %s", result, c.Name, c.Detail)
+}
+
+// kernelInfo is what could be learned about a kernel file from its header.
+type kernelInfo struct {
+	Format  string // bzImage, arm64 Image, ELF, EFI application or blank if not recognized
+	Version string // from the bzImage header, if it has one
+	Bios    bool   // a bzImage that PXE loaders can boot on BIOS hosts
+	Efi     bool   // has a PE header so UEFI firmware and loaders can start it directly
+}
+
+// parseKernelHeader identifies a kernel from the start of its file.
+func parseKernelHeader(head []byte) (k kernelInfo) {
+	le := binary.LittleEndian
+
+	if len(head) >= 0x40 && bytes.Equal(head[:2], []byte("MZ")) {
+		if peOff := int(le.Uint32(head[0x3c:])); peOff > 0 && peOff+4 <= len(head) && bytes.Equal(head[peOff:peOff+4], []byte("PE\x00\x00")) {
+			k.Efi = true
+			k.Format = "EFI application"
+		}
+	}
+
+	switch {
+	case len(head) >= 0x264 && bytes.Equal(head[0x202:0x206], []byte("HdrS")):
+		k.Format = "bzImage"
+		protocol := le.Uint16(head[0x206:])
+		// boot protocol 2.00 and up with LOADED_HIGH set is a bzImage rather than an old zImage
+		k.Bios = protocol >= 0x0200 && head[0x211]&0x01 != 0
+		if protocol >= 0x0200 {
+			if verOff := int(le.Uint16(head[0x20e:])); verOff != 0 && verOff+0x200 < len(head) {
+				ver := head[verOff+0x200:]
+				if end := bytes.IndexByte(ver, 0); end >= 0 {
+					ver = ver[:end]
+				}
+				if fields := strings.Fields(string(ver)); len(fields) > 0 {
+					k.Version = fields[0]
+				}
+			}
+		}
+	case len(head) >= 0x40 && bytes.Equal(head[0x38:0x3c], []byte("ARM\x64")):
+		k.Format = "arm64 Image"
+	case len(head) >= 4 && bytes.Equal(head[:4], []byte("\x7fELF")):
+		k.Format = "ELF"
+	}
+	return
+}
+
+// detectCompression returns the compression format of a file from its first bytes, "cpio" if it is an
+// uncompressed cpio archive, or blank if it isn't recognized.
+func detectCompression(head []byte) string {
+	magics := []struct {
+		format string
+		magic  []byte
+	}{
+		{"gzip", []byte{0x1f, 0x8b}},
+		{"xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+		{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}},
+		{"bzip2", []byte("BZh")},
+		{"lz4", []byte{0x02, 0x21, 0x4c, 0x18}},
+		{"lzma", []byte{0x5d, 0x00, 0x00}},
+		{"lzop", []byte{0x89, 'L', 'Z', 'O'}},
+		{"cpio", []byte("070701")},
+		{"cpio", []byte("070702")},
+	}
+	for _, m := range magics {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.format
+		}
+	}
+	return ""
+}
+
+// readFileHead returns up to n bytes from the start of a file.
+func readFileHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, n)
+	read, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:read], nil
+}
+
+// validateImage checks the files of an image in the image store with checkImage, running the admin's
+// scanner command on them first if one is configured.
+func validateImage(image *DistroImage) []imageCheck {
+	var scan *imageCheck
+	if igor.Server.ImageScanCommand != "" {
+		c := scanImage(getImageStorePath(image.ImageID))
+		scan = &c
+	}
+	return checkImage(image, scan)
+}
+
+// checkImage checks the files of an image that has been copied to the image store. The kernel must be
+// a recognized type, the initrd must use a known compression and the boot modes the image was
+// registered with must be possible with its kernel. If none were given they are set to what the kernel
+// supports. The result of the scanner is added last if it was run. An image that fails any check is
+// quarantined.
+func checkImage(image *DistroImage, scan *imageCheck) []imageCheck {
+	var checks []imageCheck
+	storePath := getImageStorePath(image.ImageID)
+
+	if image.Kernel != "" {
+		checks = append(checks, checkImageKernel(image, filepath.Join(storePath, image.Kernel))...)
+	}
+	if image.Initrd != "" {
+		checks = append(checks, checkImageInitrd(filepath.Join(storePath, image.Initrd)))
+	}
+	if scan != nil {
+		checks = append(checks, *scan)
+	}
+
+	image.Status = ImageReady
+	for _, c := range checks {
+		if !c.Passed {
+			image.Status = ImageQuarantined
+			break
+		}
+	}
+	if report, err := json.Marshal(checks); err == nil {
+		image.Validation = string(report)
+	}
+	return checks
+}
+
+func checkImageKernel(image *DistroImage, kPath string) []imageCheck {
+	head, err := readFileHead(kPath, kernelHeadLen)
+	if err != nil {
+		return []imageCheck{{Name: "kernel", Detail: err.Error()}}
+	}
+	k := parseKernelHeader(head)
+
+	kCheck := imageCheck{Name: "kernel", Passed: k.Format != "", Detail: k.Format}
+	if !kCheck.Passed {
+		kCheck.Detail = "not a recognized kernel image"
+		if c := detectCompression(head); c != "" && c != "cpio" {
+			kCheck.Detail = fmt.Sprintf("%s compressed file, not a bootable kernel image", c)
+		}
+	} else {
+		if k.Efi && k.Format != "EFI application" {
+			kCheck.Detail += " with EFI stub"
+		}
+		if k.Version != "" {
+			kCheck.Detail += " version " + k.Version
+		}
+	}
+
+	bootCheck := imageCheck{Name: "boot", Passed: true}
+	var possible, impossible []string
+	if k.Bios {
+		possible = append(possible, "bios")
+	}
+	if k.Efi {
+		possible = append(possible, "uefi")
+	}
+	if !image.BiosBoot && !image.UefiBoot {
+		// no boot modes were given so use whatever the kernel supports
+		image.BiosBoot, image.UefiBoot = k.Bios, k.Efi
+		if len(possible) == 0 {
+			bootCheck.Passed = false
+			bootCheck.Detail = "the kernel can't be booted with bios or uefi"
+		} else {
+			bootCheck.Detail = "detected " + strings.Join(possible, ", ")
+		}
+		return []imageCheck{kCheck, bootCheck}
+	}
+	if image.BiosBoot && !k.Bios {
+		impossible = append(impossible, "bios (kernel is not a bzImage)")
+	}
+	if image.UefiBoot && !k.Efi {
+		impossible = append(impossible, "uefi (kernel has no EFI stub)")
+	}
+	if len(impossible) > 0 {
+		bootCheck.Passed = false
+		bootCheck.Detail = "not possible: " + strings.Join(impossible, ", ")
+	} else {
+		bootCheck.Detail = "possible: " + strings.Join(possible, ", ")
+	}
+	return []imageCheck{kCheck, bootCheck}
+}
+
+func checkImageInitrd(iPath string) imageCheck {
+	head, err := readFileHead(iPath, 16)
+	if err != nil {
+		return imageCheck{Name: "initrd", Detail: err.Error()}
+	}
+	switch c := detectCompression(head); c {
+	case "":
+		return imageCheck{Name: "initrd", Detail: "unknown compression or not an initramfs"}
+	case "cpio":
+		// early microcode or firmware is often prepended uncompressed ahead of the main archive
+		return imageCheck{Name: "initrd", Passed: true, Detail: "cpio archive (uncompressed or with an early cpio prepended)"}
+	default:
+		return imageCheck{Name: "initrd", Passed: true, Detail: c + " compressed"}
+	}
+}
+
+// scanImage runs the admin's scanner command with the image's directory in the image store as its last
+// argument. The image fails the check if the command exits with an error.
+func scanImage(storePath string) imageCheck {
+	argv := append(strings.Fields(igor.Server.ImageScanCommand), storePath)
+	timeout := igor.Server.ImageScanTimeout
+	if timeout <= 0 {
+		timeout = defaultImageScanTimeout
+	}
+	out, err := processWrapper(context.Background(), time.Duration(timeout)*time.Second, argv...)
+	out = strings.TrimSpace(out)
+	if len(out) > 256 {
+		out = "..." + out[len(out)-256:]
+	}
+	if err != nil {
+		detail := err.Error()
+		if out != "" {
+			detail += " - " + out
+		}
+		return imageCheck{Name: "scan", Detail: detail}
+	}
+	if out == "" {
+		out = "no findings"
+	}
+	return imageCheck{Name: "scan", Passed: true, Detail: out}
+}
+
+// validationLines returns an image's validation results as one line per check.
+func (image *DistroImage) validationLines() []string {
+	if image.Validation == "" {
+		return nil
+	}
+	var checks []imageCheck
+	if err := json.Unmarshal([]byte(image.Validation), &checks); err != nil {
+		return []string{"unreadable validation report"}
+	}
+	lines := make([]string, 0, len(checks))
+	for _, c := range checks {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
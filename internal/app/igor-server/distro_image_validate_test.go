@@ -0,0 +1,191 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBzImage returns the start of an x86 bzImage with the given version string and optionally an EFI stub.
+func testBzImage(version string, efi bool) []byte {
+	head := make([]byte, 0x1000)
+	copy(head[0x202:], "HdrS")
+	binary.LittleEndian.PutUint16(head[0x206:], 0x020f)
+	head[0x211] = 0x01 // LOADED_HIGH
+	binary.LittleEndian.PutUint16(head[0x20e:], 0x300)
+	copy(head[0x500:], version+"\x00")
+	if efi {
+		copy(head, "MZ")
+		binary.LittleEndian.PutUint32(head[0x3c:], 0x80)
+		copy(head[0x80:], "PE\x00\x00")
+	}
+	return head
+}
+
+func TestParseKernelHeader(t *testing.T) {
+	k := parseKernelHeader(testBzImage("6.1.0-18-amd64 (debian-kernel@lists.debian.org) #1 SMP", true))
+	assert.Equal(t, kernelInfo{Format: "bzImage", Version: "6.1.0-18-amd64", Bios: true, Efi: true}, k)
+
+	k = parseKernelHeader(testBzImage("5.14.0", false))
+	assert.Equal(t, kernelInfo{Format: "bzImage", Version: "5.14.0", Bios: true}, k)
+
+	arm := make([]byte, 0x100)
+	copy(arm, "MZ")
+	copy(arm[0x38:], "ARM\x64")
+	binary.LittleEndian.PutUint32(arm[0x3c:], 0x40)
+	copy(arm[0x40:], "PE\x00\x00")
+	assert.Equal(t, kernelInfo{Format: "arm64 Image", Efi: true}, parseKernelHeader(arm))
+
+	assert.Equal(t, "ELF", parseKernelHeader([]byte("\x7fELF\x02\x01\x01")).Format)
+	assert.Equal(t, kernelInfo{}, parseKernelHeader([]byte{0x1f, 0x8b, 0x08, 0x00}))
+	assert.Equal(t, kernelInfo{}, parseKernelHeader(nil))
+}
+
+func TestDetectCompression(t *testing.T) {
+	for want, head := range map[string][]byte{
+		"gzip":  {0x1f, 0x8b, 0x08},
+		"xz":    {0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00},
+		"zstd":  {0x28, 0xb5, 0x2f, 0xfd, 0x04},
+		"bzip2": []byte("BZh91AY"),
+		"lz4":   {0x02, 0x21, 0x4c, 0x18},
+		"cpio":  []byte("07070100000001"),
+		"":      []byte("not an initrd"),
+	} {
+		assert.Equal(t, want, detectCompression(head), want)
+	}
+}
+
+func TestValidateImage(t *testing.T) {
+	savedPath, savedDir, savedScan := igor.TFTPPath, igor.ImageStoreDir, igor.Server.ImageScanCommand
+	t.Cleanup(func() {
+		igor.TFTPPath, igor.ImageStoreDir, igor.Server.ImageScanCommand = savedPath, savedDir, savedScan
+	})
+	igor.TFTPPath, igor.ImageStoreDir, igor.Server.ImageScanCommand = t.TempDir(), "images", ""
+
+	image := &DistroImage{ImageID: "abc123", Kernel: "vmlinuz", Initrd: "initrd.img"}
+	store := getImageStorePath(image.ImageID)
+	require.NoError(t, os.MkdirAll(store, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(store, image.Kernel), testBzImage("6.8.0", false), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(store, image.Initrd), []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, 0644))
+
+	// boot modes not given are taken from the kernel
+	validateImage(image)
+	assert.Equal(t, ImageReady, image.Status)
+	assert.True(t, image.BiosBoot)
+	assert.False(t, image.UefiBoot)
+	assert.Equal(t, []string{
+		"PASS kernel: bzImage version 6.8.0",
+		"PASS boot: detected bios",
+		"PASS initrd: zstd compressed",
+	}, image.validationLines())
+
+	// uefi isn't possible without an EFI stub
+	image.UefiBoot = true
+	validateImage(image)
+	assert.Equal(t, ImageQuarantined, image.Status)
+	assert.Contains(t, image.validationLines(), "FAIL boot: not possible: uefi (kernel has no EFI stub)")
+
+	// the scanner decides on its own
+	image.UefiBoot = false
+	igor.Server.ImageScanCommand = "false"
+	checks := validateImage(image)
+	assert.Equal(t, ImageQuarantined, image.Status)
+	assert.False(t, checks[len(checks)-1].Passed)
+
+	igor.Server.ImageScanCommand = "true"
+	validateImage(image)
+	assert.Equal(t, ImageReady, image.Status)
+}
+
+// useTestScanner makes a scanner script with the given body the configured image scanner.
+func useTestScanner(t *testing.T, body string) {
+	saved := igor.Server.ImageScanCommand
+	t.Cleanup(func() { igor.Server.ImageScanCommand = saved })
+	igor.Server.ImageScanCommand = filepath.Join(t.TempDir(), "scan.sh")
+	require.NoError(t, os.WriteFile(igor.Server.ImageScanCommand, []byte("#!/bin/sh\n"+body+"\n"), 0755))
+}
+
+func TestScanStagedImage(t *testing.T) {
+	saved := igor.Server.ImageStagePath
+	t.Cleanup(func() { igor.Server.ImageStagePath = saved })
+	igor.Server.ImageStagePath = t.TempDir()
+	useTestScanner(t, `ls "$1"`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(igor.Server.ImageStagePath, "a1b2"), []byte("kernel"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(igor.Server.ImageStagePath, "c3d4"), []byte("initrd"), 0644))
+	staged := &stagedImage{
+		image:     &DistroImage{Type: DistroKI, Kernel: "vmlinuz", Initrd: "initrd.img"},
+		tempFiles: []string{"a1b2.kernel", "c3d4.initrd"},
+	}
+
+	// the scanner sees the files under their real names and its directory is removed afterward
+	check := scanStagedImage(staged)
+	assert.True(t, check.Passed)
+	assert.Equal(t, "initrd.img\nvmlinuz", check.Detail)
+	entries, err := os.ReadDir(igor.Server.ImageStagePath)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestUpdateDistroImageScansUnlocked(t *testing.T) {
+	db := newTestDB(t)
+	savedPath, savedDir := igor.TFTPPath, igor.ImageStoreDir
+	t.Cleanup(func() { igor.TFTPPath, igor.ImageStoreDir = savedPath, savedDir })
+	igor.TFTPPath, igor.ImageStoreDir = t.TempDir(), "images"
+
+	image := DistroImage{Name: "img1", ImageID: "abc123", Kernel: "vmlinuz", Initrd: "initrd.img", Status: ImageQuarantined}
+	store := getImageStorePath(image.ImageID)
+	require.NoError(t, os.MkdirAll(store, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(store, image.Kernel), testBzImage("6.8.0", false), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(store, image.Initrd), []byte{0x1f, 0x8b, 0x08}, 0644))
+	require.NoError(t, db.Create(&image).Error)
+
+	// the scanner waits until the test has taken the database lock
+	flags := t.TempDir()
+	started, release := filepath.Join(flags, "started"), filepath.Join(flags, "release")
+	useTestScanner(t, "touch "+started+"\nwhile [ ! -e "+release+" ]; do sleep 0.05; done")
+
+	router := httprouter.New()
+	router.Handle(http.MethodPatch, "/images/:imageName", NewHandlerChain().ApplyTo(handleUpdateDistroImage))
+	done := make(chan int)
+	go func() {
+		r := httptest.NewRequest(http.MethodPatch, "/images/img1", nil)
+		r = r.WithContext(context.WithValue(r.Context(), jsonBodyKey{}, map[string]interface{}{"validate": true}))
+		r = addUserToContext(r, &User{Name: "admin"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		done <- w.Code
+	}()
+
+	require.Eventually(t, func() bool { _, err := os.Stat(started); return err == nil }, 5*time.Second, 10*time.Millisecond)
+	locked := make(chan struct{})
+	go func() {
+		dbAccess.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the database is locked while the image is scanned")
+	}
+	require.NoError(t, os.WriteFile(release, nil, 0644))
+	dbAccess.Unlock()
+
+	assert.Equal(t, http.StatusOK, <-done)
+	var saved DistroImage
+	require.NoError(t, db.First(&saved, "name = ?", "img1").Error)
+	assert.Equal(t, ImageReady, saved.Status)
+}
@@ -39,6 +39,12 @@ func Execute(configFilepath *string) {
 	// we may eventually give them a choice (cobbler, etc.)
 	igor.IResInstaller = NewTFTPInstaller()
 
+	// publish host DHCP/DNS entries alongside the boot files if configured
+	if igor.Address.Manager != "" {
+		igor.IAddrManager = newAddrManager()
+		igor.IResInstaller = NewAddrInstaller(igor.IResInstaller, igor.IAddrManager)
+	}
+
 	initDbBackend()
 	initAuth()
 
@@ -55,6 +55,18 @@ func doDeleteGroup(groupName string, r *http.Request) (status int, err error) {
 			return fmt.Errorf("cannot delete '%s' - must edit or remove host policies defining access for this group", group.Name)
 		}
 
+		if vpList, vpErr := dbReadVlanPools(map[string]interface{}{}, tx); vpErr != nil {
+			status = http.StatusInternalServerError
+			return vpErr
+		} else {
+			for _, vp := range vpList {
+				if groupSliceContains(vp.Groups, group.Name) {
+					status = http.StatusConflict
+					return fmt.Errorf("cannot delete '%s' - must edit or remove vlan pools limited to this group", group.Name)
+				}
+			}
+		}
+
 		// all set -- let's try to delete the group
 
 		// drop the group from any reservations -- handle like a res update from the client as this will also
@@ -88,6 +100,19 @@ func doDeleteGroup(groupName string, r *http.Request) (status int, err error) {
 			}
 		}
 
+		// remove from named vlan group lists
+		if nvList, nvErr := dbReadNamedVlans(map[string]interface{}{}, tx); nvErr != nil {
+			return nvErr // uses default err status
+		} else {
+			for _, nv := range nvList {
+				if groupSliceContains(nv.Groups, group.Name) {
+					if err = dbEditNamedVlan(&nv, map[string]interface{}{"removeGroups": []Group{*group}}, tx); err != nil {
+						return err // uses default err status
+					}
+				}
+			}
+		}
+
 		return dbDeleteGroup(group, tx) // uses default err status
 
 	}); err == nil {
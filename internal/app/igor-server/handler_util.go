@@ -28,6 +28,14 @@ var stdNameCheckPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{2,23}$
 // Interface naming convention: https://www.cisco.com/assets/sol/sb/Switches_Emulators_v2_3_5_xx/help/350_550/index.html#page/tesla_350_550_olh/ts_getting_started_01_22.html
 var stdEthCheckPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/]{2,23}$`)
 
+// Regex for host interface roles. Lowercase letters, numbers, underscore and dash, starting with a letter. Must
+// be 2-16 characters in length.
+var nicRoleCheckPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,15}$`)
+
+// Regex for the names of networks in a reservation topology. Letters, numbers, underscore and dash, starting
+// with a letter. Must be 1-16 characters in length.
+var resNetworkNameCheckPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,15}$`)
+
 // Regex for distro Image ref (Image name). Consists of a prefix (image type), followed by 8 characters which can be a combination
 // of letters and numbers. Must be 10 characters in length total. No whitespace allowed. Example: kid942c59b
 var stdImageRefCheckPattern = regexp.MustCompile(`^(ki|iso)[a-zA-Z0-9]{8}$`)
@@ -67,8 +75,8 @@ func checkGenericNameRules(name string) error {
 // a permissions check or operation. These words should not be used as the name of a given resource.
 func isResourceNameMatch(value string) error {
 	switch value {
-	case PermGroups, PermUsers, PermClusters, PermDistros, PermHosts, PermProfiles, PermReservations,
-		"hostPolicy", "group", "user", "cluster", "distro", "host", "profile", "reservation":
+	case PermGroups, PermUsers, PermClusters, PermDistros, PermHosts, PermProfiles, PermReservations, PermVlans,
+		"hostPolicy", "group", "user", "cluster", "distro", "host", "profile", "reservation", "vlan":
 		return fmt.Errorf("name cannot be restricted word '%s'", value)
 	default:
 		return nil
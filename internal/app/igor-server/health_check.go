@@ -0,0 +1,346 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HealthCheckHTTP   = "http"
+	HealthCheckScript = "script"
+	HealthCheckBMC    = "bmc"
+
+	HealthActionNone  = "none"  // failing hosts are only logged and (optionally) reported to admins
+	HealthActionBlock = "block" // failing hosts are moved to the blocked state
+	HealthActionError = "error" // failing hosts are moved to the error state for admin attention
+	// failing hosts not in a reservation are put through a maintenance period like hosts of an ending reservation
+	HealthActionMaintenance = "maintenance"
+)
+
+var (
+	// healthFailCount tracks the number of consecutive health check runs each host has failed
+	healthFailCount   = make(map[string]int)
+	healthFailCountMU sync.Mutex
+)
+
+// HealthCheckConfig describes a single health check defined in the server config file.
+type HealthCheckConfig struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	// Timeout is the number of seconds a single check against a host may take.
+	Timeout int `yaml:"timeout" json:"timeout"`
+	// URL is used by http checks. {target} is replaced by the host name and {ip} by the host IP.
+	URL          string `yaml:"url" json:"url"`
+	ExpectStatus int    `yaml:"expectStatus" json:"expectStatus"`
+	// Script is used by script checks and is the name of an executable file in server.scriptDir.
+	Script string `yaml:"script" json:"script"`
+	// Command is used by bmc checks. {target} is replaced by the host name.
+	Command string   `yaml:"command" json:"command"`
+	Sensor  string   `yaml:"sensor" json:"sensor"`
+	Min     *float64 `yaml:"min" json:"min"`
+	Max     *float64 `yaml:"max" json:"max"`
+}
+
+// HealthCheckResult is the outcome of a single health check run against a host. Results are
+// kept as a history and pruned after healthCheck.historyDays.
+type HealthCheckResult struct {
+	Base
+	HostName string `gorm:"notNull;index"`
+	Check    string `gorm:"notNull"`
+	Type     string
+	Passed   bool
+	Detail   string
+}
+
+func (r *HealthCheckResult) getHealthCheckData() common.HealthCheckData {
+	return common.HealthCheckData{
+		Host:    r.HostName,
+		Check:   r.Check,
+		Type:    r.Type,
+		Passed:  r.Passed,
+		Detail:  r.Detail,
+		Checked: r.CreatedAt,
+	}
+}
+
+// IHealthCheck is an interface for checks that determine whether a cluster node is healthy
+// beyond the network and power status gathered by IHostProbe.
+type IHealthCheck interface {
+	// name returns the configured name of the check.
+	name() string
+	// checkHosts runs the check against each host and returns one result per host.
+	checkHosts(hosts []Host) []HealthCheckResult
+}
+
+// validateHealthCheckConfig verifies that the settings needed by the given check type are present
+// and fills in defaults.
+func validateHealthCheckConfig(c *HealthCheckConfig) error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5
+	}
+	switch c.Type {
+	case HealthCheckHTTP:
+		if c.URL == "" {
+			return fmt.Errorf("'%s' url is required for type %s", c.Name, c.Type)
+		}
+		if c.ExpectStatus == 0 {
+			c.ExpectStatus = 200
+		}
+	case HealthCheckScript:
+		if c.Script == "" {
+			return fmt.Errorf("'%s' script is required for type %s", c.Name, c.Type)
+		}
+		if strings.Contains(c.Script, "/") || strings.Contains(c.Script, "..") {
+			return fmt.Errorf("'%s' script must be a file name in server.scriptDir, not a path", c.Name)
+		}
+	case HealthCheckBMC:
+		if c.Command == "" {
+			return fmt.Errorf("'%s' command is required for type %s", c.Name, c.Type)
+		}
+		if c.Min == nil && c.Max == nil {
+			return fmt.Errorf("'%s' at least one of min or max is required for type %s", c.Name, c.Type)
+		}
+	default:
+		return fmt.Errorf("'%s' type '%s' not recognized; must be one of: %s, %s, %s",
+			c.Name, c.Type, HealthCheckHTTP, HealthCheckScript, HealthCheckBMC)
+	}
+	return nil
+}
+
+// newHealthCheck returns the IHealthCheck implementation for the given config.
+func newHealthCheck(c HealthCheckConfig) IHealthCheck {
+	switch c.Type {
+	case HealthCheckHTTP:
+		return NewHttpHealthCheck(c)
+	case HealthCheckScript:
+		return NewScriptHealthCheck(c)
+	default:
+		return NewBmcHealthCheck(c)
+	}
+}
+
+// healthCheckManager runs the configured health checks against all hosts on a fixed interval.
+func healthCheckManager() {
+	defer wg.Done()
+
+	checks := make([]IHealthCheck, 0, len(igor.HealthCheck.Checks))
+	for _, c := range igor.HealthCheck.Checks {
+		checks = append(checks, newHealthCheck(c))
+	}
+
+	countdown := NewScheduleTimer(time.Minute * time.Duration(igor.HealthCheck.Interval))
+	for {
+		select {
+		case <-shutdownChan:
+			logger.Info().Msg("stopping health check manager")
+			if !countdown.t.Stop() {
+				<-countdown.t.C
+			}
+			return
+		case checkTime := <-countdown.t.C:
+			logger.Debug().Msgf("doing host health checks - %v", checkTime.Format(time.RFC3339))
+			if err := runHealthChecks(checks, &checkTime); err != nil {
+				logger.Error().Msgf("%v", err)
+			}
+			countdown.reset()
+		}
+	}
+}
+
+// runHealthChecks runs every check against hosts that are not known to be powered off, saves the
+// results and applies the configured fail action to hosts that have reached the failure threshold.
+func runHealthChecks(checks []IHealthCheck, checkTime *time.Time) error {
+
+	hosts, err := dbReadHostsTx(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+
+	hostStatusMapMU.Lock()
+	targets := make([]Host, 0, len(hosts))
+	for _, h := range hosts {
+		if hostStatusMap != nil && hostStatusMap[h.HostName] == HostStatusOff {
+			continue
+		}
+		targets = append(targets, h)
+	}
+	hostStatusMapMU.Unlock()
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	var results []HealthCheckResult
+	for _, c := range checks {
+		logger.Debug().Msgf("running health check '%s' on %d hosts", c.name(), len(targets))
+		results = append(results, c.checkHosts(targets)...)
+	}
+
+	failures := make(map[string][]string)
+	for _, r := range results {
+		if !r.Passed {
+			failures[r.HostName] = append(failures[r.HostName], r.Check+": "+r.Detail)
+		}
+	}
+
+	newlyFailed := updateHealthFailCounts(targets, failures, igor.HealthCheck.FailThreshold)
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+		if cErr := dbCreateHealthCheckResults(results, tx); cErr != nil {
+			return cErr
+		}
+		cutoff := checkTime.AddDate(0, 0, -igor.HealthCheck.HistoryDays)
+		return dbPruneHealthCheckResults(cutoff, tx)
+	}); err != nil {
+		return err
+	}
+
+	if len(newlyFailed) == 0 {
+		return nil
+	}
+
+	logger.Warn().Msgf("hosts failed %d consecutive health check runs: %s", igor.HealthCheck.FailThreshold, strings.Join(newlyFailed, ","))
+
+	affected, actionErr := applyHealthFailAction(newlyFailed, hosts)
+	if actionErr != nil {
+		logger.Error().Msgf("health check fail action '%s' could not be applied: %v", igor.HealthCheck.FailAction, actionErr)
+	}
+
+	if igor.HealthCheck.NotifyAdmins {
+		failInfo := make(map[string][]string, len(newlyFailed))
+		for _, h := range newlyFailed {
+			failInfo[h] = failures[h]
+		}
+		if hostEvent := makeHostNotifyEvent(EmailHostHealthFail, igor.HealthCheck.FailAction, affected, failInfo); hostEvent != nil {
+			hostNotifyChan <- *hostEvent
+		}
+	}
+
+	return nil
+}
+
+// updateHealthFailCounts increments the consecutive failure count of each host in failures and
+// resets the count of every other checked host. It returns the hosts that reached threshold on
+// this run so the fail action is only applied once per failure streak.
+func updateHealthFailCounts(checked []Host, failures map[string][]string, threshold int) []string {
+
+	healthFailCountMU.Lock()
+	defer healthFailCountMU.Unlock()
+
+	var reached []string
+	for _, h := range checked {
+		if _, failed := failures[h.HostName]; failed {
+			healthFailCount[h.HostName]++
+			if healthFailCount[h.HostName] == threshold {
+				reached = append(reached, h.HostName)
+			}
+		} else {
+			delete(healthFailCount, h.HostName)
+		}
+	}
+	sort.Strings(reached)
+	return reached
+}
+
+// applyHealthFailAction moves the failing hosts into the state given by healthCheck.failAction.
+// Hosts already blocked or in error are left alone. It returns the names of hosts that changed state.
+func applyHealthFailAction(failed []string, hosts []Host) (affected []string, err error) {
+
+	failSet := common.NewSet()
+	failSet.Add(failed...)
+
+	var newState HostState
+	switch igor.HealthCheck.FailAction {
+	case HealthActionBlock:
+		newState = HostBlocked
+	case HealthActionError:
+		newState = HostError
+	case HealthActionMaintenance:
+		return startHealthMaintenance(failSet, hosts)
+	default:
+		return nil, nil
+	}
+
+	var changeList []Host
+	for _, h := range hosts {
+		if failSet.Contains(h.HostName) && h.State <= HostReserved {
+			changeList = append(changeList, h)
+		}
+	}
+	if len(changeList) == 0 {
+		return nil, nil
+	}
+
+	err = performDbTx(func(tx *gorm.DB) error {
+		if editErr := dbEditHosts(changeList, map[string]interface{}{"State": newState}, tx); editErr != nil {
+			return editErr
+		}
+		// if host is in maintenance mode, keep the new state when maintenance finishes
+		for _, h := range changeList {
+			if len(h.MaintenanceRes) > 0 {
+				if editErr := dbEditHosts([]Host{h}, map[string]interface{}{"RestoreState": newState}, tx); editErr != nil {
+					return editErr
+				}
+			}
+		}
+		return nil
+	})
+
+	if err == nil {
+		affected = hostNamesOfHosts(changeList)
+		logger.Warn().Msgf("health check moved hosts to state '%s': %s", newState, strings.Join(affected, ","))
+	}
+	return
+}
+
+// startHealthMaintenance puts failing hosts that are available and not already in maintenance through a
+// maintenance period. They are blocked, get the default distro if there is one, and return to available
+// when maintenance.hostMaintenanceDuration has passed. Reserved hosts are left alone so reservations
+// aren't interrupted.
+func startHealthMaintenance(failSet *common.Set, hosts []Host) (affected []string, err error) {
+
+	var forMaintenance []Host
+	for _, h := range hosts {
+		if failSet.Contains(h.HostName) && h.State == HostAvailable && len(h.MaintenanceRes) == 0 {
+			h.RestoreState = HostAvailable
+			forMaintenance = append(forMaintenance, h)
+		}
+	}
+	if len(forMaintenance) == 0 {
+		return nil, nil
+	}
+
+	maintenanceDelta := time.Duration(float64(time.Minute) * float64(igor.Config.Maintenance.HostMaintenanceDuration))
+	maintenanceRes := &MaintenanceRes{
+		ReservationName:    "health-check",
+		MaintenanceEndTime: time.Now().Add(maintenanceDelta),
+		Hosts:              forMaintenance,
+	}
+	if err = dbCreateMaintenanceRes(maintenanceRes); err != nil {
+		return nil, err
+	}
+	if err = startMaintenance(maintenanceRes); err != nil {
+		return nil, err
+	}
+
+	affected = hostNamesOfHosts(forMaintenance)
+	logger.Warn().Msgf("health check put hosts into maintenance: %s", strings.Join(affected, ","))
+	return affected, nil
+}
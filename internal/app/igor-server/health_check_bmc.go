@@ -0,0 +1,98 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BmcHealthCheck implements IHealthCheck.
+// It runs a BMC sensor command (ex. ipmitool sensor reading) for each host and
+// passes if the reported value is within the configured min/max thresholds.
+type BmcHealthCheck struct {
+	Name    string
+	Command string
+	Sensor  string
+	Min     *float64
+	Max     *float64
+	Timeout time.Duration
+}
+
+// NewBmcHealthCheck returns a configured BMC sensor health check.
+func NewBmcHealthCheck(c HealthCheckConfig) IHealthCheck {
+	return &BmcHealthCheck{
+		Name:    c.Name,
+		Command: c.Command,
+		Sensor:  c.Sensor,
+		Min:     c.Min,
+		Max:     c.Max,
+		Timeout: time.Duration(c.Timeout) * time.Second,
+	}
+}
+
+func (c *BmcHealthCheck) name() string {
+	return c.Name
+}
+
+func (c *BmcHealthCheck) checkHosts(hosts []Host) []HealthCheckResult {
+
+	hostNames := hostNamesOfHosts(hosts)
+
+	outputMap, err := runAllCapture(c.Command, hostNames, c.Timeout)
+	if err != nil {
+		logger.Debug().Msgf("health check %s: %v", c.Name, err)
+	}
+
+	results := make([]HealthCheckResult, 0, len(hostNames))
+	for _, h := range hostNames {
+		result := HealthCheckResult{HostName: h, Check: c.Name, Type: HealthCheckBMC}
+		out, ok := outputMap[h]
+		if !ok {
+			result.Detail = "no sensor output"
+		} else if val, pErr := parseSensorReading(out, c.Sensor); pErr != nil {
+			result.Detail = pErr.Error()
+		} else {
+			result.Passed = sensorInRange(val, c.Min, c.Max)
+			result.Detail = fmt.Sprintf("%s = %g", c.Sensor, val)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// parseSensorReading finds the numeric reading for sensor in the output of a BMC command. Lines
+// are expected in the pipe-delimited form used by ipmitool, ex. "CPU Temp | 45.000 | degrees C | ok".
+// If sensor is blank the first line with a numeric reading is used.
+func parseSensorReading(output, sensor string) (float64, error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 2 {
+			continue
+		}
+		if sensor != "" && !strings.EqualFold(strings.TrimSpace(fields[0]), sensor) {
+			continue
+		}
+		if val, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64); err == nil {
+			return val, nil
+		}
+	}
+	return 0, fmt.Errorf("no reading found for sensor '%s'", sensor)
+}
+
+// sensorInRange returns true if val is within the inclusive min/max thresholds. A nil
+// threshold is not checked.
+func sensorInRange(val float64, min, max *float64) bool {
+	if min != nil && val < *min {
+		return false
+	}
+	if max != nil && val > *max {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dbCreateHealthCheckResults saves a batch of health check results.
+func dbCreateHealthCheckResults(results []HealthCheckResult, tx *gorm.DB) error {
+	if len(results) == 0 {
+		return nil
+	}
+	result := tx.Create(&results)
+	return result.Error
+}
+
+// dbReadHealthCheckResultsTx performs dbReadHealthCheckResults within a new transaction.
+func dbReadHealthCheckResultsTx(queryParams map[string]interface{}, limit int) (results []HealthCheckResult, err error) {
+	err = performDbTx(func(tx *gorm.DB) error {
+		results, err = dbReadHealthCheckResults(queryParams, limit, tx)
+		return err
+	})
+	return results, err
+}
+
+// dbReadHealthCheckResults returns health check results matching queryParams, newest first. If limit is
+// greater than 0 no more than limit results are returned.
+func dbReadHealthCheckResults(queryParams map[string]interface{}, limit int, tx *gorm.DB) (results []HealthCheckResult, err error) {
+
+	for key, val := range queryParams {
+		switch val.(type) {
+		case bool, string, int:
+			tx = tx.Where(key, val)
+		case []string:
+			// quote the column since 'check' is a reserved word in SQL
+			tx = tx.Where(clause.IN{Column: clause.Column{Name: key}, Values: stringsToAny(val.([]string))})
+		default:
+			logger.Error().Msgf("dbReadHealthCheckResults: incorrect parameter type %T received for %s: %v", val, key, val)
+		}
+	}
+	tx = tx.Order("created_at desc")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	result := tx.Find(&results)
+	return results, result.Error
+}
+
+// dbPruneHealthCheckResults permanently removes health check results older than cutoff.
+func dbPruneHealthCheckResults(cutoff time.Time, tx *gorm.DB) error {
+	result := tx.Where("created_at < ?", cutoff).Delete(&HealthCheckResult{})
+	return result.Error
+}
+
+func stringsToAny(vals []string) []interface{} {
+	out := make([]interface{}, len(vals))
+	for i, v := range vals {
+		out[i] = v
+	}
+	return out
+}
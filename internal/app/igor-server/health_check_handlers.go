@@ -0,0 +1,118 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+)
+
+// destination for route GET /hosts/:hostName/health
+func handleReadHostHealth(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	actionPrefix := "read host health"
+	rb := common.NewResponseBodyHealth()
+
+	ps := httprouter.ParamsFromContext(r.Context())
+	name := ps.ByName("hostName")
+
+	results, status, err := doReadHostHealth(name, r.URL.Query())
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		healthList := make([]common.HealthCheckData, 0, len(results))
+		for _, hr := range results {
+			healthList = append(healthList, hr.getHealthCheckData())
+		}
+		if len(healthList) == 0 {
+			rb.Message = "no health check results recorded for this host"
+		}
+		rb.Data["health"] = healthList
+		clog.Info().Msgf("%s success", actionPrefix)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// doReadHostHealth returns the health check history of the named host, optionally filtered
+// by check name and limited in size.
+func doReadHostHealth(name string, queryMap url.Values) (results []HealthCheckResult, status int, err error) {
+
+	status = http.StatusInternalServerError
+
+	hosts, err := dbReadHostsTx(map[string]interface{}{"name": name})
+	if err != nil {
+		return
+	}
+	if len(hosts) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("host '%s' not found", name)
+	}
+
+	queryParams := map[string]interface{}{"host_name": hosts[0].HostName}
+	if checks, ok := queryMap["check"]; ok {
+		queryParams["check"] = checks
+	}
+	limit := 0
+	if val, ok := queryMap["limit"]; ok {
+		limit, _ = strconv.Atoi(val[0])
+	}
+
+	if results, err = dbReadHealthCheckResultsTx(queryParams, limit); err != nil {
+		return
+	}
+
+	return results, http.StatusOK, nil
+}
+
+func validateHealthParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		if r.Method == http.MethodGet {
+
+			queryParams := r.URL.Query()
+
+		queryParamLoop:
+			for key, val := range queryParams {
+				switch strings.TrimSpace(key) {
+				case "check":
+					for _, c := range val {
+						if strings.TrimSpace(c) == "" {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break queryParamLoop
+						}
+					}
+				case "limit":
+					if limit, err := strconv.Atoi(val[0]); err != nil || limit < 1 {
+						validateErr = NewBadParamTypeError(key, val, "positive int")
+						break queryParamLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, val)
+					break queryParamLoop
+				}
+			}
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateHealthParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
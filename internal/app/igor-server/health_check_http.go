@@ -0,0 +1,80 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HttpHealthCheck implements IHealthCheck.
+// It sends a GET request to a URL built for each host and passes if the
+// response status code matches the expected value.
+type HttpHealthCheck struct {
+	Name         string
+	URL          string
+	ExpectStatus int
+	client       *http.Client
+}
+
+// NewHttpHealthCheck returns a configured HTTP health check. Certificates are not
+// verified since cluster nodes commonly serve self-signed certs.
+func NewHttpHealthCheck(c HealthCheckConfig) IHealthCheck {
+	return &HttpHealthCheck{
+		Name:         c.Name,
+		URL:          c.URL,
+		ExpectStatus: c.ExpectStatus,
+		client: &http.Client{
+			Timeout: time.Duration(c.Timeout) * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+}
+
+func (c *HttpHealthCheck) name() string {
+	return c.Name
+}
+
+func (c *HttpHealthCheck) checkHosts(hosts []Host) []HealthCheckResult {
+
+	var (
+		mu      sync.Mutex
+		results = make([]HealthCheckResult, 0, len(hosts))
+		byName  = make(map[string]Host, len(hosts))
+	)
+	for _, h := range hosts {
+		byName[h.HostName] = h
+	}
+
+	r := DefaultRunner(func(target string) error {
+		h := byName[target]
+		url := strings.ReplaceAll(c.URL, "{target}", h.HostName)
+		url = strings.ReplaceAll(url, "{ip}", h.IP)
+
+		result := HealthCheckResult{HostName: h.HostName, Check: c.Name, Type: HealthCheckHTTP}
+		resp, err := c.client.Get(url)
+		if err != nil {
+			result.Detail = err.Error()
+		} else {
+			_ = resp.Body.Close()
+			result.Passed = resp.StatusCode == c.ExpectStatus
+			result.Detail = fmt.Sprintf("status %d", resp.StatusCode)
+		}
+
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+		return nil
+	})
+	_ = r.RunAll(hostNamesOfHosts(hosts))
+
+	return results
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScriptHealthCheck implements IHealthCheck.
+// It runs an admin-supplied script from server.scriptDir once per host, passing
+// the host name as the only argument. An exit status of 0 means the host passed.
+type ScriptHealthCheck struct {
+	Name    string
+	Path    string
+	Timeout time.Duration
+}
+
+// NewScriptHealthCheck returns a configured script health check.
+func NewScriptHealthCheck(c HealthCheckConfig) IHealthCheck {
+	return &ScriptHealthCheck{
+		Name:    c.Name,
+		Path:    filepath.Join(igor.Server.ScriptDir, c.Script),
+		Timeout: time.Duration(c.Timeout) * time.Second,
+	}
+}
+
+func (c *ScriptHealthCheck) name() string {
+	return c.Name
+}
+
+func (c *ScriptHealthCheck) checkHosts(hosts []Host) []HealthCheckResult {
+
+	var (
+		mu      sync.Mutex
+		results = make([]HealthCheckResult, 0, len(hosts))
+	)
+
+	r := DefaultRunner(func(target string) error {
+		result := HealthCheckResult{HostName: target, Check: c.Name, Type: HealthCheckScript}
+		if !cmdTargetRE.MatchString(target) {
+			result.Detail = "invalid host name"
+		} else {
+			out, err := processWrapper(context.Background(), c.Timeout, c.Path, target)
+			result.Passed = err == nil
+			result.Detail = strings.TrimSpace(out)
+			if err != nil && result.Detail == "" {
+				result.Detail = err.Error()
+			}
+		}
+
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+		return nil
+	})
+	_ = r.RunAll(hostNamesOfHosts(hosts))
+
+	return results
+}
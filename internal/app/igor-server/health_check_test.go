@@ -0,0 +1,94 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSensorReading(t *testing.T) {
+	output := "CPU Temp         | 45.000     | degrees C  | ok\nFan1             | 5400.000   | RPM        | ok\n"
+
+	val, err := parseSensorReading(output, "cpu temp")
+	assert.NoError(t, err)
+	assert.Equal(t, 45.0, val)
+
+	val, err = parseSensorReading(output, "Fan1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5400.0, val)
+
+	val, err = parseSensorReading(output, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 45.0, val)
+
+	_, err = parseSensorReading(output, "PSU1")
+	assert.Error(t, err)
+
+	_, err = parseSensorReading("Error: Unable to establish IPMI v2 / RMCP+ session", "CPU Temp")
+	assert.Error(t, err)
+}
+
+func TestSensorInRange(t *testing.T) {
+	low, high := 10.0, 85.0
+	assert.True(t, sensorInRange(45, &low, &high))
+	assert.True(t, sensorInRange(85, &low, &high))
+	assert.False(t, sensorInRange(90, &low, &high))
+	assert.False(t, sensorInRange(5, &low, nil))
+	assert.True(t, sensorInRange(500, &low, nil))
+	assert.True(t, sensorInRange(-5, nil, nil))
+}
+
+func TestUpdateHealthFailCounts(t *testing.T) {
+	healthFailCount = make(map[string]int)
+	hosts := []Host{{HostName: "kn1"}, {HostName: "kn2"}}
+	fail := map[string][]string{"kn1": {"http: status 500"}}
+
+	assert.Empty(t, updateHealthFailCounts(hosts, fail, 2))
+	assert.Equal(t, []string{"kn1"}, updateHealthFailCounts(hosts, fail, 2))
+	// already reported, so the action is not repeated while the streak continues
+	assert.Empty(t, updateHealthFailCounts(hosts, fail, 2))
+	// a passing run resets the streak
+	assert.Empty(t, updateHealthFailCounts(hosts, map[string][]string{}, 2))
+	assert.Equal(t, 0, healthFailCount["kn1"])
+}
+
+func TestReadHostHealthByCheck(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, db.Create(&Host{Name: "kn1", HostName: "kn1"}).Error)
+	require.NoError(t, dbCreateHealthCheckResults([]HealthCheckResult{
+		{HostName: "kn1", Check: "disk", Type: HealthCheckScript, Passed: true},
+		{HostName: "kn1", Check: "temp", Type: HealthCheckBMC, Passed: false},
+		{HostName: "kn1", Check: "web", Type: HealthCheckHTTP, Passed: true},
+	}, db))
+
+	results, status, err := doReadHostHealth("kn1", url.Values{"check": {"temp", "web"}})
+	require.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.ElementsMatch(t, []string{"temp", "web"}, []string{results[0].Check, results[1].Check})
+
+	results, err = dbReadHealthCheckResultsTx(map[string]interface{}{"check": "disk"}, 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Passed)
+}
+
+func TestHostNotifyEventAction(t *testing.T) {
+	saved := igor.Email.SmtpServer
+	t.Cleanup(func() { igor.Email.SmtpServer = saved })
+	igor.Email.SmtpServer = "smtp.example.com"
+
+	// only health check failures report the fail action taken on the affected hosts
+	ev := makeHostNotifyEvent(EmailHostHealthFail, "block", []string{"kn1"}, map[string][]string{"kn1": {"ping"}})
+	require.NotNil(t, ev)
+	assert.Equal(t, "block", ev.Action)
+
+	ev = makeHostNotifyEvent(EmailHostFlapping, "", []string{"kn1"}, nil)
+	require.NotNil(t, ev)
+	assert.Empty(t, ev.Action)
+}
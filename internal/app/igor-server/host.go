@@ -28,7 +28,7 @@ const (
 	PermHosts = "hosts"
 )
 
-var AllowedBootModes = [...]string{"bios", "uefi"}
+var AllowedBootModes = [...]string{"bios", "uefi", "ipxe"}
 
 // Host is the compute resource being reserved. It's data contains all relevant information needed by
 // igor to make reservations and issue commands to interact with a given host or get information
@@ -38,18 +38,23 @@ type Host struct {
 	Name           string `gorm:"unique; notNull"`
 	HostName       string `gorm:"unique; notNull"`
 	SequenceID     int    `gorm:"notNull; uniqueIndex:idx_cluster_seq"`
-	Eth            string
+	Eth            string // port on the host's switch
+	SwitchName     string // name of the switch in vlan.switches the host is connected to; blank means the first one
 	Mac            string `gorm:"unique; notNull"`
 	IP             string
-	BootMode       string    `gorm:"notNull; default:bios"`
-	State          HostState // State is the HostState of this node. Default when created is HostBlocked.
-	RestoreState   HostState // State to return to after Maintenance phase is done. Either HostAvailable or HostBlocked.
-	ClusterID      int       `gorm:"notNull; uniqueIndex:idx_cluster_seq"`
-	Cluster        Cluster   `gorm:"->;<-:create; notNull"` // read/create only; hosts never change clusters
+	BootMode       string     `gorm:"notNull; default:bios"`
+	State          HostState  // State is the HostState of this node. Default when created is HostBlocked.
+	RestoreState   HostState  // State to return to after Maintenance phase is done. Either HostAvailable or HostBlocked.
+	StateReason    string     // reason given by an admin for moving the host into its current lifecycle state
+	RepairTicket   string     // ticket reference while the host is in repair
+	RepairReturn   *time.Time // expected return date while the host is in repair
+	ClusterID      int        `gorm:"notNull; uniqueIndex:idx_cluster_seq"`
+	Cluster        Cluster    `gorm:"->;<-:create; notNull"` // read/create only; hosts never change clusters
 	HostPolicyID   int
 	HostPolicy     HostPolicy       `gorm:"notNull"` // host policy assigned to this host. Assigned to policy DefaultPolicyName at host creation.
 	Reservations   []Reservation    `gorm:"many2many:reservations_hosts;"`
 	MaintenanceRes []MaintenanceRes `gorm:"many2many:maintenanceres_hosts;"`
+	Interfaces     []HostInterface  // network interfaces beyond the primary one described by Mac, Eth and SwitchName
 }
 
 func (h *Host) GetHostIPs() ([]net.IP, error) {
@@ -128,6 +133,7 @@ func (h *Host) getHostData(powered HostStatus, user *User) common.HostData {
 		SequenceID:   h.SequenceID,
 		HostName:     h.HostName,
 		Eth:          h.Eth,
+		Switch:       h.SwitchName,
 		IP:           ip,
 		Mac:          h.Mac,
 		BootMode:     h.BootMode,
@@ -138,6 +144,14 @@ func (h *Host) getHostData(powered HostStatus, user *User) common.HostData {
 		AccessGroups: groups,
 		Restricted:   restricted,
 		Reservations: resNames,
+		StateReason:  h.StateReason,
+		RepairTicket: h.RepairTicket,
+	}
+	if h.RepairReturn != nil {
+		hd.RepairReturn = h.RepairReturn.Unix()
+	}
+	for _, nic := range h.Interfaces {
+		hd.Nics = append(hd.Nics, nic.getHostNicData())
 	}
 
 	return hd
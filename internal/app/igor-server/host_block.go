@@ -50,6 +50,13 @@ func doUpdateBlockHosts(blockAction bool, hostList []string, r *http.Request) (s
 
 		if blockAction {
 
+			for _, h := range hList {
+				if h.State == HostRepair || h.State == HostDecommissioned {
+					status = http.StatusConflict
+					return fmt.Errorf("cannot block host '%s' in state %s", h.HostName, h.State)
+				}
+			}
+
 			blockedRes := make(map[string]Reservation)
 			for _, h := range hList {
 				if h.State == HostReserved {
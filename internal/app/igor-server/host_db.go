@@ -35,7 +35,7 @@ func dbReadHostsTx(queryParams map[string]interface{}) (hosts []Host, err error)
 func dbReadHosts(queryParams map[string]interface{}, tx *gorm.DB) (hosts []Host, err error) {
 
 	tx = tx.Preload("Cluster").Preload("HostPolicy").Preload("HostPolicy.AccessGroups").
-		Preload("Reservations").Preload("MaintenanceRes")
+		Preload("Reservations").Preload("Reservations.Networks").Preload("MaintenanceRes").Preload("Interfaces")
 
 	// if no params given, return all
 	if len(queryParams) == 0 {
@@ -79,6 +79,26 @@ func dbEditHosts(hosts []Host, changes map[string]interface{}, tx *gorm.DB) erro
 		}
 		delete(changes, "HostPolicy")
 	}
+	if nics, ok := changes["Interfaces"].([]HostInterface); ok {
+		// the list replaces whatever interfaces the hosts had before
+		for _, h := range hosts {
+			if err := dbDeleteHostInterfaces([]int{h.ID}, tx); err != nil {
+				return err
+			}
+			if len(nics) == 0 {
+				continue
+			}
+			hostNics := make([]HostInterface, len(nics))
+			for i, nic := range nics {
+				nic.HostID = h.ID
+				hostNics[i] = nic
+			}
+			if err := tx.Create(&hostNics).Error; err != nil {
+				return err
+			}
+		}
+		delete(changes, "Interfaces")
+	}
 	if len(changes) > 0 {
 		result := tx.Model(&hosts).Updates(changes)
 		return result.Error
@@ -86,6 +106,15 @@ func dbEditHosts(hosts []Host, changes map[string]interface{}, tx *gorm.DB) erro
 	return nil
 }
 
+// dbDeleteHostInterfaces removes the additional interfaces of the given hosts
+func dbDeleteHostInterfaces(hostIDs []int, tx *gorm.DB) error {
+	if len(hostIDs) == 0 {
+		return nil
+	}
+	result := tx.Where("host_id IN ?", hostIDs).Delete(&HostInterface{})
+	return result.Error
+}
+
 // dbDeleteHosts removes the list of hosts from the DB
 func dbDeleteHosts(targets []Host, tx *gorm.DB) error {
 	if len(targets) == 0 {
@@ -96,15 +125,18 @@ func dbDeleteHosts(targets []Host, tx *gorm.DB) error {
 }
 
 // dbCheckHostAvailable takes a list of hostnames and reports back if any are in a state that don't allow new reservations
-// to be made. If status return is 200/OK, it is assumed all the named hosts are available for scheduling.
-func dbCheckHostAvailable(hosts []string, tx *gorm.DB) (int, error) {
+// to be made. If status return is 200/OK, it is assumed all the named hosts are available for scheduling. Hosts in
+// burn-in are only considered available if allowBurnIn is true.
+func dbCheckHostAvailable(hosts []string, allowBurnIn bool, tx *gorm.DB) (int, error) {
 
 	var hostsCurrUnavail []string
 
-	// Check if any of the declared hosts are currently not accepting reservations (draining, blocked or error)
-	result := tx.Model(&Host{}).
-		Where("name IN ? AND state > ?", hosts, HostReserved).
-		Pluck("name", &hostsCurrUnavail)
+	// Check if any of the declared hosts are currently not accepting reservations (draining, blocked, error, etc.)
+	tx = tx.Model(&Host{}).Where("name IN ? AND state > ?", hosts, HostReserved)
+	if allowBurnIn {
+		tx = tx.Where("state != ?", HostBurnIn)
+	}
+	result := tx.Pluck("name", &hostsCurrUnavail)
 	if result.RowsAffected > 0 {
 		return http.StatusConflict, fmt.Errorf("the following hosts are not available at this time: %v", hostsCurrUnavail)
 	}
@@ -53,6 +53,13 @@ func doDeleteHost(hostName string, r *http.Request) (status int, err error) {
 			}
 		}
 
+		if attrErr := dbDeleteHostAttributes([]int{host.ID}, tx); attrErr != nil {
+			return attrErr
+		}
+		if nicErr := dbDeleteHostInterfaces([]int{host.ID}, tx); nicErr != nil {
+			return nicErr
+		}
+
 		deleteErr := dbDeleteHosts(hList, tx)
 		if deleteErr != nil {
 			return deleteErr
@@ -28,6 +28,7 @@ func handleReadHosts(w http.ResponseWriter, r *http.Request) {
 	rb := common.NewResponseBodyHosts()
 	var hostList []Host
 	var filterPowered *bool
+	showDetail := false
 
 	queryParams, status, err := parseHostSearchParams(queryMap, r)
 	if err == nil {
@@ -37,6 +38,9 @@ func handleReadHosts(w http.ResponseWriter, r *http.Request) {
 				tmpPwrFilter, _ := strconv.ParseBool(powered[0])
 				filterPowered = &tmpPwrFilter
 			}
+			if detail, ok := queryMap["detail"]; ok {
+				showDetail, _ = strconv.ParseBool(detail[0])
+			}
 		}
 	}
 
@@ -49,6 +53,12 @@ func handleReadHosts(w http.ResponseWriter, r *http.Request) {
 		} else {
 			refreshStatusChan <- struct{}{}
 			hostDetails = filterHostList(hostList, filterPowered, getUserFromContext(r))
+			if showDetail {
+				if err = addHostAttributes(hostList, hostDetails); err != nil {
+					stdErrorResp(rb, http.StatusInternalServerError, actionPrefix, err, clog)
+					status = http.StatusInternalServerError
+				}
+			}
 		}
 		rb.Data["hosts"] = hostDetails
 	}
@@ -195,7 +205,7 @@ func validateHostParams(handler http.Handler) http.Handler {
 							break queryParamLoop
 						}
 					}
-				case "powered":
+				case "powered", "detail":
 					if len(vals) > 1 {
 						validateErr = fmt.Errorf("invalid parameter: '%s' cannot have multiple values", key)
 						break queryParamLoop
@@ -226,6 +236,20 @@ func validateHostParams(handler http.Handler) http.Handler {
 						} else if validateErr = checkEthRules(val.(string)); validateErr != nil {
 							break patchParamLoop
 						}
+					case "switch":
+						if _, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break patchParamLoop
+						} else if validateErr = checkSwitchName(val.(string)); validateErr != nil {
+							break patchParamLoop
+						}
+					case "nics":
+						if _, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break patchParamLoop
+						} else if _, validateErr = parseHostNics(val.(string)); validateErr != nil {
+							break patchParamLoop
+						}
 					case "hostPolicy":
 						if _, ok := val.(string); !ok {
 							validateErr = NewBadParamTypeError(key, val, "string")
@@ -455,3 +479,90 @@ func validateBlockParams(handler http.Handler) http.Handler {
 		handler.ServeHTTP(w, r)
 	})
 }
+
+// destination for route PATCH /hosts-ctrl/state
+func handleStateHosts(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	stateParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "change host(s) state"
+	rb := common.NewResponseBody()
+
+	change, status, err := checkStateParams(stateParams)
+	if err == nil {
+		rb.Data["hosts"] = change.hostNameList
+		status, err = doUpdateHostState(change, r)
+	}
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		clog.Info().Msgf("%s success [%v]", actionPrefix, strings.Join(change.hostNameList, ","))
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+func validateStateParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		stateParams := getBodyFromContext(r)
+
+		if len(stateParams) > 0 {
+			_, h := stateParams["hosts"]
+			_, s := stateParams["state"]
+			_, rsn := stateParams["reason"]
+			if !h {
+				validateErr = fmt.Errorf("missing required hosts parameter")
+			} else if !s {
+				validateErr = fmt.Errorf("missing required state parameter")
+			} else if !rsn {
+				validateErr = fmt.Errorf("missing required reason parameter")
+			} else {
+
+			patchParamLoop:
+				for key, val := range stateParams {
+					switch key {
+					case "hosts", "reason", "ticket":
+						if _, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break patchParamLoop
+						}
+					case "state":
+						if sVal, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break patchParamLoop
+						} else if resolveHostState(sVal) == HostInvalid {
+							validateErr = fmt.Errorf("host state term '%s' not accepted", sVal)
+							break patchParamLoop
+						}
+					case "returnDate":
+						if _, ok := val.(float64); !ok {
+							validateErr = NewBadParamTypeError(key, val, "int")
+							break patchParamLoop
+						}
+					default:
+						validateErr = NewUnknownParamError(key, val)
+						break patchParamLoop
+					}
+				}
+			}
+		} else {
+			validateErr = NewMissingParamError("")
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateStateParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,461 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/rs/zerolog/hlog"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+const (
+	HostImportCSV  = "csv"
+	HostImportYAML = "yaml"
+
+	HostImportCreate    = "create"
+	HostImportUpdate    = "update"
+	HostImportUnchanged = "unchanged"
+)
+
+// hostCSVHeader is the column order used when exporting hosts as CSV. Imported CSV files must have
+// a header row but the columns may be in any order; only name, mac and ip are required.
+var hostCSVHeader = []string{"name", "hostname", "mac", "ip", "eth", "switch", "bootMode", "policy", "nics"}
+
+// hostImportRecord is the desired state of a single host as described by an import file. Every record
+// is a full description of the host, so blank optional fields reset the host to the default value.
+type hostImportRecord struct {
+	Name       string
+	SequenceID int
+	HostName   string
+	Mac        string
+	IP         string
+	Eth        string
+	Switch     string
+	BootMode   string
+	Policy     string
+	Nics       string // additional interfaces in the form read by parseHostNics
+	nicList    []HostInterface
+}
+
+// hostImportPlan holds the hosts to create and the field changes to make on existing hosts.
+type hostImportPlan struct {
+	changes      []common.HostImportChange
+	creates      []Host
+	updates      []Host
+	updateFields []map[string]interface{}
+}
+
+// exportHosts writes the hosts of the cluster in the given format. The YAML format is the same as
+// the igor-clusters.yaml file.
+func exportHosts(cluster *Cluster, format string) (string, error) {
+
+	if format == HostImportYAML {
+		yDoc, _, err := assembleYamlOutput([]Cluster{*cluster})
+		return string(yDoc), err
+	}
+
+	hosts := append([]Host{}, cluster.Hosts...)
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].SequenceID < hosts[j].SequenceID
+	})
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(hostCSVHeader)
+	for _, h := range hosts {
+		_ = w.Write([]string{h.Name, h.HostName, h.Mac, h.IP, h.Eth, h.SwitchName, h.BootMode, h.HostPolicy.Name, formatHostNics(h.Interfaces)})
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// parseHostImport reads host records from CSV or YAML data. Host names must use the cluster prefix.
+func parseHostImport(format, data, prefix string) ([]hostImportRecord, error) {
+
+	var records []hostImportRecord
+
+	switch format {
+	case HostImportYAML:
+		ccMap := make(map[string]ClusterConfig)
+		if err := yaml.Unmarshal([]byte(data), &ccMap); err != nil {
+			return nil, fmt.Errorf("problem reading yaml - %v", err)
+		}
+		if len(ccMap) != 1 {
+			return nil, fmt.Errorf("yaml must describe exactly one cluster")
+		}
+		for _, cc := range ccMap {
+			if cc.Prefix != prefix {
+				return nil, fmt.Errorf("yaml cluster prefix '%s' does not match cluster prefix '%s'", cc.Prefix, prefix)
+			}
+			for seq, hm := range cc.HostMap {
+				records = append(records, hostImportRecord{
+					Name:       prefix + strconv.Itoa(seq),
+					SequenceID: seq,
+					HostName:   hm["hostname"],
+					Mac:        hm["mac"],
+					IP:         hm["ip"],
+					Eth:        hm["eth"],
+					Switch:     hm["switch"],
+					BootMode:   hm["bootMode"],
+					Policy:     hm["policy"],
+					Nics:       hm["nics"],
+				})
+			}
+		}
+
+	case HostImportCSV:
+		r := csv.NewReader(strings.NewReader(data))
+		r.TrimLeadingSpace = true
+		header, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("problem reading csv header - %v", err)
+		}
+		cols := make(map[string]int)
+		for i, col := range header {
+			col = strings.TrimSpace(col)
+			known := false
+			for _, h := range hostCSVHeader {
+				if strings.EqualFold(col, h) {
+					cols[h] = i
+					known = true
+				}
+			}
+			if !known {
+				return nil, fmt.Errorf("unknown csv column '%s'; allowed columns are %s", col, strings.Join(hostCSVHeader, ","))
+			}
+		}
+		for _, req := range []string{"name", "mac", "ip"} {
+			if _, ok := cols[req]; !ok {
+				return nil, fmt.Errorf("csv is missing required column '%s'", req)
+			}
+		}
+
+		field := func(row []string, col string) string {
+			if i, ok := cols[col]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		for {
+			row, rErr := r.Read()
+			if rErr == io.EOF {
+				break
+			} else if rErr != nil {
+				return nil, fmt.Errorf("problem reading csv - %v", rErr)
+			}
+			name := field(row, "name")
+			seq, sErr := strconv.Atoi(strings.TrimPrefix(name, prefix))
+			if !strings.HasPrefix(name, prefix) || sErr != nil || seq < 1 {
+				return nil, fmt.Errorf("host name '%s' does not match the cluster naming convention %s<number>", name, prefix)
+			}
+			records = append(records, hostImportRecord{
+				Name:       name,
+				SequenceID: seq,
+				HostName:   field(row, "hostname"),
+				Mac:        field(row, "mac"),
+				IP:         field(row, "ip"),
+				Eth:        field(row, "eth"),
+				Switch:     field(row, "switch"),
+				BootMode:   field(row, "bootMode"),
+				Policy:     field(row, "policy"),
+				Nics:       field(row, "nics"),
+			})
+		}
+
+	default:
+		return nil, fmt.Errorf("format must be %s or %s", HostImportCSV, HostImportYAML)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no hosts found in import data")
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].SequenceID < records[j].SequenceID
+	})
+	return records, nil
+}
+
+// normalizeImportRecord validates the record and fills in default values.
+func normalizeImportRecord(rec *hostImportRecord, policies map[string]HostPolicy) error {
+
+	if rec.HostName == "" {
+		rec.HostName = rec.Name
+	}
+	if err := checkGenericNameRules(rec.HostName); err != nil {
+		return fmt.Errorf("host %s: %v", rec.Name, err)
+	}
+
+	hwAddr, err := net.ParseMAC(rec.Mac)
+	if err != nil {
+		return fmt.Errorf("host %s: '%s' is not a valid mac address", rec.Name, rec.Mac)
+	}
+	rec.Mac = hwAddr.String()
+
+	ip := net.ParseIP(rec.IP)
+	if ip == nil {
+		return fmt.Errorf("host %s: IP address '%s' is bad or missing", rec.Name, rec.IP)
+	}
+	rec.IP = ip.String()
+
+	if rec.Eth != "" {
+		if err = checkEthRules(rec.Eth); err != nil {
+			return fmt.Errorf("host %s: %v", rec.Name, err)
+		}
+	}
+
+	if err = checkSwitchName(rec.Switch); err != nil {
+		return fmt.Errorf("host %s: %v", rec.Name, err)
+	}
+
+	if rec.BootMode == "" {
+		rec.BootMode = AllowedBootModes[0]
+	} else if !validBootMode(rec.BootMode) {
+		return fmt.Errorf("host %s: bootMode '%s' invalid; must be one of %v", rec.Name, rec.BootMode, AllowedBootModes)
+	}
+
+	if rec.nicList, err = parseHostNics(rec.Nics); err != nil {
+		return fmt.Errorf("host %s: %v", rec.Name, err)
+	}
+	rec.Nics = formatHostNics(rec.nicList)
+
+	if rec.Policy == "" {
+		rec.Policy = DefaultPolicyName
+	}
+	if _, ok := policies[rec.Policy]; !ok {
+		return fmt.Errorf("host %s: no host policy found with name %s", rec.Name, rec.Policy)
+	}
+	return nil
+}
+
+// planHostImport validates the records against each other and the existing hosts of the cluster and
+// works out what has to change. Host names, hostnames, MAC and IP addresses must be unique across
+// the cluster once the import is applied. Existing hosts not named in the import are left alone.
+func planHostImport(records []hostImportRecord, existing []Host, policies map[string]HostPolicy, clusterID int) (*hostImportPlan, error) {
+
+	existingByName := make(map[string]Host, len(existing))
+	for _, h := range existing {
+		existingByName[h.Name] = h
+	}
+
+	// owners of each unique value once the import is applied, starting with hosts the import won't touch
+	imported := make(map[string]bool, len(records))
+	for _, rec := range records {
+		if imported[rec.Name] {
+			return nil, fmt.Errorf("host %s is listed more than once", rec.Name)
+		}
+		imported[rec.Name] = true
+	}
+	owners := map[string]map[string]string{"hostname": {}, "mac": {}, "ip": {}}
+	claim := func(kind, value, host string) error {
+		if value == "" {
+			return nil
+		}
+		if other, ok := owners[kind][value]; ok && other != host {
+			return fmt.Errorf("%s %s of host %s is already used by host %s", kind, value, host, other)
+		}
+		owners[kind][value] = host
+		return nil
+	}
+	for _, h := range existing {
+		if !imported[h.Name] {
+			_ = claim("hostname", h.HostName, h.Name)
+			_ = claim("mac", h.Mac, h.Name)
+			_ = claim("ip", h.IP, h.Name)
+			for _, nic := range h.Interfaces {
+				_ = claim("mac", nic.Mac, h.Name)
+			}
+		}
+	}
+
+	plan := &hostImportPlan{}
+	for i := range records {
+		rec := &records[i]
+		if err := normalizeImportRecord(rec, policies); err != nil {
+			return nil, err
+		}
+		for kind, val := range map[string]string{"hostname": rec.HostName, "mac": rec.Mac, "ip": rec.IP} {
+			if err := claim(kind, val, rec.Name); err != nil {
+				return nil, err
+			}
+		}
+		for _, nic := range rec.nicList {
+			if nic.Mac == rec.Mac {
+				return nil, fmt.Errorf("mac %s of host %s is listed as both its primary mac and an interface", nic.Mac, rec.Name)
+			}
+			if err := claim("mac", nic.Mac, rec.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		h, exists := existingByName[rec.Name]
+		if !exists {
+			plan.creates = append(plan.creates, Host{
+				Name:         rec.Name,
+				HostName:     rec.HostName,
+				SequenceID:   rec.SequenceID,
+				Eth:          rec.Eth,
+				SwitchName:   rec.Switch,
+				Mac:          rec.Mac,
+				IP:           rec.IP,
+				BootMode:     rec.BootMode,
+				State:        HostBlocked,
+				HostPolicyID: policies[rec.Policy].ID,
+				ClusterID:    clusterID,
+				Interfaces:   rec.nicList,
+			})
+			plan.changes = append(plan.changes, common.HostImportChange{Host: rec.Name, Action: HostImportCreate})
+			continue
+		}
+
+		var diffs []string
+		fields := make(map[string]interface{})
+		diff := func(name, column, oldVal, newVal string) {
+			if oldVal != newVal {
+				diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", name, oldVal, newVal))
+				fields[column] = newVal
+			}
+		}
+		diff("hostname", "host_name", h.HostName, rec.HostName)
+		diff("mac", "mac", h.Mac, rec.Mac)
+		diff("ip", "ip", h.IP, rec.IP)
+		diff("eth", "eth", h.Eth, rec.Eth)
+		diff("switch", "switch_name", h.SwitchName, rec.Switch)
+		diff("bootMode", "boot_mode", h.BootMode, rec.BootMode)
+		if oldNics := formatHostNics(h.Interfaces); oldNics != rec.Nics {
+			diffs = append(diffs, fmt.Sprintf("nics: %s -> %s", oldNics, rec.Nics))
+			fields["Interfaces"] = rec.nicList
+		}
+		if h.HostPolicy.Name != rec.Policy {
+			diffs = append(diffs, fmt.Sprintf("policy: %s -> %s", h.HostPolicy.Name, rec.Policy))
+			fields["HostPolicy"] = policies[rec.Policy]
+		}
+
+		if len(diffs) == 0 {
+			plan.changes = append(plan.changes, common.HostImportChange{Host: rec.Name, Action: HostImportUnchanged})
+			continue
+		}
+		plan.updates = append(plan.updates, h)
+		plan.updateFields = append(plan.updateFields, fields)
+		plan.changes = append(plan.changes, common.HostImportChange{Host: rec.Name, Action: HostImportUpdate, Changes: diffs})
+	}
+
+	return plan, nil
+}
+
+// doExportHosts returns the hosts of the cluster in the given format.
+func doExportHosts(format string) (string, int, error) {
+	clusters, err := dbReadClustersTx(nil)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if len(clusters) == 0 {
+		return "", http.StatusNotFound, fmt.Errorf("no cluster has been created yet")
+	}
+	export, err := exportHosts(&clusters[0], format)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	return export, http.StatusOK, nil
+}
+
+// doImportHosts creates and updates hosts from CSV or YAML data. If preview is true the changes are
+// worked out and returned but nothing is saved. Otherwise all changes are applied in a single
+// transaction and the igor-clusters.yaml file is rewritten to match.
+func doImportHosts(format, data string, preview bool, r *http.Request) (changes []common.HostImportChange, status int, err error) {
+
+	clog := hlog.FromRequest(r)
+	status = http.StatusInternalServerError // default status, overridden at end if no errors
+	var clusterConfs []ClusterConfig
+	var applied bool
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		clusters, rcErr := dbReadClusters(nil, tx)
+		if rcErr != nil {
+			return rcErr
+		}
+		if len(clusters) == 0 {
+			status = http.StatusConflict
+			return fmt.Errorf("a cluster must be created before hosts can be imported")
+		}
+		cluster := clusters[0]
+
+		records, pErr := parseHostImport(format, data, cluster.Prefix)
+		if pErr != nil {
+			status = http.StatusBadRequest
+			return pErr
+		}
+
+		policyList, rpErr := dbReadHostPolicies(nil, tx, clog)
+		if rpErr != nil {
+			return rpErr
+		}
+		policies := make(map[string]HostPolicy, len(policyList))
+		for _, hp := range policyList {
+			policies[hp.Name] = hp
+		}
+
+		plan, planErr := planHostImport(records, cluster.Hosts, policies, cluster.ID)
+		if planErr != nil {
+			status = http.StatusBadRequest
+			return planErr
+		}
+		changes = plan.changes
+
+		if preview || (len(plan.creates) == 0 && len(plan.updates) == 0) {
+			return nil
+		}
+
+		// apply updates before creates so values released by an update can be claimed by a new host
+		for i, h := range plan.updates {
+			if editErr := dbEditHosts([]Host{h}, plan.updateFields[i], tx); editErr != nil {
+				return editErr
+			}
+		}
+		if len(plan.creates) > 0 {
+			if createErr := dbCreateHosts(plan.creates, tx); createErr != nil {
+				return createErr
+			}
+		}
+		applied = true
+
+		var yDoc []byte
+		var finalPath string
+		var cDumpErr error
+		if clusters, cDumpErr = dbReadClusters(nil, tx); cDumpErr == nil {
+			if yDoc, clusterConfs, cDumpErr = assembleYamlOutput(clusters); cDumpErr == nil {
+				finalPath, cDumpErr = updateClusterConfigFile(yDoc, clog)
+			}
+		}
+		if cDumpErr == nil {
+			clog.Info().Msgf("%s updated on host import", finalPath)
+		}
+		return cDumpErr
+
+	}); err != nil {
+		return nil, status, err
+	}
+
+	if applied {
+		for _, c := range clusterConfs {
+			c.storeClusterRanges()
+		}
+		clusterUpdateChan <- struct{}{}
+	}
+	return changes, http.StatusOK, nil
+}
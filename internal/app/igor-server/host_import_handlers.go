@@ -0,0 +1,149 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/rs/zerolog/hlog"
+)
+
+// destination for route GET /hosts-ctrl/export
+func handleExportHosts(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	actionPrefix := "export hosts"
+	rb := common.NewResponseBody()
+
+	format := HostImportCSV
+	if val, ok := r.URL.Query()["format"]; ok {
+		format = val[0]
+	}
+
+	export, status, err := doExportHosts(format)
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Data["export"] = export
+		clog.Info().Msgf("%s success", actionPrefix)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route POST /hosts-ctrl/import
+func handleImportHosts(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	importParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "import hosts"
+	rb := common.NewResponseBodyHostImport()
+
+	format := importParams["format"].(string)
+	data := importParams["data"].(string)
+	preview, _ := importParams["preview"].(bool)
+	if preview {
+		actionPrefix = "preview host import"
+	}
+
+	changes, status, err := doImportHosts(format, data, preview, r)
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Data["changes"] = changes
+		clog.Info().Msgf("%s success", actionPrefix)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+func validateHostImportParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		checkFormat := func(val interface{}) error {
+			if f, ok := val.(string); !ok {
+				return NewBadParamTypeError("format", val, "string")
+			} else if f != HostImportCSV && f != HostImportYAML {
+				return fmt.Errorf("invalid parameter: format must be %s or %s", HostImportCSV, HostImportYAML)
+			}
+			return nil
+		}
+
+		if r.Method == http.MethodGet {
+			queryParams := r.URL.Query()
+		queryParamLoop:
+			for key, vals := range queryParams {
+				switch key {
+				case "format":
+					if len(vals) > 1 {
+						validateErr = fmt.Errorf("invalid parameter: '%s' cannot have multiple values", key)
+						break queryParamLoop
+					}
+					if validateErr = checkFormat(vals[0]); validateErr != nil {
+						break queryParamLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, vals)
+					break queryParamLoop
+				}
+			}
+		}
+
+		if r.Method == http.MethodPost {
+			importParams := getBodyFromContext(r)
+			if len(importParams) > 0 {
+				_, f := importParams["format"]
+				_, d := importParams["data"]
+				if !f {
+					validateErr = fmt.Errorf("missing required format parameter")
+				} else if !d {
+					validateErr = fmt.Errorf("missing required data parameter")
+				} else {
+				postParamLoop:
+					for key, val := range importParams {
+						switch key {
+						case "format":
+							if validateErr = checkFormat(val); validateErr != nil {
+								break postParamLoop
+							}
+						case "data":
+							if _, ok := val.(string); !ok {
+								validateErr = NewBadParamTypeError(key, val, "string")
+								break postParamLoop
+							}
+						case "preview":
+							if _, ok := val.(bool); !ok {
+								validateErr = NewBadParamTypeError(key, val, "bool")
+								break postParamLoop
+							}
+						default:
+							validateErr = NewUnknownParamError(key, val)
+							break postParamLoop
+						}
+					}
+				}
+			} else {
+				validateErr = NewMissingParamError("")
+			}
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateHostImportParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHostImport(t *testing.T) {
+	csvData := "name,mac,ip,eth\nkn2,aa:bb:cc:00:00:02,10.1.0.2,eno1\nkn1,AA:BB:CC:00:00:01,10.1.0.1,\n"
+	records, err := parseHostImport(HostImportCSV, csvData, "kn")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "kn1", records[0].Name)
+	assert.Equal(t, 1, records[0].SequenceID)
+	assert.Equal(t, "eno1", records[1].Eth)
+
+	_, err = parseHostImport(HostImportCSV, "name,mac,ip,rack\nkn1,a,b,c\n", "kn")
+	assert.Error(t, err, "unknown column")
+	_, err = parseHostImport(HostImportCSV, "name,mac\nkn1,a\n", "kn")
+	assert.Error(t, err, "missing ip column")
+	_, err = parseHostImport(HostImportCSV, "name,mac,ip\nxy1,a,b\n", "kn")
+	assert.Error(t, err, "wrong prefix")
+
+	yamlData := "testCluster:\n  prefix: kn\n  hostmap:\n    3:\n      mac: aa:bb:cc:00:00:03\n      ip: 10.1.0.3\n"
+	records, err = parseHostImport(HostImportYAML, yamlData, "kn")
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "kn3", records[0].Name)
+
+	_, err = parseHostImport(HostImportYAML, yamlData, "tc")
+	assert.Error(t, err, "prefix mismatch")
+}
+
+func TestPlanHostImport(t *testing.T) {
+	policies := map[string]HostPolicy{
+		DefaultPolicyName: {Base: Base{ID: 1}, Name: DefaultPolicyName},
+		"gpu":             {Base: Base{ID: 2}, Name: "gpu"},
+	}
+	existing := []Host{
+		{Name: "kn1", HostName: "kn1", SequenceID: 1, Mac: "aa:bb:cc:00:00:01", IP: "10.1.0.1", BootMode: AllowedBootModes[0], HostPolicy: policies[DefaultPolicyName]},
+		{Name: "kn2", HostName: "kn2", SequenceID: 2, Mac: "aa:bb:cc:00:00:02", IP: "10.1.0.2", BootMode: AllowedBootModes[0], HostPolicy: policies[DefaultPolicyName]},
+	}
+
+	records := []hostImportRecord{
+		{Name: "kn1", SequenceID: 1, Mac: "AA:BB:CC:00:00:01", IP: "10.1.0.1"},
+		{Name: "kn2", SequenceID: 2, Mac: "aa:bb:cc:00:00:02", IP: "10.1.0.22", Policy: "gpu"},
+		{Name: "kn3", SequenceID: 3, Mac: "aa:bb:cc:00:00:03", IP: "10.1.0.3"},
+	}
+	plan, err := planHostImport(records, existing, policies, 1)
+	assert.NoError(t, err)
+	assert.Len(t, plan.changes, 3)
+	assert.Equal(t, HostImportUnchanged, plan.changes[0].Action)
+	assert.Equal(t, HostImportUpdate, plan.changes[1].Action)
+	assert.ElementsMatch(t, []string{"ip: 10.1.0.2 -> 10.1.0.22", "policy: " + DefaultPolicyName + " -> gpu"}, plan.changes[1].Changes)
+	assert.Equal(t, "10.1.0.22", plan.updateFields[0]["ip"])
+	assert.Equal(t, HostImportCreate, plan.changes[2].Action)
+	assert.Len(t, plan.creates, 1)
+	assert.Equal(t, HostBlocked, plan.creates[0].State)
+	assert.Equal(t, "kn3", plan.creates[0].HostName)
+
+	// new host reuses the MAC of a host not in the import
+	records = []hostImportRecord{{Name: "kn3", SequenceID: 3, Mac: "aa:bb:cc:00:00:01", IP: "10.1.0.3"}}
+	_, err = planHostImport(records, existing, policies, 1)
+	assert.Error(t, err)
+
+	// IP released by an update can be claimed by another host in the same import
+	records = []hostImportRecord{
+		{Name: "kn1", SequenceID: 1, Mac: "aa:bb:cc:00:00:01", IP: "10.1.0.11"},
+		{Name: "kn3", SequenceID: 3, Mac: "aa:bb:cc:00:00:03", IP: "10.1.0.1"},
+	}
+	_, err = planHostImport(records, existing, policies, 1)
+	assert.NoError(t, err)
+
+	// duplicate names and unknown policies are rejected
+	records = []hostImportRecord{
+		{Name: "kn3", SequenceID: 3, Mac: "aa:bb:cc:00:00:03", IP: "10.1.0.3"},
+		{Name: "kn3", SequenceID: 3, Mac: "aa:bb:cc:00:00:04", IP: "10.1.0.4"},
+	}
+	_, err = planHostImport(records, existing, policies, 1)
+	assert.Error(t, err)
+	records = []hostImportRecord{{Name: "kn3", SequenceID: 3, Mac: "aa:bb:cc:00:00:03", IP: "10.1.0.3", Policy: "nope"}}
+	_, err = planHostImport(records, existing, policies, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"igor2/internal/pkg/common"
+)
+
+const (
+	// NicRolePrimary is the role of the interface described by the Mac, Eth and SwitchName fields of a
+	// host. Every host has one and it always boots and joins the reservation vlan unless told otherwise.
+	NicRolePrimary = "primary"
+)
+
+// HostInterface is an additional network interface of a host, beyond its primary one. The role is a
+// free-form label such as "data" or "fabric" that reservations and PXE boot use to pick interfaces.
+type HostInterface struct {
+	Base
+	HostID     int    `gorm:"notNull; index"`
+	Role       string `gorm:"notNull"`
+	Mac        string `gorm:"unique; notNull"`
+	Eth        string // port on the switch the interface is connected to; blank if it isn't managed by igor
+	SwitchName string // name of the switch in vlan.switches; blank means the first one
+	Name       string // name of the interface on the host OS, if known
+}
+
+func (i *HostInterface) getHostNicData() common.HostNicData {
+	return common.HostNicData{
+		Role:   i.Role,
+		Mac:    i.Mac,
+		Eth:    i.Eth,
+		Switch: i.SwitchName,
+		Name:   i.Name,
+	}
+}
+
+// checkNicRole returns an error if the role is not a legal interface role.
+func checkNicRole(role string) error {
+	if !nicRoleCheckPattern.MatchString(role) {
+		return fmt.Errorf("'%s' is not a legal interface role", role)
+	}
+	return nil
+}
+
+// parseHostNics reads interfaces in the form used by the cluster config, host import files and the
+// host edit command: entries separated by semicolons, each a comma-separated list of key=value pairs.
+// role and mac are required; eth, switch and name are optional.
+//
+//	role=data,mac=00:11:22:33:44:55,eth=Et5,switch=leaf2;role=fabric,mac=00:11:22:33:44:66
+func parseHostNics(spec string) ([]HostInterface, error) {
+	var nics []HostInterface
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var nic HostInterface
+		for _, pair := range strings.Split(entry, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("interface entry '%s' must be a list of key=value pairs", entry)
+			}
+			key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			switch key {
+			case "role":
+				nic.Role = val
+			case "mac":
+				nic.Mac = val
+			case "eth":
+				nic.Eth = val
+			case "switch":
+				nic.SwitchName = val
+			case "name":
+				nic.Name = val
+			default:
+				return nil, fmt.Errorf("interface entry '%s' has unknown key '%s'", entry, key)
+			}
+		}
+
+		if err := checkNicRole(nic.Role); err != nil {
+			return nil, err
+		} else if nic.Role == NicRolePrimary {
+			return nil, fmt.Errorf("role '%s' is reserved for the host's own mac and eth values", NicRolePrimary)
+		}
+		hwAddr, err := net.ParseMAC(nic.Mac)
+		if err != nil {
+			return nil, fmt.Errorf("interface entry '%s' has an invalid mac address", entry)
+		}
+		nic.Mac = hwAddr.String()
+		if seen[nic.Mac] {
+			return nil, fmt.Errorf("mac address %s is listed more than once", nic.Mac)
+		}
+		seen[nic.Mac] = true
+		if nic.Eth != "" {
+			if err = checkEthRules(nic.Eth); err != nil {
+				return nil, err
+			}
+		}
+		if nic.Name != "" {
+			if err = checkEthRules(nic.Name); err != nil {
+				return nil, err
+			}
+		}
+		if err = checkSwitchName(nic.SwitchName); err != nil {
+			return nil, err
+		}
+		nics = append(nics, nic)
+	}
+	return nics, nil
+}
+
+// formatHostNics writes interfaces in the form read by parseHostNics, ordered by role then mac.
+func formatHostNics(nics []HostInterface) string {
+	sorted := append([]HostInterface{}, nics...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Role != sorted[j].Role {
+			return sorted[i].Role < sorted[j].Role
+		}
+		return sorted[i].Mac < sorted[j].Mac
+	})
+	entries := make([]string, 0, len(sorted))
+	for _, nic := range sorted {
+		entry := "role=" + nic.Role + ",mac=" + nic.Mac
+		if nic.Eth != "" {
+			entry += ",eth=" + nic.Eth
+		}
+		if nic.SwitchName != "" {
+			entry += ",switch=" + nic.SwitchName
+		}
+		if nic.Name != "" {
+			entry += ",name=" + nic.Name
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ";")
+}
+
+// nicPorts returns one entry per switch port that should follow the reservation vlan, for interfaces of
+// the hosts with one of the given roles. Each entry is a copy of its host with Eth, SwitchName and Mac
+// taken from the interface, so the switch drivers can treat every port as a host of its own.
+// Additional interfaces without a switch port are skipped.
+func nicPorts(hosts []Host, roles []string) []Host {
+	wanted := make(map[string]bool, len(roles))
+	for _, r := range roles {
+		wanted[r] = true
+	}
+	var ports []Host
+	for _, h := range hosts {
+		if wanted[NicRolePrimary] {
+			ports = append(ports, h)
+		}
+		for _, nic := range h.Interfaces {
+			if !wanted[nic.Role] || nic.Eth == "" {
+				continue
+			}
+			p := h
+			p.Eth = nic.Eth
+			p.SwitchName = nic.SwitchName
+			p.Mac = nic.Mac
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// hostNicRoles returns every interface role found on the hosts, including the primary role.
+func hostNicRoles(hosts []Host) map[string]bool {
+	roles := map[string]bool{NicRolePrimary: true}
+	for _, h := range hosts {
+		for _, nic := range h.Interfaces {
+			roles[nic.Role] = true
+		}
+	}
+	return roles
+}
+
+// defaultNicRoles returns the interface roles that join a reservation vlan when the reservation doesn't
+// ask for specific ones.
+func defaultNicRoles() []string {
+	roles := []string{NicRolePrimary}
+	for _, r := range igor.Vlan.NicRoles {
+		if r != NicRolePrimary {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// bootMac returns the mac address the host PXE boots from. This is the first interface with the role
+// set in server.bootNicRole, or the host's primary mac if it has no such interface.
+func (h *Host) bootMac() string {
+	role := igor.Server.BootNicRole
+	if role == "" || role == NicRolePrimary {
+		return h.Mac
+	}
+	for _, nic := range h.Interfaces {
+		if nic.Role == role {
+			return nic.Mac
+		}
+	}
+	return h.Mac
+}
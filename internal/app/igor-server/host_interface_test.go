@@ -0,0 +1,90 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHostNics(t *testing.T) {
+	nics, err := parseHostNics(" role=fabric,mac=AA:BB:CC:00:00:02,eth=Et1/1,name=ib0 ; role=data,mac=aa:bb:cc:00:00:01,eth=Et5;")
+	assert.NoError(t, err)
+	assert.Len(t, nics, 2)
+	assert.Equal(t, "aa:bb:cc:00:00:02", nics[0].Mac)
+	assert.Equal(t, "ib0", nics[0].Name)
+
+	// formatting is ordered so the same interfaces always produce the same string
+	assert.Equal(t, "role=data,mac=aa:bb:cc:00:00:01,eth=Et5;role=fabric,mac=aa:bb:cc:00:00:02,eth=Et1/1,name=ib0", formatHostNics(nics))
+	again, err := parseHostNics(formatHostNics(nics))
+	assert.NoError(t, err)
+	assert.Equal(t, formatHostNics(nics), formatHostNics(again))
+
+	nics, err = parseHostNics("")
+	assert.NoError(t, err)
+	assert.Empty(t, nics)
+
+	for _, bad := range []string{
+		"mac=aa:bb:cc:00:00:01",
+		"role=data",
+		"role=data,mac=nope",
+		"role=primary,mac=aa:bb:cc:00:00:01",
+		"role=Data,mac=aa:bb:cc:00:00:01",
+		"role=data,mac=aa:bb:cc:00:00:01,speed=10",
+		"role=data,mac=aa:bb:cc:00:00:01;role=fabric,mac=AA:BB:CC:00:00:01",
+		"role=data,mac=aa:bb:cc:00:00:01,eth=E",
+	} {
+		_, err = parseHostNics(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNicPorts(t *testing.T) {
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Mac: "aa:00:00:00:00:01", Interfaces: []HostInterface{
+			{Role: "data", Mac: "aa:00:00:00:01:01", Eth: "Et11", SwitchName: "leaf2"},
+			{Role: "fabric", Mac: "aa:00:00:00:02:01", Eth: "Et21"},
+			{Role: "bmc", Mac: "aa:00:00:00:03:01"},
+		}},
+		{Name: "kn2", Eth: "Et2", Mac: "aa:00:00:00:00:02"},
+	}
+
+	ports := nicPorts(hosts, []string{NicRolePrimary})
+	assert.Equal(t, []string{"Et1", "Et2"}, []string{ports[0].Eth, ports[1].Eth})
+
+	ports = nicPorts(hosts, []string{NicRolePrimary, "data", "bmc"})
+	assert.Len(t, ports, 3)
+	assert.Equal(t, "kn1", ports[1].Name)
+	assert.Equal(t, "Et11", ports[1].Eth)
+	assert.Equal(t, "leaf2", ports[1].SwitchName)
+	assert.Equal(t, "aa:00:00:00:01:01", ports[1].Mac)
+
+	ports = nicPorts(hosts, []string{"fabric"})
+	assert.Len(t, ports, 1)
+	assert.Equal(t, "Et21", ports[0].Eth)
+
+	roles := hostNicRoles(hosts)
+	assert.True(t, roles[NicRolePrimary] && roles["data"] && roles["fabric"] && roles["bmc"])
+
+	res := &Reservation{}
+	assert.Equal(t, []string{NicRolePrimary}, res.nicRoles())
+	res.NicRoles = "data,fabric"
+	assert.False(t, res.hasNicRole(NicRolePrimary))
+	assert.Len(t, res.vlanPorts(hosts), 2)
+}
+
+func TestBootMac(t *testing.T) {
+	saved := igor.Server.BootNicRole
+	defer func() { igor.Server.BootNicRole = saved }()
+
+	h := Host{Mac: "aa:00:00:00:00:01", Interfaces: []HostInterface{{Role: "mgmt", Mac: "aa:00:00:00:01:01"}}}
+	igor.Server.BootNicRole = ""
+	assert.Equal(t, "aa:00:00:00:00:01", h.bootMac())
+	igor.Server.BootNicRole = "mgmt"
+	assert.Equal(t, "aa:00:00:00:01:01", h.bootMac())
+	igor.Server.BootNicRole = "data"
+	assert.Equal(t, "aa:00:00:00:00:01", h.bootMac())
+}
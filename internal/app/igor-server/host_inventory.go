@@ -0,0 +1,171 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"igor2/internal/pkg/common"
+)
+
+// HostAttribute is a hardware fact reported by a host through the inventory callback. Every report
+// replaces all previously stored attributes of the host.
+type HostAttribute struct {
+	Base
+	HostID   int    `gorm:"notNull; uniqueIndex:idx_host_attr"`
+	Key      string `gorm:"notNull; uniqueIndex:idx_host_attr"`
+	Value    string
+	Mismatch string // description of how the fact differs from the Host record, blank if it matches
+}
+
+func (a *HostAttribute) getHostAttributeData() common.HostAttributeData {
+	return common.HostAttributeData{
+		Key:      a.Key,
+		Value:    a.Value,
+		Mismatch: a.Mismatch,
+		Reported: a.CreatedAt,
+	}
+}
+
+// normalizeMac returns the MAC address in lower-case colon-separated form, or the trimmed
+// lower-case input if it can't be parsed.
+func normalizeMac(mac string) string {
+	if hw, err := net.ParseMAC(strings.TrimSpace(mac)); err == nil {
+		return hw.String()
+	}
+	return strings.ToLower(strings.TrimSpace(mac))
+}
+
+// buildHostAttributes flattens a hardware inventory report into host attributes and checks the
+// reported NICs against the MAC address and interface name igor has on record for the host.
+func buildHostAttributes(host *Host, inv *common.HostInventoryData, now time.Time) []HostAttribute {
+
+	attrs := make(map[string]*HostAttribute)
+	add := func(key, value string) *HostAttribute {
+		a := &HostAttribute{Base: Base{CreatedAt: now}, HostID: host.ID, Key: key, Value: value}
+		attrs[key] = a
+		return a
+	}
+
+	if inv.CPU.Model != "" {
+		add("cpu.model", inv.CPU.Model)
+	}
+	if inv.CPU.Sockets > 0 {
+		add("cpu.sockets", strconv.Itoa(inv.CPU.Sockets))
+	}
+	if inv.CPU.Cores > 0 {
+		add("cpu.cores", strconv.Itoa(inv.CPU.Cores))
+	}
+	if inv.CPU.Threads > 0 {
+		add("cpu.threads", strconv.Itoa(inv.CPU.Threads))
+	}
+	if inv.MemoryMB > 0 {
+		add("memory.mb", strconv.Itoa(inv.MemoryMB))
+	}
+
+	for _, d := range inv.Disks {
+		if d.Name == "" {
+			continue
+		}
+		value := strconv.Itoa(d.SizeGB) + "GB"
+		if d.Model != "" {
+			value += " " + d.Model
+		}
+		add("disk."+d.Name, value)
+	}
+
+	// the primary interface is named by Eth; additional interfaces by their OS name, if known
+	type expectedNic struct {
+		key, mac, name string
+		found          bool
+	}
+	expectedNics := []*expectedNic{{key: "nic.expected", mac: host.Mac, name: host.Eth}}
+	for _, nic := range host.Interfaces {
+		expectedNics = append(expectedNics, &expectedNic{key: "nic.expected." + nic.Role, mac: nic.Mac, name: nic.Name})
+	}
+	for _, n := range inv.NICs {
+		if n.Name == "" {
+			continue
+		}
+		mac := normalizeMac(n.Mac)
+		value := mac
+		if n.SpeedMbps > 0 {
+			value += " " + strconv.Itoa(n.SpeedMbps) + "Mb/s"
+		}
+		a := add("nic."+n.Name, value)
+		for _, e := range expectedNics {
+			if mac == normalizeMac(e.mac) {
+				e.found = true
+				if e.name != "" && n.Name != e.name {
+					a.Mismatch = fmt.Sprintf("expected MAC %s on interface %s", e.mac, e.name)
+				}
+				break
+			} else if e.name != "" && n.Name == e.name {
+				a.Mismatch = fmt.Sprintf("expected MAC %s", e.mac)
+			}
+		}
+	}
+	for _, e := range expectedNics {
+		if !e.found {
+			a := add(e.key, e.mac)
+			a.Mismatch = "MAC address on record was not reported by host"
+		}
+	}
+
+	for k, v := range inv.Firmware {
+		if k != "" {
+			add("firmware."+k, v)
+		}
+	}
+
+	result := make([]HostAttribute, 0, len(attrs))
+	for _, a := range attrs {
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// attributeMismatches returns the descriptions of any attributes that don't match the Host record.
+func attributeMismatches(attrs []HostAttribute) []string {
+	var mismatches []string
+	for _, a := range attrs {
+		if a.Mismatch != "" {
+			mismatches = append(mismatches, a.Key+": "+a.Mismatch)
+		}
+	}
+	return mismatches
+}
+
+// addHostAttributes fills in the stored attributes of each host in hostDetails.
+func addHostAttributes(hosts []Host, hostDetails []common.HostData) error {
+
+	attrs, err := dbReadHostAttributesTx(hostIDsOfHosts(hosts))
+	if err != nil {
+		return err
+	}
+
+	hostNameByID := make(map[int]string, len(hosts))
+	for _, h := range hosts {
+		hostNameByID[h.ID] = h.Name
+	}
+	attrsByHost := make(map[string][]common.HostAttributeData)
+	for _, a := range attrs {
+		name := hostNameByID[a.HostID]
+		attrsByHost[name] = append(attrsByHost[name], a.getHostAttributeData())
+	}
+
+	for i := range hostDetails {
+		hostDetails[i].Attributes = attrsByHost[hostDetails[i].Name]
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+)
+
+// dbReplaceHostAttributes removes all stored attributes of the host and saves the given ones in their place.
+func dbReplaceHostAttributes(hostID int, attrs []HostAttribute, tx *gorm.DB) error {
+	if err := dbDeleteHostAttributes([]int{hostID}, tx); err != nil {
+		return err
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	result := tx.Create(&attrs)
+	return result.Error
+}
+
+// dbReadHostAttributesTx performs dbReadHostAttributes within a new transaction.
+func dbReadHostAttributesTx(hostIDs []int) (attrs []HostAttribute, err error) {
+	err = performDbTx(func(tx *gorm.DB) error {
+		attrs, err = dbReadHostAttributes(hostIDs, tx)
+		return err
+	})
+	return attrs, err
+}
+
+// dbReadHostAttributes returns the attributes of the given hosts ordered by key.
+func dbReadHostAttributes(hostIDs []int, tx *gorm.DB) (attrs []HostAttribute, err error) {
+	result := tx.Where("host_id IN ?", hostIDs).Order("host_id, `key`").Find(&attrs)
+	return attrs, result.Error
+}
+
+// dbDeleteHostAttributes permanently removes all attributes of the given hosts.
+func dbDeleteHostAttributes(hostIDs []int, tx *gorm.DB) error {
+	if len(hostIDs) == 0 {
+		return nil
+	}
+	result := tx.Where("host_id IN ?", hostIDs).Delete(&HostAttribute{})
+	return result.Error
+}
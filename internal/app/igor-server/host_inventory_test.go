@@ -0,0 +1,61 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildHostAttributes(t *testing.T) {
+	now := time.Now()
+	host := &Host{Base: Base{ID: 7}, Name: "kn1", Eth: "eno1", Mac: "AA:BB:CC:00:11:22"}
+	inv := &common.HostInventoryData{
+		CPU:      common.CPUInventory{Model: "Xeon Gold", Sockets: 2, Cores: 32},
+		MemoryMB: 262144,
+		Disks:    []common.DiskInventory{{Name: "sda", Model: "SSD", SizeGB: 480}},
+		NICs: []common.NICInventory{
+			{Name: "eno1", Mac: "aa:bb:cc:00:11:22", SpeedMbps: 10000},
+			{Name: "eno2", Mac: "aa:bb:cc:00:11:23"},
+		},
+		Firmware: map[string]string{"bios": "2.1.4"},
+	}
+
+	attrs := buildHostAttributes(host, inv, now)
+	values := make(map[string]string)
+	for _, a := range attrs {
+		assert.Equal(t, 7, a.HostID)
+		assert.Equal(t, now, a.CreatedAt)
+		values[a.Key] = a.Value
+	}
+	assert.Equal(t, "Xeon Gold", values["cpu.model"])
+	assert.Equal(t, "2", values["cpu.sockets"])
+	assert.Equal(t, "262144", values["memory.mb"])
+	assert.Equal(t, "480GB SSD", values["disk.sda"])
+	assert.Equal(t, "aa:bb:cc:00:11:22 10000Mb/s", values["nic.eno1"])
+	assert.Equal(t, "2.1.4", values["firmware.bios"])
+	assert.Empty(t, attributeMismatches(attrs))
+
+	// MAC on record reported on the wrong interface
+	inv.NICs[0].Mac = "aa:bb:cc:00:11:99"
+	inv.NICs[1].Mac = "aa:bb:cc:00:11:22"
+	mismatches := attributeMismatches(buildHostAttributes(host, inv, now))
+	assert.Equal(t, []string{
+		"nic.eno1: expected MAC AA:BB:CC:00:11:22",
+		"nic.eno2: expected MAC AA:BB:CC:00:11:22 on interface eno1",
+	}, mismatches)
+
+	// MAC on record not reported at all
+	inv.NICs = inv.NICs[:1]
+	mismatches = attributeMismatches(buildHostAttributes(host, inv, now))
+	assert.Equal(t, []string{
+		"nic.eno1: expected MAC AA:BB:CC:00:11:22",
+		"nic.expected: MAC address on record was not reported by host",
+	}, mismatches)
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+)
+
+// lifecycleTargetStates are the states an admin can move a host into with a lifecycle transition.
+var lifecycleTargetStates = []HostState{HostAvailable, HostRepair, HostBurnIn, HostDecommissioned}
+
+// hostStateChange holds the parameters of an admin lifecycle transition.
+type hostStateChange struct {
+	target       HostState
+	reason       string
+	ticket       string
+	returnDate   *time.Time
+	hostNameList []string
+}
+
+// checkStateParams maps the state change parameters to a target state and list of hosts.
+func checkStateParams(stateParams map[string]interface{}) (*hostStateChange, int, error) {
+
+	change := &hostStateChange{
+		target: resolveHostState(stateParams["state"].(string)),
+		reason: strings.TrimSpace(stateParams["reason"].(string)),
+	}
+
+	valid := false
+	for _, s := range lifecycleTargetStates {
+		if change.target == s {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, http.StatusBadRequest, fmt.Errorf("hosts can only be moved to states %s, %s, %s or %s",
+			HostAvailable, HostRepair, HostBurnIn, HostDecommissioned)
+	}
+	if change.reason == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("a reason is required to change host state")
+	}
+
+	if ticket, ok := stateParams["ticket"].(string); ok {
+		change.ticket = strings.TrimSpace(ticket)
+	}
+	if ret, ok := stateParams["returnDate"].(float64); ok {
+		rt := time.Unix(int64(ret), 0)
+		change.returnDate = &rt
+	}
+	if change.target == HostRepair {
+		if change.ticket == "" {
+			return nil, http.StatusBadRequest, fmt.Errorf("a ticket reference is required to move hosts to %s", HostRepair)
+		}
+		if change.returnDate != nil && change.returnDate.Before(time.Now()) {
+			return nil, http.StatusBadRequest, fmt.Errorf("expected return date must be in the future")
+		}
+	} else if change.ticket != "" || change.returnDate != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("ticket and return date only apply to the %s state", HostRepair)
+	}
+
+	val := stateParams["hosts"].(string)
+	change.hostNameList = igor.splitRange(val)
+	if len(change.hostNameList) == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("can't parse hosts - %v", val)
+	}
+	sort.Strings(change.hostNameList)
+
+	return change, http.StatusOK, nil
+}
+
+// checkStateTransition returns an error if the host can't be moved to the target state.
+//
+// Hosts with current or future reservations can't be moved to repair or decommissioned. Hosts in an
+// active reservation, in maintenance or with a current or future reservation owned by anyone but an
+// admin can't be moved to burn-in since only admins may use burn-in hosts. The reservations must have
+// their owners loaded for this. Only hosts in repair, burn-in, decommissioned or error can be made
+// available this way; blocked hosts must be unblocked.
+func checkStateTransition(h *Host, target HostState) error {
+
+	if h.State == target {
+		return fmt.Errorf("host '%s' is already in state %s", h.Name, target)
+	}
+
+	switch target {
+	case HostRepair, HostDecommissioned:
+		if len(h.Reservations) > 0 {
+			return fmt.Errorf("host '%s' has current or future reservations; they must be removed before it can be moved to %s", h.Name, target)
+		}
+		if len(h.MaintenanceRes) > 0 {
+			return fmt.Errorf("host '%s' is in maintenance mode; try again once maintenance is finished", h.Name)
+		}
+	case HostBurnIn:
+		if h.State == HostReserved {
+			return fmt.Errorf("host '%s' is running an active reservation", h.Name)
+		}
+		if len(h.MaintenanceRes) > 0 {
+			return fmt.Errorf("host '%s' is in maintenance mode; try again once maintenance is finished", h.Name)
+		}
+		for _, r := range h.Reservations {
+			if r.Owner.Name != IgorAdmin && !groupSliceContains(r.Owner.Groups, GroupAdmins) {
+				return fmt.Errorf("host '%s' has reservation '%s' owned by non-admin %s; it must be removed before the host can be moved to %s",
+					h.Name, r.Name, r.Owner.Name, target)
+			}
+		}
+	case HostAvailable:
+		switch h.State {
+		case HostRepair, HostBurnIn, HostDecommissioned, HostError:
+		case HostBlocked:
+			return fmt.Errorf("host '%s' is blocked; use unblock to make it available", h.Name)
+		default:
+			return fmt.Errorf("host '%s' cannot be made available from state %s", h.Name, h.State)
+		}
+	}
+	return nil
+}
+
+// doUpdateHostState moves the hosts into the requested lifecycle state. All hosts must be able to make
+// the transition or none of them are changed.
+func doUpdateHostState(change *hostStateChange, r *http.Request) (status int, err error) {
+
+	clog := hlog.FromRequest(r)
+	status = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		hList, ghStatus, ghErr := getHosts(change.hostNameList, true, tx)
+		if ghErr != nil {
+			status = ghStatus
+			return ghErr
+		}
+
+		var toState, toReserved []Host
+		for i := range hList {
+			if change.target == HostBurnIn && len(hList[i].Reservations) > 0 {
+				// the host's reservations are loaded without their owners
+				ids := make([]int, 0, len(hList[i].Reservations))
+				for _, r := range hList[i].Reservations {
+					ids = append(ids, r.ID)
+				}
+				resList, rErr := dbReadReservations(map[string]interface{}{"id": ids}, nil, tx)
+				if rErr != nil {
+					return rErr
+				}
+				hList[i].Reservations = resList
+			}
+			if tErr := checkStateTransition(&hList[i], change.target); tErr != nil {
+				status = http.StatusConflict
+				return tErr
+			}
+			// a burn-in host finishing while an admin reservation is running goes back to reserved
+			if change.target == HostAvailable && getActiveReservation(&hList[i]) != nil {
+				toReserved = append(toReserved, hList[i])
+			} else {
+				toState = append(toState, hList[i])
+			}
+		}
+
+		changes := map[string]interface{}{
+			"State":        change.target,
+			"StateReason":  change.reason,
+			"RepairTicket": change.ticket,
+			"RepairReturn": change.returnDate,
+		}
+		if change.target == HostAvailable {
+			changes["StateReason"] = ""
+		}
+		if len(toState) > 0 {
+			if editErr := dbEditHosts(toState, changes, tx); editErr != nil {
+				return editErr
+			}
+		}
+		if len(toReserved) > 0 {
+			changes["State"] = HostReserved
+			if editErr := dbEditHosts(toReserved, changes, tx); editErr != nil {
+				return editErr
+			}
+		}
+		return nil
+
+	}); err == nil {
+		clog.Info().Msgf("hosts %s moved to state %s - reason: %s", strings.Join(change.hostNameList, ","), change.target, change.reason)
+		status = http.StatusOK
+	}
+	return
+}
+
+// excludeBurnInHosts returns the hosts that are not in burn-in. Hosts in burn-in keep their state
+// while running an admin reservation so they return to burn-in when the reservation ends.
+func excludeBurnInHosts(hosts []Host) []Host {
+	var result []Host
+	for _, h := range hosts {
+		if h.State != HostBurnIn {
+			result = append(result, h)
+		}
+	}
+	return result
+}
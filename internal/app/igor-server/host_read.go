@@ -147,6 +147,8 @@ func parseHostSearchParams(queryMap map[string][]string, r *http.Request) (map[s
 			} else {
 				queryParams["reservations"] = resIDsOfResList(resList)
 			}
+		case "detail":
+			// not a search parameter; determines whether host attributes are included in the response
 		default:
 			clog.Warn().Msgf("unrecognized search parameter '%s' with args '%v'", key, val)
 		}
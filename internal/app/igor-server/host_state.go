@@ -7,24 +7,30 @@ package igorserver
 import "strconv"
 
 const (
-	HostAvailable = HostState(iota) // host is available to accept reservations
-	HostReserved                    // host is running under a current reservation
-	HostBlocked                     // host is blocked from being reserved (present and future)
-	HostError                       // host state is in an error condition
-	HostInvalid                     // placeholder for failed validation of State field (not put into DB)
+	HostAvailable      = HostState(iota) // host is available to accept reservations
+	HostReserved                         // host is running under a current reservation
+	HostBlocked                          // host is blocked from being reserved (present and future)
+	HostError                            // host state is in an error condition
+	HostRepair                           // host is out for repair, tracked by a ticket reference
+	HostBurnIn                           // host is being burned in; only admins may reserve it
+	HostDecommissioned                   // host is retired but kept for history; never scheduled
+	HostInvalid                          // placeholder for failed validation of State field (not put into DB)
 )
 
 // HostState is an enum value describing a node's current availability for reservation assignment.
 // Hosts accepting reservations would be any HostState == 0.
 //
-//	0 = available   ; can be reserved, no active reservation
-//	1 = reserved    ; running an active reservation
-//	2 = blocked     ; admins have removed this node from the reservable pool
-//	3 = error       ; node unresponsive, needs admin attention
+//	0 = available      ; can be reserved, no active reservation
+//	1 = reserved       ; running an active reservation
+//	2 = blocked        ; admins have removed this node from the reservable pool
+//	3 = error          ; node unresponsive, needs admin attention
+//	4 = repair         ; node is out for repair
+//	5 = burn-in        ; node is being burned in, can only be reserved by admins
+//	6 = decommissioned ; node is retired and kept only for its history
 type HostState int
 
 func (s HostState) String() string {
-	names := []string{"available", "reserved", "blocked", "error", "invalid"}
+	names := []string{"available", "reserved", "blocked", "error", "repair", "burn-in", "decommissioned", "invalid"}
 	i := int(s)
 	switch {
 	case i <= int(HostInvalid):
@@ -36,7 +42,7 @@ func (s HostState) String() string {
 
 // resolveHostState maps the status string to its HostState (int) equivalent.
 func resolveHostState(str string) HostState {
-	names := []string{"available", "reserved", "blocked", "error"}
+	names := []string{"available", "reserved", "blocked", "error", "repair", "burn-in", "decommissioned"}
 	for i, name := range names {
 		if str == name {
 			return HostState(i)
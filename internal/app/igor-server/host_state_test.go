@@ -24,9 +24,43 @@ func TestGetHostState(t *testing.T) {
 	assert.NotEqual(t, HostError, badState, "")
 	assert.Equal(t, HostInvalid, badState, "")
 
+	assert.Equal(t, HostRepair, resolveHostState("repair"))
+	assert.Equal(t, HostBurnIn, resolveHostState("burn-in"))
+	assert.Equal(t, HostDecommissioned, resolveHostState("decommissioned"))
 }
 
 func TestHostState_String(t *testing.T) {
 	assert.Equal(t, HostAvailable.String(), "available")
+	assert.Equal(t, HostBurnIn.String(), "burn-in")
 	assert.Equal(t, HostInvalid.String(), "invalid")
 }
+
+func TestCheckStateTransition(t *testing.T) {
+
+	h := &Host{Name: "kn1", State: HostAvailable}
+	assert.NoError(t, checkStateTransition(h, HostRepair))
+	assert.NoError(t, checkStateTransition(h, HostBurnIn))
+	assert.NoError(t, checkStateTransition(h, HostDecommissioned))
+	assert.Error(t, checkStateTransition(h, HostAvailable))
+
+	h.Reservations = []Reservation{{Name: "future", Owner: User{Name: IgorAdmin}}}
+	assert.Error(t, checkStateTransition(h, HostRepair))
+	assert.Error(t, checkStateTransition(h, HostDecommissioned))
+	assert.NoError(t, checkStateTransition(h, HostBurnIn))
+
+	// only admins may use burn-in hosts, so reservations of other users must be removed first
+	h.Reservations = append(h.Reservations, Reservation{Name: "alice-future", Owner: User{Name: "alice"}})
+	assert.Error(t, checkStateTransition(h, HostBurnIn))
+	h.Reservations[1].Owner.Groups = []Group{{Name: GroupAdmins}}
+	assert.NoError(t, checkStateTransition(h, HostBurnIn))
+
+	h.State = HostReserved
+	assert.Error(t, checkStateTransition(h, HostBurnIn))
+
+	h = &Host{Name: "kn2", State: HostBlocked}
+	assert.Error(t, checkStateTransition(h, HostAvailable))
+	h.State = HostError
+	assert.NoError(t, checkStateTransition(h, HostAvailable))
+	h.State = HostDecommissioned
+	assert.NoError(t, checkStateTransition(h, HostAvailable))
+}
@@ -1,5 +1,7 @@
 package igorserver
 
+import "strconv"
+
 const (
 	HostStatusUnknown = HostStatus(iota)
 	HostStatusOff
@@ -21,3 +23,14 @@ const (
 //
 //		 The pingable(3) status is generally an indicator that the node is visible on the network but something is preventing TCP connections. Since ping is only invoked if TCP connections fail, this status is generally regarded as a warning state that the node has a bad configuration or there may be an external issue like a misconfigured firewall setting.
 type HostStatus int
+
+func (s HostStatus) String() string {
+	names := []string{"unknown", "off", "on", "ping", "up"}
+	i := int(s)
+	switch {
+	case i >= 0 && i <= int(HostStatusUp):
+		return names[i]
+	default:
+		return strconv.Itoa(i)
+	}
+}
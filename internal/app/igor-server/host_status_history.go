@@ -0,0 +1,224 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// recentTransitions holds the times of status changes for each host that fall within the flap window
+	recentTransitions = make(map[string][]time.Time)
+	// flappingHosts is the set of hosts currently considered to be flapping
+	flappingHosts   = make(map[string]bool)
+	flapDetectionMU sync.Mutex
+)
+
+// HostStatusRecord is a persisted change in a host's HostStatus as seen by the host probe manager.
+type HostStatusRecord struct {
+	Base
+	HostName   string `gorm:"notNull;index"`
+	PrevStatus string
+	Status     string `gorm:"notNull"`
+}
+
+// diffHostStatus compares two snapshots of hostStatusMap and returns a record for each host whose
+// status has changed. Hosts that are missing from prev are not considered to have changed.
+func diffHostStatus(prev, curr map[string]HostStatus, now time.Time) []HostStatusRecord {
+	var records []HostStatusRecord
+	for h, status := range curr {
+		if prevStatus, ok := prev[h]; ok && prevStatus != status {
+			records = append(records, HostStatusRecord{
+				Base:       Base{CreatedAt: now},
+				HostName:   h,
+				PrevStatus: prevStatus.String(),
+				Status:     status.String(),
+			})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].HostName < records[j].HostName
+	})
+	return records
+}
+
+// snapshotHostStatus returns a copy of hostStatusMap.
+func snapshotHostStatus() map[string]HostStatus {
+	hostStatusMapMU.Lock()
+	defer hostStatusMapMU.Unlock()
+	snapshot := make(map[string]HostStatus, len(hostStatusMap))
+	for k, v := range hostStatusMap {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// recordStatusTransitions saves the given status changes and checks the affected hosts for flapping.
+func recordStatusTransitions(records []HostStatusRecord, now time.Time) {
+
+	if len(records) == 0 {
+		return
+	}
+
+	dbAccess.Lock()
+	err := performDbTx(func(tx *gorm.DB) error {
+		if cErr := dbCreateHostStatusRecords(records, tx); cErr != nil {
+			return cErr
+		}
+		return dbPruneHostStatusRecords(now.AddDate(0, 0, -igor.StatusHistory.HistoryDays), tx)
+	})
+	dbAccess.Unlock()
+	if err != nil {
+		logger.Error().Msgf("problem saving host status transitions: %v", err)
+	}
+
+	window := time.Duration(igor.StatusHistory.FlapWindow) * time.Minute
+	newlyFlapping := detectFlapping(records, now, window, igor.StatusHistory.FlapThreshold)
+	if len(newlyFlapping) == 0 {
+		return
+	}
+
+	logger.Warn().Msgf("hosts are flapping (%d or more status changes in %d minutes): %s",
+		igor.StatusHistory.FlapThreshold, igor.StatusHistory.FlapWindow, strings.Join(newlyFlapping, ","))
+
+	if igor.StatusHistory.NotifyAdmins {
+		failInfo := make(map[string][]string, len(newlyFlapping))
+		for _, h := range newlyFlapping {
+			failInfo[h] = []string{"status flapping"}
+		}
+		if hostEvent := makeHostNotifyEvent(EmailHostFlapping, "", nil, failInfo); hostEvent != nil {
+			hostNotifyChan <- *hostEvent
+		}
+	}
+}
+
+// detectFlapping adds the given status changes to the recent transition list of each host and drops
+// any that fall outside the window. It returns hosts that have just reached threshold changes within
+// the window. A host stops being considered flapping once its count falls below threshold.
+func detectFlapping(records []HostStatusRecord, now time.Time, window time.Duration, threshold int) []string {
+
+	flapDetectionMU.Lock()
+	defer flapDetectionMU.Unlock()
+
+	for _, r := range records {
+		recentTransitions[r.HostName] = append(recentTransitions[r.HostName], r.CreatedAt)
+	}
+
+	var newlyFlapping []string
+	cutoff := now.Add(-window)
+	for h, times := range recentTransitions {
+		kept := times[:0]
+		for _, t := range times {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(recentTransitions, h)
+			delete(flappingHosts, h)
+			continue
+		}
+		recentTransitions[h] = kept
+
+		if len(kept) >= threshold {
+			if !flappingHosts[h] {
+				flappingHosts[h] = true
+				newlyFlapping = append(newlyFlapping, h)
+			}
+		} else {
+			delete(flappingHosts, h)
+		}
+	}
+	sort.Strings(newlyFlapping)
+	return newlyFlapping
+}
+
+// isHostFlapping returns true if the host is currently considered to be flapping.
+func isHostFlapping(hostName string) bool {
+	flapDetectionMU.Lock()
+	defer flapDetectionMU.Unlock()
+	return flappingHosts[hostName]
+}
+
+// getHostReliability summarizes the status history of each host over the given time window. Time
+// before a host's first recorded status in the window is not counted toward its up percentage.
+func getHostReliability(records []HostStatusRecord, start, end time.Time, window time.Duration, threshold int) []common.HostReliability {
+
+	byHost := make(map[string][]HostStatusRecord)
+	for _, r := range records {
+		byHost[r.HostName] = append(byHost[r.HostName], r)
+	}
+
+	result := make([]common.HostReliability, 0, len(byHost))
+	for h, recs := range byHost {
+		sort.Slice(recs, func(i, j int) bool {
+			return recs[i].CreatedAt.Before(recs[j].CreatedAt)
+		})
+
+		hr := common.HostReliability{Host: h}
+		var upTime, tracked time.Duration
+		for i, r := range recs {
+			if r.CreatedAt.Before(start) {
+				continue
+			}
+			hr.Transitions++
+			if r.PrevStatus == HostStatusUp.String() {
+				hr.Outages++
+			}
+
+			// count how many changes happened within window of this one to find flapping periods
+			n := 1
+			for _, next := range recs[i+1:] {
+				if next.CreatedAt.Sub(r.CreatedAt) > window {
+					break
+				}
+				n++
+			}
+			if n >= threshold {
+				hr.Flapping = true
+			}
+
+			spanEnd := end
+			if i+1 < len(recs) {
+				spanEnd = recs[i+1].CreatedAt
+			}
+			span := spanEnd.Sub(r.CreatedAt)
+			tracked += span
+			if r.Status == HostStatusUp.String() {
+				upTime += span
+			}
+		}
+		if hr.Transitions == 0 {
+			continue
+		}
+		if tracked > 0 {
+			hr.UpPercent = float64(upTime) / float64(tracked) * 100
+		}
+		result = append(result, hr)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Outages != result[j].Outages {
+			return result[i].Outages > result[j].Outages
+		}
+		return result[i].Host < result[j].Host
+	})
+	return result
+}
+
+func (r *HostStatusRecord) getStatusChangeData() common.HostStatusChange {
+	return common.HostStatusChange{
+		Time:       r.CreatedAt,
+		PrevStatus: r.PrevStatus,
+		Status:     r.Status,
+	}
+}
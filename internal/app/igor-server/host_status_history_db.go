@@ -0,0 +1,50 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dbCreateHostStatusRecords saves a batch of host status transitions.
+func dbCreateHostStatusRecords(records []HostStatusRecord, tx *gorm.DB) error {
+	if len(records) == 0 {
+		return nil
+	}
+	result := tx.Create(&records)
+	return result.Error
+}
+
+// dbReadHostStatusRecordsTx performs dbReadHostStatusRecords within a new transaction.
+func dbReadHostStatusRecordsTx(hostNames []string, start, end time.Time, limit int) (records []HostStatusRecord, err error) {
+	err = performDbTx(func(tx *gorm.DB) error {
+		records, err = dbReadHostStatusRecords(hostNames, start, end, limit, tx)
+		return err
+	})
+	return records, err
+}
+
+// dbReadHostStatusRecords returns the status transitions recorded between start and end, newest
+// first. If hostNames is empty, transitions for all hosts are returned. If limit is greater
+// than 0 no more than limit records are returned.
+func dbReadHostStatusRecords(hostNames []string, start, end time.Time, limit int, tx *gorm.DB) (records []HostStatusRecord, err error) {
+	if len(hostNames) > 0 {
+		tx = tx.Where("host_name IN ?", hostNames)
+	}
+	tx = tx.Where("created_at >= ? AND created_at <= ?", start, end).Order("created_at desc")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	result := tx.Find(&records)
+	return records, result.Error
+}
+
+// dbPruneHostStatusRecords permanently removes status transitions older than cutoff.
+func dbPruneHostStatusRecords(cutoff time.Time, tx *gorm.DB) error {
+	result := tx.Where("created_at < ?", cutoff).Delete(&HostStatusRecord{})
+	return result.Error
+}
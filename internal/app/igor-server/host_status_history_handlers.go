@@ -0,0 +1,125 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+)
+
+// destination for route GET /hosts/:hostName/status-history
+func handleReadHostStatusHistory(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	actionPrefix := "read host status history"
+	rb := common.NewResponseBodyStatusHistory()
+
+	ps := httprouter.ParamsFromContext(r.Context())
+	name := ps.ByName("hostName")
+
+	history, status, err := doReadHostStatusHistory(name, r.URL.Query())
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		if len(history.Changes) == 0 {
+			rb.Message = "no status changes recorded for this host in the given time period"
+		}
+		rb.Data["history"] = *history
+		clog.Info().Msgf("%s success", actionPrefix)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// doReadHostStatusHistory returns the recorded status changes of the named host over the last
+// number of days given in the query (default 7).
+func doReadHostStatusHistory(name string, queryMap url.Values) (history *common.HostStatusHistoryData, status int, err error) {
+
+	status = http.StatusInternalServerError
+
+	hosts, err := dbReadHostsTx(map[string]interface{}{"name": name})
+	if err != nil {
+		return
+	}
+	if len(hosts) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("host '%s' not found", name)
+	}
+
+	days := 7
+	if val, ok := queryMap["days"]; ok {
+		days, _ = strconv.Atoi(val[0])
+	}
+	limit := 0
+	if val, ok := queryMap["limit"]; ok {
+		limit, _ = strconv.Atoi(val[0])
+	}
+
+	end := time.Now()
+	start := end.AddDate(0, 0, -days)
+	records, err := dbReadHostStatusRecordsTx([]string{hosts[0].HostName}, start, end, limit)
+	if err != nil {
+		return
+	}
+
+	history = &common.HostStatusHistoryData{
+		Host:     hosts[0].Name,
+		Flapping: isHostFlapping(hosts[0].HostName),
+		Changes:  make([]common.HostStatusChange, 0, len(records)),
+	}
+	for _, rec := range records {
+		history.Changes = append(history.Changes, rec.getStatusChangeData())
+	}
+
+	return history, http.StatusOK, nil
+}
+
+func validateStatusHistoryParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		if r.Method == http.MethodGet {
+
+			queryParams := r.URL.Query()
+
+		queryParamLoop:
+			for key, val := range queryParams {
+				switch strings.TrimSpace(key) {
+				case "days":
+					if days, err := strconv.Atoi(val[0]); err != nil || days < 1 || days > igor.StatusHistory.HistoryDays {
+						validateErr = NewBadParamTypeError(key, val, fmt.Sprintf("int between 1 and %d", igor.StatusHistory.HistoryDays))
+						break queryParamLoop
+					}
+				case "limit":
+					if limit, err := strconv.Atoi(val[0]); err != nil || limit < 1 {
+						validateErr = NewBadParamTypeError(key, val, "positive int")
+						break queryParamLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, val)
+					break queryParamLoop
+				}
+			}
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateStatusHistoryParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffHostStatus(t *testing.T) {
+	now := time.Now()
+	prev := map[string]HostStatus{"kn1": HostStatusUp, "kn2": HostStatusOff, "kn3": HostStatusUp}
+	curr := map[string]HostStatus{"kn1": HostStatusPingable, "kn2": HostStatusOff, "kn3": HostStatusUp, "kn4": HostStatusUp}
+
+	records := diffHostStatus(prev, curr, now)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "kn1", records[0].HostName)
+	assert.Equal(t, "up", records[0].PrevStatus)
+	assert.Equal(t, "ping", records[0].Status)
+	assert.Equal(t, now, records[0].CreatedAt)
+}
+
+func TestDetectFlapping(t *testing.T) {
+	recentTransitions = make(map[string][]time.Time)
+	flappingHosts = make(map[string]bool)
+	window := 10 * time.Minute
+	start := time.Now()
+
+	change := func(at time.Time) []HostStatusRecord {
+		return []HostStatusRecord{{Base: Base{CreatedAt: at}, HostName: "kn1"}}
+	}
+
+	assert.Empty(t, detectFlapping(change(start), start, window, 3))
+	assert.Empty(t, detectFlapping(change(start.Add(time.Minute)), start.Add(time.Minute), window, 3))
+	assert.Equal(t, []string{"kn1"}, detectFlapping(change(start.Add(2*time.Minute)), start.Add(2*time.Minute), window, 3))
+	assert.True(t, isHostFlapping("kn1"))
+
+	// still flapping, but only reported once
+	assert.Empty(t, detectFlapping(change(start.Add(3*time.Minute)), start.Add(3*time.Minute), window, 3))
+
+	// older changes age out of the window
+	later := start.Add(time.Hour)
+	assert.Empty(t, detectFlapping(nil, later, window, 3))
+	assert.False(t, isHostFlapping("kn1"))
+}
+
+func TestGetHostReliability(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+	rec := func(host string, offset time.Duration, prev, curr HostStatus) HostStatusRecord {
+		return HostStatusRecord{Base: Base{CreatedAt: start.Add(offset)}, HostName: host, PrevStatus: prev.String(), Status: curr.String()}
+	}
+
+	records := []HostStatusRecord{
+		rec("kn1", 0, HostStatusOff, HostStatusUp),
+		rec("kn1", 4*time.Hour, HostStatusUp, HostStatusOff),
+		rec("kn1", 5*time.Hour, HostStatusOff, HostStatusUp),
+		rec("kn2", 0, HostStatusOff, HostStatusUp),
+	}
+
+	result := getHostReliability(records, start, end, time.Hour, 3)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "kn1", result[0].Host)
+	assert.Equal(t, 3, result[0].Transitions)
+	assert.Equal(t, 1, result[0].Outages)
+	assert.InDelta(t, 90.0, result[0].UpPercent, 0.01)
+	assert.False(t, result[0].Flapping)
+	assert.Equal(t, "kn2", result[1].Host)
+	assert.InDelta(t, 100.0, result[1].UpPercent, 0.01)
+}
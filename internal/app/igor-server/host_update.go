@@ -24,11 +24,28 @@ func doUpdateHost(hostName string, changes map[string]interface{}, r *http.Reque
 	if err = performDbTx(func(tx *gorm.DB) error {
 
 		hList, ghStatus, ghErr := getHosts([]string{hostName}, false, tx)
-		if err != nil {
+		if ghErr != nil {
 			status = ghStatus
 			return ghErr
 		}
 
+		if nics, ok := changes["Interfaces"].([]HostInterface); ok && len(nics) > 0 {
+			var macs []string
+			for _, nic := range nics {
+				if nic.Mac == hList[0].Mac {
+					status = http.StatusBadRequest
+					return fmt.Errorf("mac address %s is already the primary mac of host %s", nic.Mac, hostName)
+				}
+				macs = append(macs, nic.Mac)
+			}
+			if others, rhErr := dbReadHosts(map[string]interface{}{"mac": macs}, tx); rhErr != nil {
+				return rhErr
+			} else if len(others) > 0 {
+				status = http.StatusConflict
+				return fmt.Errorf("mac address %s is already used by host %s", others[0].Mac, others[0].Name)
+			}
+		}
+
 		err = dbEditHosts(hList, changes, tx)
 		if err != nil {
 			return err // uses default err status
@@ -38,7 +55,7 @@ func doUpdateHost(hostName string, changes map[string]interface{}, r *http.Reque
 			var finalPath string
 
 			for k := range changes {
-				if k == "HostPolicy" || k == "ip" || k == "eth" {
+				if k == "HostPolicy" || k == "ip" || k == "eth" || k == "switch_name" || k == "Interfaces" {
 					if k == "HostPolicy" {
 						k = "hostPolicy"
 					}
@@ -101,6 +118,18 @@ func parseHostEditParams(editParams map[string]interface{}, clog *zl.Logger) (ma
 	if val, ok := editParams["eth"].(string); ok {
 		changes["eth"] = val
 	}
+	// check for switch change
+	if val, ok := editParams["switch"].(string); ok {
+		changes["switch_name"] = val
+	}
+	// check for interface list change; the new list replaces the old one
+	if val, ok := editParams["nics"].(string); ok {
+		nics, err := parseHostNics(val)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		changes["Interfaces"] = nics
+	}
 	// determine if new host policy
 	if val, ok := editParams["hostPolicy"].(string); ok {
 		if val == "" {
@@ -38,6 +38,20 @@ func doDeleteHostPolicy(hpName string, r *http.Request) (code int, err error) {
 			return fmt.Errorf("deleting host policy while still assigned to a host is not permitted")
 		}
 
+		// do not allow delete to happen if a vlan pool is limited to the policy
+		vpList, vpErr := dbReadVlanPools(map[string]interface{}{}, tx)
+		if vpErr != nil {
+			return vpErr
+		}
+		for _, vp := range vpList {
+			for _, hp := range vp.Policies {
+				if hp.ID == target.ID {
+					code = http.StatusConflict
+					return fmt.Errorf("deleting host policy while vlan pool '%s' is limited to it is not permitted", vp.Name)
+				}
+			}
+		}
+
 		return dbDeleteHostPolicy(target, tx) // uses default err status
 
 	}); err == nil {
@@ -16,6 +16,7 @@ type Igor struct {
 	ConfigPath      string
 	ClusterConfPath string
 	IResInstaller
+	IAddrManager IAddrManager
 	IGormDb
 	IgorHome         string
 	AuthSecondary    IAuth
@@ -26,6 +27,7 @@ type Igor struct {
 	TFTPPath         string
 	PXEBIOSDir       string
 	PXEUEFIDir       string
+	PXEIPXEDir       string
 	ImageStoreDir    string
 	KickstartDir     string
 	ElevateMap       *common.PassiveTtlMap
@@ -0,0 +1,192 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"igor2/internal/pkg/api"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+)
+
+// bootURLKeyFile is the name of the key used to sign boot file URLs. It is kept next to the auth token
+// key so it survives restarts, but separate from it so an auth reset doesn't break hosts mid-boot.
+const bootURLKeyFile = "boot_url.key"
+
+var (
+	bootURLKey   []byte
+	bootURLKeyMU sync.Mutex
+)
+
+// getBootURLKey returns the boot file URL signing key, creating it the first time it is needed.
+func getBootURLKey() ([]byte, error) {
+	bootURLKeyMU.Lock()
+	defer bootURLKeyMU.Unlock()
+	if bootURLKey != nil {
+		return bootURLKey, nil
+	}
+	keyPath := filepath.Join(filepath.Dir(igor.AuthTokenKeypath), bootURLKeyFile)
+	key, err := os.ReadFile(keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if key, err = generateSecret(); err == nil {
+			err = os.WriteFile(keyPath, key, 0600)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("boot URL signing key unavailable - %v", err)
+	}
+	bootURLKey = key
+	return bootURLKey, nil
+}
+
+// signBootFile returns the signature that lets the named host fetch one boot file of a reservation.
+func signBootFile(key []byte, hostName, resName, resID, file string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join([]string{hostName, resName, resID, file}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// bootFileURL returns the signed address the host fetches the kernel or initrd of its reservation
+// from. The URL is only good for that host and that reservation while it is active on the host. It
+// carries no expiry of its own so it keeps working if the reservation is extended.
+func bootFileURL(host *Host, r *Reservation, file string) (string, error) {
+	key, err := getBootURLKey()
+	if err != nil {
+		return "", err
+	}
+	resID := strconv.Itoa(r.ID)
+	sig := signBootFile(key, host.Name, r.Name, resID, file)
+	return cbURL(strings.Join([]string{api.CbBoot, host.Name, r.Name, resID, sig, file}, "/")), nil
+}
+
+// ipxeBootScript returns the iPXE script that boots the reservation's image on the host with the
+// kernel and initrd fetched over HTTP.
+func ipxeBootScript(host *Host, r *Reservation, kernelArgs string) (string, error) {
+	kernelURL, err := bootFileURL(host, r, "kernel")
+	if err != nil {
+		return "", err
+	}
+	initrdURL, err := bootFileURL(host, r, "initrd")
+	if err != nil {
+		return "", err
+	}
+	args := strings.Join(strings.Fields(kernelArgs), " ")
+	return fmt.Sprintf("#!ipxe\necho Reservation: %s netbooting %s on host %s\nkernel %s initrd=initrd %s\ninitrd --name initrd %s\nboot\n",
+		r.Name, r.Profile.Distro.Name, host.Name, kernelURL, args, initrdURL), nil
+}
+
+// ipxeLocalScript is the iPXE script that hands booting back to the firmware so the host boots from
+// its local disk.
+const ipxeLocalScript = "#!ipxe\necho Booting from local disk\nexit\n"
+
+// destination for route GET /cb/svc/ipxe/:mac
+//
+// Serves the iPXE script of the host with the given boot mac. The iPXE binary handed out by DHCP
+// chains to this with its ${net0/mac}.
+func handleCbIpxe(w http.ResponseWriter, r *http.Request) {
+	hw, err := net.ParseMAC(httprouter.ParamsFromContext(r.Context()).ByName("mac"))
+	if err != nil {
+		http.Error(w, "invalid mac address", http.StatusBadRequest)
+		return
+	}
+	scriptPath := filepath.Join(igor.TFTPPath, igor.PXEIPXEDir, macToPxeString("01:"+hw.String()))
+	w.Header().Set("Content-Type", "text/plain")
+	if igor.Tftp.DbConfigs {
+		rel, _ := bootConfigRelPath(scriptPath)
+		content, found, err := readBootConfig(rel)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		} else if !found {
+			http.NotFound(w, r)
+		} else {
+			_, _ = w.Write([]byte(content))
+		}
+		return
+	}
+	http.ServeFile(w, r, scriptPath)
+}
+
+// destination for route GET /cb/svc/boot/:hostName/:resName/:resID/:sig/:file
+//
+// Serves the kernel or initrd of a reservation to a host booting with iPXE. The URL must carry a
+// valid signature from bootFileURL, must be requested by the host it was made for and the reservation
+// must still be active on that host.
+func handleCbBootFile(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	ps := httprouter.ParamsFromContext(r.Context())
+	hostName, resName, resID, file := ps.ByName("hostName"), ps.ByName("resName"), ps.ByName("resID"), ps.ByName("file")
+
+	if file != "kernel" && file != "initrd" {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := getBootURLKey()
+	if err != nil {
+		clog.Error().Msgf("serve boot file - %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if !hmac.Equal([]byte(ps.ByName("sig")), []byte(signBootFile(key, hostName, resName, resID, file))) {
+		clog.Warn().Msgf("serve boot file - bad signature on request for host %s from %s", hostName, r.RemoteAddr)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	hosts, status, err := doReadHosts(map[string]interface{}{"name": hostName})
+	if err != nil || len(hosts) == 0 {
+		if err == nil {
+			status = http.StatusNotFound
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	host := hosts[0]
+	ip := strings.Split(r.RemoteAddr, ":")[0]
+	if host.IP != "" && ip != host.IP {
+		clog.Warn().Msgf("serve boot file - host %s boot file requested from %s", hostName, ip)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	res := getActiveReservation(&host)
+	if res == nil || res.Name != resName || strconv.Itoa(res.ID) != resID {
+		http.Error(w, "reservation is not active on this host", http.StatusForbidden)
+		return
+	}
+
+	image := res.Profile.Distro.DistroImage
+	name := image.Kernel
+	if file == "initrd" {
+		name = image.Initrd
+	}
+	f, err := os.Open(filepath.Join(getImageStorePath(image.ImageID), name))
+	if err != nil {
+		clog.Error().Msgf("serve boot file - %v", err)
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if file == "kernel" {
+		recordBootStage(res, host.Name, BootStageKernel, "http")
+	}
+	http.ServeContent(w, r, name, fi.ModTime(), f)
+}
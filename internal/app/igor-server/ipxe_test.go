@@ -0,0 +1,146 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"igor2/internal/pkg/api"
+)
+
+func useTestBootURLKey(t *testing.T) {
+	savedPath, savedHost, savedPort := igor.AuthTokenKeypath, igor.Server.CbHost, igor.Server.CbPort
+	t.Cleanup(func() {
+		igor.AuthTokenKeypath, igor.Server.CbHost, igor.Server.CbPort = savedPath, savedHost, savedPort
+		bootURLKey = nil
+	})
+	igor.AuthTokenKeypath = filepath.Join(t.TempDir(), "auth.key")
+	igor.Server.CbHost, igor.Server.CbPort = "igor.test", 8081
+	bootURLKey = nil
+}
+
+func TestBootURLKey(t *testing.T) {
+	useTestBootURLKey(t)
+
+	key, err := getBootURLKey()
+	assert.NoError(t, err)
+	assert.Len(t, key, 64)
+
+	// the key is read back from disk after a restart
+	bootURLKey = nil
+	again, err := getBootURLKey()
+	assert.NoError(t, err)
+	assert.Equal(t, key, again)
+	info, err := os.Stat(filepath.Join(filepath.Dir(igor.AuthTokenKeypath), bootURLKeyFile))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestIpxeBootScript(t *testing.T) {
+	useTestBootURLKey(t)
+
+	host := &Host{Name: "kn1"}
+	res := &Reservation{Name: "r1", End: time.Unix(2000000000, 0)}
+	res.ID = 7
+	res.Profile.Distro.Name = "rocky9"
+	script, err := ipxeBootScript(host, res, "  console=ttyS0   quiet ")
+	assert.NoError(t, err)
+
+	key, _ := getBootURLKey()
+	sig := signBootFile(key, "kn1", "r1", "7", "kernel")
+	lines := strings.Split(script, "\n")
+	assert.Equal(t, "#!ipxe", lines[0])
+	assert.Equal(t, "kernel http://igor.test:8081/igor/cb/svc/boot/kn1/r1/7/"+sig+"/kernel initrd=initrd console=ttyS0 quiet", lines[2])
+	assert.True(t, strings.HasPrefix(lines[3], "initrd --name initrd http://igor.test:8081/igor/cb/svc/boot/kn1/r1/7/"))
+	assert.NotContains(t, lines[3], sig)
+	assert.Equal(t, "boot", lines[4])
+
+	// signatures are bound to the host, reservation and file
+	assert.NotEqual(t, sig, signBootFile(key, "kn2", "r1", "7", "kernel"))
+	assert.NotEqual(t, sig, signBootFile(key, "kn1", "r1", "8", "kernel"))
+	assert.NotEqual(t, sig, signBootFile(key, "kn1", "r1", "7", "initrd"))
+
+	// extending the reservation doesn't change the URLs the host was given
+	res.End = res.End.Add(72 * time.Hour)
+	extended, err := ipxeBootScript(host, res, "console=ttyS0 quiet")
+	assert.NoError(t, err)
+	assert.Equal(t, script, extended)
+}
+
+func newBootFileRouter() func(path string) *httptest.ResponseRecorder {
+	router := httprouter.New()
+	router.Handle(http.MethodGet, api.CbBoot+"/:hostName/:resName/:resID/:sig/:file", NewHandlerChain().ApplyTo(handleCbBootFile))
+	return func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, api.CbBoot+path, nil))
+		return w
+	}
+}
+
+func TestCbBootFileRejects(t *testing.T) {
+	useTestBootURLKey(t)
+	key, _ := getBootURLKey()
+	get := newBootFileRouter()
+
+	good := signBootFile(key, "kn1", "r1", "7", "kernel")
+	assert.Equal(t, http.StatusForbidden, get("/kn1/r1/7/"+good+"0/kernel").Code)
+	assert.Equal(t, http.StatusForbidden, get("/kn2/r1/7/"+good+"/kernel").Code)
+	assert.Equal(t, http.StatusForbidden, get("/kn1/r1/8/"+good+"/kernel").Code)
+	assert.Equal(t, http.StatusNotFound, get("/kn1/r1/7/"+good+"/vmlinuz").Code)
+}
+
+func TestCbBootFileExtendedReservation(t *testing.T) {
+	useTestBootURLKey(t)
+	db := newTestDB(t)
+	savedPath, savedDir := igor.TFTPPath, igor.ImageStoreDir
+	t.Cleanup(func() { igor.TFTPPath, igor.ImageStoreDir = savedPath, savedDir })
+	igor.TFTPPath, igor.ImageStoreDir = t.TempDir(), "images"
+
+	image := DistroImage{Name: "img1", ImageID: "abc123", Kernel: "vmlinuz", Initrd: "initrd.img"}
+	store := getImageStorePath(image.ImageID)
+	require.NoError(t, os.MkdirAll(store, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(store, image.Initrd), []byte("initrd contents"), 0644))
+
+	owner := User{Name: "alice"}
+	require.NoError(t, db.Create(&owner).Error)
+	host := Host{Name: "kn1", HostName: "kn1", SequenceID: 1, Mac: "00:00:00:00:00:01"}
+	now := time.Now()
+	res := Reservation{
+		Name:    "r1",
+		Hash:    "r1hash",
+		Owner:   owner,
+		Group:   Group{Name: "g1"},
+		Profile: Profile{Name: "p1", Owner: owner, Distro: Distro{Name: "d1", Owner: owner, DistroImage: image}},
+		Hosts:   []Host{host},
+		Start:   now.Add(-2 * time.Hour),
+		End:     now.Add(-time.Hour),
+	}
+	require.NoError(t, db.Create(&res).Error)
+
+	// the URL is made while the reservation has its original end time
+	url, err := bootFileURL(&host, &res, "initrd")
+	require.NoError(t, err)
+	path := strings.TrimPrefix(url, cbURL(api.CbBoot))
+
+	// the original end has passed but the reservation was extended, so the host can still boot
+	require.NoError(t, db.Model(&res).Update("end", now.Add(time.Hour)).Error)
+	w := newBootFileRouter()(path)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "initrd contents", w.Body.String())
+
+	// once the reservation is no longer active the URL stops working
+	require.NoError(t, db.Model(&res).Update("end", now.Add(-time.Minute)).Error)
+	assert.Equal(t, http.StatusForbidden, newBootFileRouter()(path).Code)
+}
@@ -23,6 +23,7 @@ type Kickstart struct {
 	Filename string `gorm:"unique; notNull"`
 	OwnerID  int
 	Owner    User
+	Template bool // rendered for each host that fetches it, see kickstartTemplateData
 }
 
 func filterKickstartList(kickstarts []Kickstart) []common.KickstartData {
@@ -33,6 +34,7 @@ func filterKickstartList(kickstarts []Kickstart) []common.KickstartData {
 			Name:     ks.Name,
 			FileName: ks.Filename,
 			Owner:    ks.Owner.Name,
+			Template: ks.Template,
 		})
 	}
 
@@ -6,6 +6,7 @@ package igorserver
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/rs/zerolog/hlog"
 	"gorm.io/gorm"
@@ -35,12 +36,21 @@ func registerKickstart(r *http.Request, tx *gorm.DB) (ks *Kickstart, status int,
 	// potential way of determining whether files were included and type based on count?
 	clog.Debug().Msgf("Number of files attached: %v", len(r.MultipartForm.File))
 
+	isTemplate, _ := strconv.ParseBool(r.FormValue("template"))
+	if isTemplate {
+		if err = checkKickstartTemplate(r, nil); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
 	// we need to pull files from the multiform and stage them
 	ks, err = saveKSFile(r)
 	if err != nil {
 		return ks, http.StatusInternalServerError, err
 	}
 
+	ks.Template = isTemplate
+
 	// Set user as owner
 	user := getUserFromContext(r)
 	ks.Owner = *user
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"igor2/internal/pkg/common"
@@ -135,6 +136,22 @@ func validateKSParams(handler http.Handler) http.Handler {
 			}
 		}
 
+		if r.Method == http.MethodPost {
+		postParamLoop:
+			for key, vals := range r.PostForm {
+				switch key {
+				case "template":
+					if _, err := strconv.ParseBool(vals[0]); err != nil {
+						validateErr = NewBadParamTypeError(key, vals[0], "bool")
+						break postParamLoop
+					}
+				default:
+					validateErr = NewUnknownParamError(key, vals)
+					break postParamLoop
+				}
+			}
+		}
+
 		if r.Method == http.MethodPatch {
 			if validateErr = r.ParseMultipartForm(MaxMemory); validateErr != nil {
 				clog.Warn().Msgf("validateKSParams - %v", validateErr)
@@ -156,6 +173,11 @@ func validateKSParams(handler http.Handler) http.Handler {
 						}
 					case "has_kickstart":
 						continue
+					case "template":
+						if _, err := strconv.ParseBool(vals[0]); err != nil {
+							validateErr = NewBadParamTypeError(key, vals[0], "bool")
+							break patchParamLoop
+						}
 					default:
 						validateErr = NewUnknownParamError(key, vals)
 						break patchParamLoop
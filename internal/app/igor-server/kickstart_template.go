@@ -0,0 +1,150 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"igor2/internal/pkg/api"
+)
+
+// kickstartTemplateData is what a kickstart marked as a template is rendered with when a host fetches
+// it. It has everything a boot template gets plus details of the reservation owner, the SSH keys
+// registered by the owner and group members, and the variables of the reservation's profile.
+type kickstartTemplateData struct {
+	bootTemplateData
+	Owner struct {
+		Name     string
+		FullName string
+		Email    string
+		SSHKeys  []string // public keys the owner has registered with igor
+	}
+	Hosts   []string          // names of every host in the reservation
+	SSHKeys []string          // public keys of the owner and the members of the reservation's group
+	Vars    map[string]string // variables of the reservation's profile
+}
+
+func newKickstartTemplateData(host *Host, r *Reservation, ownerKeys, allKeys []string) *kickstartTemplateData {
+	d := &kickstartTemplateData{bootTemplateData: *newBootTemplateData(host, r, kickstartURL(r))}
+	d.Owner.Name, d.Owner.FullName, d.Owner.Email = r.Owner.Name, r.Owner.FullName, r.Owner.Email
+	d.Owner.SSHKeys, d.SSHKeys = ownerKeys, allKeys
+	d.Hosts = namesOfHosts(r.Hosts)
+	d.Vars = r.Profile.vars()
+	return d
+}
+
+// kickstartURL returns the url the reservation's hosts fetch their kickstart from, or blank if the
+// image doesn't install with one.
+func kickstartURL(r *Reservation) string {
+	if !r.Profile.Distro.DistroImage.LocalBoot {
+		return ""
+	}
+	return fmt.Sprintf("http://%s:%v/%s/%s", igor.Server.CbHost, igor.Server.CbPort, api.CbKS, r.Profile.Distro.Kickstart.Filename)
+}
+
+// parseKickstartTemplate parses the contents of a kickstart marked as a template. Referring to a profile
+// variable the reservation doesn't set is an error when it's rendered.
+func parseKickstartTemplate(text string) (*template.Template, error) {
+	return template.New("kickstart").Option("missingkey=error").Parse(text)
+}
+
+// renderKickstart renders the kickstart file for the host of the reservation with the registered SSH
+// keys of its users.
+func renderKickstart(ks *Kickstart, host *Host, r *Reservation, ownerKeys, allKeys []string) ([]byte, error) {
+	text, err := os.ReadFile(filepath.Join(igor.TFTPPath, igor.KickstartDir, ks.Filename))
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseKickstartTemplate(string(text))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, newKickstartTemplateData(host, r, ownerKeys, allKeys)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkKickstartTemplate makes sure a kickstart marked as a template parses. The file attached to the
+// request is checked if there is one, otherwise the kickstart's current file.
+func checkKickstartTemplate(r *http.Request, ks *Kickstart) error {
+	var text []byte
+	if f, _, err := r.FormFile("kickstart"); err == nil {
+		defer f.Close()
+		if text, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	} else if text, err = os.ReadFile(filepath.Join(igor.TFTPPath, igor.KickstartDir, ks.Filename)); err != nil {
+		return err
+	}
+	if _, err := parseKickstartTemplate(string(text)); err != nil {
+		return fmt.Errorf("kickstart is not a valid template - %v", err)
+	}
+	return nil
+}
+
+// profileVarPattern is what a profile variable name must look like so templates can refer to it
+// as .Vars.NAME
+var profileVarPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+const (
+	maxProfileVars     = 50
+	maxProfileVarValue = 4096
+)
+
+// checkProfileVars checks variables given for a profile. A blank value unsets the variable.
+func checkProfileVars(val interface{}) error {
+	vars, ok := val.(map[string]interface{})
+	if !ok {
+		return NewBadParamTypeError("vars", val, "object")
+	}
+	if len(vars) > maxProfileVars {
+		return fmt.Errorf("a profile can't have more than %d variables", maxProfileVars)
+	}
+	for name, v := range vars {
+		if !profileVarPattern.MatchString(name) {
+			return fmt.Errorf("'%s' is not a legal variable name; use letters, digits and underscores", name)
+		}
+		if s, ok := v.(string); !ok {
+			return NewBadParamTypeError("vars."+name, v, "string")
+		} else if len(s) > maxProfileVarValue {
+			return fmt.Errorf("value of variable %s is longer than %d characters", name, maxProfileVarValue)
+		}
+	}
+	return nil
+}
+
+// mergeProfileVars applies variables given for a profile to its current ones and returns the result
+// in the form the profile stores them.
+func mergeProfileVars(current map[string]string, given map[string]interface{}) (string, error) {
+	merged := make(map[string]string, len(current)+len(given))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range given {
+		if s, _ := v.(string); s == "" {
+			delete(merged, k)
+		} else {
+			merged[k] = s
+		}
+	}
+	if len(merged) > maxProfileVars {
+		return "", fmt.Errorf("a profile can't have more than %d variables", maxProfileVars)
+	}
+	if len(merged) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(merged)
+	return string(b), err
+}
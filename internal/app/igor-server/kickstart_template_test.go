@@ -0,0 +1,67 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderKickstart(t *testing.T) {
+	savedPath, savedDir := igor.TFTPPath, igor.KickstartDir
+	t.Cleanup(func() { igor.TFTPPath, igor.KickstartDir = savedPath, savedDir })
+	igor.TFTPPath, igor.KickstartDir = t.TempDir(), "ks"
+	require.NoError(t, os.MkdirAll(filepath.Join(igor.TFTPPath, igor.KickstartDir), 0755))
+
+	text := `network --hostname={{.Host.Name}} --ip={{.Host.IP}}
+# {{.Reservation.Name}} for {{.Owner.Name}} on vlan {{.Reservation.Vlan}} with {{len .Hosts}} hosts
+timezone {{.Vars.timezone}}
+{{range .Owner.SSHKeys}}key {{.}}
+{{end}}{{len .SSHKeys}} keys
+`
+	require.NoError(t, os.WriteFile(filepath.Join(igor.TFTPPath, igor.KickstartDir, "rh.ks"), []byte(text), 0644))
+
+	ks := &Kickstart{Filename: "rh.ks", Template: true}
+	host := &Host{Name: "kn1", IP: "10.0.0.1"}
+	r := &Reservation{
+		Name:    "res1",
+		Owner:   User{Name: "alice"},
+		Vlan:    200,
+		Hosts:   []Host{*host, {Name: "kn2"}},
+		Profile: Profile{Vars: `{"timezone":"America/Denver"}`},
+	}
+
+	got, err := renderKickstart(ks, host, r, []string{"ssh-ed25519 AAAA alice"}, []string{"ssh-ed25519 AAAA alice", "ssh-ed25519 BBBB bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "network --hostname=kn1 --ip=10.0.0.1\n# res1 for alice on vlan 200 with 2 hosts\ntimezone America/Denver\nkey ssh-ed25519 AAAA alice\n2 keys\n", string(got))
+
+	// a variable the profile doesn't set is an error rather than a blank
+	r.Profile.Vars = ""
+	_, err = renderKickstart(ks, host, r, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestCheckProfileVars(t *testing.T) {
+	assert.NoError(t, checkProfileVars(map[string]interface{}{"timezone": "UTC", "_disk2": ""}))
+	assert.Error(t, checkProfileVars("timezone=UTC"))
+	assert.Error(t, checkProfileVars(map[string]interface{}{"time-zone": "UTC"}))
+	assert.Error(t, checkProfileVars(map[string]interface{}{"2disk": "sdb"}))
+	assert.Error(t, checkProfileVars(map[string]interface{}{"count": 2.0}))
+}
+
+func TestMergeProfileVars(t *testing.T) {
+	merged, err := mergeProfileVars(map[string]string{"a": "1", "b": "2"}, map[string]interface{}{"b": "", "c": "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"1","c":"3"}`, merged)
+	assert.Equal(t, map[string]string{"a": "1", "c": "3"}, (&Profile{Vars: merged}).vars())
+
+	merged, err = mergeProfileVars(map[string]string{"a": "1"}, map[string]interface{}{"a": ""})
+	assert.NoError(t, err)
+	assert.Empty(t, merged)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"gorm.io/gorm"
 )
@@ -34,6 +35,17 @@ func doUpdateKS(targetName string, r *http.Request) (code int, err error) {
 		}
 		target := kss[0]
 		oldFileName := target.Filename
+		isTemplate := target.Template
+		if tv := r.FormValue("template"); tv != "" {
+			isTemplate, _ = strconv.ParseBool(tv)
+			changes["template"] = isTemplate
+		}
+		if isTemplate {
+			if tErr := checkKickstartTemplate(r, &target); tErr != nil {
+				code = http.StatusBadRequest
+				return tErr
+			}
+		}
 		key := "kickstart"
 		targetFile, handler, fileErr := r.FormFile(key)
 		if fileErr == nil {
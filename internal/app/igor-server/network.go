@@ -5,42 +5,197 @@
 package igorserver
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 )
 
+const (
+	// DefaultSwitchName is the name given to the switch described by the single-switch vlan settings.
+	DefaultSwitchName = "default"
+)
+
+// SwitchConfig describes a single network switch in the vlan.switches list of the server config file.
+// Hosts are mapped to a switch by name and their Eth value is the port on that switch.
+type SwitchConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Network selects the switch driver. Defaults to vlan.network if blank.
+	Network         string `yaml:"network" json:"network"`
+	NetworkUser     string `yaml:"networkUser" json:"networkUser"`
+	NetworkPassword string `yaml:"networkPassword" json:"-"`
+	NetworkURL      string `yaml:"networkURL" json:"networkURL"`
+
+	// The following are only used by the ssh driver. The templates are text/template command
+	// lists run in a shell on the switch, one command per line.
+	SetTemplate   string `yaml:"setTemplate" json:"setTemplate"`
+	ClearTemplate string `yaml:"clearTemplate" json:"clearTemplate"`
+	ShowTemplate  string `yaml:"showTemplate" json:"showTemplate"`
+	// ShowRegex is matched against each line of show output and must have named groups port and vlan.
+	ShowRegex string `yaml:"showRegex" json:"showRegex"`
+	// ErrorRegex fails a command set if any line of its output matches. Not checked if blank.
+	ErrorRegex string `yaml:"errorRegex" json:"errorRegex"`
+	// KeyFile is the path to a private key used to log in, alongside or instead of networkPassword.
+	KeyFile string `yaml:"keyFile" json:"keyFile"`
+	// KnownHostsFile is used to verify the switch host key. Required unless InsecureHostKey is set.
+	KnownHostsFile string `yaml:"knownHostsFile" json:"knownHostsFile"`
+	// InsecureHostKey skips verifying the switch host key when KnownHostsFile is blank.
+	InsecureHostKey bool `yaml:"insecureHostKey" json:"insecureHostKey"`
+
+	// The following are only used by the sim driver.
+	// SimLatency is the number of milliseconds each command set takes.
+	SimLatency int `yaml:"simLatency" json:"simLatency"`
+	// SimFailRate is the chance, from 0 to 1, that a command set fails without changing anything.
+	SimFailRate float64 `yaml:"simFailRate" json:"simFailRate"`
+	// SimFailPorts are ports that make every command set including them fail.
+	SimFailPorts []string `yaml:"simFailPorts" json:"simFailPorts"`
+}
+
+// Switch drivers register their functions by network name. Each function works against a single switch.
+// The vlan functions return a map of switch port to the string form of the port's vlan value.
+// Drivers with settings of their own may also register a function to check them at startup.
 var (
-	networkSetFuncs   map[string]func([]Host, int) error
-	networkClearFuncs map[string]func([]Host) error
-	networkVlanFuncs  map[string]func() (map[string]string, error)
+	networkSetFuncs      map[string]func(*SwitchConfig, []Host, int) error
+	networkClearFuncs    map[string]func(*SwitchConfig, []Host) error
+	networkVlanFuncs     map[string]func(*SwitchConfig) (map[string]string, error)
+	networkValidateFuncs = make(map[string]func(*SwitchConfig) error)
 )
 
-// Configure the given nodes into the specified 802.1ad outer VLAN
+// validateSwitchConfig checks a switch entry of the server config and applies defaults.
+func validateSwitchConfig(sw *SwitchConfig) error {
+	if sw.Name == "" {
+		return fmt.Errorf("name cannot be blank")
+	}
+	if sw.Network == "" {
+		sw.Network = igor.Vlan.Network
+	}
+	if _, ok := networkSetFuncs[sw.Network]; !ok {
+		return fmt.Errorf("network '%s' not recognized", sw.Network)
+	}
+	if sw.NetworkUser == "" {
+		sw.NetworkUser = "igor"
+		logger.Info().Msgf("vlan.switches '%s' networkUser not specified, using default : igor", sw.Name)
+	}
+	if sw.NetworkURL == "" && sw.Network != NetworkSim {
+		return fmt.Errorf("networkURL cannot be blank")
+	}
+	if f, ok := networkValidateFuncs[sw.Network]; ok {
+		return f(sw)
+	}
+	return nil
+}
+
+// getSwitch returns the configured switch with the given name, or nil if there is none. A blank name
+// refers to the first switch in the list.
+func getSwitch(name string) *SwitchConfig {
+	if len(igor.Vlan.Switches) == 0 {
+		return nil
+	}
+	if name == "" {
+		return &igor.Vlan.Switches[0]
+	}
+	for i := range igor.Vlan.Switches {
+		if igor.Vlan.Switches[i].Name == name {
+			return &igor.Vlan.Switches[i]
+		}
+	}
+	return nil
+}
+
+// checkSwitchName returns an error if switches are configured and none has the given name.
+func checkSwitchName(name string) error {
+	if name != "" && len(igor.Vlan.Switches) > 0 && getSwitch(name) == nil {
+		return fmt.Errorf("no switch named '%s' is configured", name)
+	}
+	return nil
+}
+
+// hostsBySwitch groups the hosts by the name of the switch they are connected to.
+func hostsBySwitch(hosts []Host) (map[string][]Host, error) {
+	groups := make(map[string][]Host)
+	for _, h := range hosts {
+		sw := getSwitch(h.SwitchName)
+		if sw == nil {
+			return nil, fmt.Errorf("host %s is mapped to switch '%s' which is not configured", h.Name, h.SwitchName)
+		}
+		groups[sw.Name] = append(groups[sw.Name], h)
+	}
+	return groups, nil
+}
+
+// forEachSwitch groups the hosts by switch and calls f once for each switch in parallel. Errors from
+// every switch are collected and returned together.
+func forEachSwitch(hosts []Host, f func(*SwitchConfig, []Host) error) error {
+
+	groups, err := hostsBySwitch(hosts)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for name, swHosts := range groups {
+		wg.Add(1)
+		go func(sw *SwitchConfig, swHosts []Host) {
+			defer wg.Done()
+			if swErr := f(sw, swHosts); swErr != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("switch %s: %v", sw.Name, swErr))
+				mu.Unlock()
+			}
+		}(getSwitch(name), swHosts)
+	}
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return errors.Join(errs...)
+}
+
+// networkSnapshot holds the vlan of each switch port before a change was made, keyed by switch name
+// then port. Ports without a vlan in the configured range are left out.
+type networkSnapshot map[string]map[string]string
+
+// Configure the given nodes into the specified 802.1ad outer VLAN. If any switch fails, every switch
+// is returned to the port vlans it had before the change.
 func networkSet(nodes []Host, vlan int) error {
-	// if in dev env, just log and return
-	if DEVMODE {
+	_, err := networkSetWithRollback(nodes, vlan)
+	return err
+}
+
+// networkSetWithRollback works like networkSet but also returns the port vlans from before the change
+// so the caller can undo it with networkRollback if a later step fails.
+func networkSetWithRollback(nodes []Host, vlan int) (networkSnapshot, error) {
+	// if in dev env, just log and return unless the switches are simulated
+	if DEVMODE && !simNetworkEnabled() {
 		logger.Debug().Msg("in dev env running networkSet(), no external action taken")
-		return nil
+		return nil, nil
 	}
 
 	if igor.Vlan.Network == "" {
 		// they don't want to do vlan segmentation
 		logger.Debug().Msg("not doing vlan segmentation")
-		return nil
+		return nil, nil
 	}
 
-	f, ok := networkSetFuncs[igor.Vlan.Network]
-	if !ok {
-		logger.Error().Msgf("no such network mode: %v", igor.Vlan.Network)
-	}
-	return f(nodes, vlan)
+	return networkChange(nodes, func(sw *SwitchConfig, swHosts []Host) error {
+		f, ok := networkSetFuncs[sw.Network]
+		if !ok {
+			return fmt.Errorf("no such network mode: %v", sw.Network)
+		}
+		return f(sw, swHosts, vlan)
+	})
 }
 
-// Clear any 802.1ad configuration on the given nodes
+// Clear any 802.1ad configuration on the given nodes. If any switch fails, every switch is returned
+// to the port vlans it had before the change.
 func networkClear(nodes []Host) error {
-	// if in dev env, just log and return
-	if DEVMODE {
+	// if in dev env, just log and return unless the switches are simulated
+	if DEVMODE && !simNetworkEnabled() {
 		logger.Debug().Msg("in dev env running networkClear(), no external action taken")
 		return nil
 	}
@@ -51,20 +206,105 @@ func networkClear(nodes []Host) error {
 		return nil
 	}
 
-	f, ok := networkClearFuncs[igor.Vlan.Network]
-	if !ok {
-		logger.Error().Msgf("no such network mode: %v", igor.Vlan.Network)
+	_, err := networkChange(nodes, func(sw *SwitchConfig, swHosts []Host) error {
+		f, ok := networkClearFuncs[sw.Network]
+		if !ok {
+			return fmt.Errorf("no such network mode: %v", sw.Network)
+		}
+		return f(sw, swHosts)
+	})
+	return err
+}
+
+// networkChange snapshots the port vlans of the switches the nodes are on, then applies the change to
+// every switch in parallel. If the change fails on any switch all of them are rolled back to the
+// snapshot. Nothing is changed if the snapshot can't be read.
+func networkChange(nodes []Host, apply func(*SwitchConfig, []Host) error) (networkSnapshot, error) {
+
+	snap, err := takeNetworkSnapshot(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read current port vlans, no changes made: %v", err)
+	}
+
+	if err = forEachSwitch(nodes, apply); err != nil {
+		logger.Error().Msgf("network change failed, rolling back: %v", err)
+		if rbErr := networkRollback(nodes, snap); rbErr != nil {
+			logger.Error().Msgf("network rollback failed: %v", rbErr)
+			return nil, fmt.Errorf("%v; rollback to previous port vlans also failed: %v", err, rbErr)
+		}
+		return nil, fmt.Errorf("%v; all switch ports were rolled back to their previous vlans", err)
+	}
+	return snap, nil
+}
+
+// takeNetworkSnapshot reads the current port vlans of every switch the nodes are on.
+func takeNetworkSnapshot(nodes []Host) (networkSnapshot, error) {
+	snap := make(networkSnapshot)
+	var mu sync.Mutex
+	err := forEachSwitch(nodes, func(sw *SwitchConfig, _ []Host) error {
+		f, ok := networkVlanFuncs[sw.Network]
+		if !ok {
+			return fmt.Errorf("no such network mode: %v", sw.Network)
+		}
+		ports, vErr := f(sw)
+		if vErr != nil {
+			return vErr
+		}
+		mu.Lock()
+		snap[sw.Name] = ports
+		mu.Unlock()
+		return nil
+	})
+	return snap, err
+}
+
+// networkRollback returns the ports of the nodes to the vlans recorded in the snapshot. Ports that had
+// no vlan are cleared. A nil snapshot means nothing was changed and is ignored.
+func networkRollback(nodes []Host, snap networkSnapshot) error {
+	if snap == nil {
+		return nil
+	}
+	return forEachSwitch(nodes, func(sw *SwitchConfig, swHosts []Host) error {
+		toSet, toClear := groupByPreviousVlan(swHosts, snap[sw.Name])
+		var errs []error
+		if len(toClear) > 0 {
+			if f, ok := networkClearFuncs[sw.Network]; ok {
+				errs = append(errs, f(sw, toClear))
+			}
+		}
+		if f, ok := networkSetFuncs[sw.Network]; ok {
+			for vlan, hosts := range toSet {
+				errs = append(errs, f(sw, hosts, vlan))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// groupByPreviousVlan splits hosts into those whose port had a vlan, grouped by that vlan, and those
+// whose port had none.
+func groupByPreviousVlan(hosts []Host, ports map[string]string) (map[int][]Host, []Host) {
+	toSet := make(map[int][]Host)
+	var toClear []Host
+	for _, h := range hosts {
+		if v, ok := ports[h.Eth]; ok {
+			if vlan, err := strconv.Atoi(v); err == nil && vlan > 0 {
+				toSet[vlan] = append(toSet[vlan], h)
+				continue
+			}
+		}
+		toClear = append(toClear, h)
 	}
-	return f(nodes)
+	return toSet, toClear
 }
 
 // Collect VLAN status for all nodes
-// This should return a key-value map where the key is the host name
-// and the value is the string form of the vlan value
+// This should return a key-value map where the key is the portKey of each
+// host port, primary or interface, and the value is the string form of the vlan value
 func networkVlan() (map[string]string, error) {
 	// if in dev env, just return the vlan assigned
-	// to the reservation's hosts in a map
-	if DEVMODE {
+	// to the reservation's hosts in a map unless the switches are simulated
+	if DEVMODE && !simNetworkEnabled() {
 		logger.Debug().Msg("in dev env running networkVlan(), just returning res vlans assigned to hosts")
 		reservations, err := dbReadReservationsTx(map[string]interface{}{}, map[string]time.Time{})
 		if err != nil {
@@ -72,8 +312,12 @@ func networkVlan() (map[string]string, error) {
 		}
 		result := map[string]string{}
 		for _, res := range reservations {
-			for _, host := range res.Hosts {
-				result[host.Name] = strconv.Itoa(res.Vlan)
+			for _, seg := range res.vlanSegments(res.Hosts) {
+				for _, p := range seg.Ports {
+					if key := portKey(p); key != "" {
+						result[key] = strconv.Itoa(seg.Vlan)
+					}
+				}
 			}
 		}
 		return result, nil
@@ -85,28 +329,52 @@ func networkVlan() (map[string]string, error) {
 		return nil, nil
 	}
 
-	f, ok := networkVlanFuncs[igor.Vlan.Network]
-	if !ok {
-		logger.Error().Msgf("no such network mode: %v", igor.Vlan.Network)
+	hosts, err := dbReadHostsTx(map[string]interface{}{})
+	if err != nil {
+		return nil, err
 	}
-	return f()
-}
 
-func nextVLAN() (int, error) {
-	reservations, err := dbReadReservationsTx(map[string]interface{}{}, map[string]time.Time{})
+	// read the port vlans of every switch with at least one host port
+	ports := allHostPorts(hosts)
+	portVlans, err := takeNetworkSnapshot(ports)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-OuterLoop:
-	for i := igor.Vlan.RangeMin; i <= igor.Vlan.RangeMax; i++ {
-		for _, res := range reservations {
-			if i == res.Vlan {
-				continue OuterLoop
-			}
+
+	return mergeSwitchVlans(ports, portVlans), nil
+}
+
+// mergeSwitchVlans maps each port to its vlan on its switch, keyed by portKey. Ports that weren't
+// reported by their switch are left out.
+func mergeSwitchVlans(ports []Host, portVlans map[string]map[string]string) map[string]string {
+	result := make(map[string]string)
+	for _, p := range ports {
+		key := portKey(p)
+		if key == "" {
+			continue
 		}
+		if vlan, ok := portVlans[getSwitch(p.SwitchName).Name][p.Eth]; ok {
+			result[key] = vlan
+		}
+	}
+	return result
+}
 
-		return i, nil
+// portKey identifies a switch port by its switch and port name. It is blank if the port isn't on a
+// configured switch.
+func portKey(p Host) string {
+	sw := getSwitch(p.SwitchName)
+	if sw == nil || p.Eth == "" {
+		return ""
 	}
+	return sw.Name + "|" + p.Eth
+}
 
-	return 0, fmt.Errorf("no vlans available")
+// allHostPorts returns every switch port of the hosts, the primary one and those of their interfaces.
+func allHostPorts(hosts []Host) []Host {
+	var roles []string
+	for role := range hostNicRoles(hosts) {
+		roles = append(roles, role)
+	}
+	return nicPorts(hosts, roles)
 }
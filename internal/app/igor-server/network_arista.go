@@ -20,9 +20,9 @@ import (
 
 func init() {
 	if networkSetFuncs == nil {
-		networkSetFuncs = make(map[string]func([]Host, int) error)
-		networkClearFuncs = make(map[string]func([]Host) error)
-		networkVlanFuncs = make(map[string]func() (map[string]string, error))
+		networkSetFuncs = make(map[string]func(*SwitchConfig, []Host, int) error)
+		networkClearFuncs = make(map[string]func(*SwitchConfig, []Host) error)
+		networkVlanFuncs = make(map[string]func(*SwitchConfig) (map[string]string, error))
 	}
 	networkSetFuncs["arista"] = aristaSet
 	networkClearFuncs["arista"] = aristaClear
@@ -31,21 +31,40 @@ func init() {
 
 var aristaClearTemplate = `enable
 configure terminal
-interface {{ $.Eth }}
+{{- range $.Eths }}
+interface {{ . }}
 no switchport access vlan
-switchport mode access`
+switchport mode access
+{{- end }}`
 
 var aristaSetTemplate = `enable
 configure terminal
-interface {{ $.Eth }}
+{{- range $.Eths }}
+interface {{ . }}
 switchport mode dot1q-tunnel
-switchport access vlan {{ $.VLAN }}`
+switchport access vlan {{ $.VLAN }}
+{{- end }}`
 
+// AristaConfig holds the values used to fill in the arista command templates. All ports of a single
+// switch are configured in one batch.
 type AristaConfig struct {
-	Eth  string
+	Eths []string
 	VLAN int
 }
 
+// hostPorts returns the switch ports of the given hosts, skipping any host without one.
+func hostPorts(hosts []Host) []string {
+	var ports []string
+	for _, h := range hosts {
+		if h.Eth == "" {
+			logger.Warn().Msgf("host %s has no switch port set; skipping network configuration", h.Name)
+			continue
+		}
+		ports = append(ports, h.Eth)
+	}
+	return ports
+}
+
 // Issue the given commands via the specified URL, username, and password.
 func aristaJSONRPC(user, password, URL string, commands []string) (map[string]interface{}, error) {
 	logger.Debug().Msgf("url for arista: %v", URL)
@@ -99,74 +118,68 @@ func aristaJSONRPC(user, password, URL string, commands []string) (map[string]in
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshaling arista response body to json: %v - body received: %v", err, string(body))
 	}
+	// eAPI stops at the first failed command and reports it in the error block
+	if eapiErr, ok := result["error"].(map[string]interface{}); ok {
+		return nil, fmt.Errorf("arista command failed: %v", eapiErr["message"])
+	}
 
 	return result, nil
 }
 
-func aristaSet(hosts []Host, vlan int) error {
-	t := template.Must(template.New("set").Parse(aristaSetTemplate))
+// aristaRun fills in the template with the host ports and sends the result to the switch as a single batch.
+func aristaRun(sw *SwitchConfig, tmpl string, hosts []Host, vlan int) (map[string]interface{}, error) {
+	t := template.Must(template.New("arista").Parse(tmpl))
 
-	for _, h := range hosts {
-		var b bytes.Buffer
-		c := &AristaConfig{
-			Eth:  h.Eth,
-			VLAN: vlan,
-		}
-		err := t.Execute(&b, c)
-		if err != nil {
-			return err
-		}
-		// now split b into strings with newlines
-		commands := strings.Split(b.String(), "\n")
-		logger.Debug().Msgf("aristaSet commands being sent: %v", commands)
-
-		result, err := aristaJSONRPC(igor.Vlan.NetworkUser, igor.Vlan.NetworkPassword, igor.Vlan.NetworkURL, commands)
-		if err != nil {
-			return err
-		}
-		logger.Debug().Msgf("aristaSet response received: %v", result)
+	c := &AristaConfig{
+		Eths: hostPorts(hosts),
+		VLAN: vlan,
+	}
+	if len(c.Eths) == 0 {
+		return nil, nil
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, c); err != nil {
+		return nil, err
 	}
+	// now split b into strings with newlines
+	commands := strings.Split(b.String(), "\n")
+	logger.Debug().Msgf("commands being sent to switch %s: %v", sw.Name, commands)
 
-	return nil
+	return aristaJSONRPC(sw.NetworkUser, sw.NetworkPassword, sw.NetworkURL, commands)
 }
 
-func aristaClear(hosts []Host) error {
-	t := template.Must(template.New("set").Parse(aristaClearTemplate))
-
-	for _, h := range hosts {
-		var b bytes.Buffer
-		c := &AristaConfig{
-			Eth: h.Eth,
-		}
-		err := t.Execute(&b, c)
-		if err != nil {
-			return err
-		}
-		// now split b into strings with newlines
-		commands := strings.Split(b.String(), "\n")
-		logger.Debug().Msgf("aristaClear commands being sent: %v", commands)
-
-		result, err := aristaJSONRPC(igor.Vlan.NetworkUser, igor.Vlan.NetworkPassword, igor.Vlan.NetworkURL, commands)
-		if err != nil {
-			return err
-		}
-		logger.Debug().Msgf("aristaClear response received: %v", result)
+func aristaSet(sw *SwitchConfig, hosts []Host, vlan int) error {
+	result, err := aristaRun(sw, aristaSetTemplate, hosts, vlan)
+	if err != nil {
+		return err
 	}
+	logger.Debug().Msgf("aristaSet response received from switch %s: %v", sw.Name, result)
+	return nil
+}
 
+func aristaClear(sw *SwitchConfig, hosts []Host) error {
+	result, err := aristaRun(sw, aristaClearTemplate, hosts, 0)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Msgf("aristaClear response received from switch %s: %v", sw.Name, result)
 	return nil
 }
 
-func aristaVlan() (map[string]string, error) {
+// aristaVlan returns the vlan of every switch port in the configured vlan range.
+func aristaVlan(sw *SwitchConfig) (map[string]string, error) {
 	// get vlan mappings for the range we care about
 	commands := []string{fmt.Sprintf("show vlan %v-%v", igor.Vlan.RangeMin, igor.Vlan.RangeMax)}
-	res, err := aristaJSONRPC(igor.Vlan.NetworkUser, igor.Vlan.NetworkPassword, igor.Vlan.NetworkURL, commands)
-	result := make(map[string]string)
+	res, err := aristaJSONRPC(sw.NetworkUser, sw.NetworkPassword, sw.NetworkURL, commands)
 	if err != nil {
-		logger.Error().Msgf("error sending command to vlan service: %v", err.Error())
+		logger.Error().Msgf("error sending command to switch %s: %v", sw.Name, err.Error())
 		return nil, err
 	}
 	// parse out the block of data we actually want from the response
-	res2 := res["result"].([]interface{})
+	res2, ok := res["result"].([]interface{})
+	if !ok || len(res2) == 0 {
+		return nil, fmt.Errorf("unexpected response from switch %s: %v", sw.Name, res)
+	}
 	res3 := res2[0].(map[string]interface{})
 	data := res3["vlans"].(map[string]interface{})
 	ethMap := make(map[string]string)
@@ -179,19 +192,6 @@ func aristaVlan() (map[string]string, error) {
 			ethMap[eth] = key
 		}
 	}
-	keys := make([]string, len(ethMap))
-	i := 0
-	for k := range ethMap {
-		keys[i] = k
-		i++
-	}
-	hosts, err := dbReadHostsTx(map[string]interface{}{"eth": keys})
-	if err != nil {
-		return nil, err
-	}
-	for _, h := range hosts {
-		result[h.Name] = ethMap[h.Eth]
-	}
 
-	return result, nil
+	return ethMap, nil
 }
@@ -0,0 +1,233 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+func init() {
+	if networkSetFuncs == nil {
+		networkSetFuncs = make(map[string]func(*SwitchConfig, []Host, int) error)
+		networkClearFuncs = make(map[string]func(*SwitchConfig, []Host) error)
+		networkVlanFuncs = make(map[string]func(*SwitchConfig) (map[string]string, error))
+	}
+	networkSetFuncs["nxapi"] = nxapiSet
+	networkClearFuncs["nxapi"] = nxapiClear
+	networkVlanFuncs["nxapi"] = nxapiVlan
+}
+
+// NX-API runs configuration commands without entering configure mode first.
+var nxapiClearTemplate = `{{- range $.Eths }}
+interface {{ . }}
+no switchport access vlan
+switchport mode access
+{{- end }}`
+
+var nxapiSetTemplate = `{{- range $.Eths }}
+interface {{ . }}
+switchport
+switchport mode dot1q-tunnel
+switchport access vlan {{ $.VLAN }}
+{{- end }}`
+
+// nxapiRequest is a single command in an NX-API JSON-RPC request.
+type nxapiRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Cmd     string `json:"cmd"`
+		Version int    `json:"version"`
+	} `json:"params"`
+	ID int `json:"id"`
+}
+
+// nxapiResponse is the response to a single command in an NX-API JSON-RPC request.
+type nxapiResponse struct {
+	Result *struct {
+		Body json.RawMessage `json:"body"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Msg string `json:"msg"`
+		} `json:"data"`
+	} `json:"error"`
+	ID int `json:"id"`
+}
+
+// nxapiJSONRPC sends the commands to a Cisco NX-OS switch as one NX-API JSON-RPC request and returns
+// the body of each command's result. An error is returned if any command fails.
+func nxapiJSONRPC(sw *SwitchConfig, commands []string) ([]json.RawMessage, error) {
+
+	reqs := make([]nxapiRequest, 0, len(commands))
+	for i, c := range commands {
+		r := nxapiRequest{JSONRPC: "2.0", Method: "cli", ID: i + 1}
+		r.Params.Cmd = c
+		r.Params.Version = 1
+		reqs = append(reqs, r)
+	}
+	data, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %v", err)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+			TLSHandshakeTimeout: time.Second * 5,
+		},
+		Timeout: time.Second * 60,
+	}
+
+	URL := sw.NetworkURL
+	if !strings.Contains(URL, "://") {
+		URL = "http://" + URL
+	}
+	logger.Debug().Msgf("url for nxapi: %v", URL)
+	req, err := http.NewRequest(http.MethodPost, URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json-rpc")
+	req.SetBasicAuth(sw.NetworkUser, sw.NetworkPassword)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("post failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("readall: %v", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("nxapi login failed for user %s", sw.NetworkUser)
+	}
+
+	// a request with a single command gets back a single object rather than a list
+	var results []nxapiResponse
+	if len(commands) == 1 && !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var single nxapiResponse
+		err = json.Unmarshal(body, &single)
+		results = append(results, single)
+	} else {
+		err = json.Unmarshal(body, &results)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling nxapi response body to json: %v - body received: %v", err, string(body))
+	}
+
+	bodies := make([]json.RawMessage, len(commands))
+	for _, r := range results {
+		if r.Error != nil {
+			msg := r.Error.Message
+			if r.Error.Data.Msg != "" {
+				msg += " - " + strings.TrimSpace(r.Error.Data.Msg)
+			}
+			cmd := ""
+			if r.ID > 0 && r.ID <= len(commands) {
+				cmd = commands[r.ID-1]
+			}
+			return nil, fmt.Errorf("command '%s' failed: %s", cmd, msg)
+		}
+		if r.ID > 0 && r.ID <= len(commands) && r.Result != nil {
+			bodies[r.ID-1] = r.Result.Body
+		}
+	}
+	return bodies, nil
+}
+
+// nxapiRun fills in the template with the host ports and sends the result to the switch as a single batch.
+func nxapiRun(sw *SwitchConfig, tmpl string, hosts []Host, vlan int) error {
+	t := template.Must(template.New("nxapi").Parse(tmpl))
+
+	c := &AristaConfig{
+		Eths: hostPorts(hosts),
+		VLAN: vlan,
+	}
+	if len(c.Eths) == 0 {
+		return nil
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, c); err != nil {
+		return err
+	}
+	commands := strings.Split(strings.TrimSpace(b.String()), "\n")
+	logger.Debug().Msgf("commands being sent to switch %s: %v", sw.Name, commands)
+
+	_, err := nxapiJSONRPC(sw, commands)
+	return err
+}
+
+func nxapiSet(sw *SwitchConfig, hosts []Host, vlan int) error {
+	return nxapiRun(sw, nxapiSetTemplate, hosts, vlan)
+}
+
+func nxapiClear(sw *SwitchConfig, hosts []Host) error {
+	return nxapiRun(sw, nxapiClearTemplate, hosts, 0)
+}
+
+// nxapiVlan returns the access vlan of every switch port in the configured vlan range. Ports are
+// reported both in the long (Ethernet1/5) and short (Eth1/5) form so either can be used as a host's eth.
+func nxapiVlan(sw *SwitchConfig) (map[string]string, error) {
+
+	bodies, err := nxapiJSONRPC(sw, []string{"show interface switchport"})
+	if err != nil {
+		logger.Error().Msgf("error sending command to switch %s: %v", sw.Name, err.Error())
+		return nil, err
+	}
+
+	var data struct {
+		Table struct {
+			Rows json.RawMessage `json:"ROW_interface"`
+		} `json:"TABLE_interface"`
+	}
+	if err = json.Unmarshal(bodies[0], &data); err != nil {
+		return nil, fmt.Errorf("unexpected response from switch %s: %v", sw.Name, err)
+	}
+
+	// NX-OS returns a single object instead of a list when there is only one row
+	var rows []map[string]interface{}
+	if err = json.Unmarshal(data.Table.Rows, &rows); err != nil {
+		var row map[string]interface{}
+		if err = json.Unmarshal(data.Table.Rows, &row); err != nil {
+			return nil, fmt.Errorf("unexpected response from switch %s: %v", sw.Name, err)
+		}
+		rows = append(rows, row)
+	}
+
+	ethMap := make(map[string]string)
+	for _, row := range rows {
+		port, _ := row["interface"].(string)
+		var vlan int
+		switch v := row["access_vlan"].(type) {
+		case float64:
+			vlan = int(v)
+		case string:
+			vlan, _ = strconv.Atoi(v)
+		}
+		if port == "" || vlan < igor.Vlan.RangeMin || vlan > igor.Vlan.RangeMax {
+			continue
+		}
+		ethMap[port] = strconv.Itoa(vlan)
+		ethMap[strings.Replace(port, "Ethernet", "Eth", 1)] = strconv.Itoa(vlan)
+	}
+
+	return ethMap, nil
+}
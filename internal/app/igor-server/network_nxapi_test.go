@@ -0,0 +1,103 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMockNxapi starts a mock NX-API server that records the commands it receives and answers
+// "show interface switchport" with the given rows. Any command in failCmds returns an error.
+func newMockNxapi(t *testing.T, rows string, failCmds map[string]bool) (*httptest.Server, *[][]string) {
+	var batches [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var reqs []nxapiRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		var cmds []string
+		var resp []map[string]interface{}
+		for _, req := range reqs {
+			cmds = append(cmds, req.Params.Cmd)
+			switch {
+			case failCmds[req.Params.Cmd]:
+				resp = append(resp, map[string]interface{}{"id": req.ID, "error": map[string]interface{}{
+					"code": -32602, "message": "Invalid params", "data": map[string]string{"msg": "% Invalid command"}}})
+			case req.Params.Cmd == "show interface switchport":
+				resp = append(resp, map[string]interface{}{"id": req.ID, "result": map[string]interface{}{
+					"body": json.RawMessage(`{"TABLE_interface": {"ROW_interface": ` + rows + `}}`)}})
+			default:
+				resp = append(resp, map[string]interface{}{"id": req.ID, "result": nil})
+			}
+		}
+		batches = append(batches, cmds)
+		if len(reqs) == 1 {
+			_ = json.NewEncoder(w).Encode(resp[0])
+		} else {
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	}))
+	return srv, &batches
+}
+
+func TestNxapiSetClear(t *testing.T) {
+	srv, batches := newMockNxapi(t, "[]", map[string]bool{"interface Eth1/9": true})
+	defer srv.Close()
+	sw := &SwitchConfig{Name: "nexus1", Network: "nxapi", NetworkUser: "admin", NetworkPassword: "secret", NetworkURL: srv.URL + "/ins"}
+
+	hosts := []Host{{Name: "kn1", Eth: "Eth1/1"}, {Name: "kn2", Eth: "Eth1/2"}, {Name: "kn3"}}
+	assert.NoError(t, nxapiSet(sw, hosts, 150))
+	assert.Equal(t, [][]string{{
+		"interface Eth1/1", "switchport", "switchport mode dot1q-tunnel", "switchport access vlan 150",
+		"interface Eth1/2", "switchport", "switchport mode dot1q-tunnel", "switchport access vlan 150",
+	}}, *batches)
+
+	assert.NoError(t, nxapiClear(sw, hosts[:1]))
+	assert.Equal(t, []string{"interface Eth1/1", "no switchport access vlan", "switchport mode access"}, (*batches)[1])
+
+	err := nxapiSet(sw, []Host{{Name: "kn9", Eth: "Eth1/9"}}, 150)
+	assert.ErrorContains(t, err, "Invalid command")
+
+	sw.NetworkPassword = "wrong"
+	assert.Error(t, nxapiSet(sw, hosts, 150))
+}
+
+func TestNxapiVlan(t *testing.T) {
+	savedMin, savedMax := igor.Vlan.RangeMin, igor.Vlan.RangeMax
+	defer func() { igor.Vlan.RangeMin, igor.Vlan.RangeMax = savedMin, savedMax }()
+	igor.Vlan.RangeMin, igor.Vlan.RangeMax = 100, 200
+
+	rows := `[
+		{"interface": "Ethernet1/1", "oper_mode": "dot1q-tunnel", "access_vlan": 150},
+		{"interface": "Ethernet1/2", "oper_mode": "access", "access_vlan": "1"},
+		{"interface": "Ethernet1/3", "oper_mode": "dot1q-tunnel", "access_vlan": "101"}
+	]`
+	srv, _ := newMockNxapi(t, rows, nil)
+	defer srv.Close()
+	sw := &SwitchConfig{Name: "nexus1", NetworkUser: "admin", NetworkPassword: "secret", NetworkURL: srv.URL}
+
+	ports, err := nxapiVlan(sw)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Ethernet1/1": "150", "Eth1/1": "150",
+		"Ethernet1/3": "101", "Eth1/3": "101",
+	}, ports)
+
+	// a single row comes back as an object rather than a list
+	srv2, _ := newMockNxapi(t, `{"interface": "Ethernet1/4", "access_vlan": 120}`, nil)
+	defer srv2.Close()
+	sw.NetworkURL = srv2.URL
+	ports, err = nxapiVlan(sw)
+	assert.NoError(t, err)
+	assert.Equal(t, "120", ports["Eth1/4"])
+}
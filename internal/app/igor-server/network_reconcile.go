@@ -0,0 +1,277 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"igor2/internal/pkg/common"
+)
+
+var (
+	// vlanSyncStatus holds the outcome of the most recent reconcile run
+	vlanSyncStatus   *common.VlanSyncStatusData
+	vlanSyncStatusMU sync.Mutex
+)
+
+// vlanReconcileManager periodically compares the vlan of every host's switch ports with the vlans of the
+// reservation the host is in, alerting on drift and repairing it if vlan.reconcile.force is set.
+func vlanReconcileManager() {
+	defer wg.Done()
+	countdown := NewScheduleTimer(time.Minute * time.Duration(igor.Vlan.Reconcile.Interval))
+	for {
+		select {
+		case <-shutdownChan:
+			logger.Info().Msg("stopping vlan reconcile manager")
+			if !countdown.t.Stop() {
+				<-countdown.t.C
+			}
+			return
+		case checkTime := <-countdown.t.C:
+			logger.Debug().Msgf("doing vlan reconcile - %v", checkTime.Format(time.RFC3339))
+			if err := reconcileVlans(checkTime, igor.Vlan.Reconcile.Force); err != nil {
+				logger.Error().Msgf("vlan reconcile failed: %v", err)
+			}
+			countdown.reset()
+		}
+	}
+}
+
+// expectedPortVlans returns the switch ports to compare and the vlan each should be in, keyed by
+// portKey, along with the name of the reservation each belongs to. The primary port of every host is
+// compared and should have no vlan unless an installed reservation puts it in one. Interface ports are
+// compared while a reservation puts them in its vlans. Hosts in a reservation that has started but
+// isn't installed yet are left out since they are about to change.
+func expectedPortVlans(hosts []Host, now time.Time) ([]Host, map[string]string, map[string]string) {
+	var ports []Host
+	expected := make(map[string]string)
+	resNames := make(map[string]string)
+	add := func(p Host, vlan int, resName string) {
+		key := portKey(p)
+		if key == "" {
+			return
+		}
+		if _, seen := expected[key]; !seen {
+			ports = append(ports, p)
+		}
+		expected[key] = ""
+		if vlan > 0 {
+			expected[key] = strconv.Itoa(vlan)
+		}
+		if resName != "" {
+			resNames[key] = resName
+		}
+	}
+
+hostLoop:
+	for _, h := range hosts {
+		var active *Reservation
+		for i := range h.Reservations {
+			if !h.Reservations[i].IsActive(now) {
+				continue
+			}
+			if !h.Reservations[i].Installed {
+				continue hostLoop
+			}
+			active = &h.Reservations[i]
+			break
+		}
+		if active == nil {
+			add(h, 0, "")
+			continue
+		}
+		// the primary port stays out of the vlan if the reservation left it out
+		add(h, 0, active.Name)
+		for _, seg := range active.vlanSegments([]Host{h}) {
+			for _, p := range seg.Ports {
+				add(p, seg.Vlan, active.Name)
+			}
+		}
+	}
+	return ports, expected, resNames
+}
+
+// findVlanDrift compares the expected vlan of each port with what its switch reports. Drift first seen
+// in an earlier run keeps its original Since time.
+func findVlanDrift(ports []Host, expected, resNames, actual map[string]string, prev []common.VlanDriftData, now time.Time) []common.VlanDriftData {
+
+	prevSince := make(map[string]time.Time, len(prev))
+	for _, d := range prev {
+		prevSince[d.Switch+"|"+d.Port+"|"+d.Expected+"|"+d.Actual] = d.Since
+	}
+
+	var drift []common.VlanDriftData
+	for _, p := range ports {
+		key := portKey(p)
+		exp, ok := expected[key]
+		if !ok {
+			continue
+		}
+		act := actual[key]
+		if act == "0" {
+			act = ""
+		}
+		if exp == act {
+			continue
+		}
+		d := common.VlanDriftData{
+			Host:        p.Name,
+			Switch:      getSwitch(p.SwitchName).Name,
+			Port:        p.Eth,
+			Reservation: resNames[key],
+			Expected:    exp,
+			Actual:      act,
+			Since:       now,
+		}
+		if since, seen := prevSince[key+"|"+d.Expected+"|"+d.Actual]; seen {
+			d.Since = since
+		}
+		drift = append(drift, d)
+	}
+	return drift
+}
+
+// repairVlanDrift moves each drifted port to its expected vlan, clearing ports that should have none.
+// Ports that should share a vlan are changed together.
+func repairVlanDrift(drift []common.VlanDriftData, portsByKey map[string]Host) {
+
+	groups := make(map[string][]int)
+	for i, d := range drift {
+		groups[d.Expected] = append(groups[d.Expected], i)
+	}
+
+	for exp, idx := range groups {
+		var ports []Host
+		for _, i := range idx {
+			ports = append(ports, portsByKey[drift[i].Switch+"|"+drift[i].Port])
+		}
+		var err error
+		if exp == "" {
+			err = networkClear(ports)
+		} else {
+			vlan, _ := strconv.Atoi(exp)
+			err = networkSet(ports, vlan)
+		}
+		for _, i := range idx {
+			if err != nil {
+				drift[i].RepairError = err.Error()
+			} else {
+				drift[i].Repaired = true
+			}
+		}
+	}
+}
+
+// reconcileVlans compares switch port vlans with reservation vlans, records the result for
+// GET /sync/status and alerts admins about drift not seen in the previous run.
+func reconcileVlans(checkTime time.Time, force bool) error {
+
+	// keep reservations from being installed or removed while ports are compared and repaired
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	status := &common.VlanSyncStatusData{
+		Time:     checkTime,
+		Interval: igor.Vlan.Reconcile.Interval,
+		Force:    force,
+	}
+
+	vlanSyncStatusMU.Lock()
+	var prev []common.VlanDriftData
+	if vlanSyncStatus != nil {
+		prev = vlanSyncStatus.Drift
+	}
+	vlanSyncStatusMU.Unlock()
+
+	err := func() error {
+		hosts, err := dbReadHostsTx(map[string]interface{}{})
+		if err != nil {
+			return err
+		}
+		actual, err := networkVlan()
+		if err != nil {
+			return err
+		}
+
+		ports, expected, resNames := expectedPortVlans(hosts, checkTime)
+		status.Checked = len(ports)
+		status.Drift = findVlanDrift(ports, expected, resNames, actual, prev, checkTime)
+
+		if force && len(status.Drift) > 0 {
+			portsByKey := make(map[string]Host, len(ports))
+			for _, p := range ports {
+				portsByKey[portKey(p)] = p
+			}
+			repairVlanDrift(status.Drift, portsByKey)
+		}
+		return nil
+	}()
+	if err != nil {
+		status.Error = err.Error()
+		// carry drift forward so it isn't reported as new once the switches can be read again
+		status.Drift = prev
+	}
+
+	vlanSyncStatusMU.Lock()
+	vlanSyncStatus = status
+	vlanSyncStatusMU.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	newDrift := make(map[string][]string)
+	for _, d := range status.Drift {
+		if !d.Since.Equal(checkTime) {
+			continue
+		}
+		info := fmt.Sprintf("port %s expected vlan %s, switch %s reports %s", d.Port, vlanOrNone(d.Expected), d.Switch, vlanOrNone(d.Actual))
+		if d.Repaired {
+			info += ", repaired"
+		} else if d.RepairError != "" {
+			info += ", repair failed: " + d.RepairError
+		}
+		newDrift[d.Host] = append(newDrift[d.Host], info)
+	}
+	if len(status.Drift) > 0 {
+		names := make([]string, 0, len(status.Drift))
+		for _, d := range status.Drift {
+			names = append(names, d.Host+" "+d.Port)
+		}
+		sort.Strings(names)
+		logger.Warn().Msgf("vlan drift found on %d port(s): %s", len(names), strings.Join(names, ","))
+	}
+
+	if len(newDrift) > 0 && igor.Vlan.Reconcile.NotifyAdmins {
+		if hostEvent := makeHostNotifyEvent(EmailVlanDrift, "", nil, newDrift); hostEvent != nil {
+			hostNotifyChan <- *hostEvent
+		}
+	}
+	return nil
+}
+
+func vlanOrNone(vlan string) string {
+	if vlan == "" {
+		return "(none)"
+	}
+	return vlan
+}
+
+// getVlanSyncStatus returns a copy of the most recent reconcile result, or nil if none has run yet.
+func getVlanSyncStatus() *common.VlanSyncStatusData {
+	vlanSyncStatusMU.Lock()
+	defer vlanSyncStatusMU.Unlock()
+	if vlanSyncStatus == nil {
+		return nil
+	}
+	status := *vlanSyncStatus
+	status.Drift = append([]common.VlanDriftData{}, vlanSyncStatus.Drift...)
+	return &status
+}
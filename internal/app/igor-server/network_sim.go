@@ -0,0 +1,144 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NetworkSim is the name of the simulated switch driver. It keeps port vlans in memory so the vlan code
+// paths can run in development and in tests without switch hardware. Port state is lost on restart.
+const NetworkSim = "sim"
+
+var (
+	// simPorts holds the vlan of each port of the simulated switches, keyed by switch name then port.
+	simPorts   = make(map[string]map[string]string)
+	simPortsMU sync.Mutex
+)
+
+func init() {
+	if networkSetFuncs == nil {
+		networkSetFuncs = make(map[string]func(*SwitchConfig, []Host, int) error)
+		networkClearFuncs = make(map[string]func(*SwitchConfig, []Host) error)
+		networkVlanFuncs = make(map[string]func(*SwitchConfig) (map[string]string, error))
+	}
+	networkSetFuncs[NetworkSim] = simSet
+	networkClearFuncs[NetworkSim] = simClear
+	networkVlanFuncs[NetworkSim] = simVlan
+	networkValidateFuncs[NetworkSim] = simValidate
+}
+
+// simValidate checks the failure and latency settings of a simulated switch.
+func simValidate(sw *SwitchConfig) error {
+	if sw.SimLatency < 0 {
+		return fmt.Errorf("simLatency cannot be negative")
+	}
+	if sw.SimFailRate < 0 || sw.SimFailRate > 1 {
+		return fmt.Errorf("simFailRate must be between 0 and 1")
+	}
+	return nil
+}
+
+// simNetworkEnabled returns true if every configured switch is simulated. The network functions skip
+// their DEVMODE shortcuts in that case so the simulated switches are used.
+func simNetworkEnabled() bool {
+	if len(igor.Vlan.Switches) == 0 {
+		return false
+	}
+	for _, sw := range igor.Vlan.Switches {
+		if sw.Network != NetworkSim {
+			return false
+		}
+	}
+	return true
+}
+
+// simRun applies the change to each port of the hosts in order, after waiting for the configured
+// latency. A command set fails as a whole at the configured rate before anything is changed. A port in
+// simFailPorts fails the command set once the ports before it have been changed, the same way a real
+// switch can stop partway through a batch.
+func simRun(sw *SwitchConfig, hosts []Host, change func(ports map[string]string, eth string)) error {
+	if sw.SimLatency > 0 {
+		time.Sleep(time.Duration(sw.SimLatency) * time.Millisecond)
+	}
+	if sw.SimFailRate > 0 && rand.Float64() < sw.SimFailRate {
+		return fmt.Errorf("simulated command failure")
+	}
+
+	failPorts := make(map[string]bool, len(sw.SimFailPorts))
+	for _, p := range sw.SimFailPorts {
+		failPorts[p] = true
+	}
+
+	simPortsMU.Lock()
+	defer simPortsMU.Unlock()
+	ports, ok := simPorts[sw.Name]
+	if !ok {
+		ports = make(map[string]string)
+		simPorts[sw.Name] = ports
+	}
+	for _, h := range hosts {
+		if failPorts[h.Eth] {
+			return fmt.Errorf("simulated failure on port %s", h.Eth)
+		}
+		change(ports, h.Eth)
+	}
+	return nil
+}
+
+func simSet(sw *SwitchConfig, hosts []Host, vlan int) error {
+	return simRun(sw, hosts, func(ports map[string]string, eth string) {
+		ports[eth] = strconv.Itoa(vlan)
+	})
+}
+
+func simClear(sw *SwitchConfig, hosts []Host) error {
+	return simRun(sw, hosts, func(ports map[string]string, eth string) {
+		delete(ports, eth)
+	})
+}
+
+func simVlan(sw *SwitchConfig) (map[string]string, error) {
+	if sw.SimLatency > 0 {
+		time.Sleep(time.Duration(sw.SimLatency) * time.Millisecond)
+	}
+	if sw.SimFailRate > 0 && rand.Float64() < sw.SimFailRate {
+		return nil, fmt.Errorf("simulated command failure")
+	}
+
+	simPortsMU.Lock()
+	defer simPortsMU.Unlock()
+	result := make(map[string]string, len(simPorts[sw.Name]))
+	for port, vlan := range simPorts[sw.Name] {
+		result[port] = vlan
+	}
+	return result, nil
+}
+
+// simSetPort puts a port of a simulated switch directly into the given vlan, or clears it if vlan is
+// blank, without going through the driver. It stands in for changes made on the switch by hand.
+func simSetPort(switchName, eth, vlan string) {
+	simPortsMU.Lock()
+	defer simPortsMU.Unlock()
+	if simPorts[switchName] == nil {
+		simPorts[switchName] = make(map[string]string)
+	}
+	if vlan == "" {
+		delete(simPorts[switchName], eth)
+	} else {
+		simPorts[switchName][eth] = vlan
+	}
+}
+
+// simReset clears the port state of every simulated switch.
+func simReset() {
+	simPortsMU.Lock()
+	defer simPortsMU.Unlock()
+	simPorts = make(map[string]map[string]string)
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useSimSwitches configures the given simulated switches with empty port state for the length of the test.
+func useSimSwitches(t *testing.T, switches ...SwitchConfig) {
+	savedNetwork, savedSwitches := igor.Vlan.Network, igor.Vlan.Switches
+	t.Cleanup(func() {
+		igor.Vlan.Network, igor.Vlan.Switches = savedNetwork, savedSwitches
+		simReset()
+	})
+	for i := range switches {
+		switches[i].Network = NetworkSim
+		assert.NoError(t, validateSwitchConfig(&switches[i]))
+	}
+	igor.Vlan.Network = NetworkSim
+	igor.Vlan.Switches = switches
+	simReset()
+}
+
+func simSnapshot(t *testing.T, hosts []Host) networkSnapshot {
+	snap, err := takeNetworkSnapshot(hosts)
+	assert.NoError(t, err)
+	return snap
+}
+
+func TestSimValidate(t *testing.T) {
+	assert.NoError(t, simValidate(&SwitchConfig{SimLatency: 5, SimFailRate: 0.5}))
+	assert.Error(t, simValidate(&SwitchConfig{SimLatency: -1}))
+	assert.Error(t, simValidate(&SwitchConfig{SimFailRate: 1.5}))
+
+	useSimSwitches(t, SwitchConfig{Name: "leaf1"})
+	assert.True(t, simNetworkEnabled())
+	igor.Vlan.Switches = append(igor.Vlan.Switches, SwitchConfig{Name: "leaf2", Network: "arista"})
+	assert.False(t, simNetworkEnabled())
+}
+
+func TestSimReservationNetwork(t *testing.T) {
+	useSimSwitches(t, SwitchConfig{Name: "leaf1"}, SwitchConfig{Name: "leaf2"})
+
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Interfaces: []HostInterface{{Role: "data", Eth: "Et1", SwitchName: "leaf2"}}},
+		{Name: "kn2", Eth: "Et2", Interfaces: []HostInterface{{Role: "data", Eth: "Et2", SwitchName: "leaf2"}}},
+	}
+	res := &Reservation{Vlan: 100, Hosts: hosts, Networks: []ResNetwork{
+		{Name: "ctrl", Vlan: 100, NicRoles: NicRolePrimary},
+		{Name: "data", Vlan: 101, NicRoles: "data"},
+	}}
+
+	snap, err := res.networkSet(res.Hosts)
+	assert.NoError(t, err)
+	assert.Equal(t, networkSnapshot{"leaf1": {}, "leaf2": {}}, snap)
+	now := simSnapshot(t, res.allVlanPorts(hosts))
+	assert.Equal(t, map[string]string{"Et1": "100", "Et2": "100"}, now["leaf1"])
+	assert.Equal(t, map[string]string{"Et1": "101", "Et2": "101"}, now["leaf2"])
+
+	// undoing the install puts every port back the way it was
+	assert.NoError(t, networkRollback(res.allVlanPorts(hosts), snap))
+	now = simSnapshot(t, res.allVlanPorts(hosts))
+	assert.Empty(t, now["leaf1"])
+	assert.Empty(t, now["leaf2"])
+
+	// dropping a host clears only its ports
+	_, err = res.networkSet(res.Hosts)
+	assert.NoError(t, err)
+	assert.NoError(t, networkClear(res.allVlanPorts(hosts[1:])))
+	now = simSnapshot(t, res.allVlanPorts(hosts))
+	assert.Equal(t, map[string]string{"Et1": "100"}, now["leaf1"])
+	assert.Equal(t, map[string]string{"Et1": "101"}, now["leaf2"])
+}
+
+func TestSimFailureRollsBack(t *testing.T) {
+	useSimSwitches(t, SwitchConfig{Name: "leaf1"}, SwitchConfig{Name: "leaf2", SimFailPorts: []string{"Et2"}})
+	simSetPort("leaf1", "Et1", "150")
+
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", SwitchName: "leaf1", Interfaces: []HostInterface{{Role: "data", Eth: "Et1", SwitchName: "leaf2"}}},
+		{Name: "kn2", Eth: "Et2", SwitchName: "leaf1", Interfaces: []HostInterface{{Role: "data", Eth: "Et2", SwitchName: "leaf2"}}},
+	}
+	res := &Reservation{Vlan: 100, Hosts: hosts, Networks: []ResNetwork{
+		{Name: "ctrl", Vlan: 100, NicRoles: NicRolePrimary},
+		{Name: "data", Vlan: 101, NicRoles: "data"},
+	}}
+
+	// the data network fails on leaf2, so the control network already set on leaf1 is undone as well
+	_, err := res.networkSet(res.Hosts)
+	assert.Error(t, err)
+	now := simSnapshot(t, res.allVlanPorts(hosts))
+	assert.Equal(t, map[string]string{"Et1": "150"}, now["leaf1"])
+	assert.Empty(t, now["leaf2"])
+
+	// failures of a whole command set leave everything untouched
+	igor.Vlan.Switches[1].SimFailPorts = nil
+	igor.Vlan.Switches[1].SimFailRate = 1
+	_, err = res.networkSet(res.Hosts)
+	assert.Error(t, err)
+	_, err = takeNetworkSnapshot(res.allVlanPorts(hosts))
+	assert.Error(t, err)
+	igor.Vlan.Switches[1].SimFailRate = 0
+	assert.Equal(t, map[string]string{"Et1": "150"}, simSnapshot(t, hosts)["leaf1"])
+}
+
+func TestSimLatency(t *testing.T) {
+	useSimSwitches(t, SwitchConfig{Name: "leaf1", SimLatency: 20})
+
+	start := time.Now()
+	assert.NoError(t, networkSet([]Host{{Name: "kn1", Eth: "Et1"}}, 100))
+	// one read for the snapshot and one command set
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestSimDriftRepair(t *testing.T) {
+	useSimSwitches(t, SwitchConfig{Name: "leaf1"})
+
+	now := time.Now()
+	res := Reservation{Name: "r1", Vlan: 100, Installed: true, Start: now.Add(-time.Hour), End: now.Add(time.Hour)}
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Reservations: []Reservation{res}},
+		{Name: "kn2", Eth: "Et2", Reservations: []Reservation{res}},
+		{Name: "kn3", Eth: "Et3"},
+	}
+	assert.NoError(t, networkSet(hosts[:2], 100))
+
+	// someone moves kn2 by hand and puts the unreserved kn3 in a vlan
+	simSetPort("leaf1", "Et2", "120")
+	simSetPort("leaf1", "Et3", "130")
+
+	ports, expected, resNames := expectedPortVlans(hosts, now)
+	actual := mergeSwitchVlans(ports, simSnapshot(t, ports))
+	drift := findVlanDrift(ports, expected, resNames, actual, nil, now)
+	assert.Len(t, drift, 2)
+	assert.Equal(t, "kn2", drift[0].Host)
+	assert.Equal(t, "100", drift[0].Expected)
+	assert.Equal(t, "120", drift[0].Actual)
+	assert.Equal(t, "kn3", drift[1].Host)
+
+	portsByKey := map[string]Host{}
+	for _, p := range ports {
+		portsByKey[portKey(p)] = p
+	}
+	repairVlanDrift(drift, portsByKey)
+	assert.True(t, drift[0].Repaired && drift[1].Repaired)
+	actual = mergeSwitchVlans(ports, simSnapshot(t, ports))
+	assert.Empty(t, findVlanDrift(ports, expected, resNames, actual, nil, now))
+	assert.Equal(t, map[string]string{"leaf1|Et1": "100", "leaf1|Et2": "100"}, actual)
+}
+
+func TestSimDriftRepairInterface(t *testing.T) {
+	useSimSwitches(t, SwitchConfig{Name: "leaf1"}, SwitchConfig{Name: "leaf2"})
+
+	now := time.Now()
+	res := Reservation{Name: "r1", Vlan: 100, NicRoles: "primary,data", Installed: true, Start: now.Add(-time.Hour), End: now.Add(time.Hour)}
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Reservations: []Reservation{res}, Interfaces: []HostInterface{{Role: "data", Eth: "Et1", SwitchName: "leaf2"}}},
+	}
+	res.Hosts = hosts
+	_, err := res.networkSet(hosts)
+	assert.NoError(t, err)
+
+	// the data nic is moved out of the reservation vlan by hand
+	simSetPort("leaf2", "Et1", "130")
+
+	ports, expected, resNames := expectedPortVlans(hosts, now)
+	actual := mergeSwitchVlans(ports, simSnapshot(t, ports))
+	drift := findVlanDrift(ports, expected, resNames, actual, nil, now)
+	assert.Len(t, drift, 1)
+	assert.Equal(t, "leaf2", drift[0].Switch)
+	assert.Equal(t, "Et1", drift[0].Port)
+
+	portsByKey := map[string]Host{}
+	for _, p := range ports {
+		portsByKey[portKey(p)] = p
+	}
+	repairVlanDrift(drift, portsByKey)
+	assert.True(t, drift[0].Repaired)
+	actual = mergeSwitchVlans(ports, simSnapshot(t, ports))
+	assert.Equal(t, map[string]string{"leaf1|Et1": "100", "leaf2|Et1": "100"}, actual)
+}
@@ -0,0 +1,288 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	sshSwitchDialTimeout = 10 * time.Second
+	sshSwitchRunTimeout  = 60 * time.Second
+)
+
+func init() {
+	if networkSetFuncs == nil {
+		networkSetFuncs = make(map[string]func(*SwitchConfig, []Host, int) error)
+		networkClearFuncs = make(map[string]func(*SwitchConfig, []Host) error)
+		networkVlanFuncs = make(map[string]func(*SwitchConfig) (map[string]string, error))
+	}
+	networkSetFuncs["ssh"] = sshSet
+	networkClearFuncs["ssh"] = sshClear
+	networkVlanFuncs["ssh"] = sshVlan
+	networkValidateFuncs["ssh"] = sshValidate
+}
+
+// SSHTemplateData holds the values available to the ssh driver command templates. Eths and VLAN are
+// set for the set and clear templates, RangeMin and RangeMax for all of them.
+type SSHTemplateData struct {
+	Eths     []string
+	VLAN     int
+	RangeMin int
+	RangeMax int
+}
+
+// sshValidate checks that the templates and regular expressions of an ssh switch are usable.
+func sshValidate(sw *SwitchConfig) error {
+	for name, tmpl := range map[string]string{"setTemplate": sw.SetTemplate, "clearTemplate": sw.ClearTemplate, "showTemplate": sw.ShowTemplate} {
+		if strings.TrimSpace(tmpl) == "" {
+			return fmt.Errorf("%s cannot be blank for the ssh network driver", name)
+		}
+		if _, err := template.New(name).Parse(tmpl); err != nil {
+			return fmt.Errorf("%s is invalid: %v", name, err)
+		}
+	}
+	re, err := regexp.Compile(sw.ShowRegex)
+	if err != nil {
+		return fmt.Errorf("showRegex is invalid: %v", err)
+	}
+	if re.SubexpIndex("port") < 0 || re.SubexpIndex("vlan") < 0 {
+		return fmt.Errorf("showRegex must have named groups 'port' and 'vlan', ex. (?P<port>\\S+)\\s+(?P<vlan>\\d+)")
+	}
+	if _, err = regexp.Compile(sw.ErrorRegex); err != nil {
+		return fmt.Errorf("errorRegex is invalid: %v", err)
+	}
+	if sw.KeyFile != "" {
+		if _, err = os.Stat(sw.KeyFile); err != nil {
+			return fmt.Errorf("keyFile: %v", err)
+		}
+	}
+	if sw.KnownHostsFile == "" {
+		if !sw.InsecureHostKey {
+			return fmt.Errorf("knownHostsFile is required unless insecureHostKey is set")
+		}
+		logger.Warn().Msgf("vlan.switches '%s' insecureHostKey is set, switch host key will not be verified", sw.Name)
+	} else if _, err = knownhosts.New(sw.KnownHostsFile); err != nil {
+		return fmt.Errorf("knownHostsFile: %v", err)
+	}
+	return nil
+}
+
+// sshRenderCommands fills in the template and returns the resulting non-blank lines.
+func sshRenderCommands(name, tmpl string, data *SSHTemplateData) ([]string, error) {
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err = t.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	var commands []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+	return commands, nil
+}
+
+// sshClientConfig builds the login settings for the switch.
+func sshClientConfig(sw *SwitchConfig) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if sw.KeyFile != "" {
+		key, err := os.ReadFile(sw.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("problem reading key file %s: %v", sw.KeyFile, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if sw.NetworkPassword != "" {
+		auth = append(auth, ssh.Password(sw.NetworkPassword))
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case sw.KnownHostsFile != "":
+		cb, err := knownhosts.New(sw.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		hostKeyCallback = cb
+	case sw.InsecureHostKey:
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("switch %s has no knownHostsFile to verify its host key", sw.Name)
+	}
+
+	return &ssh.ClientConfig{
+		User:            sw.NetworkUser,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshSwitchDialTimeout,
+	}, nil
+}
+
+// sshRunCommands opens a shell on the switch, sends the commands and returns everything the switch
+// printed. The shell ends when the commands are done, so templates that leave the switch CLI in a
+// nested mode should finish with the commands needed to log out.
+func sshRunCommands(sw *SwitchConfig, commands []string) (string, error) {
+
+	config, err := sshClientConfig(sw)
+	if err != nil {
+		return "", err
+	}
+	addr := sw.NetworkURL
+	if _, _, spErr := net.SplitHostPort(addr); spErr != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	client, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return "", fmt.Errorf("ssh connection failed: %v", err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	var out bytes.Buffer
+	session.Stdout = &out
+	session.Stderr = &out
+	session.Stdin = strings.NewReader(strings.Join(commands, "\n") + "\n")
+	if err = session.Shell(); err != nil {
+		return "", err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(sshSwitchRunTimeout):
+		_ = session.Close()
+		return out.String(), fmt.Errorf("commands did not finish within %v", sshSwitchRunTimeout)
+	}
+
+	var exitMissing *ssh.ExitMissingError
+	if err != nil && !errors.As(err, &exitMissing) {
+		return out.String(), fmt.Errorf("%v - output: %s", err, strings.TrimSpace(out.String()))
+	}
+	if line := sshMatchError(sw.ErrorRegex, out.String()); line != "" {
+		return out.String(), fmt.Errorf("switch reported an error: %s", line)
+	}
+	return out.String(), nil
+}
+
+// sshMatchError returns the first line of output matching the error pattern, or blank if none do.
+func sshMatchError(pattern, output string) string {
+	if pattern == "" {
+		return ""
+	}
+	re := regexp.MustCompile(pattern)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if re.MatchString(scanner.Text()) {
+			return strings.TrimSpace(scanner.Text())
+		}
+	}
+	return ""
+}
+
+// sshRun renders the template for the host ports and runs the result on the switch as a single batch.
+func sshRun(sw *SwitchConfig, name, tmpl string, hosts []Host, vlan int) error {
+	data := &SSHTemplateData{
+		Eths:     hostPorts(hosts),
+		VLAN:     vlan,
+		RangeMin: igor.Vlan.RangeMin,
+		RangeMax: igor.Vlan.RangeMax,
+	}
+	if len(data.Eths) == 0 {
+		return nil
+	}
+	commands, err := sshRenderCommands(name, tmpl, data)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Msgf("commands being sent to switch %s: %v", sw.Name, commands)
+	out, err := sshRunCommands(sw, commands)
+	logger.Debug().Msgf("%s output received from switch %s: %s", name, sw.Name, out)
+	return err
+}
+
+func sshSet(sw *SwitchConfig, hosts []Host, vlan int) error {
+	return sshRun(sw, "setTemplate", sw.SetTemplate, hosts, vlan)
+}
+
+func sshClear(sw *SwitchConfig, hosts []Host) error {
+	return sshRun(sw, "clearTemplate", sw.ClearTemplate, hosts, 0)
+}
+
+// sshVlan runs the show template on the switch and parses the port vlans from its output.
+func sshVlan(sw *SwitchConfig) (map[string]string, error) {
+	commands, err := sshRenderCommands("showTemplate", sw.ShowTemplate, &SSHTemplateData{
+		RangeMin: igor.Vlan.RangeMin,
+		RangeMax: igor.Vlan.RangeMax,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out, err := sshRunCommands(sw, commands)
+	if err != nil {
+		logger.Error().Msgf("error sending command to switch %s: %v", sw.Name, err.Error())
+		return nil, err
+	}
+	return parseSwitchShowOutput(sw.ShowRegex, out, igor.Vlan.RangeMin, igor.Vlan.RangeMax), nil
+}
+
+var switchPortListSep = regexp.MustCompile(`[,\s]+`)
+
+// parseSwitchShowOutput matches the pattern against each line of output and returns the vlan of every
+// port found within the vlan range. The port group may hold a single port or a comma or space separated
+// list of ports, as printed by commands that show all ports of each vlan.
+func parseSwitchShowOutput(pattern, output string, rangeMin, rangeMax int) map[string]string {
+	re := regexp.MustCompile(pattern)
+	portIdx, vlanIdx := re.SubexpIndex("port"), re.SubexpIndex("vlan")
+
+	ethMap := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		m := re.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		vlan, err := strconv.Atoi(m[vlanIdx])
+		if err != nil || vlan < rangeMin || vlan > rangeMax {
+			continue
+		}
+		for _, port := range switchPortListSep.Split(strings.TrimSpace(m[portIdx]), -1) {
+			if port != "" {
+				ethMap[port] = strconv.Itoa(vlan)
+			}
+		}
+	}
+	return ethMap
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHRenderCommands(t *testing.T) {
+	tmpl := `configure
+{{- range .Eths }}
+interface {{ . }}
+  switchport access vlan {{ $.VLAN }}
+{{- end }}
+commit
+exit`
+	commands, err := sshRenderCommands("setTemplate", tmpl, &SSHTemplateData{Eths: []string{"ethernet1/1/1", "ethernet1/1/2"}, VLAN: 120})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"configure",
+		"interface ethernet1/1/1", "switchport access vlan 120",
+		"interface ethernet1/1/2", "switchport access vlan 120",
+		"commit", "exit",
+	}, commands)
+}
+
+func TestParseSwitchShowOutput(t *testing.T) {
+	// one port per line, as from a per-interface listing
+	out := `Port      Mode     Vlan
+Eth1/1    access   105
+Eth1/2    access   1
+Eth1/3    trunk    300
+`
+	ports := parseSwitchShowOutput(`^(?P<port>Eth\S+)\s+\S+\s+(?P<vlan>\d+)`, out, 100, 200)
+	assert.Equal(t, map[string]string{"Eth1/1": "105"}, ports)
+
+	// all ports of each vlan on one line, as from a vlan listing
+	out = `VLAN  Name       Status   Ports
+110   res-a      active   Eth1/4, Eth1/5
+111   res-b      active   Eth1/6
+`
+	ports = parseSwitchShowOutput(`^(?P<vlan>\d+)\s+\S+\s+active\s+(?P<port>.*)$`, out, 100, 200)
+	assert.Equal(t, map[string]string{"Eth1/4": "110", "Eth1/5": "110", "Eth1/6": "111"}, ports)
+}
+
+func TestSSHValidate(t *testing.T) {
+	sw := &SwitchConfig{
+		Name:          "dell1",
+		SetTemplate:   "interface {{ range .Eths }}{{ . }}{{ end }}",
+		ClearTemplate: "interface {{ range .Eths }}{{ . }}{{ end }}",
+		ShowTemplate:  "show vlan",
+		ShowRegex:     `(?P<port>\S+)\s+(?P<vlan>\d+)`,
+	}
+	// host keys are only left unverified when asked for
+	assert.Error(t, sshValidate(sw))
+	_, err := sshClientConfig(sw)
+	assert.Error(t, err)
+
+	sw.InsecureHostKey = true
+	assert.NoError(t, sshValidate(sw))
+	_, err = sshClientConfig(sw)
+	assert.NoError(t, err)
+
+	sw.ShowRegex = `(\S+)\s+(\d+)`
+	assert.Error(t, sshValidate(sw))
+
+	sw.ShowRegex = `(?P<port>\S+)\s+(?P<vlan>\d+)`
+	sw.SetTemplate = "interface {{ range .Eths }}"
+	assert.Error(t, sshValidate(sw))
+
+	assert.Equal(t, "% Error: bad vlan", sshMatchError(`^% Error`, "ok\n% Error: bad vlan\n"))
+	assert.Equal(t, "", sshMatchError("", "% Error: bad vlan"))
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostsBySwitch(t *testing.T) {
+	saved := igor.Vlan.Switches
+	defer func() { igor.Vlan.Switches = saved }()
+	igor.Vlan.Switches = []SwitchConfig{{Name: "leaf1"}, {Name: "leaf2"}}
+
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1"},
+		{Name: "kn2", Eth: "Et1", SwitchName: "leaf2"},
+		{Name: "kn3", Eth: "Et2", SwitchName: "leaf1"},
+	}
+	groups, err := hostsBySwitch(hosts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kn1", "kn3"}, namesOfHosts(groups["leaf1"]))
+	assert.Equal(t, []string{"kn2"}, namesOfHosts(groups["leaf2"]))
+
+	_, err = hostsBySwitch([]Host{{Name: "kn4", SwitchName: "spine"}})
+	assert.Error(t, err)
+	assert.Error(t, checkSwitchName("spine"))
+	assert.NoError(t, checkSwitchName("leaf2"))
+
+	// ports with the same name on different switches are kept apart, and interface ports are included
+	hosts[0].Interfaces = []HostInterface{{Role: "data", Eth: "Et9", SwitchName: "leaf2"}}
+	merged := mergeSwitchVlans(allHostPorts(hosts), map[string]map[string]string{
+		"leaf1": {"Et1": "101", "Et2": "102"},
+		"leaf2": {"Et1": "201", "Et9": "301"},
+	})
+	assert.Equal(t, map[string]string{"leaf1|Et1": "101", "leaf2|Et1": "201", "leaf1|Et2": "102", "leaf2|Et9": "301"}, merged)
+}
+
+func TestNetworkChangeRollback(t *testing.T) {
+	saved := igor.Vlan.Switches
+	defer func() {
+		igor.Vlan.Switches = saved
+		delete(networkSetFuncs, "fake")
+		delete(networkClearFuncs, "fake")
+		delete(networkVlanFuncs, "fake")
+	}()
+	igor.Vlan.Switches = []SwitchConfig{{Name: "leaf1", Network: "fake"}, {Name: "leaf2", Network: "fake"}}
+
+	// in-memory switches where every set on leaf2 fails after the first port is moved
+	var mu sync.Mutex
+	ports := map[string]map[string]string{
+		"leaf1": {"Et1": "101"},
+		"leaf2": {},
+	}
+	failLeaf2 := true
+	networkSetFuncs["fake"] = func(sw *SwitchConfig, hosts []Host, vlan int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, h := range hosts {
+			ports[sw.Name][h.Eth] = strconv.Itoa(vlan)
+			if sw.Name == "leaf2" && failLeaf2 {
+				return fmt.Errorf("port %s rejected", h.Eth)
+			}
+		}
+		return nil
+	}
+	networkClearFuncs["fake"] = func(sw *SwitchConfig, hosts []Host) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, h := range hosts {
+			delete(ports[sw.Name], h.Eth)
+		}
+		return nil
+	}
+	networkVlanFuncs["fake"] = func(sw *SwitchConfig) (map[string]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result := make(map[string]string)
+		for k, v := range ports[sw.Name] {
+			result[k] = v
+		}
+		return result, nil
+	}
+
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", SwitchName: "leaf1"},
+		{Name: "kn2", Eth: "Et2", SwitchName: "leaf1"},
+		{Name: "kn3", Eth: "Et1", SwitchName: "leaf2"},
+	}
+	apply := func(sw *SwitchConfig, swHosts []Host) error {
+		return networkSetFuncs["fake"](sw, swHosts, 150)
+	}
+
+	snap, err := networkChange(hosts, apply)
+	assert.ErrorContains(t, err, "rolled back")
+	assert.Nil(t, snap)
+	assert.Equal(t, map[string]string{"Et1": "101"}, ports["leaf1"])
+	assert.Empty(t, ports["leaf2"])
+
+	failLeaf2 = false
+	snap, err = networkChange(hosts, apply)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Et1": "150", "Et2": "150"}, ports["leaf1"])
+	assert.Equal(t, map[string]string{"Et1": "150"}, ports["leaf2"])
+
+	// a later failure can undo the change with the snapshot
+	assert.NoError(t, networkRollback(hosts, snap))
+	assert.Equal(t, map[string]string{"Et1": "101"}, ports["leaf1"])
+	assert.Empty(t, ports["leaf2"])
+}
+
+func TestFindVlanDrift(t *testing.T) {
+	saved := igor.Vlan.Switches
+	defer func() { igor.Vlan.Switches = saved }()
+	igor.Vlan.Switches = []SwitchConfig{{Name: "leaf1"}}
+
+	now := time.Now()
+	active := Reservation{Name: "res1", Vlan: 101, Installed: true, Start: now.Add(-time.Hour), End: now.Add(time.Hour)}
+	installing := Reservation{Name: "res2", Vlan: 102, Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+	future := Reservation{Name: "res3", Vlan: 103, Installed: true, Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}
+
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Reservations: []Reservation{active}},
+		{Name: "kn2", Eth: "Et2", Reservations: []Reservation{active}},
+		{Name: "kn3", Eth: "Et3", Reservations: []Reservation{installing}},
+		{Name: "kn4", Eth: "Et4", Reservations: []Reservation{future}},
+		{Name: "kn5", Eth: "Et5"},
+		{Name: "kn6"},
+	}
+	ports, expected, resNames := expectedPortVlans(hosts, now)
+	assert.Equal(t, []string{"kn1", "kn2", "kn4", "kn5"}, namesOfHosts(ports))
+	assert.Equal(t, map[string]string{"leaf1|Et1": "101", "leaf1|Et2": "101", "leaf1|Et4": "", "leaf1|Et5": ""}, expected)
+	assert.Equal(t, map[string]string{"leaf1|Et1": "res1", "leaf1|Et2": "res1"}, resNames)
+
+	actual := map[string]string{"leaf1|Et1": "101", "leaf1|Et2": "150", "leaf1|Et3": "999", "leaf1|Et5": "110"}
+	earlier := now.Add(-time.Hour)
+	prev := []common.VlanDriftData{{Host: "kn2", Switch: "leaf1", Port: "Et2", Expected: "101", Actual: "150", Since: earlier}}
+	drift := findVlanDrift(ports, expected, resNames, actual, prev, now)
+	assert.Len(t, drift, 2)
+	assert.Equal(t, common.VlanDriftData{Host: "kn2", Switch: "leaf1", Port: "Et2", Reservation: "res1", Expected: "101", Actual: "150", Since: earlier}, drift[0])
+	assert.Equal(t, common.VlanDriftData{Host: "kn5", Switch: "leaf1", Port: "Et5", Expected: "", Actual: "110", Since: now}, drift[1])
+
+	// interface ports in the reservation vlan are compared too, unused ones are not
+	data := Reservation{Name: "res4", Vlan: 104, NicRoles: "primary,data", Installed: true, Start: now.Add(-time.Hour), End: now.Add(time.Hour)}
+	hosts = []Host{{Name: "kn7", Eth: "Et7", Reservations: []Reservation{data}, Interfaces: []HostInterface{
+		{Role: "data", Eth: "Et17"},
+		{Role: "mgmt", Eth: "Et27"},
+	}}}
+	ports, expected, resNames = expectedPortVlans(hosts, now)
+	assert.Len(t, ports, 2)
+	assert.Equal(t, map[string]string{"leaf1|Et7": "104", "leaf1|Et17": "104"}, expected)
+	drift = findVlanDrift(ports, expected, resNames, map[string]string{"leaf1|Et7": "104", "leaf1|Et17": "120"}, nil, now)
+	assert.Equal(t, []common.VlanDriftData{{Host: "kn7", Switch: "leaf1", Port: "Et17", Reservation: "res4", Expected: "104", Actual: "120", Since: now}}, drift)
+}
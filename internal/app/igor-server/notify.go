@@ -41,6 +41,7 @@ func initNotify() {
 			"resEdit":        resEdit,
 			"replaceInfo":    replaceInfo,
 			"ownerEmailList": ownerEmailList,
+			"join":           strings.Join,
 		}
 
 		var t *template.Template
@@ -72,6 +73,27 @@ func initNotify() {
 		t, _ = t.Parse(SenderInfoTemplate)
 		tMap[EmailAcctRemovedIssue] = t
 
+		t = template.New("EmailHostHealthFail")
+		t.Funcs(tFuncs)
+		t = template.Must(t.Parse(BaseEmailTemplate))
+		t, _ = t.Parse(NotifyHostHealthFailTemplate)
+		t, _ = t.Parse(SenderInfoTemplate)
+		tMap[EmailHostHealthFail] = t
+
+		t = template.New("EmailHostFlapping")
+		t.Funcs(tFuncs)
+		t = template.Must(t.Parse(BaseEmailTemplate))
+		t, _ = t.Parse(NotifyHostFlappingTemplate)
+		t, _ = t.Parse(SenderInfoTemplate)
+		tMap[EmailHostFlapping] = t
+
+		t = template.New("EmailVlanDrift")
+		t.Funcs(tFuncs)
+		t = template.Must(t.Parse(BaseEmailTemplate))
+		t, _ = t.Parse(NotifyVlanDriftTemplate)
+		t, _ = t.Parse(SenderInfoTemplate)
+		tMap[EmailVlanDrift] = t
+
 		t = template.New("EmailGroupCreated")
 		t.Funcs(tFuncs)
 		t = template.Must(t.Parse(BaseEmailTemplate))
@@ -454,6 +476,74 @@ func processGroupNotifyEvent(msg GroupNotifyEvent) error {
 	return nil
 }
 
+type HostNotifyEvent struct {
+	NotifyEvent
+	Action   string
+	Affected []string
+	Failures map[string][]string
+}
+
+// makeHostNotifyEvent returns a struct to be sent over the notify channel. The action is what was done to the affected
+// hosts and is blank for events where nothing was done to them. It returns nil if the email config settings prevent
+// email from being sent.
+func makeHostNotifyEvent(nType int, action string, affected []string, failures map[string][]string) *HostNotifyEvent {
+
+	if len(igor.Email.SmtpServer) == 0 {
+		logger.Debug().Msgf("no SMTP server defined - host email will not be sent")
+		return nil
+	}
+
+	return &HostNotifyEvent{
+		NotifyEvent: NotifyEvent{
+			Type:     nType,
+			Instance: igor.InstanceName,
+			HelpLink: igor.Email.HelpLink,
+		},
+		Action:   action,
+		Affected: affected,
+		Failures: failures,
+	}
+}
+
+func processHostNotifyEvent(msg HostNotifyEvent) error {
+
+	var t *template.Template
+	var subj string
+	var toList []string
+
+	switch msg.Type {
+
+	case EmailHostHealthFail, EmailHostFlapping, EmailVlanDrift:
+		switch msg.Type {
+		case EmailHostHealthFail:
+			subj = "igor: hosts failing health checks"
+		case EmailHostFlapping:
+			subj = "igor: hosts with flapping status"
+		default:
+			subj = "igor: switch port vlans out of sync with reservations"
+		}
+		t = tMap[msg.Type]
+		queryAdmins := map[string]interface{}{"name": GroupAdmins, "showMembers": true}
+		if gList, err := dbReadGroupsTx(queryAdmins, true); err != nil {
+			return err
+		} else {
+			for _, m := range gList[0].Members {
+				addEmailToList(&toList, m.Email)
+			}
+		}
+	default:
+		err := fmt.Errorf("unrecognized notify type '%d' - aborting email send", msg.Type)
+		logger.Error().Msgf("%v", err)
+		return err
+	}
+
+	if err := sendEmail(t, subj, toList, nil, nil, true, msg); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 type ResNotifyEvent struct {
 	NotifyEvent
 	Cluster    string
@@ -725,6 +815,12 @@ const (
 	EmailGroupRmvOwner
 )
 
+const (
+	EmailHostHealthFail = iota + 1400
+	EmailHostFlapping
+	EmailVlanDrift
+)
+
 const (
 	ResInfoTemplate = `
 {{template "mail-body" .}}
@@ -908,6 +1004,59 @@ const (
 
 <p>Review these resources and either delete or re-assign their ownership to users they were shared with. Check logs for more information.</p>
 
+{{block "sender-info" .}}{{end}}
+{{end}}
+`
+
+	NotifyHostHealthFailTemplate = `
+{{template "base" .}}
+{{define "mail-body"}}
+<p>To the Igor administration team,</p>
+
+<p>The following hosts have failed host health checks on consecutive runs:</p>
+
+<ul>
+{{range $host, $checks := .Failures}}<li>{{$host}}: {{range $checks}}{{.}}; {{end}}</li>
+{{end}}</ul>
+
+{{if and .Action .Affected}}<p>The configured fail action '{{.Action}}' was applied to these hosts: {{join .Affected ", "}}</p>{{end}}
+
+<p>Use 'igor host health' to review the check history of each host.</p>
+
+{{block "sender-info" .}}{{end}}
+{{end}}
+`
+
+	NotifyHostFlappingTemplate = `
+{{template "base" .}}
+{{define "mail-body"}}
+<p>To the Igor administration team,</p>
+
+<p>The following hosts have changed power/network status repeatedly in a short period of time. This can be a sign of failing hardware or a network problem:</p>
+
+<ul>
+{{range $host, $info := .Failures}}<li>{{$host}}</li>
+{{end}}</ul>
+
+<p>Use 'igor host status-history' to review the status changes of each host.</p>
+
+{{block "sender-info" .}}{{end}}
+{{end}}
+`
+
+	NotifyVlanDriftTemplate = `
+{{template "base" .}}
+{{define "mail-body"}}
+<p>To the Igor administration team,</p>
+
+<p>The vlans reported by the switch for the following hosts don't match their reservations:</p>
+
+<ul>
+{{range $host, $info := .Failures}}<li>{{$host}}: {{range $info}}{{.}}; {{end}}</li>
+{{end}}</ul>
+
+<p>Use 'igor sync status' to review the current state, or 'igor sync vlan -f' to correct the switch ports.</p>
+
 {{block "sender-info" .}}{{end}}
 {{end}}
 `
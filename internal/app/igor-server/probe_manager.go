@@ -48,6 +48,10 @@ func hostProbeManager(hosts []Host) {
 		hostStatusMap[h] = HostStatusUnknown
 	}
 
+	// lastStatus is the snapshot of hostStatusMap taken at the end of the previous probe cycle. It is
+	// used to find status transitions; nil until the first cycle completes.
+	var lastStatus map[string]HostStatus
+
 	for {
 		select {
 		case <-shutdownChan:
@@ -112,6 +116,15 @@ func hostProbeManager(hosts []Host) {
 				hostStatusMapMU.Unlock()
 			}
 
+			now := time.Now()
+			currStatus := snapshotHostStatus()
+			if lastStatus != nil {
+				transitions := diffHostStatus(lastStatus, currStatus, now)
+				recordStatusTransitions(transitions, now)
+				recordOsReachable(hostsNowUp(transitions))
+			}
+			lastStatus = currStatus
+
 			countdown.Reset(timeout)
 		}
 	}
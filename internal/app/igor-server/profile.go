@@ -5,6 +5,7 @@
 package igorserver
 
 import (
+	"encoding/json"
 	"igor2/internal/pkg/common"
 	"sort"
 )
@@ -28,6 +29,19 @@ type Profile struct {
 	Distro      Distro
 	IsDefault   bool
 	KernelArgs  string // Added to Distro kernel args if they exist.
+	Vars        string // JSON object of variables given to kickstarts that are templates
+	UserData    string // cloud-init user-data given to reservation hosts by the NoCloud datasource
+}
+
+// vars returns the profile's kickstart template variables.
+func (p *Profile) vars() map[string]string {
+	vars := map[string]string{}
+	if p.Vars != "" {
+		if err := json.Unmarshal([]byte(p.Vars), &vars); err != nil {
+			logger.Error().Msgf("profile %s has unreadable variables - %v", p.Name, err)
+		}
+	}
+	return vars
 }
 
 // duplicate makes a deep copy of a profile, setting the given user as the new owner
@@ -38,6 +52,8 @@ func (p *Profile) duplicate(user *User) *Profile {
 		Description: p.Description,
 		Distro:      p.Distro,
 		KernelArgs:  p.KernelArgs,
+		Vars:        p.Vars,
+		UserData:    p.UserData,
 	}
 }
 
@@ -50,6 +66,8 @@ func filterProfileList(profiles []Profile) []common.ProfileData {
 			Owner:       profile.Owner.Name,
 			Distro:      profile.Distro.Name,
 			KernelArgs:  profile.KernelArgs,
+			Vars:        profile.vars(),
+			UserData:    profile.UserData,
 		})
 	}
 
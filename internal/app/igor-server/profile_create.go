@@ -42,6 +42,16 @@ func doCreateProfile(createProfileParams map[string]interface{}, r *http.Request
 		desc, _ = createProfileParams["description"].(string)
 		var kernelArgs string
 		kernelArgs, _ = createProfileParams["kernelArgs"].(string)
+		var userData string
+		userData, _ = createProfileParams["userData"].(string)
+		var vars string
+		if given, ok := createProfileParams["vars"].(map[string]interface{}); ok {
+			var vErr error
+			if vars, vErr = mergeProfileVars(nil, given); vErr != nil {
+				code = http.StatusBadRequest
+				return vErr
+			}
+		}
 
 		profile = &Profile{
 			Name:        profileName,
@@ -49,6 +59,8 @@ func doCreateProfile(createProfileParams map[string]interface{}, r *http.Request
 			Owner:       *owner,
 			Distro:      *distro,
 			KernelArgs:  kernelArgs,
+			Vars:        vars,
+			UserData:    userData,
 		}
 
 		return dbCreateProfile(profile, tx) // uses default err code
@@ -160,6 +160,14 @@ func validateProfileParams(handler http.Handler) http.Handler {
 							} else if validateErr = checkDistroNameRules(distro); validateErr != nil {
 								break postPutParamLoop
 							}
+						case "vars":
+							if validateErr = checkProfileVars(val); validateErr != nil {
+								break postPutParamLoop
+							}
+						case "userData":
+							if validateErr = checkProfileUserData(val); validateErr != nil {
+								break postPutParamLoop
+							}
 						default:
 							validateErr = NewUnknownParamError(key, val)
 							break postPutParamLoop
@@ -237,6 +245,14 @@ func validateProfileParams(handler http.Handler) http.Handler {
 					} else if validateErr = checkReservedProfileNames(name); validateErr != nil {
 						break patchParamLoop
 					}
+				case "vars":
+					if validateErr = checkProfileVars(val); validateErr != nil {
+						break patchParamLoop
+					}
+				case "userData":
+					if validateErr = checkProfileUserData(val); validateErr != nil {
+						break patchParamLoop
+					}
 				default:
 					validateErr = NewUnknownParamError(key, val)
 					break patchParamLoop
@@ -81,6 +81,16 @@ func parseProfileEditParams(p *Profile, editParams map[string]interface{}) (map[
 	if ka, ok := editParams["kernelArgs"].(string); ok {
 		changes["kernel_args"] = ka
 	}
+	if ud, ok := editParams["userData"].(string); ok {
+		changes["user_data"] = ud
+	}
+	if given, ok := editParams["vars"].(map[string]interface{}); ok {
+		vars, err := mergeProfileVars(p.vars(), given)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		changes["vars"] = vars
+	}
 
 	// if profile is default and user making valid changes,
 	// then make the profile permanent for the user
@@ -29,6 +29,8 @@ type Reservation struct {
 	ProfileID   int
 	Profile     Profile
 	Vlan        int
+	NicRoles    string // comma-separated host interface roles that join the vlan; blank means only the primary one
+	Networks    []ResNetwork
 	Start       time.Time
 	End         time.Time
 	OrigEnd     time.Time `gorm:"<-:create"`
@@ -38,6 +40,7 @@ type Reservation struct {
 	Hosts        []Host `gorm:"many2many:reservations_hosts;"`
 	Installed    bool
 	InstallError string
+	BootStages   []BootStage
 	CycleOnStart bool
 	NextNotify   time.Duration
 	// Hash is the unique ID used for history tracking
@@ -128,6 +131,13 @@ func filterReservationList(resList []Reservation, user *User) []common.Reservati
 			Vlan:         r.Vlan,
 			RemainHours:  int(remaining),
 		}
+		if igor.Vlan.Network != "" {
+			resCopy.NicRoles = r.nicRoles()
+			for _, n := range r.Networks {
+				resCopy.Networks = append(resCopy.Networks, n.getResNetworkData())
+			}
+		}
+		resCopy.BootProgress = r.getBootProgress(hostNameList)
 
 		reportList = append(reportList, resCopy)
 	}
@@ -161,6 +171,8 @@ func (r *Reservation) DeepCopy() *Reservation {
 	clone.HistCallback = r.HistCallback
 	clone.Hosts = make([]Host, len(r.Hosts))
 	copy(clone.Hosts, r.Hosts)
+	clone.Networks = make([]ResNetwork, len(r.Networks))
+	copy(clone.Networks, r.Networks)
 
 	return &clone
 }
@@ -187,6 +199,29 @@ func (r *Reservation) Remaining(t time.Time) time.Duration {
 	return r.Duration()
 }
 
+// nicRoles returns the host interface roles that join the reservation's vlan.
+func (r *Reservation) nicRoles() []string {
+	if r.NicRoles == "" {
+		return []string{NicRolePrimary}
+	}
+	return strings.Split(r.NicRoles, ",")
+}
+
+// hasNicRole returns true if interfaces with the given role join the reservation's vlan.
+func (r *Reservation) hasNicRole(role string) bool {
+	for _, nr := range r.nicRoles() {
+		if nr == role {
+			return true
+		}
+	}
+	return false
+}
+
+// vlanPorts returns the switch ports of the given hosts that belong in the reservation's vlan.
+func (r *Reservation) vlanPorts(hosts []Host) []Host {
+	return nicPorts(hosts, r.nicRoles())
+}
+
 func (r *Reservation) getKernelArgs() string {
 	// profile args should append behind distro args if both exist
 	kArgs := ""
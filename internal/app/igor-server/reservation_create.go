@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -239,28 +240,53 @@ func doCreateReservation(resParams map[string]interface{}, r *http.Request) (res
 
 		// set the VLAN
 		vlan := 0
+		vlanFromPool := false
 		// skip if not using vlan
 		if igor.Vlan.Network != "" {
 			if thisVlan, ok := resParams["vlan"].(string); ok {
 				// user wants a specific vlan
 				if thisVlan != "" {
-					vlanInt, pvStatus, pvErr := parseVLAN(thisVlan, *resOwner, tx)
+					vlanInt, fromPool, pvStatus, pvErr := parseVLAN(thisVlan, *resOwner, isElevated, tx)
 					if pvErr != nil {
 						status = pvStatus
 						return pvErr
 					}
 					vlan = vlanInt
+					vlanFromPool = fromPool
 
 				} else {
 					status = http.StatusBadRequest
 					return fmt.Errorf("vlan specified in reservation parameters, but no value included")
 				}
-			} else {
-				// pick next available
-				if vlan, err = nextVLAN(); err != nil {
-					clog.Error().Msgf("error - %v", err.Error())
-				}
 			}
+			// otherwise the next available is picked once the hosts are known
+		}
+
+		// interfaces of the hosts that join the reservation vlan
+		nicRoles := defaultNicRoles()
+		if roles, ok := resParams["nics"].([]interface{}); ok {
+			nicRoles = nil
+			for _, role := range roles {
+				nicRoles = append(nicRoles, role.(string))
+			}
+		}
+
+		// logical networks of the reservation, each with its own vlan
+		var networks []ResNetwork
+		if spec, ok := resParams["topology"].(string); ok {
+			if igor.Vlan.Network == "" {
+				status = http.StatusBadRequest
+				return fmt.Errorf("a topology cannot be used since no vlan service is configured")
+			}
+			if _, nOk := resParams["nics"]; nOk {
+				status = http.StatusBadRequest
+				return fmt.Errorf("nics and topology cannot be used together; set interface roles in the topology instead")
+			}
+			if networks, err = parseTopology(spec); err != nil {
+				status = http.StatusBadRequest
+				return err
+			}
+			nicRoles = networks[0].nicRoles()
 		}
 
 		var cycleOnStart = true
@@ -318,6 +344,8 @@ func doCreateReservation(resParams map[string]interface{}, r *http.Request) (res
 			Hosts:        hosts,
 			Profile:      *profile,
 			Vlan:         vlan,
+			NicRoles:     strings.Join(nicRoles, ","),
+			Networks:     networks,
 			CycleOnStart: cycleOnStart,
 			NextNotify:   nextNotify,
 			Hash:         hex.EncodeToString(hash.Sum(nil)),
@@ -338,6 +366,46 @@ func doCreateReservation(resParams map[string]interface{}, r *http.Request) (res
 				res.Hosts = hostList
 			}
 		}
+
+		// vlan pools can be limited to host policies, so the vlan depends on the hosts that were scheduled
+		if igor.Vlan.Network != "" {
+			if _, ok := resParams["nics"]; ok {
+				available := hostNicRoles(res.Hosts)
+				for _, role := range nicRoles {
+					if !available[role] {
+						status = http.StatusBadRequest
+						return fmt.Errorf("none of the reserved hosts has an interface with role '%s'", role)
+					}
+				}
+			}
+			if vlan == 0 {
+				// pick next available
+				if res.Vlan, err = nextVLAN(resOwner, res.Hosts, tx); err != nil {
+					clog.Error().Msgf("error - %v", err.Error())
+				}
+			} else if vlanFromPool {
+				if cpStatus, cpErr := checkVlanPoolAccess(vlan, resOwner, res.Hosts, tx); cpErr != nil {
+					status = cpStatus
+					return cpErr
+				}
+			}
+			if len(res.Networks) > 0 {
+				if ctErr := checkTopology(res.Networks, res.Hosts); ctErr != nil {
+					status = http.StatusBadRequest
+					return ctErr
+				}
+				// the first network uses the reservation vlan and the rest get vlans of their own
+				res.Networks[0].Vlan = res.Vlan
+				extra, nvErr := nextVLANs(resOwner, res.Hosts, len(res.Networks)-1, []int{res.Vlan}, tx)
+				if nvErr != nil {
+					status = http.StatusConflict
+					return fmt.Errorf("unable to allocate vlans for the reservation topology: %v", nvErr)
+				}
+				for i, v := range extra {
+					res.Networks[i+1].Vlan = v
+				}
+			}
+		}
 		// insert new reservation to the db
 		return dbCreateReservation(res, tx)
 
@@ -352,40 +420,68 @@ func doCreateReservation(resParams map[string]interface{}, r *http.Request) (res
 	return res, resIsNow, http.StatusCreated, nil
 }
 
-func parseVLAN(vlan string, user User, tx *gorm.DB) (int, int, error) {
+// parseVLAN resolves the vlan requested for a reservation, which can be the name of one of the user's
+// reservations, a named vlan the user can use, or a vlan id. The returned bool is true if the id is
+// newly drawn from the vlan range, in which case any pool restrictions still have to be checked once
+// the reservation hosts are known.
+func parseVLAN(vlan string, user User, isElevated bool, tx *gorm.DB) (int, bool, int, error) {
 	// First check to see if we've been handed a reservation name
 	resList, err := dbReadReservations(map[string]interface{}{"name": vlan}, nil, tx)
 	if err != nil {
-		return -1, http.StatusInternalServerError, err
+		return -1, false, http.StatusInternalServerError, err
 	}
 	if len(resList) > 0 {
 		// Check to see if resTarget owner is the same as user
 		resTarget := resList[0]
 		if resTarget.Owner.Name == user.Name {
-			return resTarget.Vlan, http.StatusOK, nil
+			return resTarget.Vlan, false, http.StatusOK, nil
 		}
-		return -1, http.StatusForbidden, fmt.Errorf("owner of reservation specified for VLAN does not match user")
+		return -1, false, http.StatusForbidden, fmt.Errorf("owner of reservation specified for VLAN does not match user")
+	}
+
+	// Next see if it's a named vlan
+	vlanList, err := dbReadNamedVlans(map[string]interface{}{"name": vlan}, tx)
+	if err != nil {
+		return -1, false, http.StatusInternalServerError, err
+	}
+	if len(vlanList) > 0 {
+		if isElevated || vlanList[0].canUse(&user) {
+			return vlanList[0].VlanID, false, http.StatusOK, nil
+		}
+		return -1, false, http.StatusForbidden, fmt.Errorf("you cannot use vlan '%s'", vlan)
 	}
 
 	// See if it's a VLAN ID
 	vlanID64, pErr := strconv.ParseInt(vlan, 10, 64)
 	if pErr != nil {
 		// It wasn't an int, either.
-		return -1, http.StatusBadRequest, fmt.Errorf("expected VLAN to be reservation name or VLAN ID: %s", vlan)
+		return -1, false, http.StatusBadRequest, fmt.Errorf("expected VLAN to be reservation name, vlan name or VLAN ID: %s", vlan)
 	}
 	vlanID := int(vlanID64)
 
 	// Yep, it's an int
 	if vlanID < igor.Vlan.RangeMin || vlanID > igor.Vlan.RangeMax {
 		// VLAN number isn't in the permitted range
-		return -1, http.StatusBadRequest, fmt.Errorf("VLAN number outside permitted range: %s", vlan)
+		return -1, false, http.StatusBadRequest, fmt.Errorf("VLAN number outside permitted range: %s", vlan)
+	}
+
+	// Named vlans can only be used by their owner and groups
+	vlanList, err = dbReadNamedVlans(map[string]interface{}{"vlan_id": vlanID}, tx)
+	if err != nil {
+		return -1, false, http.StatusInternalServerError, err
+	} else if len(vlanList) > 0 {
+		if isElevated || vlanList[0].canUse(&user) {
+			return vlanID, false, http.StatusOK, nil
+		}
+		return -1, false, http.StatusForbidden, fmt.Errorf("cannot set VLAN -- %s is the named vlan '%s'", vlan, vlanList[0].Name)
 	}
 
-	// See who's already using that VLAN ID
-	resList, err = dbReadReservations(map[string]interface{}{"vlan": vlan}, nil, tx)
+	// See who's already using that VLAN ID, either as the reservation vlan or on a topology network
+	resList, err = reservationsOnVlan(vlanID, tx)
 	if err != nil {
-		return -1, http.StatusInternalServerError, err
-	} else if len(resList) > 0 {
+		return -1, false, http.StatusInternalServerError, err
+	}
+	if len(resList) > 0 {
 		ownsOne := false
 		for _, r := range resList {
 			if r.Owner.Name == user.Name {
@@ -393,10 +489,11 @@ func parseVLAN(vlan string, user User, tx *gorm.DB) (int, int, error) {
 			}
 		}
 		if !ownsOne {
-			return -1, http.StatusForbidden, fmt.Errorf("cannot set VLAN -- must have ownership of at least one reservation using it: %s", vlan)
+			return -1, false, http.StatusForbidden, fmt.Errorf("cannot set VLAN -- must have ownership of at least one reservation using it: %s", vlan)
 		}
+		return vlanID, false, http.StatusOK, nil
 	}
-	return vlanID, http.StatusOK, nil
+	return vlanID, !isElevated, http.StatusOK, nil
 }
 
 // Determines if reservation starts now or in the future and returns proper time values. If the future start date
@@ -68,13 +68,13 @@ func dbReadReservations(queryParams map[string]interface{}, timeParams map[strin
 	if len(queryParams) == 0 && len(timeParams) == 0 {
 		result := tx.Joins("Owner").Joins("Group").Joins("Profile").
 			Preload("Profile.Distro").Preload("Profile.Distro.DistroImage").Preload("Profile.Distro.Kickstart").Preload("Profile.Owner").Preload("Profile.Owner.Groups").
-			Preload("Owner.Groups").Preload("Hosts").Find(&resList)
+			Preload("Owner.Groups").Preload("Hosts").Preload("Hosts.Interfaces").Preload("Networks").Preload("BootStages").Find(&resList)
 		return resList, result.Error
 	}
 
 	tx = tx.Preload("Owner").Preload("Group").Preload("Profile").
 		Preload("Profile.Distro").Preload("Profile.Distro.DistroImage").Preload("Profile.Distro.Kickstart").Preload("Profile.Owner").Preload("Profile.Owner.Groups").
-		Preload("Owner.Groups").Preload("Hosts")
+		Preload("Owner.Groups").Preload("Hosts").Preload("Hosts.Interfaces").Preload("Networks").Preload("BootStages")
 
 	if len(timeParams) > 0 {
 		resolveTimeWhereClauses(timeParams, tx)
@@ -186,7 +186,7 @@ func dbEditReservation(res *Reservation, changes map[string]interface{}, tx *gor
 			var result *gorm.DB
 
 			for _, dropHost := range dropHosts {
-				if dropHost.State != HostBlocked {
+				if dropHost.State != HostBlocked && dropHost.State != HostBurnIn {
 					result = tx.Model(dropHost).Update("State", HostAvailable)
 					if result.Error != nil {
 						return result.Error
@@ -237,7 +237,7 @@ func dbDeleteReservation(res *Reservation, perms []Permission, isResNow bool, tx
 	if isResNow {
 
 		for _, host := range res.Hosts {
-			if host.State != HostBlocked {
+			if host.State != HostBlocked && host.State != HostBurnIn {
 				result := tx.Model(&host).Omit("access_group_id").Update("State", HostAvailable)
 				if result.Error != nil {
 					return result.Error
@@ -251,6 +251,16 @@ func dbDeleteReservation(res *Reservation, perms []Permission, isResNow bool, tx
 		return clErr
 	}
 
+	// delete the topology networks of the reservation
+	if result := tx.Where("reservation_id = ?", res.ID).Delete(&ResNetwork{}); result.Error != nil {
+		return result.Error
+	}
+
+	// delete the boot progress of the reservation's hosts
+	if result := tx.Where("reservation_id = ?", res.ID).Delete(&BootStage{}); result.Error != nil {
+		return result.Error
+	}
+
 	// delete the permissions for this reservation
 	result := tx.Delete(perms)
 	if result.Error != nil {
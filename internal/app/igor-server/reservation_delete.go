@@ -139,7 +139,7 @@ func uninstallRes(res *Reservation) (err error) {
 	// skip if not using vlan
 	if igor.Vlan.Network != "" {
 		// clean up the network config
-		if ncErr := networkClear(res.Hosts); ncErr != nil {
+		if ncErr := networkClear(res.allVlanPorts(res.Hosts)); ncErr != nil {
 			err = fmt.Errorf("error clearing network isolation: %v", ncErr)
 		}
 	}
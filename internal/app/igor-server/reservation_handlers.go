@@ -231,6 +231,27 @@ func validateResvParams(handler http.Handler) http.Handler {
 								validateErr = NewBadParamTypeError(key, val, "string")
 								break postPutParamLoop
 							}
+						case "nics":
+							if roles, ok := val.([]interface{}); !ok || len(roles) == 0 {
+								validateErr = NewBadParamTypeError(key, val, "non-empty []string")
+								break postPutParamLoop
+							} else {
+								for _, role := range roles {
+									if roleStr, rOk := role.(string); !rOk {
+										validateErr = NewBadParamTypeError(key, val, "[]string")
+										break postPutParamLoop
+									} else if validateErr = checkNicRole(roleStr); validateErr != nil {
+										break postPutParamLoop
+									}
+								}
+							}
+						case "topology":
+							if spec, ok := val.(string); !ok {
+								validateErr = NewBadParamTypeError(key, val, "string")
+								break postPutParamLoop
+							} else if _, validateErr = parseTopology(spec); validateErr != nil {
+								break postPutParamLoop
+							}
 						case "nodeList":
 							if thisNodeList, ok := val.(string); !ok {
 								validateErr = NewBadParamTypeError(key, val, "string")
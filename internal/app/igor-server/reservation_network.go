@@ -0,0 +1,231 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"strings"
+
+	"igor2/internal/pkg/common"
+)
+
+// MaxResNetworks is the most logical networks a reservation topology can have.
+const MaxResNetworks = 8
+
+// ResNetwork is one logical network of a reservation topology. Each network gets its own vlan.
+// The first network of a reservation always uses the reservation's own vlan.
+type ResNetwork struct {
+	Base
+	ReservationID int    `gorm:"notNull; index"`
+	Name          string `gorm:"notNull"`
+	Vlan          int
+	Hosts         string // comma-separated names of the hosts on the network; blank means every reservation host
+	NicRoles      string // comma-separated host interface roles that join the network
+}
+
+func (n *ResNetwork) hostNames() []string {
+	if n.Hosts == "" {
+		return nil
+	}
+	return strings.Split(n.Hosts, ",")
+}
+
+func (n *ResNetwork) nicRoles() []string {
+	return strings.Split(n.NicRoles, ",")
+}
+
+func (n *ResNetwork) getResNetworkData() common.ResNetworkData {
+	nd := common.ResNetworkData{
+		Name: n.Name,
+		Vlan: n.Vlan,
+		Nics: n.nicRoles(),
+	}
+	if names := n.hostNames(); len(names) > 0 {
+		nd.Hosts, _ = igor.ClusterRefs[0].UnsplitRange(names)
+	}
+	return nd
+}
+
+// parseTopology reads a reservation topology: networks separated by semicolons, each written as
+//
+//	NAME[:HOSTS[:ROLE1,ROLE2,...]]
+//
+// where HOSTS is a host range and ROLES are host interface roles. Leaving out HOSTS puts every host
+// of the reservation on the network, and leaving out ROLES uses the default vlan roles. A given
+// interface role of a host can only be on one network.
+//
+//	ctrl;data1:kn[1-2]:data;data2:kn[3-4]:data
+func parseTopology(spec string) ([]ResNetwork, error) {
+	var networks []ResNetwork
+	names := common.NewSet()
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		n := ResNetwork{Name: strings.TrimSpace(parts[0])}
+		if !resNetworkNameCheckPattern.MatchString(n.Name) {
+			return nil, fmt.Errorf("network name '%s' is not valid", n.Name)
+		}
+		if names.Contains(n.Name) {
+			return nil, fmt.Errorf("network name '%s' is used more than once", n.Name)
+		}
+		names.Add(n.Name)
+
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+			hostNames := igor.splitRange(strings.TrimSpace(parts[1]))
+			if len(hostNames) == 0 {
+				return nil, fmt.Errorf("network '%s' has an invalid host range '%s'", n.Name, parts[1])
+			}
+			n.Hosts = strings.Join(hostNames, ",")
+		}
+
+		roles := defaultNicRoles()
+		if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+			roles = nil
+			for _, role := range strings.Split(parts[2], ",") {
+				role = strings.TrimSpace(role)
+				if err := checkNicRole(role); err != nil {
+					return nil, fmt.Errorf("network '%s': %v", n.Name, err)
+				}
+				roles = append(roles, role)
+			}
+		}
+		n.NicRoles = strings.Join(roles, ",")
+		networks = append(networks, n)
+	}
+
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("topology must have at least one network")
+	}
+	if len(networks) > MaxResNetworks {
+		return nil, fmt.Errorf("topology cannot have more than %d networks", MaxResNetworks)
+	}
+	return networks, nil
+}
+
+// checkTopology verifies the networks against the hosts the reservation was scheduled on. Every host
+// named by a network must be in the reservation, every role must exist on the network's hosts, and no
+// interface can be on more than one network.
+func checkTopology(networks []ResNetwork, hosts []Host) error {
+	byName := make(map[string]Host, len(hosts))
+	for _, h := range hosts {
+		byName[h.Name] = h
+	}
+	claimed := make(map[string]string)
+	for _, n := range networks {
+		netHosts := hosts
+		if names := n.hostNames(); len(names) > 0 {
+			netHosts = make([]Host, 0, len(names))
+			for _, name := range names {
+				h, ok := byName[name]
+				if !ok {
+					return fmt.Errorf("network '%s' includes host %s which is not part of the reservation", n.Name, name)
+				}
+				netHosts = append(netHosts, h)
+			}
+		}
+		available := hostNicRoles(netHosts)
+		for _, role := range n.nicRoles() {
+			if !available[role] {
+				return fmt.Errorf("none of the hosts on network '%s' has an interface with role '%s'", n.Name, role)
+			}
+			for _, h := range netHosts {
+				key := h.Name + "/" + role
+				if other, ok := claimed[key]; ok {
+					return fmt.Errorf("interface role '%s' of host %s is on both network '%s' and '%s'", role, h.Name, other, n.Name)
+				}
+				claimed[key] = n.Name
+			}
+		}
+	}
+	return nil
+}
+
+// vlanSegment is a set of switch ports that share a vlan.
+type vlanSegment struct {
+	Vlan  int
+	Ports []Host
+}
+
+// vlanSegments returns the switch ports of the given hosts grouped by the vlan they belong in. Hosts
+// that aren't named by any network of the topology, such as hosts added later, are only on networks
+// that include every host.
+func (r *Reservation) vlanSegments(hosts []Host) []vlanSegment {
+	if len(r.Networks) == 0 {
+		return []vlanSegment{{Vlan: r.Vlan, Ports: r.vlanPorts(hosts)}}
+	}
+	segments := make([]vlanSegment, 0, len(r.Networks))
+	for _, n := range r.Networks {
+		netHosts := hosts
+		if names := n.hostNames(); len(names) > 0 {
+			netHosts = nil
+			for _, h := range hosts {
+				for _, name := range names {
+					if h.Name == name {
+						netHosts = append(netHosts, h)
+						break
+					}
+				}
+			}
+		}
+		if ports := nicPorts(netHosts, n.nicRoles()); len(ports) > 0 {
+			segments = append(segments, vlanSegment{Vlan: n.Vlan, Ports: ports})
+		}
+	}
+	return segments
+}
+
+// allVlanPorts returns the switch ports of the given hosts on any of the reservation's networks.
+func (r *Reservation) allVlanPorts(hosts []Host) []Host {
+	var ports []Host
+	for _, seg := range r.vlanSegments(hosts) {
+		ports = append(ports, seg.Ports...)
+	}
+	return ports
+}
+
+// hostVlan returns the vlan the host's primary port belongs in, or 0 if it isn't on any network.
+func (r *Reservation) hostVlan(host Host) int {
+	for _, seg := range r.vlanSegments([]Host{host}) {
+		for _, p := range seg.Ports {
+			if p.Eth == host.Eth && p.SwitchName == host.SwitchName {
+				return seg.Vlan
+			}
+		}
+	}
+	return 0
+}
+
+// networkSet configures the ports of the given hosts into the vlans of the reservation's networks. If
+// any network fails, the ports of networks that were already set are returned to their previous vlans.
+// The returned snapshot holds the port vlans from before the change for networkRollback.
+func (r *Reservation) networkSet(hosts []Host) (networkSnapshot, error) {
+	merged := make(networkSnapshot)
+	var done []Host
+	for _, seg := range r.vlanSegments(hosts) {
+		snap, err := networkSetWithRollback(seg.Ports, seg.Vlan)
+		if err != nil {
+			if len(merged) > 0 {
+				if rbErr := networkRollback(done, merged); rbErr != nil {
+					return nil, fmt.Errorf("%v; rollback of the other reservation networks also failed: %v", err, rbErr)
+				}
+			}
+			return nil, err
+		}
+		done = append(done, seg.Ports...)
+		// keep the earliest snapshot of each switch since later ones include changes made here
+		for sw, ports := range snap {
+			if _, ok := merged[sw]; !ok {
+				merged[sw] = ports
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	return merged, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"igor2/internal/pkg/common"
+)
+
+func setTestClusterRefs(t *testing.T) {
+	saved := igor.ClusterRefs
+	t.Cleanup(func() { igor.ClusterRefs = saved })
+	r, _ := common.NewRange("kn", 1, 16)
+	igor.ClusterRefs = []common.Range{*r}
+}
+
+func TestParseTopology(t *testing.T) {
+	setTestClusterRefs(t)
+
+	networks, err := parseTopology(" ctrl ; data1:kn[1-2]:data ; data2:kn[3-4]:data,fabric")
+	assert.NoError(t, err)
+	assert.Len(t, networks, 3)
+	assert.Equal(t, ResNetwork{Name: "ctrl", NicRoles: NicRolePrimary}, networks[0])
+	assert.Equal(t, "kn1,kn2", networks[1].Hosts)
+	assert.Equal(t, []string{"data", "fabric"}, networks[2].nicRoles())
+
+	networks, err = parseTopology("all::data")
+	assert.NoError(t, err)
+	assert.Equal(t, "", networks[0].Hosts)
+	assert.Equal(t, "data", networks[0].NicRoles)
+
+	for _, bad := range []string{
+		"",
+		" ; ",
+		"ctrl;ctrl",
+		"1net",
+		"data:zz[1-2]",
+		"data:kn1:Data",
+		"a;b;c;d;e;f;g;h;i",
+	} {
+		_, err = parseTopology(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCheckTopology(t *testing.T) {
+	setTestClusterRefs(t)
+
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Interfaces: []HostInterface{{Role: "data", Eth: "Et11"}}},
+		{Name: "kn2", Eth: "Et2", Interfaces: []HostInterface{{Role: "data", Eth: "Et12"}}},
+	}
+	networks, _ := parseTopology("ctrl;d1:kn1:data;d2:kn2:data")
+	assert.NoError(t, checkTopology(networks, hosts))
+
+	networks, _ = parseTopology("ctrl;d1:kn[1-3]:data")
+	assert.Error(t, checkTopology(networks, hosts))
+	networks, _ = parseTopology("ctrl;d1:kn1:fabric")
+	assert.Error(t, checkTopology(networks, hosts))
+	networks, _ = parseTopology("ctrl;d1::data;d2:kn2:data")
+	assert.Error(t, checkTopology(networks, hosts))
+	networks, _ = parseTopology("ctrl;other:kn1")
+	assert.Error(t, checkTopology(networks, hosts))
+}
+
+func TestVlanSegments(t *testing.T) {
+	hosts := []Host{
+		{Name: "kn1", Eth: "Et1", Interfaces: []HostInterface{{Role: "data", Eth: "Et11"}}},
+		{Name: "kn2", Eth: "Et2", Interfaces: []HostInterface{{Role: "data", Eth: "Et12"}}},
+		{Name: "kn3", Eth: "Et3"},
+	}
+
+	// without a topology there is one segment in the reservation vlan
+	res := &Reservation{Vlan: 100}
+	segs := res.vlanSegments(hosts)
+	assert.Len(t, segs, 1)
+	assert.Len(t, segs[0].Ports, 3)
+	assert.Equal(t, 100, res.hostVlan(hosts[2]))
+
+	res.Networks = []ResNetwork{
+		{Name: "ctrl", Vlan: 100, NicRoles: NicRolePrimary},
+		{Name: "d1", Vlan: 101, Hosts: "kn1", NicRoles: "data"},
+		{Name: "d2", Vlan: 102, Hosts: "kn2,kn3", NicRoles: "data"},
+	}
+	segs = res.vlanSegments(hosts)
+	assert.Len(t, segs, 3)
+	assert.Equal(t, []string{"Et11"}, []string{segs[1].Ports[0].Eth})
+	assert.Len(t, segs[2].Ports, 1)
+	assert.Len(t, res.allVlanPorts(hosts), 5)
+	assert.Equal(t, 100, res.hostVlan(hosts[0]))
+
+	// a host left off every network that includes its primary port has no vlan
+	res.Networks[0].Hosts = "kn1,kn2"
+	assert.Equal(t, 0, res.hostVlan(hosts[2]))
+	assert.Len(t, res.vlanSegments(hosts[2:]), 0)
+}
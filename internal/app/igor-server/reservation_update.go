@@ -155,9 +155,10 @@ func doUpdateReservation(resName string, editParams map[string]interface{}, r *h
 	status = http.StatusOK
 
 	if dropped {
-		if vlanErr := networkClear(droppedHosts); vlanErr != nil {
+		if vlanErr := networkClear(res.allVlanPorts(droppedHosts)); vlanErr != nil {
 			clog.Error().Msgf("vlan error on res node drop - %v", vlanErr)
 		}
+		releaseHostAddrs(droppedHosts)
 		if _, powerErr := doPowerHosts(PowerOff, hostNamesOfHosts(droppedHosts), clog); powerErr != nil {
 			clog.Error().Msgf("problem powering off dropped hosts for reservation '%s': %v", resName, powerErr)
 		}
@@ -192,9 +193,11 @@ func doUpdateReservation(resName string, editParams map[string]interface{}, r *h
 	if (len(addHosts) > 0) && (res.Installed || (res.Start.Before(time.Now()) && time.Now().Before(res.End))) {
 		if err = performDbTx(func(tx *gorm.DB) error {
 			// var result *gorm.DB
-			err = dbEditHosts(addHosts, map[string]interface{}{"State": HostReserved}, tx)
-			if err != nil {
-				return err
+			if resHosts := excludeBurnInHosts(addHosts); len(resHosts) > 0 {
+				err = dbEditHosts(resHosts, map[string]interface{}{"State": HostReserved}, tx)
+				if err != nil {
+					return err
+				}
 			}
 
 			// create and add power perms
@@ -209,7 +212,7 @@ func doUpdateReservation(resName string, editParams map[string]interface{}, r *h
 			// skip if not using vlan
 			if igor.Vlan.Network != "" {
 				// update network config
-				if nsErr := networkSet(addHosts, res.Vlan); nsErr != nil {
+				if _, nsErr := res.networkSet(addHosts); nsErr != nil {
 					return fmt.Errorf("error setting network isolation: %v", nsErr)
 				}
 			}
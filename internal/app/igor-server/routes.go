@@ -7,7 +7,6 @@ package igorserver
 import (
 	"fmt"
 	"net/http"
-	"path/filepath"
 	"strings"
 
 	"igor2/internal/pkg/api"
@@ -36,10 +35,27 @@ func applyCbRoutes(router *httprouter.Router) {
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbLocal))
 	router.Handle(http.MethodGet, api.CbInfo, hcCb.ApplyTo(getInfo))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbInfo))
+	router.Handle(http.MethodPost, api.CbInventory, hcCb.ApplyTo(handleCbInventory))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.CbInventory))
 	router.Handle(http.MethodGet, api.Public, hcCb.ApplyTo(publicShowHandler))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.Public))
+	router.Handle(http.MethodGet, api.CbIpxe+"/:mac", hcCb.ApplyTo(handleCbIpxe))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbIpxe+"/:mac"))
+	router.Handle(http.MethodGet, api.CbBoot+"/:hostName/:resName/:resID/:sig/:file", hcCb.ApplyTo(handleCbBootFile))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbBoot+"/:hostName/:resName/:resID/:sig/:file"))
+	router.Handle(http.MethodGet, api.CbDisk+"/:imageName", hcCb.ApplyTo(handleCbDisk))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbDisk+"/:imageName"))
+	router.Handle(http.MethodPost, api.CbImaged, hcCb.ApplyTo(handleCbImaged))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.CbImaged))
+	router.Handle(http.MethodGet, api.CbIso+"/:imageName/*filepath", hcCb.ApplyTo(handleCbIso))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbIso+"/:imageName/*filepath"))
+	router.Handle(http.MethodGet, api.CbKeys, hcCb.ApplyTo(handleCbKeys))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbKeys))
+	router.Handle(http.MethodGet, api.CbKS+"/*filepath", hcCb.ApplyTo(handleCbKickstart))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbKS+"/*filepath"))
+	router.Handle(http.MethodGet, api.CbNoCloud+"/:file", hcCb.ApplyTo(handleCbNoCloud))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.CbNoCloud+"/:file"))
 	logger.Debug().Msgf("registered node callback routes:\n%s", strings.Join(routes, "\n"))
-	router.ServeFiles(api.CbKS+"/*filepath", http.Dir(filepath.Join(igor.TFTPPath, igor.KickstartDir)))
 	router.ServeFiles(api.CbScript+"/*filepath", http.Dir(igor.Server.ScriptDir))
 }
 
@@ -125,6 +141,22 @@ func applyApiRoutes(router *httprouter.Router) {
 	router.Handle(http.MethodGet, api.Hosts, hcReadHosts.ApplyTo(handleReadHosts))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.Hosts))
 
+	// Read host health check history
+	hcReadHostHealth := NewHandlerChain()
+	hcReadHostHealth.Extend(hcDefaultChain)
+	hcReadHostHealth.Extend(hcAuthChain)
+	hcReadHostHealth.Add(validateHealthParams)
+	router.Handle(http.MethodGet, api.HostsHealth, hcReadHostHealth.ApplyTo(handleReadHostHealth))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.HostsHealth))
+
+	// Read host status history
+	hcReadHostStatusHist := NewHandlerChain()
+	hcReadHostStatusHist.Extend(hcDefaultChain)
+	hcReadHostStatusHist.Extend(hcAuthChain)
+	hcReadHostStatusHist.Add(validateStatusHistoryParams)
+	router.Handle(http.MethodGet, api.HostsStatusHist, hcReadHostStatusHist.ApplyTo(handleReadHostStatusHistory))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.HostsStatusHist))
+
 	// Update hosts
 	hcUpdateHost := NewHandlerChain()
 	hcUpdateHost.Extend(hcDefaultChain)
@@ -160,6 +192,30 @@ func applyApiRoutes(router *httprouter.Router) {
 	router.Handle(http.MethodPatch, api.HostsBlock, hcBlockHosts.ApplyTo(handleBlockHosts))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPatch, api.HostsBlock))
 
+	hcStateHosts := NewHandlerChain()
+	hcStateHosts.Extend(hcDefaultChain)
+	hcStateHosts.Add(storeJSONBodyHandler)
+	hcStateHosts.Extend(hcAuthChain)
+	hcStateHosts.Add(validateStateParams)
+	router.Handle(http.MethodPatch, api.HostsState, hcStateHosts.ApplyTo(handleStateHosts))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPatch, api.HostsState))
+
+	// bulk host import/export
+	hcImportHosts := NewHandlerChain()
+	hcImportHosts.Extend(hcDefaultChain)
+	hcImportHosts.Add(storeJSONBodyHandler)
+	hcImportHosts.Extend(hcAuthChain)
+	hcImportHosts.Add(validateHostImportParams)
+	router.Handle(http.MethodPost, api.HostsImport, hcImportHosts.ApplyTo(handleImportHosts))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.HostsImport))
+
+	hcExportHosts := NewHandlerChain()
+	hcExportHosts.Extend(hcDefaultChain)
+	hcExportHosts.Extend(hcAuthChain)
+	hcExportHosts.Add(validateHostImportParams)
+	router.Handle(http.MethodGet, api.HostsExport, hcExportHosts.ApplyTo(handleExportHosts))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.HostsExport))
+
 	hcApplHostPolicy := NewHandlerChain()
 	hcApplHostPolicy.Extend(hcDefaultChain)
 	hcApplHostPolicy.Add(storeJSONBodyHandler)
@@ -202,6 +258,108 @@ func applyApiRoutes(router *httprouter.Router) {
 	router.Handle(http.MethodDelete, api.HostPolicyName, hcDeleteHostPolicy.ApplyTo(handleDeleteHostPolicy))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodDelete, api.HostPolicyName))
 
+	// Create boot template
+	hcCreateBootTemplate := NewHandlerChain()
+	hcCreateBootTemplate.Extend(hcDefaultChain)
+	hcCreateBootTemplate.Add(storeJSONBodyHandler)
+	hcCreateBootTemplate.Extend(hcAuthChain)
+	hcCreateBootTemplate.Add(validateBootTemplateParams)
+	router.Handle(http.MethodPost, api.BootTemplates, hcCreateBootTemplate.ApplyTo(handleCreateBootTemplate))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.BootTemplates))
+
+	// Read boot templates
+	hcReadBootTemplates := NewHandlerChain()
+	hcReadBootTemplates.Extend(hcDefaultChain)
+	hcReadBootTemplates.Extend(hcAuthChain)
+	hcReadBootTemplates.Add(validateBootTemplateParams)
+	router.Handle(http.MethodGet, api.BootTemplates, hcReadBootTemplates.ApplyTo(handleReadBootTemplates))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.BootTemplates))
+
+	// Delete boot templates
+	hcDeleteBootTemplate := NewHandlerChain()
+	hcDeleteBootTemplate.Extend(hcDefaultChain)
+	hcDeleteBootTemplate.Extend(hcAuthChain)
+	hcDeleteBootTemplate.Add(validateBootTemplateParams)
+	router.Handle(http.MethodDelete, api.BootTemplateBreed, hcDeleteBootTemplate.ApplyTo(handleDeleteBootTemplate))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodDelete, api.BootTemplateBreed))
+
+	// Preview a boot template
+	hcRenderBootTemplate := NewHandlerChain()
+	hcRenderBootTemplate.Extend(hcDefaultChain)
+	hcRenderBootTemplate.Add(storeJSONBodyHandler)
+	hcRenderBootTemplate.Extend(hcAuthChain)
+	hcRenderBootTemplate.Add(validateBootTemplateParams)
+	router.Handle(http.MethodPost, api.BootTemplatesRender, hcRenderBootTemplate.ApplyTo(handleRenderBootTemplate))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.BootTemplatesRender))
+
+	// Create vlan pool
+	hcCreateVlanPool := NewHandlerChain()
+	hcCreateVlanPool.Extend(hcDefaultChain)
+	hcCreateVlanPool.Add(storeJSONBodyHandler)
+	hcCreateVlanPool.Extend(hcAuthChain)
+	hcCreateVlanPool.Add(validateVlanPoolParams)
+	router.Handle(http.MethodPost, api.VlanPools, hcCreateVlanPool.ApplyTo(handleCreateVlanPool))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.VlanPools))
+
+	// Read vlan pools
+	hcReadVlanPools := NewHandlerChain()
+	hcReadVlanPools.Extend(hcDefaultChain)
+	hcReadVlanPools.Extend(hcAuthChain)
+	hcReadVlanPools.Add(validateVlanPoolParams)
+	router.Handle(http.MethodGet, api.VlanPools, hcReadVlanPools.ApplyTo(handleReadVlanPools))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.VlanPools))
+
+	// Update vlan pool
+	hcUpdateVlanPool := NewHandlerChain()
+	hcUpdateVlanPool.Extend(hcDefaultChain)
+	hcUpdateVlanPool.Add(storeJSONBodyHandler)
+	hcUpdateVlanPool.Extend(hcAuthChain)
+	hcUpdateVlanPool.Add(validateVlanPoolParams)
+	router.Handle(http.MethodPatch, api.VlanPoolsName, hcUpdateVlanPool.ApplyTo(handleUpdateVlanPool))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPatch, api.VlanPoolsName))
+
+	// Delete vlan pool
+	hcDeleteVlanPool := NewHandlerChain()
+	hcDeleteVlanPool.Extend(hcDefaultChain)
+	hcDeleteVlanPool.Extend(hcAuthChain)
+	hcDeleteVlanPool.Add(validateVlanPoolParams)
+	router.Handle(http.MethodDelete, api.VlanPoolsName, hcDeleteVlanPool.ApplyTo(handleDeleteVlanPool))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodDelete, api.VlanPoolsName))
+
+	// Create named vlan
+	hcCreateVlan := NewHandlerChain()
+	hcCreateVlan.Extend(hcDefaultChain)
+	hcCreateVlan.Add(storeJSONBodyHandler)
+	hcCreateVlan.Extend(hcAuthChain)
+	hcCreateVlan.Add(validateVlanParams)
+	router.Handle(http.MethodPost, api.Vlans, hcCreateVlan.ApplyTo(handleCreateVlan))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.Vlans))
+
+	// Read vlans
+	hcReadVlans := NewHandlerChain()
+	hcReadVlans.Extend(hcDefaultChain)
+	hcReadVlans.Extend(hcAuthChain)
+	hcReadVlans.Add(validateVlanParams)
+	router.Handle(http.MethodGet, api.Vlans, hcReadVlans.ApplyTo(handleReadVlans))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.Vlans))
+
+	// Update named vlan
+	hcUpdateVlan := NewHandlerChain()
+	hcUpdateVlan.Extend(hcDefaultChain)
+	hcUpdateVlan.Add(storeJSONBodyHandler)
+	hcUpdateVlan.Extend(hcAuthChain)
+	hcUpdateVlan.Add(validateVlanParams)
+	router.Handle(http.MethodPatch, api.VlansName, hcUpdateVlan.ApplyTo(handleUpdateVlan))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPatch, api.VlansName))
+
+	// Delete named vlan
+	hcDeleteVlan := NewHandlerChain()
+	hcDeleteVlan.Extend(hcDefaultChain)
+	hcDeleteVlan.Extend(hcAuthChain)
+	hcDeleteVlan.Add(validateVlanParams)
+	router.Handle(http.MethodDelete, api.VlansName, hcDeleteVlan.ApplyTo(handleDeleteVlan))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodDelete, api.VlansName))
+
 	// Create reservations
 	hcCreateResv := NewHandlerChain()
 	hcCreateResv.Extend(hcDefaultChain)
@@ -270,6 +428,31 @@ func applyApiRoutes(router *httprouter.Router) {
 	router.Handle(http.MethodDelete, api.UsersName, hcDeleteUsers.ApplyTo(handleDeleteUser))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodDelete, api.UsersName))
 
+	// Add user ssh keys
+	hcCreateSSHKey := NewHandlerChain()
+	hcCreateSSHKey.Extend(hcDefaultChain)
+	hcCreateSSHKey.Add(storeJSONBodyHandler)
+	hcCreateSSHKey.Extend(hcAuthChain)
+	hcCreateSSHKey.Add(validateSSHKeyParams)
+	router.Handle(http.MethodPost, api.UserKeys, hcCreateSSHKey.ApplyTo(handleCreateSSHKey))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPost, api.UserKeys))
+
+	// Read user ssh keys
+	hcReadSSHKeys := NewHandlerChain()
+	hcReadSSHKeys.Extend(hcDefaultChain)
+	hcReadSSHKeys.Extend(hcAuthChain)
+	hcReadSSHKeys.Add(validateSSHKeyParams)
+	router.Handle(http.MethodGet, api.UserKeys, hcReadSSHKeys.ApplyTo(handleReadSSHKeys))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.UserKeys))
+
+	// Delete user ssh keys
+	hcDeleteSSHKey := NewHandlerChain()
+	hcDeleteSSHKey.Extend(hcDefaultChain)
+	hcDeleteSSHKey.Extend(hcAuthChain)
+	hcDeleteSSHKey.Add(validateSSHKeyParams)
+	router.Handle(http.MethodDelete, api.UserKeysName, hcDeleteSSHKey.ApplyTo(handleDeleteSSHKey))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodDelete, api.UserKeysName))
+
 	// Do elevate user
 	hcElevateUsers := NewHandlerChain()
 	hcElevateUsers.Extend(hcDefaultChain)
@@ -373,6 +556,15 @@ func applyApiRoutes(router *httprouter.Router) {
 	router.Handle(http.MethodGet, api.Images, hcReadDistroImages.ApplyTo(handleReadDistroImage))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.Images))
 
+	// Approve or validate distro images
+	hcUpdateDistroImages := NewHandlerChain()
+	hcUpdateDistroImages.Extend(hcDefaultChain)
+	hcUpdateDistroImages.Add(storeJSONBodyHandler)
+	hcUpdateDistroImages.Extend(hcAuthChain)
+	hcUpdateDistroImages.Add(validateDistroImageParams)
+	router.Handle(http.MethodPatch, api.ImagesName, hcUpdateDistroImages.ApplyTo(handleUpdateDistroImage))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodPatch, api.ImagesName))
+
 	// Delete distro images
 	hcDeleteDistroImages := NewHandlerChain()
 	hcDeleteDistroImages.Extend(hcDefaultChain)
@@ -450,6 +642,13 @@ func applyApiRoutes(router *httprouter.Router) {
 	router.Handle(http.MethodGet, api.Sync, hcSync.ApplyTo(syncHandler))
 	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.Sync))
 
+	// Get scheduled vlan reconcile status
+	hcSyncStatus := NewHandlerChain()
+	hcSyncStatus.Extend(hcDefaultChain)
+	hcSyncStatus.Extend(hcAuthChain)
+	router.Handle(http.MethodGet, api.SyncStatus, hcSyncStatus.ApplyTo(handleSyncStatus))
+	routes = append(routes, fmt.Sprintf("        -> %s %s", http.MethodGet, api.SyncStatus))
+
 	// Run Token IAuth Secret Reset command
 	hcTokenAuthKeyReset := NewHandlerChain()
 	hcTokenAuthKeyReset.Extend(hcDefaultChain)
@@ -33,14 +33,14 @@ func scheduleHostsByName(res *Reservation, tx *gorm.DB, clog *zl.Logger) (int, e
 		}
 	}
 
-	// check if all hosts are in an available state
-	status, err := dbCheckHostAvailable(hostNameList, tx)
+	// check if all hosts are in an available state (admins may also reserve hosts in burn-in)
+	isElevated := userElevated(res.Owner.Name)
+	status, err := dbCheckHostAvailable(hostNameList, isElevated, tx)
 	if err != nil {
 		return status, err
 	}
 
 	// check that no hosts have conflicts in their host policy
-	isElevated := userElevated(res.Owner.Name)
 	status, err = dbCheckHostPolicyConflicts(hostNameList, groupAccessList, isElevated, res.Start, res.End, res.End, clog)
 	if err != nil {
 		return status, err
@@ -515,18 +515,21 @@ func installReservations(checkTime *time.Time) error {
 			if !r.Installed {
 				// sanity check that the hosts having their state updated should be HOST_AVAILABLE (0)
 				for _, h := range r.Hosts {
-					if h.State > HostAvailable {
+					if h.State > HostAvailable && h.State != HostBurnIn {
 						logger.Error().Msgf("host %s for reservation '%s' start in the state %v before being made available", h.Name, r.Name, h.State)
 					}
 				}
 
+				var snap networkSnapshot
 				if err = performDbTx(func(tx *gorm.DB) error {
 
 					// change the reservation's hosts to 'reserved'
 					logger.Debug().Msg("changing state of reservation hosts to reserved")
 					changes := map[string]interface{}{"State": HostReserved}
-					if ehErr := dbEditHosts(r.Hosts, changes, tx); ehErr != nil {
-						return ehErr
+					if resHosts := excludeBurnInHosts(r.Hosts); len(resHosts) > 0 {
+						if ehErr := dbEditHosts(resHosts, changes, tx); ehErr != nil {
+							return ehErr
+						}
 					}
 
 					// create the power permission for the reservation's hosts and add it to the permissions table
@@ -542,8 +545,9 @@ func installReservations(checkTime *time.Time) error {
 
 					// skip if not using vlan
 					if igor.Vlan.Network != "" {
-						// update network config
-						if nsErr := networkSet(r.Hosts, r.Vlan); nsErr != nil {
+						// update network config; a failed change has already been rolled back
+						var nsErr error
+						if snap, nsErr = r.networkSet(r.Hosts); nsErr != nil {
 							return fmt.Errorf("error setting network isolation: %v", nsErr)
 						}
 					}
@@ -551,10 +555,6 @@ func installReservations(checkTime *time.Time) error {
 					// install the reservation's profile to its hosts
 					logger.Debug().Msgf("installing PXE files for reservation %s", r.Name)
 					if irErr := igor.IResInstaller.Install(&r); irErr != nil {
-						// update the reservation with the error message
-						if irErr = dbEditReservation(&r, map[string]interface{}{"install_error": irErr.Error()}, tx); irErr != nil {
-							return irErr
-						}
 						return irErr
 					}
 
@@ -569,10 +569,22 @@ func installReservations(checkTime *time.Time) error {
 					}
 
 					// update the reservation as installed
-					return dbEditReservation(&r, map[string]interface{}{"installed": true}, tx)
+					return dbEditReservation(&r, map[string]interface{}{"installed": true, "install_error": ""}, tx)
 
 				}); err != nil {
 					logger.Error().Msgf("failed to install reservation '%s' - %v", r.Name, err)
+					installErr := err.Error()
+					// undo the network change if a step after it failed
+					if rbErr := networkRollback(r.allVlanPorts(r.Hosts), snap); rbErr != nil {
+						logger.Error().Msgf("failed to roll back network changes for reservation '%s' - %v", r.Name, rbErr)
+						installErr += fmt.Sprintf("; rollback to previous port vlans also failed: %v", rbErr)
+					}
+					// the install is attempted again on the next check
+					if ieErr := performDbTx(func(tx *gorm.DB) error {
+						return dbEditReservation(&r, map[string]interface{}{"install_error": installErr}, tx)
+					}); ieErr != nil {
+						logger.Error().Msgf("failed to record install error for reservation '%s' - %v", r.Name, ieErr)
+					}
 					continue
 				}
 
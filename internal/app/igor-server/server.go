@@ -17,6 +17,8 @@ import (
 	"syscall"
 	"time"
 
+	"igor2/internal/pkg/tftp"
+
 	"github.com/rs/cors"
 	//_ "net/http/pprof"
 )
@@ -28,6 +30,7 @@ var (
 	resNotifyChan     = make(chan ResNotifyEvent, 100)
 	acctNotifyChan    = make(chan AcctNotifyEvent, 100)
 	groupNotifyChan   = make(chan GroupNotifyEvent, 100)
+	hostNotifyChan    = make(chan HostNotifyEvent, 100)
 	refreshStatusChan = make(chan struct{}, 250)
 	clusterUpdateChan = make(chan struct{})
 	shutdownChan      = make(chan struct{})
@@ -44,6 +47,10 @@ func runServer() {
 	logger.Info().Msg("starting reservation manager")
 	go reservationManager()
 
+	// start the database write manager used by code that can't write while a transaction is open
+	wg.Add(1)
+	go dbWriteManager()
+
 	// start maintenance manager if a maintenance period has been specified
 	if igor.Maintenance.HostMaintenanceDuration > 0 {
 		logger.Info().Msgf("starting maintenance manager; host maintanance interval set to %v minutes",
@@ -63,6 +70,25 @@ func runServer() {
 		logger.Warn().Msg("notification manager is disabled")
 	}
 
+	// the health check manager will not run if no checks are configured
+	if len(igor.HealthCheck.Checks) > 0 {
+		logger.Info().Msgf("starting health check manager; %d check(s) configured", len(igor.HealthCheck.Checks))
+		wg.Add(1)
+		go healthCheckManager()
+	} else {
+		logger.Warn().Msg("health check manager is disabled")
+	}
+
+	// the vlan reconcile manager will not run without vlan segmentation or if disabled in config
+	if igor.Vlan.Network != "" && igor.Vlan.Reconcile.Interval > 0 {
+		logger.Info().Msgf("starting vlan reconcile manager; running every %d minute(s), force=%v",
+			igor.Vlan.Reconcile.Interval, igor.Vlan.Reconcile.Force)
+		wg.Add(1)
+		go vlanReconcileManager()
+	} else {
+		logger.Warn().Msg("vlan reconcile manager is disabled")
+	}
+
 	// the group sync manager will not run if disabled in config
 	if igor.Auth.Ldap.Sync.EnableUserSync || igor.Auth.Ldap.Sync.EnableGroupSync {
 		logger.Info().Msgf("starting LDAP sync manager; sync types (users=%v, groups=%v)",
@@ -137,6 +163,15 @@ func runServer() {
 		cbSrv.TLSConfig = tlsConfig
 	}
 
+	var tftpSrv *tftp.Server
+	if igor.Tftp.Enabled {
+		tftpSrv = startTFTPServer()
+	}
+
+	if err := refreshBootTemplates(); err != nil {
+		logger.Error().Msgf("failed to load boot templates, built-in templates will be used - %v", err)
+	}
+
 	go fillKernelInfoBacklog()
 
 	// Initialize and start the initrd job queue
@@ -159,6 +194,10 @@ func runServer() {
 
 		shutdownServer(apiSrv, "REST service")
 		shutdownServer(cbSrv, "node callback service")
+		if tftpSrv != nil {
+			_ = tftpSrv.Close()
+			logger.Info().Msg("built-in tftp server shut down")
+		}
 
 		close(shutdownChan) // shuts down reservationManager and notificationManager
 	}()
@@ -319,6 +358,11 @@ func notificationManager() {
 			if err := processGroupNotifyEvent(groupNotifyMsg); err != nil {
 				logger.Error().Msgf("%v", err)
 			}
+		case hostNotifyMsg := <-hostNotifyChan:
+			logger.Debug().Msg("received a host event message")
+			if err := processHostNotifyEvent(hostNotifyMsg); err != nil {
+				logger.Error().Msgf("%v", err)
+			}
 		case resNotifyMsg := <-resNotifyChan:
 			logger.Debug().Msg("received a reservation event message")
 			// do something with the event
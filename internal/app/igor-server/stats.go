@@ -176,6 +176,15 @@ func runStats(optionParams map[string][]string) (stats common.StatsData, status
 		}
 		stats.ByUser = byUser
 		stats.Global = global
+
+		// summarize host status changes over the same window to help spot unreliable hardware
+		statusRecords, srErr := dbReadHostStatusRecordsTx(nil, start, end, 0)
+		if srErr != nil {
+			logger.Error().Msgf("stats: problem reading host status history - %v", srErr)
+		} else {
+			window := time.Duration(igor.StatusHistory.FlapWindow) * time.Minute
+			stats.Reliability = getHostReliability(statusRecords, start, end, window, igor.StatusHistory.FlapThreshold)
+		}
 	}
 
 	return
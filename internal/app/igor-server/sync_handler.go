@@ -20,7 +20,7 @@ import (
 
 func syncHandler(w http.ResponseWriter, r *http.Request) {
 	// runs a sync command on a given option
-	// options currently include: arista
+	// options currently include: vlan, or the name of a network driver (arista, nxapi)
 	clog := hlog.FromRequest(r)
 	actionPrefix := "sync"
 	rb := common.NewResponseBody()
@@ -37,6 +37,26 @@ func syncHandler(w http.ResponseWriter, r *http.Request) {
 	makeJsonResponse(w, status, rb)
 }
 
+// handleSyncStatus returns the result of the most recent scheduled vlan reconcile.
+func handleSyncStatus(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+	actionPrefix := "sync status"
+	rb := common.NewResponseBodySyncStatus()
+	status := http.StatusOK
+
+	if igor.Vlan.Network == "" || igor.Vlan.Reconcile.Interval <= 0 {
+		status = http.StatusConflict
+		stdErrorResp(rb, status, actionPrefix, fmt.Errorf("scheduled vlan reconcile is not enabled"), clog)
+	} else if vs := getVlanSyncStatus(); vs == nil {
+		rb.Message = fmt.Sprintf("vlan reconcile has not run yet; it runs every %d minute(s)", igor.Vlan.Reconcile.Interval)
+	} else {
+		rb.Data["status"] = *vs
+		clog.Info().Msgf("%s success", actionPrefix)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
 // Gather data integrity information, report, and fix
 func runSync(params map[string][]string) (result map[string]interface{}, status int, err error) {
 	// change based on outcome
@@ -57,25 +77,25 @@ func runSync(params map[string][]string) (result map[string]interface{}, status
 	// already check if present in validation
 	cmd := strings.ToLower(params["cmd"][0])
 
-	switch cmd {
-	case "arista":
-		if igor.Vlan.Network == "" {
-			// they're not doing vlan segmentation
-			err := fmt.Errorf("not doing vlan segmentation, nothing to sync")
-			return nil, http.StatusBadRequest, err
-		}
-		return syncArista(force, quiet, scope)
-	default:
+	_, isDriver := networkVlanFuncs[cmd]
+	if cmd != "vlan" && !isDriver {
 		status = http.StatusBadRequest
 		err = fmt.Errorf("sync command %v not recognized", cmd)
 		return
 	}
+	if igor.Vlan.Network == "" {
+		// they're not doing vlan segmentation
+		err := fmt.Errorf("not doing vlan segmentation, nothing to sync")
+		return nil, http.StatusBadRequest, err
+	}
+	return syncVlan(cmd, force, quiet, scope)
 }
 
-// sync builds a map of all hosts, where each host has the following
+// syncVlan builds a map of all hosts, where each host has the following
 // information captured about it:
-// map["Host.Name"]{"powered":string, "res_vlan":string, "actual_vlan":string}
-func syncArista(force, quiet bool, scope string) (result map[string]interface{}, status int, err error) {
+// map["Host.Name"]{"powered":string, "switch":string, "res_vlan":string, "switch_vlan":string}
+// If cmd is the name of a network driver, only hosts on switches using that driver are included.
+func syncVlan(cmd string, force, quiet bool, scope string) (result map[string]interface{}, status int, err error) {
 	result = make(map[string]interface{})
 	hosts := []Host{}
 	// determine scope of sync
@@ -121,11 +141,21 @@ func syncArista(force, quiet bool, scope string) (result map[string]interface{},
 		}
 	}
 
-	// get Arista vlan data
-	logger.Debug().Msg("retrieving Arista data, this may take a few moments...")
+	if cmd != "vlan" {
+		var driverHosts []Host
+		for _, h := range hosts {
+			if sw := getSwitch(h.SwitchName); sw != nil && sw.Network == cmd {
+				driverHosts = append(driverHosts, h)
+			}
+		}
+		hosts = driverHosts
+	}
+
+	// get vlan data merged from every configured switch
+	logger.Debug().Msgf("retrieving switch data from %d switch(es), this may take a few moments...", len(igor.Vlan.Switches))
 	gt, err := networkVlan()
 	if err != nil {
-		logger.Error().Msg("Error gathering VLAN data from Arista")
+		logger.Error().Msg("Error gathering VLAN data from switches")
 		return result, http.StatusInternalServerError, err
 	}
 
@@ -148,13 +178,39 @@ func syncArista(force, quiet bool, scope string) (result map[string]interface{},
 	for _, host := range hosts {
 		for _, r := range host.Reservations {
 			if r.IsActive(time.Now()) {
-				withRes[host.Name] = map[string]interface{}{"res_vlan": strconv.Itoa(r.Vlan)}
+				withRes[host.Name] = map[string]interface{}{"res_vlan": strconv.Itoa(r.hostVlan(host))}
 			}
 		}
 	}
 
 	// report to construct
 	report := make(map[string]map[string]string)
+	// syncPort compares a host port with the vlan it should be in and corrects it if force is set
+	syncPort := func(data map[string]string, port Host) error {
+		if sw := getSwitch(port.SwitchName); sw != nil {
+			data["switch"] = sw.Name
+		}
+		data["switch_vlan"] = gt[portKey(port)]
+		// if the switch had no vlan assigned, make explicit for readability
+		if data["switch_vlan"] == "0" || data["switch_vlan"] == "" {
+			data["switch_vlan"] = "(none)"
+		}
+
+		if force && data["res_vlan"] != data["switch_vlan"] {
+			vlan, err := strconv.Atoi(data["res_vlan"])
+			if err != nil {
+				return err
+			}
+			if err := networkSet([]Host{port}, vlan); err != nil {
+				logger.Error().Msgf("unable to set up network isolation for host %v port %v", port.Name, port.Eth)
+				data["status"] = "VLAN correction failed!"
+			} else {
+				data["status"] = "VLAN correction succeeded"
+			}
+		}
+		return nil
+	}
+
 	// aggregate all to report and sync the node if force
 	hostStatusMapMU.Lock()
 	for _, host := range hosts {
@@ -186,30 +242,36 @@ func syncArista(force, quiet bool, scope string) (result map[string]interface{},
 			data["powered"] = "unknown"
 		}
 
-		data["switch_vlan"] = gt[host_name]
-		// if arista had no vlan assigned, make explicit for readability
-		if data["switch_vlan"] == "0" || data["switch_vlan"] == "" {
-			data["switch_vlan"] = "(none)"
+		if err := syncPort(data, host); err != nil {
+			hostStatusMapMU.Unlock()
+			return result, http.StatusInternalServerError, err
 		}
+		report[host_name] = data
 
-		if force && data["res_vlan"] != data["switch_vlan"] {
-			vlan, err := strconv.Atoi(data["res_vlan"])
-			if err != nil {
-				return result, http.StatusInternalServerError, err
+		// interface ports the reservation puts in its vlans are reported on rows of their own
+		for _, r := range host.Reservations {
+			if !r.IsActive(time.Now()) {
+				continue
 			}
-			if err := networkSet([]Host{host}, vlan); err != nil {
-				logger.Error().Msgf("unable to set up network isolation for host %v", host_name)
-				data["status"] = "VLAN correction failed!"
-			} else {
-				data["status"] = "VLAN correction succeeded"
+			for _, seg := range r.vlanSegments([]Host{host}) {
+				for _, p := range seg.Ports {
+					if p.Eth == host.Eth && p.SwitchName == host.SwitchName {
+						continue
+					}
+					portData := map[string]string{"powered": data["powered"], "res_vlan": strconv.Itoa(seg.Vlan)}
+					if err := syncPort(portData, p); err != nil {
+						hostStatusMapMU.Unlock()
+						return result, http.StatusInternalServerError, err
+					}
+					report[fmt.Sprintf("%s (%s)", host_name, p.Eth)] = portData
+				}
 			}
 		}
-		report[host_name] = data
 	}
 	hostStatusMapMU.Unlock()
 
-	logger.Debug().Msgf("report compiled by syncArista: %v", report)
-	result["command"] = "arista"
+	logger.Debug().Msgf("report compiled by syncVlan: %v", report)
+	result["command"] = cmd
 	result["report"] = report
 	result["force"] = strconv.FormatBool(force)
 	result["quiet"] = strconv.FormatBool(quiet)
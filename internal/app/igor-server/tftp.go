@@ -9,8 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	"igor2/internal/pkg/api"
+	"text/template"
 )
 
 type TFTPInstaller struct {
@@ -37,7 +36,32 @@ func (b *TFTPInstaller) Install(r *Reservation) error {
 }
 
 func generateBootFile(host *Host, r *Reservation) error {
-	var content string
+	content, masterPath, err := makeBootConfig(host, r, nil)
+	if err != nil {
+		logger.Error().Msgf("%s: generate boot file - %v", host.Name, err)
+		return fmt.Errorf("%s", host.Name)
+	}
+	pxePath := getPxePath(host)
+
+	// Write master to backup
+	if err := writeBootConfig(masterPath, host.Name, content); err != nil {
+		logger.Error().Msgf("%s: res install - error writing master config to backup path - %s", host.Name, err.Error())
+		return fmt.Errorf("%s", host.Name)
+	}
+
+	// Write the content to the file
+	if err := writeBootConfig(pxePath, host.Name, content); err != nil {
+		logger.Error().Msgf("%s: res install - error writing master config to main path - %s", host.Name, err.Error())
+		return fmt.Errorf("%s", host.Name)
+	}
+	recordBootStage(r, host.Name, BootStagePxe, host.BootMode)
+	return nil
+}
+
+// makeBootConfig returns the boot config of the host for the reservation and the path its backup copy
+// is written to. The installer arguments come from the active boot template of the image breed unless
+// another template is given, which lets admins preview a template before saving it.
+func makeBootConfig(host *Host, r *Reservation, tmpl *template.Template) (content, masterPath string, err error) {
 	image := r.Profile.Distro.DistroImage
 	kernelPath := filepath.Join(igor.ImageStoreDir, image.ImageID, image.Kernel)
 	initrdPath := filepath.Join(igor.ImageStoreDir, image.ImageID, image.Initrd)
@@ -45,9 +69,6 @@ func generateBootFile(host *Host, r *Reservation) error {
 	bootMode := host.BootMode
 	osType := image.Breed
 
-	masterPath := filepath.Join(igor.TFTPPath, igor.PXEBIOSDir, "igor", host.Name)
-	pxePath := getPxePath(host)
-
 	kernel_args := ""
 	if r.Profile.Distro.KernelArgs != "" {
 		kernel_args = fmt.Sprintf("%s %s", kernel_args, r.Profile.Distro.KernelArgs)
@@ -55,12 +76,23 @@ func generateBootFile(host *Host, r *Reservation) error {
 	if r.Profile.KernelArgs != "" {
 		kernel_args = fmt.Sprintf("%s %s", kernel_args, r.Profile.KernelArgs)
 	}
+	if repoArg := isoRepoArg(osType, isoInstallURL(&image)); repoArg != "" {
+		kernel_args = fmt.Sprintf("%s %s", kernel_args, repoArg)
+	}
+	if diskArgs := diskBootArgs(&image); diskArgs != "" {
+		kernel_args = fmt.Sprintf("%s %s", kernel_args, diskArgs)
+	}
 
-	// Construct the auto-install part of the boot file based on OS type
-	autoInstallFilePath := ""
-	if image.LocalBoot {
-		ksFile := r.Profile.Distro.Kickstart.Filename
-		autoInstallFilePath = fmt.Sprintf("http://%s:%v/%s/%s", igor.Server.CbHost, igor.Server.CbPort, api.CbKS, ksFile)
+	// Render the auto-install part of the boot file from the breed's boot template
+	autoInstallFilePath := kickstartURL(r)
+	var autoInstallPart string
+	if tmpl != nil {
+		autoInstallPart, err = renderBootTemplate(tmpl, newBootTemplateData(host, r, autoInstallFilePath))
+	} else {
+		autoInstallPart, err = renderBootArgs(host, r, autoInstallFilePath)
+	}
+	if err != nil {
+		return "", "", err
 	}
 
 	switch bootMode {
@@ -74,54 +106,27 @@ func generateBootFile(host *Host, r *Reservation) error {
 		if kernel_args != "" {
 			appendStmt = fmt.Sprintf("%s %s", appendStmt, kernel_args)
 		}
-		autoInstallPart := ""
-		if autoInstallFilePath != "" {
-			switch osType {
-			case "redhat":
-				appendStmt = "IPAPPEND 2\n" + appendStmt
-				autoInstallPart = fmt.Sprintf(" inst.lang=  inst.kssendmac text inst.ksdevice=bootif inst.ks=%s ", autoInstallFilePath)
-			case "ubuntu", "debian", "freebsd", "generic", "nexenta", "suse", "unix", "vmware", "windows", "xen":
-				autoInstallPart = fmt.Sprintf(" lang=  netcfg/choose_interface=%s text  auto-install/enable=true priority=critical hostname=%s url=%s domain=local.lan", host.Mac, host.Name, autoInstallFilePath)
-			default:
-				logger.Error().Msgf("%s: generate boot file - unknown OS type: %s", host.Name, osType)
-				return fmt.Errorf("%s", host.Name)
-			}
+		if osType == "redhat" && autoInstallFilePath != "" {
+			// pxelinux adds BOOTIF for the installer to find the boot interface with
+			appendStmt = "IPAPPEND 2\n" + appendStmt
 		}
 		content = fmt.Sprintf("%s\n%s\n%s\n%s\n%s %s\n", defaultLabel, defaultOptions, biosLabel, kernel, appendStmt, autoInstallPart)
+		masterPath = filepath.Join(igor.TFTPPath, igor.PXEBIOSDir, "igor", host.Name)
 	case "uefi":
 		// Generate content for UEFI
 		label := fmt.Sprintf("\"Reservation: %s netbooting %s on host %s\"", r.Name, r.Profile.Distro.Name, host.Name)
-		autoInstallPart := ""
-		if autoInstallFilePath != "" {
-			switch osType {
-			case "redhat":
-				autoInstallPart = fmt.Sprintf(" lang=  inst.kssendmac inst.text inst.ksdevice=bootif inst.ks=%s", autoInstallFilePath)
-			case "ubuntu", "debian", "freebsd", "generic", "nexenta", "suse", "unix", "vmware", "windows", "xen":
-				autoInstallPart = fmt.Sprintf(" lang=  netcfg/choose_interface=%s text  auto-install/enable=true priority=critical url=%s", host.Mac, autoInstallFilePath)
-			default:
-				logger.Error().Msgf("%s: generate boot file - unknown OS type: %s", host.Name, osType)
-				return fmt.Errorf("%s", host.Name)
-			}
-		}
 		content = fmt.Sprintf("set default=install-menu\nset timeout=6\n\nmenuentry %s --id install-menu {\n    linuxefi %s %s %s\n    initrdefi %s\n}\n", label, kernelPath, autoInstallPart, kernel_args, initrdPath)
 		masterPath = filepath.Join(igor.TFTPPath, igor.PXEUEFIDir, "igor", host.Name)
+	case "ipxe":
+		// Generate an iPXE script that fetches the kernel and initrd over HTTP
+		if content, err = ipxeBootScript(host, r, autoInstallPart+" "+kernel_args); err != nil {
+			return "", "", err
+		}
+		masterPath = filepath.Join(igor.TFTPPath, igor.PXEIPXEDir, "igor", host.Name)
 	default:
-		logger.Error().Msgf("%s: generate boot file - unknown boot mode: %s", host.Name, bootMode)
-		return fmt.Errorf("%s", host.Name)
+		return "", "", fmt.Errorf("unknown boot mode: %s", bootMode)
 	}
-
-	// Write master to backup
-	if err := writeFile(masterPath, content); err != nil {
-		logger.Error().Msgf("%s: res install - error writing master config to backup path - %s", host.Name, err.Error())
-		return fmt.Errorf("%s", host.Name)
-	}
-
-	// Write the content to the file
-	if err := writeFile(pxePath, content); err != nil {
-		logger.Error().Msgf("%s: res install - error writing master config to main path - %s", host.Name, err.Error())
-		return fmt.Errorf("%s", host.Name)
-	}
-	return nil
+	return content, masterPath, nil
 }
 
 func (b *TFTPInstaller) Uninstall(r *Reservation) error {
@@ -130,7 +135,7 @@ func (b *TFTPInstaller) Uninstall(r *Reservation) error {
 	for _, host := range r.Hosts {
 		pxePath := getPxePath(&host)
 
-		err := os.Remove(pxePath)
+		err := removeBootConfig(pxePath)
 		if err != nil {
 			// record the failure but no need to halt
 			logger.Warn().Msgf("pxeconfig file for host %v encountered a problem during uninstall: %v", host.Name, err.Error())
@@ -144,12 +149,14 @@ func macToPxeString(macAddr string) string {
 }
 
 func getPxePath(host *Host) string {
-	macString := "01:" + host.Mac
+	macString := "01:" + host.bootMac()
 	switch host.BootMode {
 	case "bios":
 		return filepath.Join(igor.TFTPPath, igor.PXEBIOSDir, macToPxeString(macString))
 	case "uefi":
 		return filepath.Join(igor.TFTPPath, igor.PXEUEFIDir, "grub.cfg-"+macToPxeString(macString))
+	case "ipxe":
+		return filepath.Join(igor.TFTPPath, igor.PXEIPXEDir, macToPxeString(macString))
 	default:
 		return ""
 	}
@@ -216,11 +223,13 @@ menuentry %s --id install-menu {
 	fi
 }
 			`, label)
+	case "ipxe":
+		content = ipxeLocalScript
 	default:
 		return fmt.Errorf("unknown boot mode: %s", host.BootMode)
 	}
 
-	if err := writeFile(path, content); err != nil {
+	if err := writeBootConfig(path, host.Name, content); err != nil {
 		return err
 	}
 	return nil
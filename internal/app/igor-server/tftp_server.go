@@ -0,0 +1,111 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"igor2/internal/pkg/tftp"
+)
+
+// startTFTPServer starts the built-in TFTP server on tftp.listen. It serves the same tree an
+// external tftpd would, plus the boot configs kept in the database when tftp.dbConfigs is set.
+func startTFTPServer() *tftp.Server {
+	conn, err := net.ListenPacket("udp", igor.Tftp.Listen)
+	if err != nil {
+		exitPrintFatal(fmt.Sprintf("could not start the built-in tftp server on %s - %v", igor.Tftp.Listen, err))
+	}
+	srv := &tftp.Server{
+		Handler:    tftpReadFile,
+		OnTransfer: logTFTPTransfer,
+	}
+	go func() {
+		if serveErr := srv.Serve(conn); serveErr != nil {
+			logger.Error().Msgf("built-in tftp server stopped - %v", serveErr)
+		}
+	}()
+	logger.Info().Msgf("built-in tftp server listening on %s", conn.LocalAddr())
+	return srv
+}
+
+// tftpCleanPath turns a requested file name into a path relative to the TFTP root. Leading slashes
+// and DOS separators sent by some boot firmware are accepted, anything outside the root is not.
+func tftpCleanPath(filename string) (string, error) {
+	name := path.Clean("/" + strings.ReplaceAll(filename, "\\", "/"))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		return "", tftp.ErrNotFound
+	}
+	return name, nil
+}
+
+// tftpReadFile opens a requested file, looking in the database first when boot configs are kept
+// there.
+func tftpReadFile(filename string, _ net.Addr) (io.ReadSeeker, error) {
+	name, err := tftpCleanPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	if igor.Tftp.DbConfigs {
+		content, found, readErr := readBootConfig(name)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if found {
+			return strings.NewReader(content), nil
+		}
+	}
+	f, err := os.Open(filepath.Join(igor.TFTPPath, filepath.FromSlash(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, tftp.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if fi, statErr := f.Stat(); statErr != nil || fi.IsDir() {
+		f.Close()
+		return nil, tftp.ErrNotFound
+	}
+	return f, nil
+}
+
+// tftpHost returns the host with the given address and a description of it for the log. The host
+// is nil if the address doesn't belong to a known host.
+func tftpHost(addr net.Addr) (*Host, string) {
+	ip := addr.String()
+	if ua, ok := addr.(*net.UDPAddr); ok {
+		ip = ua.IP.String()
+	}
+	if hosts, _, err := doReadHosts(map[string]interface{}{"ip": ip}); err == nil && len(hosts) > 0 {
+		return &hosts[0], fmt.Sprintf("%s (%s)", hosts[0].Name, ip)
+	}
+	return nil, ip
+}
+
+// logTFTPTransfer records each transfer against the host that made it, and the kernel fetch in the
+// host's boot progress. Boot firmware probes for many config names that don't exist, so those are only
+// logged at debug level.
+func logTFTPTransfer(t tftp.Transfer) {
+	host, who := tftpHost(t.Remote)
+	switch {
+	case t.Err == nil:
+		logger.Info().Msgf("tftp: %s fetched %s - %d bytes in %v", who, t.Filename, t.Bytes, t.Duration.Round(1e6))
+		if host != nil {
+			if name, err := tftpCleanPath(t.Filename); err == nil {
+				recordKernelFetch(host, name, "tftp")
+			}
+		}
+	case errors.Is(t.Err, tftp.ErrNotFound):
+		logger.Debug().Msgf("tftp: %s requested %s - not found", who, t.Filename)
+	default:
+		logger.Warn().Msgf("tftp: %s failed to fetch %s after %d bytes - %v", who, t.Filename, t.Bytes, t.Err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"igor2/internal/pkg/tftp"
+)
+
+func TestTftpCleanPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"pxelinux.0":                "pxelinux.0",
+		"/pxelinux.cfg/default":     "pxelinux.cfg/default",
+		"\\grub\\grub.cfg":          "grub/grub.cfg",
+		"../../etc/passwd":          "etc/passwd",
+		"igor/./r1/../r1/vmlinuz":   "igor/r1/vmlinuz",
+		"pxelinux.cfg//01-aa-bb-cc": "pxelinux.cfg/01-aa-bb-cc",
+	} {
+		got, err := tftpCleanPath(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "/", ".."} {
+		_, err := tftpCleanPath(in)
+		assert.ErrorIs(t, err, tftp.ErrNotFound, in)
+	}
+}
+
+func TestTftpReadFile(t *testing.T) {
+	saved := igor.TFTPPath
+	t.Cleanup(func() { igor.TFTPPath = saved })
+	igor.TFTPPath = t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(igor.TFTPPath, "pxelinux.cfg"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(igor.TFTPPath, "pxelinux.cfg", "default"), []byte("DEFAULT local\n"), 0644))
+
+	rs, err := tftpReadFile("/pxelinux.cfg/default", nil)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(rs)
+	assert.Equal(t, "DEFAULT local\n", string(data))
+	rs.(io.Closer).Close()
+
+	_, err = tftpReadFile("pxelinux.cfg/01-aa-bb-cc-dd-ee-ff", nil)
+	assert.ErrorIs(t, err, tftp.ErrNotFound)
+	_, err = tftpReadFile("pxelinux.cfg", nil)
+	assert.ErrorIs(t, err, tftp.ErrNotFound)
+}
@@ -76,6 +76,9 @@ func dbDeleteUser(user *User, tx *gorm.DB) error {
 	if err := tx.Model(&user).Association("Groups").Clear(); err != nil {
 		return err
 	}
+	if err := dbDeleteUserSSHKeys(user.ID, tx); err != nil {
+		return err
+	}
 
 	result := tx.Delete(&user)
 	return result.Error
@@ -89,6 +89,18 @@ func doDeleteUser(username string, r *http.Request) (status int, err error) {
 			}
 		}
 
+		clog.Debug().Msgf("checking for '%s' owned vlans", username)
+		if ovList, ovErr := dbReadNamedVlans(searchByOwnerID, tx); ovErr != nil {
+			return ovErr // uses default err status
+		} else if len(ovList) > 0 {
+			var ovNames []string
+			for _, ov := range ovList {
+				ovNames = append(ovNames, ov.Name)
+			}
+			status = http.StatusConflict
+			return fmt.Errorf("cannot delete user - remove or transfer ownership of vlans in list first: %v", ovNames)
+		}
+
 		// *** All good! let's start deleting stuff ***
 
 		// remove owned profiles
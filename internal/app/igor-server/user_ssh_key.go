@@ -0,0 +1,86 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+
+	"igor2/internal/pkg/common"
+)
+
+// maxUserSSHKeys is how many SSH keys a user can register
+const maxUserSSHKeys = 20
+
+// UserSSHKey is an SSH public key a user registered with igor. The keys of a reservation's owner and the
+// members of its group are given to the reservation's hosts through kickstart templates, cloud-init and
+// the keys callback.
+type UserSSHKey struct {
+	Base
+	UserID      int    `gorm:"notNull; uniqueIndex:idx_user_key_name; uniqueIndex:idx_user_key_fp"`
+	Name        string `gorm:"notNull; uniqueIndex:idx_user_key_name"`
+	Key         string `gorm:"notNull"` // in authorized_keys form: type, key and comment
+	Fingerprint string `gorm:"notNull; uniqueIndex:idx_user_key_fp"`
+}
+
+func (k *UserSSHKey) getSSHKeyData() common.SSHKeyData {
+	return common.SSHKeyData{
+		Name:        k.Name,
+		Fingerprint: k.Fingerprint,
+		Key:         k.Key,
+		Created:     k.CreatedAt.Unix(),
+	}
+}
+
+// parseSSHKey checks that text is a single SSH public key and returns it on one line with the
+// fingerprint and comment of the key.
+func parseSSHKey(text string) (key, fingerprint, comment string, err error) {
+	pub, comment, options, rest, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(text)))
+	if err != nil {
+		return "", "", "", fmt.Errorf("not a valid SSH public key - %v", err)
+	}
+	if len(options) > 0 {
+		return "", "", "", fmt.Errorf("SSH key options like '%s' are not allowed", options[0])
+	}
+	if len(strings.TrimSpace(string(rest))) > 0 {
+		return "", "", "", fmt.Errorf("give one SSH public key at a time")
+	}
+	key = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+	if comment != "" {
+		key += " " + comment
+	}
+	return key, ssh.FingerprintSHA256(pub), comment, nil
+}
+
+// reservationSSHKeys returns the registered SSH keys of the reservation's owner and of the members of
+// its group, the owner's first. Members of the group of all users are not included.
+func reservationSSHKeys(r *Reservation) (ownerKeys, allKeys []string, err error) {
+	var keys []UserSSHKey
+	if err = performDbTx(func(tx *gorm.DB) error {
+		var kErr error
+		keys, kErr = dbReadReservationSSHKeys(r, tx)
+		return kErr
+	}); err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.UserID == r.OwnerID {
+			ownerKeys = append(ownerKeys, k.Key)
+			seen[k.Fingerprint] = true
+		}
+	}
+	allKeys = append(allKeys, ownerKeys...)
+	for _, k := range keys {
+		if !seen[k.Fingerprint] {
+			allKeys = append(allKeys, k.Key)
+			seen[k.Fingerprint] = true
+		}
+	}
+	return ownerKeys, allKeys, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+)
+
+// dbCreateSSHKey saves a new SSH key for a user.
+func dbCreateSSHKey(key *UserSSHKey, tx *gorm.DB) error {
+	return tx.Create(key).Error
+}
+
+// dbReadSSHKeys returns the SSH keys of a user, oldest first.
+func dbReadSSHKeys(userID int, tx *gorm.DB) (keys []UserSSHKey, err error) {
+	result := tx.Where("user_id = ?", userID).Order("id").Find(&keys)
+	return keys, result.Error
+}
+
+// dbDeleteSSHKey deletes a user's SSH key by name and returns how many were deleted.
+func dbDeleteSSHKey(userID int, name string, tx *gorm.DB) (int64, error) {
+	result := tx.Where("user_id = ? AND name = ?", userID, name).Delete(&UserSSHKey{})
+	return result.RowsAffected, result.Error
+}
+
+// dbDeleteUserSSHKeys deletes every SSH key of a user.
+func dbDeleteUserSSHKeys(userID int, tx *gorm.DB) error {
+	return tx.Where("user_id = ?", userID).Delete(&UserSSHKey{}).Error
+}
+
+// dbReadReservationSSHKeys returns the SSH keys of the reservation owner and of the members of the
+// reservation's group unless it is the group of all users.
+func dbReadReservationSSHKeys(r *Reservation, tx *gorm.DB) (keys []UserSSHKey, err error) {
+	userIDs := []int{r.OwnerID}
+	if r.GroupID != 0 && r.Group.Name != GroupAll {
+		var memberIDs []int
+		if result := tx.Table("groups_users").Where("group_id = ?", r.GroupID).Pluck("user_id", &memberIDs); result.Error != nil {
+			return nil, result.Error
+		}
+		userIDs = append(userIDs, memberIDs...)
+	}
+	result := tx.Where("user_id IN ?", userIDs).Order("id").Find(&keys)
+	return keys, result.Error
+}
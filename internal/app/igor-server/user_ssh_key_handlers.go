@@ -0,0 +1,280 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+
+	"igor2/internal/pkg/common"
+)
+
+// destination for route POST /users/:userName/keys
+func handleCreateSSHKey(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	createParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	userName := httprouter.ParamsFromContext(r.Context()).ByName("userName")
+	actionPrefix := "add ssh key"
+	rb := common.NewResponseBody()
+
+	key, status, err := doCreateSSHKey(userName, createParams, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Message = fmt.Sprintf("ssh key '%s' (%s) added for user %s", key.Name, key.Fingerprint, userName)
+		clog.Info().Msgf("%s success - %s by user %s", actionPrefix, rb.Message, getUserFromContext(r).Name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route GET /users/:userName/keys
+func handleReadSSHKeys(w http.ResponseWriter, r *http.Request) {
+
+	clog := hlog.FromRequest(r)
+	userName := httprouter.ParamsFromContext(r.Context()).ByName("userName")
+	actionPrefix := "read ssh keys"
+	rb := common.NewResponseBodySSHKeys()
+	var keys []UserSSHKey
+
+	status := http.StatusOK
+	err := performDbTx(func(tx *gorm.DB) error {
+		user, uStatus, uErr := getSSHKeyUser(userName, r, tx)
+		if uErr != nil {
+			status = uStatus
+			return uErr
+		}
+		var kErr error
+		if keys, kErr = dbReadSSHKeys(user.ID, tx); kErr != nil {
+			status = http.StatusInternalServerError
+		}
+		return kErr
+	})
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		keyList := make([]common.SSHKeyData, 0, len(keys))
+		for _, k := range keys {
+			keyList = append(keyList, k.getSSHKeyData())
+		}
+		rb.Data["keys"] = keyList
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route DELETE /users/:userName/keys/:keyName
+func handleDeleteSSHKey(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	clog := hlog.FromRequest(r)
+	ps := httprouter.ParamsFromContext(r.Context())
+	userName, keyName := ps.ByName("userName"), ps.ByName("keyName")
+	actionPrefix := "delete ssh key"
+	rb := common.NewResponseBody()
+
+	status := http.StatusOK
+	err := performDbTx(func(tx *gorm.DB) error {
+		user, uStatus, uErr := getSSHKeyUser(userName, r, tx)
+		if uErr != nil {
+			status = uStatus
+			return uErr
+		}
+		count, dErr := dbDeleteSSHKey(user.ID, keyName, tx)
+		if dErr != nil {
+			status = http.StatusInternalServerError
+			return dErr
+		} else if count == 0 {
+			status = http.StatusNotFound
+			return fmt.Errorf("user %s has no ssh key named '%s'", userName, keyName)
+		}
+		return nil
+	})
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Message = fmt.Sprintf("ssh key '%s' of user %s deleted", keyName, userName)
+		clog.Info().Msgf("%s success - %s by user %s", actionPrefix, rb.Message, getUserFromContext(r).Name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route GET /cb/svc/keys
+//
+// Returns the registered SSH keys of the owner and group members of the calling host's active
+// reservation in authorized_keys form.
+func handleCbKeys(w http.ResponseWriter, r *http.Request) {
+	clog := hlog.FromRequest(r)
+
+	host, ip := callerHost(r)
+	if host == nil {
+		clog.Warn().Msgf("serve ssh keys - no host has IP %s", ip)
+		http.NotFound(w, r)
+		return
+	}
+	res := getActiveReservation(host)
+	if res == nil {
+		clog.Warn().Msgf("serve ssh keys - host %s has no active reservation", host.Name)
+		http.NotFound(w, r)
+		return
+	}
+	_, keys, err := reservationSSHKeys(res)
+	if err != nil {
+		clog.Error().Msgf("%s: serve ssh keys for reservation %s - %v", host.Name, res.Name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	content := fmt.Sprintf("# igor reservation %s\n", res.Name)
+	for _, k := range keys {
+		content += k + "\n"
+	}
+	_, _ = w.Write([]byte(content))
+}
+
+func doCreateSSHKey(userName string, params map[string]interface{}, r *http.Request) (key *UserSSHKey, status int, err error) {
+
+	status = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	text, fingerprint, comment, pErr := parseSSHKey(params["key"].(string))
+	if pErr != nil {
+		return nil, http.StatusBadRequest, pErr
+	}
+	key = &UserSSHKey{Key: text, Fingerprint: fingerprint}
+	if name, ok := params["name"].(string); ok {
+		key.Name = strings.TrimSpace(name)
+	} else if checkGenericNameRules(comment) == nil {
+		key.Name = comment
+	}
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+		user, uStatus, uErr := getSSHKeyUser(userName, r, tx)
+		if uErr != nil {
+			status = uStatus
+			return uErr
+		}
+		key.UserID = user.ID
+		keys, kErr := dbReadSSHKeys(user.ID, tx)
+		if kErr != nil {
+			return kErr
+		}
+		if len(keys) >= maxUserSSHKeys {
+			status = http.StatusConflict
+			return fmt.Errorf("user %s already has the most ssh keys allowed (%d)", userName, maxUserSSHKeys)
+		}
+		names := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			if k.Fingerprint == key.Fingerprint {
+				status = http.StatusConflict
+				return fmt.Errorf("this ssh key is already registered as '%s'", k.Name)
+			}
+			names[k.Name] = true
+		}
+		if key.Name == "" || (names[key.Name] && params["name"] == nil) {
+			// pick the first free default name
+			for i := len(keys) + 1; ; i++ {
+				if name := fmt.Sprintf("key%d", i); !names[name] {
+					key.Name = name
+					break
+				}
+			}
+		} else if names[key.Name] {
+			status = http.StatusConflict
+			return fmt.Errorf("user %s already has an ssh key named '%s'", userName, key.Name)
+		}
+		return dbCreateSSHKey(key, tx) // uses default err status
+	}); err != nil {
+		return nil, status, err
+	}
+	return key, http.StatusCreated, nil
+}
+
+// getSSHKeyUser returns the user whose ssh keys a request is for. Users can only manage their own keys
+// unless they are an elevated admin.
+func getSSHKeyUser(userName string, r *http.Request, tx *gorm.DB) (*User, int, error) {
+	actionUser := getUserFromContext(r)
+	if actionUser.Name != userName && !userElevated(actionUser.Name) {
+		return nil, http.StatusForbidden, fmt.Errorf("you cannot manage the ssh keys of user '%s'", userName)
+	}
+	users, status, err := getUsers([]string{userName}, true, tx)
+	if err != nil {
+		return nil, status, err
+	}
+	return &users[0], http.StatusOK, nil
+}
+
+func validateSSHKeyParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		ps := httprouter.ParamsFromContext(r.Context())
+		validateErr = checkUsernameRules(ps.ByName("userName"))
+
+		if validateErr == nil && r.Method == http.MethodPost {
+			keyParams := getBodyFromContext(r)
+			if keyParams == nil {
+				validateErr = NewMissingParamError("")
+			} else if keyParams["key"] == nil {
+				validateErr = NewMissingParamError("key")
+			} else {
+			paramLoop:
+				for key, val := range keyParams {
+					switch key {
+					case "key":
+						if text, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if len(text) > 16384 {
+							validateErr = fmt.Errorf("ssh key is too long")
+							break paramLoop
+						}
+					case "name":
+						if name, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkGenericNameRules(strings.TrimSpace(name)); validateErr != nil {
+							break paramLoop
+						}
+					default:
+						validateErr = NewUnknownParamError(key, val)
+						break paramLoop
+					}
+				}
+			}
+		}
+
+		if validateErr == nil && r.Method == http.MethodDelete {
+			validateErr = checkGenericNameRules(ps.ByName("keyName"))
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateSSHKeyParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSSHKey(t *testing.T) (string, string) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))), ssh.FingerprintSHA256(sshPub)
+}
+
+func TestParseSSHKey(t *testing.T) {
+	text, fp := newTestSSHKey(t)
+
+	key, fingerprint, comment, err := parseSSHKey("  " + text + " alice@laptop\n")
+	require.NoError(t, err)
+	assert.Equal(t, text+" alice@laptop", key)
+	assert.Equal(t, fp, fingerprint)
+	assert.Equal(t, "alice@laptop", comment)
+
+	key, _, comment, err = parseSSHKey(text)
+	require.NoError(t, err)
+	assert.Equal(t, text, key)
+	assert.Empty(t, comment)
+
+	other, _ := newTestSSHKey(t)
+	_, _, _, err = parseSSHKey(`command="/bin/true" ` + text)
+	assert.Error(t, err)
+	_, _, _, err = parseSSHKey(text + "\n" + other)
+	assert.Error(t, err)
+	_, _, _, err = parseSSHKey("ssh-ed25519 notakey")
+	assert.Error(t, err)
+}
+
+func TestSSHKeyPathMatcher(t *testing.T) {
+	assert.True(t, sshKeyPathMatcher.MatchString("/users/alice/keys"))
+	assert.True(t, sshKeyPathMatcher.MatchString("/users/alice/keys/laptop"))
+	assert.False(t, sshKeyPathMatcher.MatchString("/users/alice"))
+	assert.False(t, sshKeyPathMatcher.MatchString("/users/alice/keys/laptop/extra"))
+}
@@ -0,0 +1,550 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"gorm.io/gorm"
+)
+
+const PermVlans = "vlans"
+
+// NamedVlan is a vlan id that stays allocated until it is deleted, so a long-running project can keep the
+// same network across many reservations. Its owner and members of its groups can put reservations on it
+// by giving its name as the reservation vlan.
+type NamedVlan struct {
+	Base
+	Name        string `gorm:"unique; notNull"`
+	VlanID      int    `gorm:"unique; notNull"`
+	Description string
+	OwnerID     int
+	Owner       User
+	Groups      []Group `gorm:"many2many:named_vlans_groups;"`
+}
+
+// canUse returns true if the user may put reservations on the vlan.
+func (v *NamedVlan) canUse(user *User) bool {
+	return v.Owner.Name == user.Name || user.isMemberOfAnyGroup(v.Groups)
+}
+
+// usedVlans returns the vlan ids held by reservations and named vlans.
+func usedVlans(tx *gorm.DB) (map[int]bool, error) {
+	var resVlans []int
+	if result := tx.Model(&Reservation{}).Where("vlan > 0").Pluck("vlan", &resVlans); result.Error != nil {
+		return nil, result.Error
+	}
+	var netVlans []int
+	if result := tx.Model(&ResNetwork{}).Where("vlan > 0").Pluck("vlan", &netVlans); result.Error != nil {
+		return nil, result.Error
+	}
+	var namedVlans []int
+	if result := tx.Model(&NamedVlan{}).Pluck("vlan_id", &namedVlans); result.Error != nil {
+		return nil, result.Error
+	}
+	used := make(map[int]bool, len(resVlans)+len(netVlans)+len(namedVlans))
+	for _, id := range append(append(resVlans, netVlans...), namedVlans...) {
+		used[id] = true
+	}
+	return used, nil
+}
+
+// reservationsOnVlan returns the reservations using a vlan id, either as the reservation vlan or on a
+// topology network.
+func reservationsOnVlan(vlanID int, tx *gorm.DB) ([]Reservation, error) {
+	resList, err := dbReadReservations(map[string]interface{}{"vlan": vlanID}, nil, tx)
+	if err != nil {
+		return nil, err
+	}
+	var netResIDs []int
+	if result := tx.Model(&ResNetwork{}).Where("vlan = ?", vlanID).Pluck("reservation_id", &netResIDs); result.Error != nil {
+		return nil, result.Error
+	}
+	var missing []int
+	for _, id := range netResIDs {
+		found := false
+		for _, res := range resList {
+			if res.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		netResList, nrErr := dbReadReservations(map[string]interface{}{"id": missing}, nil, tx)
+		if nrErr != nil {
+			return nil, nrErr
+		}
+		resList = append(resList, netResList...)
+	}
+	return resList, nil
+}
+
+// policyIDsOfHosts returns the distinct host policy ids of the hosts.
+func policyIDsOfHosts(hosts []Host) []int {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, h := range hosts {
+		if h.HostPolicyID > 0 && !seen[h.HostPolicyID] {
+			seen[h.HostPolicyID] = true
+			ids = append(ids, h.HostPolicyID)
+		}
+	}
+	return ids
+}
+
+// poolOfVlan returns the pool the vlan id belongs to, or nil if it isn't in one.
+func poolOfVlan(vlan int, pools []VlanPool) *VlanPool {
+	for i := range pools {
+		if pools[i].contains(vlan) {
+			return &pools[i]
+		}
+	}
+	return nil
+}
+
+// pickVlan returns the first free vlan id, trying the pools the user may draw from before the part of the
+// vlan range not covered by any pool. Pools limited to host policies come first, then pools limited to
+// groups, then unrestricted pools. Returns 0 if nothing is free.
+func pickVlan(pools []VlanPool, user *User, policyIDs []int, used map[int]bool, rangeMin, rangeMax int) int {
+
+	var eligible []VlanPool
+	for _, p := range pools {
+		if p.allows(user, policyIDs) {
+			eligible = append(eligible, p)
+		}
+	}
+	rank := func(p *VlanPool) int {
+		if len(p.Policies) > 0 {
+			return 0
+		} else if len(p.Groups) > 0 {
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(eligible, func(i, j int) bool {
+		if rank(&eligible[i]) != rank(&eligible[j]) {
+			return rank(&eligible[i]) < rank(&eligible[j])
+		}
+		return eligible[i].Min < eligible[j].Min
+	})
+
+	for _, p := range eligible {
+		for id := p.Min; id <= p.Max; id++ {
+			if !used[id] {
+				return id
+			}
+		}
+	}
+	for id := rangeMin; id <= rangeMax; id++ {
+		if !used[id] && poolOfVlan(id, pools) == nil {
+			return id
+		}
+	}
+	return 0
+}
+
+// nextVLAN picks a free vlan id for a reservation with the given owner and hosts.
+func nextVLAN(owner *User, hosts []Host, tx *gorm.DB) (int, error) {
+	vlans, err := nextVLANs(owner, hosts, 1, nil, tx)
+	if err != nil {
+		return 0, err
+	}
+	return vlans[0], nil
+}
+
+// nextVLANs picks count distinct free vlan ids for a reservation with the given owner and hosts. Ids in
+// taken are treated as used.
+func nextVLANs(owner *User, hosts []Host, count int, taken []int, tx *gorm.DB) ([]int, error) {
+	pools, err := dbReadVlanPools(map[string]interface{}{}, tx)
+	if err != nil {
+		return nil, err
+	}
+	used, err := usedVlans(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range taken {
+		used[id] = true
+	}
+	vlans := make([]int, 0, count)
+	for len(vlans) < count {
+		vlan := pickVlan(pools, owner, policyIDsOfHosts(hosts), used, igor.Vlan.RangeMin, igor.Vlan.RangeMax)
+		if vlan <= 0 {
+			return nil, fmt.Errorf("no vlans available")
+		}
+		used[vlan] = true
+		vlans = append(vlans, vlan)
+	}
+	return vlans, nil
+}
+
+// checkVlanPoolAccess returns an error if the user can't draw the vlan id from the pool it belongs to.
+// Hosts are only known when the reservation names them, so pools limited to host policies require that.
+func checkVlanPoolAccess(vlan int, user *User, hosts []Host, tx *gorm.DB) (int, error) {
+	pools, err := dbReadVlanPools(map[string]interface{}{}, tx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	pool := poolOfVlan(vlan, pools)
+	if pool == nil || pool.allows(user, policyIDsOfHosts(hosts)) {
+		return http.StatusOK, nil
+	}
+	if len(pool.Policies) > 0 && len(policyIDsOfHosts(hosts)) == 0 {
+		return http.StatusForbidden, fmt.Errorf("VLAN %d is in pool '%s' which is limited to certain host policies; reserve hosts by name to use it", vlan, pool.Name)
+	}
+	return http.StatusForbidden, fmt.Errorf("VLAN %d is in pool '%s' which is not available to this reservation", vlan, pool.Name)
+}
+
+// filterVlanList builds the list of allocated vlans: named vlans and any other ids held by reservations.
+func filterVlanList(named []NamedVlan, resList []Reservation, pools []VlanPool) []common.VlanData {
+
+	resByVlan := make(map[int][]string)
+	for _, r := range resList {
+		if r.Vlan > 0 {
+			resByVlan[r.Vlan] = append(resByVlan[r.Vlan], r.Name)
+		}
+		for _, n := range r.Networks {
+			if n.Vlan > 0 && n.Vlan != r.Vlan {
+				resByVlan[n.Vlan] = append(resByVlan[n.Vlan], r.Name+"/"+n.Name)
+			}
+		}
+	}
+
+	var result []common.VlanData
+	for _, v := range named {
+		var groups []string
+		for _, g := range v.Groups {
+			groups = append(groups, g.Name)
+		}
+		vd := common.VlanData{
+			Name:         v.Name,
+			ID:           v.VlanID,
+			Owner:        v.Owner.Name,
+			Groups:       groups,
+			Description:  v.Description,
+			Reservations: resByVlan[v.VlanID],
+		}
+		if p := poolOfVlan(v.VlanID, pools); p != nil {
+			vd.Pool = p.Name
+		}
+		result = append(result, vd)
+		delete(resByVlan, v.VlanID)
+	}
+	for id, resNames := range resByVlan {
+		vd := common.VlanData{ID: id, Reservations: resNames}
+		if p := poolOfVlan(id, pools); p != nil {
+			vd.Pool = p.Name
+		}
+		result = append(result, vd)
+	}
+
+	for i := range result {
+		sort.Strings(result[i].Reservations)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// getNamedVlan looks up a named vlan, returning a 404 error if it doesn't exist.
+func getNamedVlan(name string, tx *gorm.DB) (*NamedVlan, int, error) {
+	vList, err := dbReadNamedVlans(map[string]interface{}{"name": name}, tx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	} else if len(vList) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("vlan '%s' not found", name)
+	}
+	return &vList[0], http.StatusOK, nil
+}
+
+// getNamedVlanGroups looks up the groups allowed to use a named vlan.
+func getNamedVlanGroups(names []interface{}, tx *gorm.DB) ([]Group, int, error) {
+	var grNames []string
+	for _, name := range names {
+		nm := name.(string)
+		if nm == GroupAll || strings.HasPrefix(nm, GroupUserPrefix) || nm == GroupNoneAlias {
+			return nil, http.StatusConflict, fmt.Errorf("group not allowed for a vlan: %v", nm)
+		}
+		grNames = append(grNames, nm)
+	}
+	return getGroups(grNames, true, tx)
+}
+
+func doCreateNamedVlan(params map[string]interface{}, r *http.Request) (vlan *NamedVlan, code int, err error) {
+
+	actionUser := getUserFromContext(r)
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if igor.Vlan.Network == "" {
+		return nil, http.StatusConflict, fmt.Errorf("vlan segmentation is not enabled on this server")
+	}
+	if !userElevated(actionUser.Name) {
+		return nil, http.StatusForbidden, fmt.Errorf("creating a named vlan requires admin elevated privilege")
+	}
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		name := params["name"].(string)
+		if existing, rErr := dbReadNamedVlans(map[string]interface{}{"name": name}, tx); rErr != nil {
+			return rErr
+		} else if len(existing) > 0 {
+			code = http.StatusConflict
+			return fmt.Errorf("vlan '%s' already exists", name)
+		}
+
+		owner := actionUser
+		if ownerName, ok := params["owner"].(string); ok && ownerName != "" {
+			users, status, guErr := getUsers([]string{ownerName}, true, tx)
+			if guErr != nil {
+				code = status
+				return guErr
+			}
+			owner = &users[0]
+		}
+
+		vlan = &NamedVlan{Name: name, Owner: *owner}
+		if desc, ok := params["description"].(string); ok {
+			vlan.Description = desc
+		}
+		if names, ok := params["groups"].([]interface{}); ok && len(names) > 0 {
+			groups, status, gErr := getNamedVlanGroups(names, tx)
+			if gErr != nil {
+				code = status
+				return gErr
+			}
+			vlan.Groups = groups
+		}
+
+		used, uErr := usedVlans(tx)
+		if uErr != nil {
+			return uErr
+		}
+		pools, pErr := dbReadVlanPools(map[string]interface{}{}, tx)
+		if pErr != nil {
+			return pErr
+		}
+
+		if id, ok := params["id"].(float64); ok {
+			// a specific id has to be free; ids still held by a reservation can be named if every
+			// reservation using it belongs to the new owner
+			vlan.VlanID = int(id)
+			if vlan.VlanID < igor.Vlan.RangeMin || vlan.VlanID > igor.Vlan.RangeMax {
+				code = http.StatusBadRequest
+				return fmt.Errorf("VLAN number outside permitted range: %d", vlan.VlanID)
+			}
+			if existing, rErr := dbReadNamedVlans(map[string]interface{}{"vlan_id": vlan.VlanID}, tx); rErr != nil {
+				return rErr
+			} else if len(existing) > 0 {
+				code = http.StatusConflict
+				return fmt.Errorf("VLAN %d is already named '%s'", vlan.VlanID, existing[0].Name)
+			}
+			resList, rErr := reservationsOnVlan(vlan.VlanID, tx)
+			if rErr != nil {
+				return rErr
+			}
+			for _, res := range resList {
+				if res.Owner.Name != owner.Name {
+					code = http.StatusConflict
+					return fmt.Errorf("VLAN %d is in use by reservation '%s'", vlan.VlanID, res.Name)
+				}
+			}
+		} else {
+			if poolName, ok := params["pool"].(string); ok && poolName != "" {
+				pool := findVlanPool(poolName, pools)
+				if pool == nil {
+					code = http.StatusNotFound
+					return fmt.Errorf("vlan pool '%s' not found", poolName)
+				}
+				// admins choosing a pool are not held to its restrictions
+				for id := pool.Min; id <= pool.Max; id++ {
+					if !used[id] {
+						vlan.VlanID = id
+						break
+					}
+				}
+			} else {
+				vlan.VlanID = pickVlan(pools, owner, nil, used, igor.Vlan.RangeMin, igor.Vlan.RangeMax)
+			}
+			if vlan.VlanID == 0 {
+				code = http.StatusConflict
+				return fmt.Errorf("no vlans available")
+			}
+		}
+
+		return dbCreateNamedVlan(vlan, tx) // uses default err status
+
+	}); err == nil {
+		code = http.StatusCreated
+	}
+	return
+}
+
+// findVlanPool returns the named pool from the list, or nil if it isn't there.
+func findVlanPool(name string, pools []VlanPool) *VlanPool {
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i]
+		}
+	}
+	return nil
+}
+
+// doReadVlans returns the allocated vlans matching the query. Params can include 'name', 'owner'
+// and 'pool'.
+func doReadVlans(queryMap map[string][]string) ([]common.VlanData, int, error) {
+
+	var named []NamedVlan
+	var resList []Reservation
+	var pools []VlanPool
+
+	if err := performDbTx(func(tx *gorm.DB) error {
+		var err error
+		if named, err = dbReadNamedVlans(map[string]interface{}{}, tx); err != nil {
+			return err
+		}
+		if resList, err = dbReadReservations(map[string]interface{}{}, map[string]time.Time{}, tx); err != nil {
+			return err
+		}
+		pools, err = dbReadVlanPools(map[string]interface{}{}, tx)
+		return err
+	}); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	all := filterVlanList(named, resList, pools)
+	if len(queryMap) == 0 {
+		return all, http.StatusOK, nil
+	}
+
+	matches := func(key, val string) bool {
+		vals, ok := queryMap[key]
+		if !ok {
+			return true
+		}
+		for _, v := range vals {
+			for _, item := range strings.Split(v, ",") {
+				if item == val {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var result []common.VlanData
+	for _, vd := range all {
+		if matches("name", vd.Name) && matches("owner", vd.Owner) && matches("pool", vd.Pool) && matches("id", strconv.Itoa(vd.ID)) {
+			result = append(result, vd)
+		}
+	}
+	return result, http.StatusOK, nil
+}
+
+func doUpdateNamedVlan(name string, params map[string]interface{}, r *http.Request) (code int, err error) {
+
+	actionUser := getUserFromContext(r)
+	isElevated := userElevated(actionUser.Name)
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		vlan, status, gvErr := getNamedVlan(name, tx)
+		if gvErr != nil {
+			code = status
+			return gvErr
+		}
+		if !isElevated && vlan.Owner.Name != actionUser.Name {
+			code = http.StatusForbidden
+			return fmt.Errorf("only the owner of vlan '%s' or an elevated admin can change it", name)
+		}
+
+		changes := map[string]interface{}{}
+		if val, ok := params["name"].(string); ok {
+			if existing, rErr := dbReadNamedVlans(map[string]interface{}{"name": val}, tx); rErr != nil {
+				return rErr
+			} else if len(existing) > 0 {
+				code = http.StatusConflict
+				return fmt.Errorf("vlan '%s' already exists", val)
+			}
+			changes["name"] = val
+		}
+		if val, ok := params["description"].(string); ok {
+			changes["description"] = val
+		}
+		if val, ok := params["owner"].(string); ok {
+			if !isElevated {
+				code = http.StatusForbidden
+				return fmt.Errorf("changing the owner of a vlan requires admin elevated privilege")
+			}
+			users, status, guErr := getUsers([]string{val}, true, tx)
+			if guErr != nil {
+				code = status
+				return guErr
+			}
+			changes["owner_id"] = users[0].ID
+		}
+		for _, key := range []string{"addGroups", "removeGroups"} {
+			if names, ok := params[key].([]interface{}); ok && len(names) > 0 {
+				groups, status, gErr := getNamedVlanGroups(names, tx)
+				if gErr != nil {
+					code = status
+					return gErr
+				}
+				changes[key] = groups
+			}
+		}
+
+		return dbEditNamedVlan(vlan, changes, tx) // uses default err status
+
+	}); err == nil {
+		code = http.StatusOK
+	}
+	return
+}
+
+// doDeleteNamedVlan releases a named vlan. This is refused while reservations are using it.
+func doDeleteNamedVlan(name string, r *http.Request) (code int, err error) {
+
+	actionUser := getUserFromContext(r)
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		vlan, status, gvErr := getNamedVlan(name, tx)
+		if gvErr != nil {
+			code = status
+			return gvErr
+		}
+		if !userElevated(actionUser.Name) && vlan.Owner.Name != actionUser.Name {
+			code = http.StatusForbidden
+			return fmt.Errorf("only the owner of vlan '%s' or an elevated admin can delete it", name)
+		}
+
+		resList, rErr := reservationsOnVlan(vlan.VlanID, tx)
+		if rErr != nil {
+			return rErr
+		} else if len(resList) > 0 {
+			code = http.StatusConflict
+			return fmt.Errorf("vlan '%s' is in use by reservation(s) %s", name, strings.Join(resNamesOfResList(resList), ","))
+		}
+
+		return dbDeleteNamedVlan(vlan, tx) // uses default err status
+
+	}); err == nil {
+		code = http.StatusOK
+	}
+	return
+}
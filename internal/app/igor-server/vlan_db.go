@@ -0,0 +1,64 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+)
+
+func dbCreateNamedVlan(vlan *NamedVlan, tx *gorm.DB) error {
+	result := tx.Create(vlan)
+	return result.Error
+}
+
+func dbReadNamedVlans(queryParams map[string]interface{}, tx *gorm.DB) (vlans []NamedVlan, err error) {
+	tx = tx.Preload("Owner").Preload("Groups")
+	for key, val := range queryParams {
+		switch val.(type) {
+		case []string, []int:
+			tx = tx.Where(key+" IN ?", val)
+		default:
+			tx = tx.Where(key, val)
+		}
+	}
+	result := tx.Find(&vlans)
+	return vlans, result.Error
+}
+
+// dbEditNamedVlan applies the changes to the named vlan.
+func dbEditNamedVlan(vlan *NamedVlan, changes map[string]interface{}, tx *gorm.DB) error {
+
+	if groups, ok := changes["removeGroups"].([]Group); ok {
+		if err := tx.Model(vlan).Association("Groups").Delete(groups); err != nil {
+			return err
+		}
+	}
+	if groups, ok := changes["addGroups"].([]Group); ok {
+		if err := tx.Model(vlan).Association("Groups").Append(groups); err != nil {
+			return err
+		}
+	}
+
+	fields := map[string]interface{}{}
+	for _, key := range []string{"name", "description", "owner_id"} {
+		if val, ok := changes[key]; ok {
+			fields[key] = val
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	result := tx.Model(vlan).Updates(fields)
+	return result.Error
+}
+
+// dbDeleteNamedVlan removes the given named vlan from the DB
+func dbDeleteNamedVlan(vlan *NamedVlan, tx *gorm.DB) error {
+	if err := tx.Model(vlan).Association("Groups").Clear(); err != nil {
+		return err
+	}
+	result := tx.Delete(vlan)
+	return result.Error
+}
@@ -0,0 +1,433 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/hlog"
+)
+
+// destination for route POST /vlanpools
+func handleCreateVlanPool(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	createParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "create vlan pool"
+	rb := common.NewResponseBody()
+
+	pool, status, err := doCreateVlanPool(createParams, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		clog.Info().Msgf("%s success - '%s' created with range %d-%d", actionPrefix, pool.Name, pool.Min, pool.Max)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route GET /vlanpools
+func handleReadVlanPools(w http.ResponseWriter, r *http.Request) {
+
+	queryMap := r.URL.Query()
+	clog := hlog.FromRequest(r)
+	actionPrefix := "read vlan pools"
+	rb := common.NewResponseBodyVlanPools()
+
+	queryParams := map[string]interface{}{}
+	if names, ok := queryMap["name"]; ok {
+		queryParams["name"] = names
+	}
+
+	pools, used, status, err := doReadVlanPools(queryParams)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else if len(pools) == 0 {
+		rb.Message = "search returned no results"
+	} else {
+		rb.Data["vlanPools"] = filterVlanPoolList(pools, used)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route PATCH /vlanpools/:vlanpoolName
+func handleUpdateVlanPool(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	editParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "update vlan pool"
+	name := httprouter.ParamsFromContext(r.Context()).ByName("vlanpoolName")
+	rb := common.NewResponseBody()
+
+	status, err := doUpdateVlanPool(name, editParams, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		clog.Info().Msgf("%s success - '%s' updated", actionPrefix, name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route DELETE /vlanpools/:vlanpoolName
+func handleDeleteVlanPool(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	clog := hlog.FromRequest(r)
+	actionPrefix := "delete vlan pool"
+	name := httprouter.ParamsFromContext(r.Context()).ByName("vlanpoolName")
+	rb := common.NewResponseBody()
+
+	status, err := doDeleteVlanPool(name)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		clog.Info().Msgf("%s success - '%s' deleted", actionPrefix, name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route POST /vlans
+func handleCreateVlan(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	createParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "create vlan"
+	rb := common.NewResponseBody()
+
+	vlan, status, err := doCreateNamedVlan(createParams, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		rb.Message = fmt.Sprintf("vlan '%s' created with id %d", vlan.Name, vlan.VlanID)
+		clog.Info().Msgf("%s success - '%s' created with id %d", actionPrefix, vlan.Name, vlan.VlanID)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route GET /vlans
+func handleReadVlans(w http.ResponseWriter, r *http.Request) {
+
+	clog := hlog.FromRequest(r)
+	actionPrefix := "read vlans"
+	rb := common.NewResponseBodyVlans()
+
+	vlans, status, err := doReadVlans(r.URL.Query())
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else if len(vlans) == 0 {
+		rb.Message = "search returned no results"
+	} else {
+		rb.Data["vlans"] = vlans
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route PATCH /vlans/:vlanName
+func handleUpdateVlan(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	editParams := getBodyFromContext(r)
+	clog := hlog.FromRequest(r)
+	actionPrefix := "update vlan"
+	name := httprouter.ParamsFromContext(r.Context()).ByName("vlanName")
+	rb := common.NewResponseBody()
+
+	status, err := doUpdateNamedVlan(name, editParams, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		clog.Info().Msgf("%s success - '%s' updated", actionPrefix, name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// destination for route DELETE /vlans/:vlanName
+func handleDeleteVlan(w http.ResponseWriter, r *http.Request) {
+
+	dbAccess.Lock()
+	defer dbAccess.Unlock()
+
+	clog := hlog.FromRequest(r)
+	actionPrefix := "delete vlan"
+	name := httprouter.ParamsFromContext(r.Context()).ByName("vlanName")
+	rb := common.NewResponseBody()
+
+	status, err := doDeleteNamedVlan(name, r)
+
+	if err != nil {
+		stdErrorResp(rb, status, actionPrefix, err, clog)
+	} else {
+		clog.Info().Msgf("%s success - '%s' deleted", actionPrefix, name)
+	}
+
+	makeJsonResponse(w, status, rb)
+}
+
+// validateNameListParam checks that val is a list of strings that each pass the check.
+func validateNameListParam(key string, val interface{}, check func(string) error) error {
+	names, ok := val.([]interface{})
+	if !ok {
+		return NewBadParamTypeError(key, val, "string array")
+	}
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok {
+			return NewBadParamTypeError(key, n, "string array")
+		}
+		if err := check(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateVlanIDParam checks that val is a whole number within the server vlan range.
+func validateVlanIDParam(key string, val interface{}) error {
+	id, ok := val.(float64)
+	if !ok || id != float64(int(id)) {
+		return NewBadParamTypeError(key, val, "int")
+	}
+	if int(id) < igor.Vlan.RangeMin || int(id) > igor.Vlan.RangeMax {
+		return fmt.Errorf("%s %d is outside the server vlan range %d-%d", key, int(id), igor.Vlan.RangeMin, igor.Vlan.RangeMax)
+	}
+	return nil
+}
+
+func validateVlanPoolParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+
+			poolParams := getBodyFromContext(r)
+
+			if len(poolParams) == 0 {
+				validateErr = NewMissingParamError("")
+			} else if r.Method == http.MethodPost && (poolParams["name"] == nil || poolParams["min"] == nil || poolParams["max"] == nil) {
+				validateErr = fmt.Errorf("missing vlan pool name, min or max (required)")
+			} else {
+			paramLoop:
+				for key, val := range poolParams {
+					switch key {
+					case "name":
+						if name, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkGenericNameRules(name); validateErr != nil {
+							break paramLoop
+						}
+					case "description":
+						if desc, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkDesc(desc); validateErr != nil {
+							break paramLoop
+						}
+					case "min", "max":
+						if validateErr = validateVlanIDParam(key, val); validateErr != nil {
+							break paramLoop
+						}
+					case "groups", "addGroups", "removeGroups":
+						if (key == "groups") != (r.Method == http.MethodPost) {
+							validateErr = NewUnknownParamError(key, val)
+							break paramLoop
+						}
+						if validateErr = validateNameListParam(key, val, checkGroupNameRules); validateErr != nil {
+							break paramLoop
+						}
+					case "policies", "addPolicies", "removePolicies":
+						if (key == "policies") != (r.Method == http.MethodPost) {
+							validateErr = NewUnknownParamError(key, val)
+							break paramLoop
+						}
+						if validateErr = validateNameListParam(key, val, checkHostPolicyNameRules); validateErr != nil {
+							break paramLoop
+						}
+					default:
+						validateErr = NewUnknownParamError(key, val)
+						break paramLoop
+					}
+				}
+			}
+		}
+
+		if r.Method == http.MethodGet {
+		queryParamLoop:
+			for key, vals := range r.URL.Query() {
+				switch key {
+				case "name":
+					for _, val := range vals {
+						if validateErr = checkGenericNameRules(val); validateErr != nil {
+							break queryParamLoop
+						}
+					}
+				default:
+					validateErr = NewUnknownParamError(key, vals)
+					break queryParamLoop
+				}
+			}
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateVlanPoolParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func validateVlanParams(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var validateErr error
+		clog := hlog.FromRequest(r)
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+
+			vlanParams := getBodyFromContext(r)
+
+			if len(vlanParams) == 0 {
+				validateErr = NewMissingParamError("")
+			} else if _, ok := vlanParams["name"]; !ok && r.Method == http.MethodPost {
+				validateErr = fmt.Errorf("missing vlan name (required)")
+			} else if vlanParams["id"] != nil && vlanParams["pool"] != nil {
+				validateErr = fmt.Errorf("vlan id and pool cannot both be given")
+			} else {
+			paramLoop:
+				for key, val := range vlanParams {
+					switch key {
+					case "name":
+						if name, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkGenericNameRules(name); validateErr != nil {
+							break paramLoop
+						}
+					case "description":
+						if desc, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkDesc(desc); validateErr != nil {
+							break paramLoop
+						}
+					case "owner":
+						if owner, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkUsernameRules(owner); validateErr != nil {
+							break paramLoop
+						}
+					case "id":
+						if r.Method != http.MethodPost {
+							validateErr = NewUnknownParamError(key, val)
+							break paramLoop
+						}
+						if validateErr = validateVlanIDParam(key, val); validateErr != nil {
+							break paramLoop
+						}
+					case "pool":
+						if r.Method != http.MethodPost {
+							validateErr = NewUnknownParamError(key, val)
+							break paramLoop
+						}
+						if pool, ok := val.(string); !ok {
+							validateErr = NewBadParamTypeError(key, val, "string")
+							break paramLoop
+						} else if validateErr = checkGenericNameRules(pool); validateErr != nil {
+							break paramLoop
+						}
+					case "groups", "addGroups", "removeGroups":
+						if (key == "groups") != (r.Method == http.MethodPost) {
+							validateErr = NewUnknownParamError(key, val)
+							break paramLoop
+						}
+						if validateErr = validateNameListParam(key, val, checkGroupNameRules); validateErr != nil {
+							break paramLoop
+						}
+					default:
+						validateErr = NewUnknownParamError(key, val)
+						break paramLoop
+					}
+				}
+			}
+		}
+
+		if r.Method == http.MethodGet {
+		queryParamLoop:
+			for key, vals := range r.URL.Query() {
+				switch key {
+				case "name", "pool":
+					for _, val := range vals {
+						if validateErr = checkGenericNameRules(val); validateErr != nil {
+							break queryParamLoop
+						}
+					}
+				case "owner":
+					for _, val := range vals {
+						if validateErr = checkUsernameRules(val); validateErr != nil {
+							break queryParamLoop
+						}
+					}
+				case "id":
+					for _, val := range vals {
+						if _, err := strconv.Atoi(val); err != nil {
+							validateErr = NewBadParamTypeError(key, val, "int")
+							break queryParamLoop
+						}
+					}
+				default:
+					validateErr = NewUnknownParamError(key, vals)
+					break queryParamLoop
+				}
+			}
+		}
+
+		if validateErr != nil {
+			reqUrl, _ := url.QueryUnescape(r.URL.RequestURI())
+			clog.Warn().Msgf("validateVlanParams - failed validation for %s:%s:%v - %v", getUserFromContext(r).Name, r.Method, reqUrl, validateErr)
+			createValidationErrMessage(validateErr, w)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
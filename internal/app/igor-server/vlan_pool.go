@@ -0,0 +1,311 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"igor2/internal/pkg/common"
+
+	zl "github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"gorm.io/gorm"
+)
+
+// VlanPool sets aside a range of vlan ids within vlan.rangeMin/Max. A pool can be limited to members of
+// certain groups, to reservations whose hosts all use certain host policies, or both. Pools may not
+// overlap. Ids in the vlan range that are not part of any pool are available to everyone.
+type VlanPool struct {
+	Base
+	Name        string `gorm:"unique; notNull"`
+	Description string
+	Min         int
+	Max         int
+	Groups      []Group      `gorm:"many2many:vlan_pools_groups;"`
+	Policies    []HostPolicy `gorm:"many2many:vlan_pools_policies;"`
+}
+
+// contains returns true if the vlan id is part of the pool.
+func (p *VlanPool) contains(vlan int) bool {
+	return vlan >= p.Min && vlan <= p.Max
+}
+
+// allows returns true if the pool can supply a vlan to the user for hosts with the given policy ids.
+func (p *VlanPool) allows(user *User, policyIDs []int) bool {
+	if len(p.Groups) > 0 && !user.isMemberOfAnyGroup(p.Groups) {
+		return false
+	}
+	if len(p.Policies) > 0 {
+		if len(policyIDs) == 0 {
+			return false
+		}
+	policyLoop:
+		for _, id := range policyIDs {
+			for _, hp := range p.Policies {
+				if hp.ID == id {
+					continue policyLoop
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+func filterVlanPoolList(pools []VlanPool, used map[int]bool) []common.VlanPoolData {
+	var result []common.VlanPoolData
+	for _, p := range pools {
+		count := 0
+		for id := range used {
+			if p.contains(id) {
+				count++
+			}
+		}
+		groups := make([]string, 0, len(p.Groups))
+		for _, g := range p.Groups {
+			groups = append(groups, g.Name)
+		}
+		policies := make([]string, 0, len(p.Policies))
+		for _, hp := range p.Policies {
+			policies = append(policies, hp.Name)
+		}
+		result = append(result, common.VlanPoolData{
+			Name:        p.Name,
+			Description: p.Description,
+			Min:         p.Min,
+			Max:         p.Max,
+			Groups:      groups,
+			Policies:    policies,
+			Used:        count,
+		})
+	}
+	return result
+}
+
+// checkVlanPoolRange makes sure the range is inside the configured vlan range and doesn't overlap any
+// other pool. The pool with the id skip is left out of the overlap check so a pool can be resized.
+func checkVlanPoolRange(min, max, skip int, pools []VlanPool) error {
+	if min > max {
+		return fmt.Errorf("vlan pool range %d-%d is invalid", min, max)
+	}
+	if min < igor.Vlan.RangeMin || max > igor.Vlan.RangeMax {
+		return fmt.Errorf("vlan pool range %d-%d must be within the server vlan range %d-%d", min, max, igor.Vlan.RangeMin, igor.Vlan.RangeMax)
+	}
+	for _, p := range pools {
+		if p.ID != skip && min <= p.Max && max >= p.Min {
+			return fmt.Errorf("vlan pool range %d-%d overlaps pool '%s' (%d-%d)", min, max, p.Name, p.Min, p.Max)
+		}
+	}
+	return nil
+}
+
+// getVlanPoolGroups looks up the groups a pool is limited to. System groups are not allowed.
+func getVlanPoolGroups(names []interface{}, tx *gorm.DB) ([]Group, int, error) {
+	var grNames []string
+	for _, name := range names {
+		nm := name.(string)
+		if nm == GroupAll || nm == GroupAdmins || strings.HasPrefix(nm, GroupUserPrefix) || nm == GroupNoneAlias {
+			return nil, http.StatusConflict, fmt.Errorf("group not allowed for a vlan pool: %v", nm)
+		}
+		grNames = append(grNames, nm)
+	}
+	return getGroups(grNames, true, tx)
+}
+
+// getVlanPoolPolicies looks up the host policies a pool is limited to.
+func getVlanPoolPolicies(names []interface{}, tx *gorm.DB, clog *zl.Logger) ([]HostPolicy, int, error) {
+	var hpNames []string
+	for _, name := range names {
+		hpNames = append(hpNames, name.(string))
+	}
+	hpList, status, err := getHostPolicies(hpNames, tx, clog)
+	if err != nil {
+		return nil, status, err
+	}
+	if len(hpList) != len(hpNames) {
+		return nil, http.StatusNotFound, fmt.Errorf("one or more policies in '%s' not found", strings.Join(hpNames, ","))
+	}
+	return hpList, http.StatusOK, nil
+}
+
+func doCreateVlanPool(params map[string]interface{}, r *http.Request) (pool *VlanPool, code int, err error) {
+
+	clog := hlog.FromRequest(r)
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if igor.Vlan.Network == "" {
+		return nil, http.StatusConflict, fmt.Errorf("vlan segmentation is not enabled on this server")
+	}
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		name := params["name"].(string)
+		pools, rErr := dbReadVlanPools(map[string]interface{}{}, tx)
+		if rErr != nil {
+			return rErr
+		}
+		for _, p := range pools {
+			if p.Name == name {
+				code = http.StatusConflict
+				return fmt.Errorf("vlan pool '%s' already exists", name)
+			}
+		}
+
+		pool = &VlanPool{
+			Name: name,
+			Min:  int(params["min"].(float64)),
+			Max:  int(params["max"].(float64)),
+		}
+		if desc, ok := params["description"].(string); ok {
+			pool.Description = desc
+		}
+		if rangeErr := checkVlanPoolRange(pool.Min, pool.Max, 0, pools); rangeErr != nil {
+			code = http.StatusConflict
+			return rangeErr
+		}
+
+		if names, ok := params["groups"].([]interface{}); ok && len(names) > 0 {
+			groups, status, gErr := getVlanPoolGroups(names, tx)
+			if gErr != nil {
+				code = status
+				return gErr
+			}
+			pool.Groups = groups
+		}
+		if names, ok := params["policies"].([]interface{}); ok && len(names) > 0 {
+			policies, status, pErr := getVlanPoolPolicies(names, tx, clog)
+			if pErr != nil {
+				code = status
+				return pErr
+			}
+			pool.Policies = policies
+		}
+
+		return dbCreateVlanPool(pool, tx) // uses default err status
+
+	}); err == nil {
+		code = http.StatusCreated
+	}
+	return
+}
+
+func doReadVlanPools(queryParams map[string]interface{}) ([]VlanPool, map[int]bool, int, error) {
+	var pools []VlanPool
+	var used map[int]bool
+	err := performDbTx(func(tx *gorm.DB) error {
+		var err error
+		if pools, err = dbReadVlanPools(queryParams, tx); err != nil {
+			return err
+		}
+		used, err = usedVlans(tx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Min < pools[j].Min
+	})
+	return pools, used, http.StatusOK, nil
+}
+
+func doUpdateVlanPool(name string, params map[string]interface{}, r *http.Request) (code int, err error) {
+
+	clog := hlog.FromRequest(r)
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+
+		pools, rErr := dbReadVlanPools(map[string]interface{}{}, tx)
+		if rErr != nil {
+			return rErr
+		}
+		var pool *VlanPool
+		for i := range pools {
+			if pools[i].Name == name {
+				pool = &pools[i]
+			} else if newName, ok := params["name"].(string); ok && pools[i].Name == newName {
+				code = http.StatusConflict
+				return fmt.Errorf("vlan pool '%s' already exists", newName)
+			}
+		}
+		if pool == nil {
+			code = http.StatusNotFound
+			return fmt.Errorf("vlan pool '%s' not found", name)
+		}
+
+		changes := map[string]interface{}{}
+		if val, ok := params["name"].(string); ok {
+			changes["name"] = val
+		}
+		if val, ok := params["description"].(string); ok {
+			changes["description"] = val
+		}
+		min, max := pool.Min, pool.Max
+		if val, ok := params["min"].(float64); ok {
+			min = int(val)
+			changes["min"] = min
+		}
+		if val, ok := params["max"].(float64); ok {
+			max = int(val)
+			changes["max"] = max
+		}
+		if rangeErr := checkVlanPoolRange(min, max, pool.ID, pools); rangeErr != nil {
+			code = http.StatusConflict
+			return rangeErr
+		}
+
+		for _, key := range []string{"addGroups", "removeGroups"} {
+			if names, ok := params[key].([]interface{}); ok && len(names) > 0 {
+				groups, status, gErr := getVlanPoolGroups(names, tx)
+				if gErr != nil {
+					code = status
+					return gErr
+				}
+				changes[key] = groups
+			}
+		}
+		for _, key := range []string{"addPolicies", "removePolicies"} {
+			if names, ok := params[key].([]interface{}); ok && len(names) > 0 {
+				policies, status, pErr := getVlanPoolPolicies(names, tx, clog)
+				if pErr != nil {
+					code = status
+					return pErr
+				}
+				changes[key] = policies
+			}
+		}
+
+		return dbEditVlanPool(pool, changes, tx) // uses default err status
+
+	}); err == nil {
+		code = http.StatusOK
+	}
+	return
+}
+
+// doDeleteVlanPool removes a pool. Vlans already handed out from the pool are not affected, and its ids
+// become available to everyone.
+func doDeleteVlanPool(name string) (code int, err error) {
+
+	code = http.StatusInternalServerError // default status, overridden at end if no errors
+
+	if err = performDbTx(func(tx *gorm.DB) error {
+		pools, rErr := dbReadVlanPools(map[string]interface{}{"name": name}, tx)
+		if rErr != nil {
+			return rErr
+		} else if len(pools) == 0 {
+			code = http.StatusNotFound
+			return fmt.Errorf("vlan pool '%s' not found", name)
+		}
+		return dbDeleteVlanPool(&pools[0], tx) // uses default err status
+	}); err == nil {
+		code = http.StatusOK
+	}
+	return
+}
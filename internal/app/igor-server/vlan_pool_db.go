@@ -0,0 +1,77 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"gorm.io/gorm"
+)
+
+func dbCreateVlanPool(pool *VlanPool, tx *gorm.DB) error {
+	result := tx.Create(pool)
+	return result.Error
+}
+
+func dbReadVlanPools(queryParams map[string]interface{}, tx *gorm.DB) (pools []VlanPool, err error) {
+	tx = tx.Preload("Groups").Preload("Policies")
+	for key, val := range queryParams {
+		switch val.(type) {
+		case []string, []int:
+			tx = tx.Where(key+" IN ?", val)
+		default:
+			tx = tx.Where(key, val)
+		}
+	}
+	result := tx.Find(&pools)
+	return pools, result.Error
+}
+
+// dbEditVlanPool applies the changes to the pool.
+func dbEditVlanPool(pool *VlanPool, changes map[string]interface{}, tx *gorm.DB) error {
+
+	if groups, ok := changes["removeGroups"].([]Group); ok {
+		if err := tx.Model(pool).Association("Groups").Delete(groups); err != nil {
+			return err
+		}
+	}
+	if groups, ok := changes["addGroups"].([]Group); ok {
+		if err := tx.Model(pool).Association("Groups").Append(groups); err != nil {
+			return err
+		}
+	}
+	if policies, ok := changes["removePolicies"].([]HostPolicy); ok {
+		if err := tx.Model(pool).Association("Policies").Delete(policies); err != nil {
+			return err
+		}
+	}
+	if policies, ok := changes["addPolicies"].([]HostPolicy); ok {
+		if err := tx.Model(pool).Association("Policies").Append(policies); err != nil {
+			return err
+		}
+	}
+
+	fields := map[string]interface{}{}
+	for _, key := range []string{"name", "description", "min", "max"} {
+		if val, ok := changes[key]; ok {
+			fields[key] = val
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	result := tx.Model(pool).Updates(fields)
+	return result.Error
+}
+
+// dbDeleteVlanPool removes the given pool from the DB
+func dbDeleteVlanPool(pool *VlanPool, tx *gorm.DB) error {
+	if err := tx.Model(pool).Association("Groups").Clear(); err != nil {
+		return err
+	}
+	if err := tx.Model(pool).Association("Policies").Clear(); err != nil {
+		return err
+	}
+	result := tx.Delete(pool)
+	return result.Error
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package igorserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"igor2/internal/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickVlan(t *testing.T) {
+	red := Group{Name: "red"}
+	blue := Group{Name: "blue"}
+	gpu := HostPolicy{Base: Base{ID: 7}, Name: "gpu"}
+
+	pools := []VlanPool{
+		{Base: Base{ID: 1}, Name: "open", Min: 110, Max: 119},
+		{Base: Base{ID: 2}, Name: "red", Min: 120, Max: 121, Groups: []Group{red}},
+		{Base: Base{ID: 3}, Name: "gpu", Min: 130, Max: 131, Policies: []HostPolicy{gpu}},
+		{Base: Base{ID: 4}, Name: "blue", Min: 140, Max: 149, Groups: []Group{blue}},
+	}
+	alice := &User{Name: "alice", Groups: []Group{red}}
+	bob := &User{Name: "bob"}
+	used := map[int]bool{}
+
+	// policy-limited pools come first, then group-limited, then unrestricted
+	assert.Equal(t, 130, pickVlan(pools, alice, []int{7}, used, 100, 200))
+	assert.Equal(t, 120, pickVlan(pools, alice, []int{3}, used, 100, 200))
+	assert.Equal(t, 110, pickVlan(pools, bob, nil, used, 100, 200))
+
+	// every host must be covered by the pool's policies
+	assert.Equal(t, 120, pickVlan(pools, alice, []int{7, 3}, used, 100, 200))
+
+	// full pools fall through to the next eligible pool, then to ids outside any pool
+	used[120], used[121] = true, true
+	assert.Equal(t, 110, pickVlan(pools, alice, nil, used, 100, 200))
+	for id := 110; id <= 119; id++ {
+		used[id] = true
+	}
+	assert.Equal(t, 100, pickVlan(pools, bob, nil, used, 100, 200))
+	assert.Equal(t, 0, pickVlan(pools, bob, nil, used, 110, 121))
+}
+
+func TestCheckVlanPoolRange(t *testing.T) {
+	savedMin, savedMax := igor.Vlan.RangeMin, igor.Vlan.RangeMax
+	defer func() { igor.Vlan.RangeMin, igor.Vlan.RangeMax = savedMin, savedMax }()
+	igor.Vlan.RangeMin, igor.Vlan.RangeMax = 100, 200
+
+	pools := []VlanPool{
+		{Base: Base{ID: 1}, Name: "a", Min: 110, Max: 119},
+		{Base: Base{ID: 2}, Name: "b", Min: 150, Max: 159},
+	}
+	assert.NoError(t, checkVlanPoolRange(120, 149, 0, pools))
+	assert.Error(t, checkVlanPoolRange(130, 120, 0, pools))
+	assert.Error(t, checkVlanPoolRange(90, 105, 0, pools))
+	assert.Error(t, checkVlanPoolRange(190, 210, 0, pools))
+	assert.Error(t, checkVlanPoolRange(119, 125, 0, pools))
+	assert.Error(t, checkVlanPoolRange(100, 200, 0, pools))
+
+	// a pool can be resized over its own range
+	assert.NoError(t, checkVlanPoolRange(105, 125, 1, pools))
+	assert.Error(t, checkVlanPoolRange(105, 155, 1, pools))
+}
+
+func TestNamedVlanInUseByNetwork(t *testing.T) {
+	db := newTestDB(t)
+	savedNetwork, savedMin, savedMax, savedElevate := igor.Vlan.Network, igor.Vlan.RangeMin, igor.Vlan.RangeMax, igor.ElevateMap
+	t.Cleanup(func() {
+		igor.Vlan.Network, igor.Vlan.RangeMin, igor.Vlan.RangeMax, igor.ElevateMap = savedNetwork, savedMin, savedMax, savedElevate
+	})
+	igor.Vlan.Network, igor.Vlan.RangeMin, igor.Vlan.RangeMax = "sim", 100, 200
+	igor.ElevateMap = common.NewPassiveTtlMap(time.Minute)
+
+	admin := User{Name: IgorAdmin, Email: "admin@example.com"}
+	bob := User{Name: "bob", Email: "bob@example.com"}
+	require.NoError(t, db.Create(&admin).Error)
+	require.NoError(t, db.Create(&bob).Error)
+	// vlan 150 is only held by the second network of bob's reservation
+	res := Reservation{
+		Name:     "topo",
+		Hash:     "topohash",
+		Owner:    bob,
+		Group:    Group{Name: "g1"},
+		Profile:  Profile{Name: "p1", Owner: bob, Distro: Distro{Name: "d1", Owner: bob, DistroImage: DistroImage{Name: "img1", ImageID: "abc123"}}},
+		Vlan:     140,
+		Networks: []ResNetwork{{Name: "front", Vlan: 140}, {Name: "back", Vlan: 150}},
+	}
+	require.NoError(t, db.Create(&res).Error)
+
+	r := addUserToContext(httptest.NewRequest(http.MethodPost, "/", nil), &admin)
+	_, code, err := doCreateNamedVlan(map[string]interface{}{"name": "back", "id": float64(150)}, r)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.ErrorContains(t, err, "in use by reservation 'topo'")
+
+	require.NoError(t, db.Create(&NamedVlan{Name: "back", VlanID: 150, Owner: admin}).Error)
+	code, err = doDeleteNamedVlan("back", r)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.ErrorContains(t, err, "in use by reservation(s) topo")
+
+	// the reservation's own vlan is only listed once
+	resList, err := reservationsOnVlan(140, db)
+	require.NoError(t, err)
+	assert.Len(t, resList, 1)
+}
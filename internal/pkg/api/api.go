@@ -11,42 +11,65 @@ const (
 	IgorApiVersion = ""
 	BaseUrl        = UrlRoot + IgorApiVersion
 
-	AuthReset         = BaseUrl + "/authreset"
-	CbLocal           = BaseUrl + "/cb/svc/local"
-	CbInfo            = BaseUrl + "/cb/svc/info"
-	CbKS              = BaseUrl + "/cb/svc/ks"
-	CbScript          = BaseUrl + "/cb/svc/scripts"
-	Clusters          = BaseUrl + "/clusters"
-	ClusterMotd       = Clusters + "/motd"
-	Config            = BaseUrl + "/config"
-	Distros           = BaseUrl + "/distros"
-	DistrosName       = Distros + "/:distroName"
-	Elevate           = BaseUrl + "/elevate"
-	Groups            = BaseUrl + "/groups"
-	GroupsName        = Groups + "/:groupName"
-	Hosts             = BaseUrl + "/hosts"
-	HostsName         = Hosts + "/:hostName"
-	HostsCtrl         = BaseUrl + "/hosts-ctrl"
-	HostsBlock        = HostsCtrl + "/block"
-	HostsPower        = HostsCtrl + "/power"
-	HostApplyPolicy   = HostsCtrl + "/policy"
-	HostPolicy        = BaseUrl + "/hostpolicy"
-	HostPolicyName    = HostPolicy + "/:hostpolicyName"
-	Images            = BaseUrl + "/images"
-	ImagesName        = Images + "/:imageName"
-	ImageRegister     = Images + "/register"
-	Kickstarts        = BaseUrl + "/kickstart"
-	KickstartsName    = Kickstarts + "/:kickstartName"
-	KickstartRegister = Kickstarts + "/register"
-	Login             = BaseUrl + "/login"
-	Profiles          = BaseUrl + "/profiles"
-	ProfileName       = Profiles + "/:profileName"
-	Public            = BaseUrl + "/public"
-	PublicSettings    = Config + "/public"
-	Reservations      = BaseUrl + "/reservations"
-	ReservationsName  = Reservations + "/:resName"
-	Stats             = BaseUrl + "/stats"
-	Sync              = BaseUrl + "/sync"
-	Users             = BaseUrl + "/users"
-	UsersName         = Users + "/:userName"
+	AuthReset           = BaseUrl + "/authreset"
+	BootTemplates       = BaseUrl + "/boottemplates"
+	BootTemplateBreed   = BootTemplates + "/:breed"
+	BootTemplatesRender = BootTemplates + "/render"
+	CbLocal             = BaseUrl + "/cb/svc/local"
+	CbInfo              = BaseUrl + "/cb/svc/info"
+	CbInventory         = BaseUrl + "/cb/svc/inventory"
+	CbBoot              = BaseUrl + "/cb/svc/boot"
+	CbDisk              = BaseUrl + "/cb/svc/disk"
+	CbImaged            = BaseUrl + "/cb/svc/imaged"
+	CbIpxe              = BaseUrl + "/cb/svc/ipxe"
+	CbIso               = BaseUrl + "/cb/svc/iso"
+	CbKeys              = BaseUrl + "/cb/svc/keys"
+	CbKS                = BaseUrl + "/cb/svc/ks"
+	CbNoCloud           = BaseUrl + "/cb/svc/nocloud"
+	CbScript            = BaseUrl + "/cb/svc/scripts"
+	Clusters            = BaseUrl + "/clusters"
+	ClusterMotd         = Clusters + "/motd"
+	Config              = BaseUrl + "/config"
+	Distros             = BaseUrl + "/distros"
+	DistrosName         = Distros + "/:distroName"
+	Elevate             = BaseUrl + "/elevate"
+	Groups              = BaseUrl + "/groups"
+	GroupsName          = Groups + "/:groupName"
+	Hosts               = BaseUrl + "/hosts"
+	HostsName           = Hosts + "/:hostName"
+	HostsHealth         = HostsName + "/health"
+	HostsStatusHist     = HostsName + "/status-history"
+	HostsCtrl           = BaseUrl + "/hosts-ctrl"
+	HostsBlock          = HostsCtrl + "/block"
+	HostsPower          = HostsCtrl + "/power"
+	HostsState          = HostsCtrl + "/state"
+	HostsImport         = HostsCtrl + "/import"
+	HostsExport         = HostsCtrl + "/export"
+	HostApplyPolicy     = HostsCtrl + "/policy"
+	HostPolicy          = BaseUrl + "/hostpolicy"
+	HostPolicyName      = HostPolicy + "/:hostpolicyName"
+	Images              = BaseUrl + "/images"
+	ImagesName          = Images + "/:imageName"
+	ImageRegister       = Images + "/register"
+	Kickstarts          = BaseUrl + "/kickstart"
+	KickstartsName      = Kickstarts + "/:kickstartName"
+	KickstartRegister   = Kickstarts + "/register"
+	Login               = BaseUrl + "/login"
+	Profiles            = BaseUrl + "/profiles"
+	ProfileName         = Profiles + "/:profileName"
+	Public              = BaseUrl + "/public"
+	PublicSettings      = Config + "/public"
+	Reservations        = BaseUrl + "/reservations"
+	ReservationsName    = Reservations + "/:resName"
+	Stats               = BaseUrl + "/stats"
+	Sync                = BaseUrl + "/sync"
+	SyncStatus          = Sync + "/status"
+	Users               = BaseUrl + "/users"
+	UsersName           = Users + "/:userName"
+	UserKeys            = UsersName + "/keys"
+	UserKeysName        = UserKeys + "/:keyName"
+	Vlans               = BaseUrl + "/vlans"
+	VlansName           = Vlans + "/:vlanName"
+	VlanPools           = BaseUrl + "/vlanpools"
+	VlanPoolsName       = VlanPools + "/:vlanpoolName"
 )
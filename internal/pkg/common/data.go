@@ -18,27 +18,38 @@ type ShowData struct {
 }
 
 type ReservationData struct {
-	Name         string   `json:"name"`
-	Description  string   `json:"description"`
-	Owner        string   `json:"owner"`
-	Group        string   `json:"group"`
-	Profile      string   `json:"profile"`
-	Distro       string   `json:"distro"`
-	Vlan         int      `json:"vlan"`
-	Start        int64    `json:"start"`
-	End          int64    `json:"end"`
-	OrigEnd      int64    `json:"origEnd"`
-	ExtendCount  int      `json:"extendCount"`
-	Hosts        []string `json:"hosts"`
-	HostRange    string   `json:"hostRange"`
-	HostsUp      string   `json:"hostsUp"`
-	HostsOn      string   `json:"hostsOn"`
-	HostsPing    string   `json:"hostsPing"`
-	HostsOff     string   `json:"hostsOff"`
-	HostsPowerNA string   `json:"hostsPowerNA"`
-	Installed    bool     `json:"installed"`
-	InstallError string   `json:"installError"`
-	RemainHours  int      `json:"remainHours"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Owner        string           `json:"owner"`
+	Group        string           `json:"group"`
+	Profile      string           `json:"profile"`
+	Distro       string           `json:"distro"`
+	Vlan         int              `json:"vlan"`
+	NicRoles     []string         `json:"nicRoles,omitempty"`
+	Networks     []ResNetworkData `json:"networks,omitempty"`
+	Start        int64            `json:"start"`
+	End          int64            `json:"end"`
+	OrigEnd      int64            `json:"origEnd"`
+	ExtendCount  int              `json:"extendCount"`
+	Hosts        []string         `json:"hosts"`
+	HostRange    string           `json:"hostRange"`
+	HostsUp      string           `json:"hostsUp"`
+	HostsOn      string           `json:"hostsOn"`
+	HostsPing    string           `json:"hostsPing"`
+	HostsOff     string           `json:"hostsOff"`
+	HostsPowerNA string           `json:"hostsPowerNA"`
+	Installed    bool             `json:"installed"`
+	InstallError string           `json:"installError"`
+	BootProgress []HostBootData   `json:"bootProgress,omitempty"`
+	RemainHours  int              `json:"remainHours"`
+}
+
+// HostBootData is the furthest stage a reservation host has reached booting the reservation's image
+type HostBootData struct {
+	Host   string `json:"host"`
+	Stage  string `json:"stage"`
+	Time   int64  `json:"time"`
+	Detail string `json:"detail,omitempty"`
 }
 
 // DistroData contains the filtered contents of a Distro for user consumption
@@ -67,6 +78,9 @@ type DistroImageData struct {
 	Breed     string   `json:"breed"`
 	Local     string   `json:"local"`
 	Boot      []string `json:"boot"`
+	Source    string   `json:"source,omitempty"`
+	Status    string   `json:"status"`
+	Checks    []string `json:"checks,omitempty"`
 }
 
 // KickstartData contains the filtered contents of a Kickstart for user consumption
@@ -74,15 +88,18 @@ type KickstartData struct {
 	Name     string `json:"name"`
 	FileName string `json:"fileName"`
 	Owner    string `json:"owner"`
+	Template bool   `json:"template"`
 }
 
 // ProfileData creates a client-safe filtered result
 type ProfileData struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Owner       string `json:"owner"`
-	Distro      string `json:"distro"`
-	KernelArgs  string `json:"kernelArgs"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Owner       string            `json:"owner"`
+	Distro      string            `json:"distro"`
+	KernelArgs  string            `json:"kernelArgs"`
+	Vars        map[string]string `json:"vars"`
+	UserData    string            `json:"userData"`
 }
 
 type HostData struct {
@@ -90,6 +107,7 @@ type HostData struct {
 	SequenceID   int      `json:"sequenceID"`
 	HostName     string   `json:"hostName"`
 	Eth          string   `json:"eth"`
+	Switch       string   `json:"switch,omitempty"`
 	IP           string   `json:"ip"`
 	Mac          string   `json:"mac"`
 	BootMode     string   `json:"bootMode"`
@@ -100,6 +118,133 @@ type HostData struct {
 	AccessGroups []string `json:"accessGroups"`
 	Restricted   bool     `json:"restricted"`
 	Reservations []string `json:"reservations"`
+	StateReason  string   `json:"stateReason,omitempty"`
+	RepairTicket string   `json:"repairTicket,omitempty"`
+	RepairReturn int64    `json:"repairReturn,omitempty"`
+	// Nics lists the interfaces of the host beyond its primary one.
+	Nics []HostNicData `json:"nics,omitempty"`
+	// Attributes holds the hardware inventory last reported by the host. Only included when requested.
+	Attributes []HostAttributeData `json:"attributes,omitempty"`
+}
+
+// ResNetworkData describes one logical network of a reservation topology.
+type ResNetworkData struct {
+	Name  string   `json:"name"`
+	Vlan  int      `json:"vlan"`
+	Hosts string   `json:"hosts,omitempty"`
+	Nics  []string `json:"nics"`
+}
+
+// HostNicData describes an additional network interface of a host.
+type HostNicData struct {
+	Role   string `json:"role"`
+	Mac    string `json:"mac"`
+	Eth    string `json:"eth,omitempty"`
+	Switch string `json:"switch,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// HostAttributeData is a single hardware fact reported by a host. Mismatch describes how the fact
+// differs from what igor expects for the host, if at all.
+type HostAttributeData struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	Mismatch string    `json:"mismatch,omitempty"`
+	Reported time.Time `json:"reported"`
+}
+
+// HostInventoryData is the hardware inventory a booted host sends to the igor callback service.
+type HostInventoryData struct {
+	CPU      CPUInventory      `json:"cpu"`
+	MemoryMB int               `json:"memoryMB"`
+	Disks    []DiskInventory   `json:"disks"`
+	NICs     []NICInventory    `json:"nics"`
+	Firmware map[string]string `json:"firmware"`
+}
+
+type CPUInventory struct {
+	Model   string `json:"model"`
+	Sockets int    `json:"sockets"`
+	Cores   int    `json:"cores"`
+	Threads int    `json:"threads"`
+}
+
+type DiskInventory struct {
+	Name   string `json:"name"`
+	Model  string `json:"model"`
+	SizeGB int    `json:"sizeGB"`
+}
+
+type NICInventory struct {
+	Name      string `json:"name"`
+	Mac       string `json:"mac"`
+	SpeedMbps int    `json:"speedMbps"`
+}
+
+// HostImportChange describes what a bulk host import will do (or did) to a single host. Changes lists
+// each altered field as "field: old -> new".
+type HostImportChange struct {
+	Host    string   `json:"host"`
+	Action  string   `json:"action"`
+	Changes []string `json:"changes"`
+}
+
+// VlanSyncStatusData is the outcome of the most recent scheduled comparison of switch port vlans with
+// reservation vlans.
+type VlanSyncStatusData struct {
+	Time     time.Time       `json:"time"`
+	Interval int             `json:"interval"`
+	Force    bool            `json:"force"`
+	Checked  int             `json:"checked"`
+	Drift    []VlanDriftData `json:"drift"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// VlanDriftData describes a host whose switch port vlan doesn't match its reservation. A blank
+// Expected or Actual value means no vlan.
+type VlanDriftData struct {
+	Host        string    `json:"host"`
+	Switch      string    `json:"switch"`
+	Port        string    `json:"port"`
+	Reservation string    `json:"reservation,omitempty"`
+	Expected    string    `json:"expected"`
+	Actual      string    `json:"actual"`
+	Since       time.Time `json:"since"`
+	Repaired    bool      `json:"repaired"`
+	RepairError string    `json:"repairError,omitempty"`
+}
+
+// HealthCheckData is the result of a single host health check.
+type HealthCheckData struct {
+	Host    string    `json:"host"`
+	Check   string    `json:"check"`
+	Type    string    `json:"type"`
+	Passed  bool      `json:"passed"`
+	Detail  string    `json:"detail"`
+	Checked time.Time `json:"checked"`
+}
+
+// HostStatusChange is a single recorded change in a host's power/network status.
+type HostStatusChange struct {
+	Time       time.Time `json:"time"`
+	PrevStatus string    `json:"prevStatus"`
+	Status     string    `json:"status"`
+}
+
+// HostStatusHistoryData is the status change history of a host.
+type HostStatusHistoryData struct {
+	Host     string             `json:"host"`
+	Flapping bool               `json:"flapping"`
+	Changes  []HostStatusChange `json:"changes"`
+}
+
+// HostReliability summarizes the status changes of a host over a period of time.
+type HostReliability struct {
+	Host        string  `json:"host"`
+	Transitions int     `json:"transitions"`
+	Outages     int     `json:"outages"`
+	UpPercent   float64 `json:"upPercent"`
+	Flapping    bool    `json:"flapping"`
 }
 
 type ClusterData struct {
@@ -121,6 +266,14 @@ type UserData struct {
 	JoinDate int64    `json:"joinDate"`
 }
 
+// SSHKeyData describes an SSH public key a user registered with igor.
+type SSHKeyData struct {
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+	Key         string `json:"key"`
+	Created     int64  `json:"created"`
+}
+
 // GroupData is textual information about a group that is most relevant to users.
 type GroupData struct {
 	Name         string   `json:"name"`
@@ -132,6 +285,52 @@ type GroupData struct {
 	Reservations []string `json:"reservations"`
 }
 
+// BootTemplateData describes one version of the boot template of a distro breed. Version 0 is the
+// built-in template.
+type BootTemplateData struct {
+	Breed       string `json:"breed"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Created     int64  `json:"created"`
+	Active      bool   `json:"active"`
+	Template    string `json:"template"`
+}
+
+// BootRenderData is a boot config rendered as a preview. Version is the boot template version used,
+// or -1 for a template that was given with the request.
+type BootRenderData struct {
+	Host        string `json:"host"`
+	Reservation string `json:"reservation"`
+	Breed       string `json:"breed"`
+	BootMode    string `json:"bootMode"`
+	Version     int    `json:"version"`
+	Config      string `json:"config"`
+}
+
+// VlanPoolData describes a range of vlan ids set aside for certain groups or host policies.
+type VlanPoolData struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Min         int      `json:"min"`
+	Max         int      `json:"max"`
+	Groups      []string `json:"groups"`
+	Policies    []string `json:"policies"`
+	Used        int      `json:"used"`
+}
+
+// VlanData describes an allocated vlan id. Named vlans persist until deleted, while unnamed ones are
+// held by reservations and freed when the last of them ends.
+type VlanData struct {
+	Name         string   `json:"name,omitempty"`
+	ID           int      `json:"id"`
+	Pool         string   `json:"pool,omitempty"`
+	Owner        string   `json:"owner,omitempty"`
+	Groups       []string `json:"groups,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Reservations []string `json:"reservations"`
+}
+
 type HostPolicyData struct {
 	Name         string          `json:"name"`
 	Hosts        string          `json:"hosts"`
@@ -148,6 +347,8 @@ type StatsData struct {
 	Records []ResHistory            `json:"records"`
 	ByUser  map[string]ResStatCount `json:"by_user"`
 	Global  ResStatCount            `json:"global"`
+	// Reliability lists hosts that changed status during the stats window, least reliable first
+	Reliability []HostReliability `json:"reliability"`
 }
 
 // ScheduleBlock contains 2 variables:
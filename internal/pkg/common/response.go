@@ -284,6 +284,48 @@ func (rb *ResponseBodyUsers) GetStatus() string {
 	return getStatus(&rb.ResponseBodyBase)
 }
 
+// ResponseBodySSHKeys casts its Data field as SSHKeyData
+type ResponseBodySSHKeys struct {
+	ResponseBodyBase
+	Data map[string][]SSHKeyData `json:"data"`
+}
+
+func NewResponseBodySSHKeys() *ResponseBodySSHKeys {
+	response := &ResponseBodySSHKeys{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string][]SSHKeyData),
+	}
+	return response
+}
+
+func (rb *ResponseBodySSHKeys) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodySSHKeys) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySSHKeys) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySSHKeys) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySSHKeys) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodySSHKeys) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySSHKeys) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
 // ResponseBodyGroups casts its Data field as GroupData
 type ResponseBodyGroups struct {
 	ResponseBodyBase
@@ -370,6 +412,174 @@ func (rb *ResponseBodyPolicies) GetStatus() string {
 	return getStatus(&rb.ResponseBodyBase)
 }
 
+// ResponseBodyBootTemplates casts its Data field as BootTemplateData
+type ResponseBodyBootTemplates struct {
+	ResponseBodyBase
+	Data map[string][]BootTemplateData `json:"data"`
+}
+
+func NewResponseBodyBootTemplates() *ResponseBodyBootTemplates {
+	response := &ResponseBodyBootTemplates{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string][]BootTemplateData),
+	}
+	return response
+}
+
+func (rb *ResponseBodyBootTemplates) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyBootTemplates) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootTemplates) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootTemplates) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootTemplates) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyBootTemplates) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootTemplates) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
+// ResponseBodyBootRender casts its Data field as BootRenderData
+type ResponseBodyBootRender struct {
+	ResponseBodyBase
+	Data map[string]BootRenderData `json:"data"`
+}
+
+func NewResponseBodyBootRender() *ResponseBodyBootRender {
+	response := &ResponseBodyBootRender{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string]BootRenderData),
+	}
+	return response
+}
+
+func (rb *ResponseBodyBootRender) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyBootRender) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootRender) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootRender) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootRender) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyBootRender) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyBootRender) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
+// ResponseBodyVlanPools casts its Data field as VlanPoolData
+type ResponseBodyVlanPools struct {
+	ResponseBodyBase
+	Data map[string][]VlanPoolData `json:"data"`
+}
+
+func NewResponseBodyVlanPools() *ResponseBodyVlanPools {
+	response := &ResponseBodyVlanPools{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string][]VlanPoolData),
+	}
+	return response
+}
+
+func (rb *ResponseBodyVlanPools) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyVlanPools) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlanPools) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlanPools) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlanPools) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyVlanPools) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlanPools) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
+// ResponseBodyVlans casts its Data field as VlanData
+type ResponseBodyVlans struct {
+	ResponseBodyBase
+	Data map[string][]VlanData `json:"data"`
+}
+
+func NewResponseBodyVlans() *ResponseBodyVlans {
+	response := &ResponseBodyVlans{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string][]VlanData),
+	}
+	return response
+}
+
+func (rb *ResponseBodyVlans) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyVlans) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlans) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlans) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlans) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyVlans) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyVlans) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
 // ResponseBodyImages casts its Data field as DistroData
 type ResponseBodyImages struct {
 	ResponseBodyBase
@@ -580,6 +790,90 @@ func (rb *ResponseBodyReservations) GetStatus() string {
 	return getStatus(&rb.ResponseBodyBase)
 }
 
+// ResponseBodyHealth casts its Data field as HealthCheckData lists
+type ResponseBodyHealth struct {
+	ResponseBodyBase
+	Data map[string][]HealthCheckData `json:"data"`
+}
+
+func NewResponseBodyHealth() *ResponseBodyHealth {
+	response := &ResponseBodyHealth{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string][]HealthCheckData),
+	}
+	return response
+}
+
+func (rb *ResponseBodyHealth) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyHealth) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHealth) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHealth) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHealth) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyHealth) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHealth) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
+// ResponseBodyStatusHistory casts its Data field as HostStatusHistoryData
+type ResponseBodyStatusHistory struct {
+	ResponseBodyBase
+	Data map[string]HostStatusHistoryData `json:"data"`
+}
+
+func NewResponseBodyStatusHistory() *ResponseBodyStatusHistory {
+	response := &ResponseBodyStatusHistory{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string]HostStatusHistoryData),
+	}
+	return response
+}
+
+func (rb *ResponseBodyStatusHistory) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyStatusHistory) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyStatusHistory) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyStatusHistory) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyStatusHistory) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyStatusHistory) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyStatusHistory) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
 // ResponseBodyStats casts its Data field as StatsData
 type ResponseBodyStats struct {
 	ResponseBodyBase
@@ -622,6 +916,90 @@ func (rb *ResponseBodyStats) GetStatus() string {
 	return getStatus(&rb.ResponseBodyBase)
 }
 
+// ResponseBodyHostImport casts its Data field as a list of HostImportChange
+type ResponseBodyHostImport struct {
+	ResponseBodyBase
+	Data map[string][]HostImportChange `json:"data"`
+}
+
+func NewResponseBodyHostImport() *ResponseBodyHostImport {
+	response := &ResponseBodyHostImport{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string][]HostImportChange),
+	}
+	return response
+}
+
+func (rb *ResponseBodyHostImport) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodyHostImport) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHostImport) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHostImport) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHostImport) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodyHostImport) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodyHostImport) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
+// ResponseBodySyncStatus casts its Data field as VlanSyncStatusData
+type ResponseBodySyncStatus struct {
+	ResponseBodyBase
+	Data map[string]VlanSyncStatusData `json:"data"`
+}
+
+func NewResponseBodySyncStatus() *ResponseBodySyncStatus {
+	response := &ResponseBodySyncStatus{
+		ResponseBodyBase: NewResponseBodyBase(),
+		Data:             make(map[string]VlanSyncStatusData),
+	}
+	return response
+}
+
+func (rb *ResponseBodySyncStatus) SetStatus(httpCode int) {
+	setStatus(&rb.ResponseBodyBase, httpCode)
+}
+
+func (rb *ResponseBodySyncStatus) IsSuccess() bool {
+	return isSuccess(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySyncStatus) IsFail() bool {
+	return isFail(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySyncStatus) IsError() bool {
+	return isError(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySyncStatus) SetMessage(msg string) {
+	setMessage(&rb.ResponseBodyBase, msg)
+}
+
+func (rb *ResponseBodySyncStatus) GetMessage() string {
+	return getMessage(&rb.ResponseBodyBase)
+}
+
+func (rb *ResponseBodySyncStatus) GetStatus() string {
+	return getStatus(&rb.ResponseBodyBase)
+}
+
 // ResponseBodySync casts its Data field as StatsData
 type ResponseBodySync struct {
 	ResponseBodyBase
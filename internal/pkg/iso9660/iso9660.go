@@ -0,0 +1,334 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+// Package iso9660 is a small read-only ISO9660 reader. It is enough to pull boot files out of distro
+// install media and to serve the media tree over HTTP without mounting it. Rock Ridge names are used
+// when present. Joliet and multi-extent files are not supported.
+package iso9660
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SectorSize is the size of an ISO9660 logical sector.
+	SectorSize = 2048
+
+	vdStart       = 16
+	vdPrimary     = 1
+	vdTerminator  = 255
+	flagDirectory = 0x02
+	maxDepth      = 64
+)
+
+// Image is an ISO9660 file system read from an io.ReaderAt. It implements fs.FS, and the files it
+// opens implement io.Seeker so the tree can be given to http.FS.
+type Image struct {
+	r         io.ReaderAt
+	blockSize int64
+	root      *entry
+}
+
+type entry struct {
+	name    string
+	extent  int64
+	size    int64
+	dir     bool
+	modTime time.Time
+}
+
+// Open reads the primary volume descriptor of the image.
+func Open(r io.ReaderAt) (*Image, error) {
+	buf := make([]byte, SectorSize)
+	for sector := int64(vdStart); ; sector++ {
+		if _, err := r.ReadAt(buf, sector*SectorSize); err != nil {
+			return nil, fmt.Errorf("not an ISO9660 image: %v", err)
+		}
+		if string(buf[1:6]) != "CD001" {
+			return nil, fmt.Errorf("not an ISO9660 image: bad volume descriptor at sector %d", sector)
+		}
+		switch buf[0] {
+		case vdPrimary:
+			img := &Image{r: r, blockSize: int64(binary.LittleEndian.Uint16(buf[128:130]))}
+			if img.blockSize == 0 {
+				img.blockSize = SectorSize
+			}
+			root, _, err := parseRecord(buf[156:190])
+			if err != nil {
+				return nil, err
+			}
+			if root == nil {
+				return nil, fmt.Errorf("not an ISO9660 image: missing root directory record")
+			}
+			root.name = "."
+			img.root = root
+			return img, nil
+		case vdTerminator:
+			return nil, fmt.Errorf("not an ISO9660 image: no primary volume descriptor")
+		}
+	}
+}
+
+// parseRecord decodes the directory record at the start of b and returns it along with its length.
+// A zero length means the rest of the sector is padding.
+func parseRecord(b []byte) (*entry, int, error) {
+	if len(b) == 0 || b[0] == 0 {
+		return nil, 0, nil
+	}
+	n := int(b[0])
+	if n < 34 || n > len(b) {
+		return nil, 0, fmt.Errorf("corrupt directory record")
+	}
+	nameLen := int(b[32])
+	if 33+nameLen > n {
+		return nil, 0, fmt.Errorf("corrupt directory record")
+	}
+	e := &entry{
+		extent:  int64(binary.LittleEndian.Uint32(b[2:6])),
+		size:    int64(binary.LittleEndian.Uint32(b[10:14])),
+		dir:     b[25]&flagDirectory != 0,
+		modTime: recordTime(b[18:25]),
+	}
+	raw := b[33 : 33+nameLen]
+	switch {
+	case nameLen == 1 && raw[0] == 0:
+		e.name = "."
+	case nameLen == 1 && raw[0] == 1:
+		e.name = ".."
+	default:
+		e.name = cleanName(string(raw))
+	}
+	// Rock Ridge alternate name in the system use area
+	su := 33 + nameLen
+	if nameLen%2 == 0 {
+		su++
+	}
+	if su < n {
+		if rr := rockRidgeName(b[su:n]); rr != "" {
+			e.name = rr
+		}
+	}
+	return e, n, nil
+}
+
+// cleanName strips the version suffix and the trailing dot of a file with no extension.
+func cleanName(name string) string {
+	if i := strings.IndexByte(name, ';'); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, ".")
+}
+
+// rockRidgeName returns the name from the NM entries of a system use area, if any.
+func rockRidgeName(su []byte) string {
+	var name string
+	for len(su) >= 4 {
+		l := int(su[2])
+		if l < 4 || l > len(su) {
+			break
+		}
+		if string(su[:2]) == "NM" && l >= 5 {
+			// skip the current and parent directory flags
+			if su[4]&0x06 == 0 {
+				name += string(su[5:l])
+			}
+		}
+		su = su[l:]
+	}
+	return name
+}
+
+func recordTime(b []byte) time.Time {
+	if b[0] == 0 && b[1] == 0 && b[2] == 0 {
+		return time.Time{}
+	}
+	loc := time.FixedZone("", int(int8(b[6]))*15*60)
+	return time.Date(1900+int(b[0]), time.Month(b[1]), int(b[2]), int(b[3]), int(b[4]), int(b[5]), 0, loc)
+}
+
+// readDir returns the entries of a directory, not counting its "." and ".." records.
+func (img *Image) readDir(dir *entry) ([]*entry, error) {
+	data := make([]byte, dir.size)
+	if _, err := img.r.ReadAt(data, dir.extent*img.blockSize); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var entries []*entry
+	for off := 0; off < len(data); {
+		e, n, err := parseRecord(data[off:])
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			// records never cross a sector boundary, so skip the padding to the next one
+			off = (off/SectorSize + 1) * SectorSize
+			continue
+		}
+		off += n
+		if e.name != "." && e.name != ".." {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// lookup finds the entry for a slash-separated path. Names are matched without regard to case since
+// plain ISO9660 names are upper case.
+func (img *Image) lookup(name string) (*entry, error) {
+	e := img.root
+	if name == "." {
+		return e, nil
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) > maxDepth {
+		return nil, fs.ErrNotExist
+	}
+	for _, part := range parts {
+		if !e.dir {
+			return nil, fs.ErrNotExist
+		}
+		entries, err := img.readDir(e)
+		if err != nil {
+			return nil, err
+		}
+		var found *entry
+		for _, c := range entries {
+			if c.name == part {
+				found = c
+				break
+			}
+			if found == nil && strings.EqualFold(c.name, part) {
+				found = c
+			}
+		}
+		if found == nil {
+			return nil, fs.ErrNotExist
+		}
+		e = found
+	}
+	return e, nil
+}
+
+// Open opens the named file or directory in the image.
+func (img *Image) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	e, err := img.lookup(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	f := &File{img: img, e: e, path: name}
+	if !e.dir {
+		f.sr = io.NewSectionReader(img.r, e.extent*img.blockSize, e.size)
+	}
+	return f, nil
+}
+
+// File is an open file or directory of an Image.
+type File struct {
+	sr      *io.SectionReader
+	img     *Image
+	e       *entry
+	path    string
+	entries []*entry
+	dirPos  int
+}
+
+func (f *File) Stat() (fs.FileInfo, error) {
+	return fileInfo{f.e}, nil
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	if f.e.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: errors.New("is a directory")}
+	}
+	return f.sr.Read(p)
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	if f.e.dir {
+		return 0, &fs.PathError{Op: "seek", Path: f.path, Err: errors.New("is a directory")}
+	}
+	return f.sr.Seek(offset, whence)
+}
+
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if f.e.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: errors.New("is a directory")}
+	}
+	return f.sr.ReadAt(p, off)
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+// ReadDir reads the entries of a directory in name order, following the fs.ReadDirFile rules for n.
+func (f *File) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !f.e.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.path, Err: errors.New("not a directory")}
+	}
+	if f.entries == nil {
+		entries, err := f.img.readDir(f.e)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+		f.entries = entries
+	}
+	rest := f.entries[f.dirPos:]
+	if n > 0 {
+		if len(rest) == 0 {
+			return nil, io.EOF
+		}
+		if n < len(rest) {
+			rest = rest[:n]
+		}
+	}
+	f.dirPos += len(rest)
+	result := make([]fs.DirEntry, len(rest))
+	for i, e := range rest {
+		result[i] = fs.FileInfoToDirEntry(fileInfo{e})
+	}
+	return result, nil
+}
+
+type fileInfo struct {
+	e *entry
+}
+
+func (fi fileInfo) Name() string {
+	return path.Base(fi.e.name)
+}
+
+func (fi fileInfo) Size() int64 {
+	return fi.e.size
+}
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.e.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi fileInfo) ModTime() time.Time {
+	return fi.e.modTime
+}
+
+func (fi fileInfo) IsDir() bool {
+	return fi.e.dir
+}
+
+func (fi fileInfo) Sys() any {
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package iso9660
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	name   string
+	rrName string
+	extent int
+	size   int
+	dir    bool
+}
+
+func putRecord(buf []byte, r testRecord) int {
+	name := []byte(r.name)
+	if r.name == "\x00" || r.name == "\x01" {
+		name = []byte{r.name[0]}
+	}
+	n := 33 + len(name)
+	if len(name)%2 == 0 {
+		n++
+	}
+	var su []byte
+	if r.rrName != "" {
+		su = append([]byte{'N', 'M', byte(5 + len(r.rrName)), 1, 0}, r.rrName...)
+	}
+	n += len(su)
+	if n%2 == 1 {
+		n++
+	}
+	buf[0] = byte(n)
+	binary.LittleEndian.PutUint32(buf[2:], uint32(r.extent))
+	binary.BigEndian.PutUint32(buf[6:], uint32(r.extent))
+	binary.LittleEndian.PutUint32(buf[10:], uint32(r.size))
+	binary.BigEndian.PutUint32(buf[14:], uint32(r.size))
+	copy(buf[18:25], []byte{123, 4, 5, 6, 7, 8, 0})
+	if r.dir {
+		buf[25] = flagDirectory
+	}
+	buf[32] = byte(len(name))
+	copy(buf[33:], name)
+	copy(buf[33+len(name)+(1-len(name)%2):], su)
+	return n
+}
+
+func putDir(img []byte, sector, parent int, children []testRecord) {
+	buf := img[sector*SectorSize : (sector+1)*SectorSize]
+	off := putRecord(buf, testRecord{name: "\x00", extent: sector, size: SectorSize, dir: true})
+	off += putRecord(buf[off:], testRecord{name: "\x01", extent: parent, size: SectorSize, dir: true})
+	for _, c := range children {
+		off += putRecord(buf[off:], c)
+	}
+}
+
+// buildTestImage lays out a small image with the same shape as Red Hat install media.
+func buildTestImage() []byte {
+	img := make([]byte, 25*SectorSize)
+
+	pvd := img[16*SectorSize:]
+	pvd[0] = vdPrimary
+	copy(pvd[1:6], "CD001")
+	binary.LittleEndian.PutUint16(pvd[128:], SectorSize)
+	putRecord(pvd[156:], testRecord{name: "\x00", extent: 18, size: SectorSize, dir: true})
+	term := img[17*SectorSize:]
+	term[0] = vdTerminator
+	copy(term[1:6], "CD001")
+
+	putDir(img, 18, 18, []testRecord{
+		{name: "IMAGES", extent: 19, size: SectorSize, dir: true},
+		{name: "README.TXT;1", extent: 20, size: 6},
+	})
+	putDir(img, 19, 18, []testRecord{{name: "PXEBOOT", extent: 21, size: SectorSize, dir: true}})
+	putDir(img, 21, 19, []testRecord{
+		{name: "INITRD.IMG;1", rrName: "initrd.img", extent: 23, size: 7},
+		{name: "VMLINUZ.;1", extent: 22, size: SectorSize + 1},
+	})
+	copy(img[20*SectorSize:], "readme")
+	copy(img[22*SectorSize:], bytes.Repeat([]byte{'k'}, SectorSize+1))
+	copy(img[23*SectorSize:], "initrd!")
+	return img
+}
+
+func TestImage(t *testing.T) {
+	img, err := Open(bytes.NewReader(buildTestImage()))
+	assert.NoError(t, err)
+
+	assert.NoError(t, fstest.TestFS(img, "README.TXT", "IMAGES/PXEBOOT/VMLINUZ", "IMAGES/PXEBOOT/initrd.img"))
+
+	// plain names match in any case, rock ridge names are used as is
+	data, err := fs.ReadFile(img, "images/pxeboot/vmlinuz")
+	assert.NoError(t, err)
+	assert.Len(t, data, SectorSize+1)
+	data, err = fs.ReadFile(img, "images/pxeboot/initrd.img")
+	assert.NoError(t, err)
+	assert.Equal(t, "initrd!", string(data))
+
+	f, err := img.Open("README.TXT")
+	assert.NoError(t, err)
+	_, err = f.(io.Seeker).Seek(2, io.SeekStart)
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(f)
+	assert.Equal(t, "adme", string(data))
+
+	entries, err := fs.ReadDir(img, "images/pxeboot")
+	assert.NoError(t, err)
+	assert.Equal(t, "VMLINUZ", entries[0].Name())
+	assert.Equal(t, "initrd.img", entries[1].Name())
+
+	_, err = img.Open("images/missing")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = img.Open("README.TXT/x")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = Open(bytes.NewReader(make([]byte, 20*SectorSize)))
+	assert.Error(t, err)
+
+	// a zeroed root directory record is rejected rather than read
+	corrupt := buildTestImage()
+	clear(corrupt[16*SectorSize+156 : 16*SectorSize+190])
+	_, err = Open(bytes.NewReader(corrupt))
+	assert.ErrorContains(t, err, "missing root directory record")
+}
@@ -0,0 +1,351 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+// Package tftp is a small read-only TFTP server (RFC 1350) with the blksize, tsize and timeout
+// options (RFC 2347, 2348, 2349) that network boot firmware relies on. Files are always sent as
+// they are, so netascii transfers are not translated.
+package tftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	opRRQ   = 1
+	opWRQ   = 2
+	opDATA  = 3
+	opACK   = 4
+	opERROR = 5
+	opOACK  = 6
+
+	errNotDefined   = 0
+	errNotFound     = 1
+	errAccess       = 2
+	errIllegalOp    = 4
+	errUnknownTID   = 5
+	errBadOption    = 8
+	defaultBlksize  = 512
+	maxBlksize      = 65464
+	defaultTimeout  = time.Second
+	defaultRetries  = 5
+	maxRequestBytes = 1024
+)
+
+// ErrNotFound is returned by a ReadHandler when the requested file doesn't exist.
+var ErrNotFound = errors.New("file not found")
+
+// ReadHandler opens the named file for the client at remote. The returned reader is closed when the
+// transfer ends if it implements io.Closer. ErrNotFound is reported to the client as file not found
+// and any other error as an access violation.
+type ReadHandler func(filename string, remote net.Addr) (io.ReadSeeker, error)
+
+// Transfer describes a finished transfer for logging.
+type Transfer struct {
+	Filename string
+	Remote   net.Addr
+	Bytes    int64
+	Duration time.Duration
+	Err      error
+}
+
+// Server answers TFTP read requests using its Handler.
+type Server struct {
+	Handler ReadHandler
+	// OnTransfer, if set, is called at the end of every transfer whether or not it succeeded.
+	OnTransfer func(Transfer)
+	// Timeout is how long to wait for an acknowledgement before sending a packet again, unless the
+	// client asks for a different timeout. Defaults to one second.
+	Timeout time.Duration
+	// Retries is how many times a packet is sent again before the transfer is abandoned. Defaults to 5.
+	Retries int
+
+	mu     sync.Mutex
+	conn   net.PacketConn
+	closed bool
+}
+
+// ListenAndServe listens on the UDP address and serves requests until Close is called.
+func (s *Server) ListenAndServe(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(conn)
+}
+
+// Serve answers requests arriving on conn until Close is called. Each transfer is carried out from
+// its own port as the protocol requires.
+func (s *Server) Serve(conn net.PacketConn) error {
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	buf := make([]byte, maxRequestBytes)
+	for {
+		n, remote, err := conn.ReadFrom(buf)
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		if n < 2 {
+			continue
+		}
+		pkt := append([]byte(nil), buf[:n]...)
+		switch binary.BigEndian.Uint16(pkt) {
+		case opRRQ:
+			go s.serveRead(conn.LocalAddr(), remote, pkt[2:])
+		case opWRQ:
+			_, _ = conn.WriteTo(errorPacket(errAccess, "server is read-only"), remote)
+		default:
+			_, _ = conn.WriteTo(errorPacket(errIllegalOp, "illegal operation"), remote)
+		}
+	}
+}
+
+// Close stops the server. Transfers already under way are allowed to finish.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// parseRequest splits the body of a request into the filename, mode and options.
+func parseRequest(b []byte) (filename, mode string, opts map[string]string, err error) {
+	fields := strings.Split(string(b), "\x00")
+	if len(fields) < 3 || fields[len(fields)-1] != "" {
+		return "", "", nil, fmt.Errorf("malformed request")
+	}
+	fields = fields[:len(fields)-1]
+	filename, mode = fields[0], strings.ToLower(fields[1])
+	if filename == "" {
+		return "", "", nil, fmt.Errorf("malformed request")
+	}
+	opts = make(map[string]string)
+	for i := 2; i+1 < len(fields); i += 2 {
+		opts[strings.ToLower(fields[i])] = fields[i+1]
+	}
+	return filename, mode, opts, nil
+}
+
+func errorPacket(code uint16, msg string) []byte {
+	b := make([]byte, 4, 5+len(msg))
+	binary.BigEndian.PutUint16(b, opERROR)
+	binary.BigEndian.PutUint16(b[2:], code)
+	b = append(b, msg...)
+	return append(b, 0)
+}
+
+// transfer is the state of one read request.
+type transfer struct {
+	conn    net.PacketConn
+	remote  net.Addr
+	blksize int
+	timeout time.Duration
+	retries int
+	buf     []byte
+}
+
+func (s *Server) serveRead(local, remote net.Addr, req []byte) {
+	start := time.Now()
+	result := Transfer{Remote: remote}
+	defer func() {
+		if s.OnTransfer != nil {
+			result.Duration = time.Since(start)
+			s.OnTransfer(result)
+		}
+	}()
+
+	// reply from a new port on the same address the request arrived on
+	laddr := ":0"
+	if ua, ok := local.(*net.UDPAddr); ok && !ua.IP.IsUnspecified() {
+		laddr = net.JoinHostPort(ua.IP.String(), "0")
+	}
+	conn, err := net.ListenPacket("udp", laddr)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	defer conn.Close()
+
+	t := &transfer{conn: conn, remote: remote, blksize: defaultBlksize, timeout: s.Timeout, retries: s.Retries}
+	if t.timeout <= 0 {
+		t.timeout = defaultTimeout
+	}
+	if t.retries <= 0 {
+		t.retries = defaultRetries
+	}
+
+	filename, mode, opts, err := parseRequest(req)
+	result.Filename = filename
+	if err != nil {
+		result.Err = err
+		t.send(errorPacket(errNotDefined, err.Error()))
+		return
+	}
+	if mode != "octet" && mode != "netascii" {
+		result.Err = fmt.Errorf("unsupported transfer mode %s", mode)
+		t.send(errorPacket(errIllegalOp, result.Err.Error()))
+		return
+	}
+
+	r, err := s.Handler(filename, remote)
+	if err != nil {
+		result.Err = err
+		if errors.Is(err, ErrNotFound) {
+			t.send(errorPacket(errNotFound, "file not found"))
+		} else {
+			t.send(errorPacket(errAccess, "access violation"))
+		}
+		return
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	oack, err := t.negotiate(opts, r)
+	if err != nil {
+		result.Err = err
+		t.send(errorPacket(errBadOption, err.Error()))
+		return
+	}
+	if oack != nil {
+		if err = t.exchange(oack, 0); err != nil {
+			result.Err = err
+			return
+		}
+	}
+	result.Bytes, result.Err = t.sendFile(r)
+}
+
+// negotiate applies the options the server supports and returns the option acknowledgement to send,
+// or nil if the client asked for none of them.
+func (t *transfer) negotiate(opts map[string]string, r io.Seeker) ([]byte, error) {
+	var reply bytes.Buffer
+	add := func(name, val string) {
+		reply.WriteString(name)
+		reply.WriteByte(0)
+		reply.WriteString(val)
+		reply.WriteByte(0)
+	}
+	if v, ok := opts["blksize"]; ok {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 8 {
+			return nil, fmt.Errorf("invalid blksize %s", v)
+		}
+		if size > maxBlksize {
+			size = maxBlksize
+		}
+		t.blksize = size
+		add("blksize", strconv.Itoa(size))
+	}
+	if v, ok := opts["timeout"]; ok {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 1 || secs > 255 {
+			return nil, fmt.Errorf("invalid timeout %s", v)
+		}
+		t.timeout = time.Duration(secs) * time.Second
+		add("timeout", v)
+	}
+	if _, ok := opts["tsize"]; ok {
+		size, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		add("tsize", strconv.FormatInt(size, 10))
+	}
+	if reply.Len() == 0 {
+		return nil, nil
+	}
+	return append([]byte{0, opOACK}, reply.Bytes()...), nil
+}
+
+func (t *transfer) send(pkt []byte) {
+	_, _ = t.conn.WriteTo(pkt, t.remote)
+}
+
+// exchange sends the packet until the client acknowledges the given block number.
+func (t *transfer) exchange(pkt []byte, block uint16) error {
+	if t.buf == nil {
+		t.buf = make([]byte, maxRequestBytes)
+	}
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		t.send(pkt)
+		deadline := time.Now().Add(t.timeout)
+		for {
+			if err := t.conn.SetReadDeadline(deadline); err != nil {
+				return err
+			}
+			n, from, err := t.conn.ReadFrom(t.buf)
+			if err != nil {
+				var ne net.Error
+				if errors.As(err, &ne) && ne.Timeout() {
+					break
+				}
+				return err
+			}
+			if from.String() != t.remote.String() {
+				// someone else's packet on our port
+				_, _ = t.conn.WriteTo(errorPacket(errUnknownTID, "unknown transfer id"), from)
+				continue
+			}
+			if n < 4 {
+				continue
+			}
+			switch binary.BigEndian.Uint16(t.buf) {
+			case opACK:
+				if binary.BigEndian.Uint16(t.buf[2:]) == block {
+					return nil
+				}
+				// a late acknowledgement of an earlier block, keep waiting
+			case opERROR:
+				return fmt.Errorf("client error %d: %s", binary.BigEndian.Uint16(t.buf[2:]), strings.TrimRight(string(t.buf[4:n]), "\x00"))
+			}
+		}
+	}
+	return fmt.Errorf("timed out waiting for acknowledgement of block %d", block)
+}
+
+// sendFile sends the file one block at a time. A final block shorter than the block size, possibly
+// empty, marks the end. Block numbers wrap around for files of more than 65535 blocks.
+func (t *transfer) sendFile(r io.Reader) (int64, error) {
+	pkt := make([]byte, 4+t.blksize)
+	binary.BigEndian.PutUint16(pkt, opDATA)
+	var sent int64
+	for block := uint16(1); ; block++ {
+		n, err := io.ReadFull(r, pkt[4:])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.send(errorPacket(errNotDefined, "read error"))
+			return sent, err
+		}
+		binary.BigEndian.PutUint16(pkt[2:], block)
+		if err = t.exchange(pkt[:4+n], block); err != nil {
+			return sent, err
+		}
+		sent += int64(n)
+		if n < t.blksize {
+			return sent, nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 National Technology & Engineering Solutions of Sandia, LLC (NTESS).
+// Under the terms of Contract DE-NA0003525 with NTESS, the U.S. Government retains
+// certain rights in this software.
+
+package tftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startTestServer(t *testing.T, files map[string][]byte, done chan Transfer) string {
+	s := &Server{
+		Handler: func(filename string, remote net.Addr) (io.ReadSeeker, error) {
+			data, ok := files[filename]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			return bytes.NewReader(data), nil
+		},
+		OnTransfer: func(tr Transfer) { done <- tr },
+		Timeout:    200 * time.Millisecond,
+	}
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = s.Serve(conn) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return conn.LocalAddr().String()
+}
+
+func request(op uint16, fields ...string) []byte {
+	b := []byte{0, byte(op)}
+	for _, f := range fields {
+		b = append(b, f...)
+		b = append(b, 0)
+	}
+	return b
+}
+
+func ack(block uint16) []byte {
+	return []byte{0, opACK, byte(block >> 8), byte(block)}
+}
+
+// fetch reads a whole file the way a boot client does, acknowledging each packet.
+func fetch(t *testing.T, server string, fields ...string) (data []byte, oack string, errMsg string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+	srv, _ := net.ResolveUDPAddr("udp", server)
+	_, err = conn.WriteTo(request(opRRQ, fields...), srv)
+	assert.NoError(t, err)
+
+	buf := make([]byte, 70000)
+	blksize := defaultBlksize
+	for {
+		assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		n, from, err := conn.ReadFrom(buf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		switch binary.BigEndian.Uint16(buf) {
+		case opOACK:
+			oack = strings.ReplaceAll(strings.TrimRight(string(buf[2:n]), "\x00"), "\x00", " ")
+			if i := strings.Index(oack, "blksize "); i >= 0 {
+				blksize = 0
+				for _, c := range oack[i+8:] {
+					if c < '0' || c > '9' {
+						break
+					}
+					blksize = blksize*10 + int(c-'0')
+				}
+			}
+			_, _ = conn.WriteTo(ack(0), from)
+		case opDATA:
+			data = append(data, buf[4:n]...)
+			_, _ = conn.WriteTo(ack(binary.BigEndian.Uint16(buf[2:])), from)
+			if n-4 < blksize {
+				return
+			}
+		case opERROR:
+			return nil, "", strings.TrimRight(string(buf[4:n]), "\x00")
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	done := make(chan Transfer, 10)
+	files := map[string][]byte{
+		"pxelinux.cfg/default": []byte("DEFAULT local\n"),
+		"kernel":               bytes.Repeat([]byte("k"), 3*512),
+	}
+	server := startTestServer(t, files, done)
+
+	data, oack, errMsg := fetch(t, server, "pxelinux.cfg/default", "octet")
+	assert.Equal(t, "", errMsg)
+	assert.Equal(t, "", oack)
+	assert.Equal(t, files["pxelinux.cfg/default"], data)
+	tr := <-done
+	assert.NoError(t, tr.Err)
+	assert.Equal(t, int64(14), tr.Bytes)
+	assert.Equal(t, "pxelinux.cfg/default", tr.Filename)
+
+	// an exact multiple of the block size ends with an empty block
+	data, _, _ = fetch(t, server, "kernel", "octet")
+	assert.Equal(t, files["kernel"], data)
+	assert.NoError(t, (<-done).Err)
+
+	data, oack, _ = fetch(t, server, "kernel", "octet", "tsize", "0", "blksize", "1000")
+	assert.Equal(t, "blksize 1000 tsize 1536", oack)
+	assert.Equal(t, files["kernel"], data)
+	assert.Equal(t, int64(1536), (<-done).Bytes)
+
+	_, _, errMsg = fetch(t, server, "missing", "octet")
+	assert.Equal(t, "file not found", errMsg)
+	assert.ErrorIs(t, (<-done).Err, ErrNotFound)
+
+	_, _, errMsg = fetch(t, server, "kernel", "mail")
+	assert.Contains(t, errMsg, "unsupported transfer mode")
+	<-done
+}
+
+func TestServerRejectsWrites(t *testing.T) {
+	server := startTestServer(t, nil, make(chan Transfer, 1))
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+	srv, _ := net.ResolveUDPAddr("udp", server)
+	_, _ = conn.WriteTo(request(opWRQ, "upload", "octet"), srv)
+
+	buf := make([]byte, 100)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(opERROR), binary.BigEndian.Uint16(buf))
+	assert.Equal(t, uint16(errAccess), binary.BigEndian.Uint16(buf[2:n]))
+}
+
+func TestParseRequest(t *testing.T) {
+	name, mode, opts, err := parseRequest([]byte("a/b\x00OCTET\x00BLKSIZE\x001468\x00"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a/b", name)
+	assert.Equal(t, "octet", mode)
+	assert.Equal(t, map[string]string{"blksize": "1468"}, opts)
+
+	_, _, _, err = parseRequest([]byte("a/b\x00octet"))
+	assert.Error(t, err)
+	_, _, _, err = parseRequest([]byte("\x00octet\x00"))
+	assert.Error(t, err)
+}